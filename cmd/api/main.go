@@ -1,59 +1,273 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
+	"os"
+	_ "time/tzdata" // time zones for images without a zoneinfo database
 
 	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/auth"
 	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/database"
+	"github.com/todo-api-go-sda/internal/database/migrations"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/graph"
+	"github.com/todo-api-go-sda/internal/grpcapi"
 	"github.com/todo-api-go-sda/internal/handlers"
-	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/realtime"
 	"github.com/todo-api-go-sda/internal/repository"
 	"github.com/todo-api-go-sda/internal/services"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.Load()
 
-	// Connect to database
-	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{})
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	// Run the migrate subcommand instead of the server when requested
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(&cfg.Database, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if cfg.Auth.JWTAlgorithm == "HS256" && cfg.Auth.JWTSecret == "" {
+		log.Printf("JWT_SECRET is not set; using a random secret, issued tokens will not survive a restart")
+	}
+	if cfg.Server.CursorSecret == "" {
+		log.Printf("CURSOR_SECRET is not set; using a random secret, page cursors will not survive a restart")
+	}
+
+	// Initialize storage
+	var (
+		taskRepo        repository.TaskRepository
+		projectRepo     repository.ProjectRepository
+		tagRepo         repository.TagRepository
+		userRepo        repository.UserRepository
+		apiTokenRepo    repository.APITokenRepository
+		idempotencyRepo repository.IdempotencyRepository
+		webhookRepo     repository.WebhookRepository
+	)
+	bus := events.NewBus(cfg.Events.ReplayBufferSize)
+	if cfg.Events.PostgresBridge && cfg.Database.Driver != config.DriverPostgres {
+		log.Printf("EVENTS_POSTGRES_BRIDGE requires the postgres driver; task events reach this replica's clients only")
 	}
+	if cfg.Database.Driver == config.DriverMemory {
+		taskRepo = repository.NewMemoryTaskRepository()
+		projectRepo = repository.NewMemoryProjectRepository(taskRepo)
+		tagRepo = repository.NewMemoryTagRepository(taskRepo)
+		userRepo = repository.NewMemoryUserRepository()
+		apiTokenRepo = repository.NewMemoryAPITokenRepository()
+		idempotencyRepo = repository.NewMemoryIdempotencyRepository()
+		webhookRepo = repository.NewMemoryWebhookRepository(taskRepo)
+	} else {
+		// Connect to database
+		db, err := database.Open(&cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
 
-	// Auto-migrate the schema
-	if err := db.AutoMigrate(&models.Task{}); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+		// Check the schema version, migrating only when allowed
+		migrator, err := migrations.New(db)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		if err := ensureSchema(migrator, cfg.Database.AutoMigrate); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+
+		taskRepo = repository.NewTaskRepository(db)
+		projectRepo = repository.NewProjectRepository(db)
+		tagRepo = repository.NewTagRepository(db)
+		userRepo = repository.NewUserRepository(db)
+		apiTokenRepo = repository.NewAPITokenRepository(db)
+		idempotencyRepo = repository.NewIdempotencyRepository(db)
+		webhookRepo = repository.NewWebhookRepository(db)
+
+		// Fan task events out to every replica
+		if cfg.Events.PostgresBridge && cfg.Database.Driver == config.DriverPostgres {
+			bridge := events.NewPostgresBridge(db, cfg.Database.DSN(), cfg.Events.PostgresChannel, bus)
+			go bridge.Run(context.Background())
+		}
+	}
+
+	// Initialize token signing
+	tokens, err := auth.NewTokenManager(&cfg.Auth)
+	if err != nil {
+		log.Fatalf("Failed to initialize token signing: %v", err)
 	}
 
 	// Initialize dependencies
-	taskRepo := repository.NewTaskRepository(db)
-	taskService := services.NewTaskService(taskRepo)
-	taskHandler := handlers.NewTaskHandler(taskService)
+	taskService := services.NewTaskService(taskRepo, projectRepo, tagRepo, &cfg.Tasks, bus)
+	taskHandler := handlers.NewTaskHandler(taskService, &cfg.Server)
+	projectService := services.NewProjectService(projectRepo)
+	projectHandler := handlers.NewProjectHandler(projectService)
+	tagService := services.NewTagService(tagRepo)
+	tagHandler := handlers.NewTagHandler(tagService)
+	authService := services.NewAuthService(userRepo, tokens)
+	authHandler := handlers.NewAuthHandler(authService)
+	apiTokenService := services.NewAPITokenService(apiTokenRepo)
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenService)
+	requireAuth := middleware.RequireAuth(tokens, apiTokenService)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, &cfg.Idempotency)
+	webhookService := services.NewWebhookService(webhookRepo)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, &cfg.Server)
+	eventHandler := handlers.NewEventHandler(bus, &cfg.Events)
+	realtimeHandler := handlers.NewRealtimeHandler(realtime.NewHub(taskService, projectService, bus, &cfg.Events))
+	schema, err := graph.NewSchema(taskService, projectService, &cfg.Server, &cfg.GraphQL)
+	if err != nil {
+		log.Fatalf("Failed to build the GraphQL schema: %v", err)
+	}
+	graphqlHandler := handlers.NewGraphQLHandler(schema)
+
+	// Purge expired tasks from the trash in the background
+	if cfg.Tasks.TrashRetention > 0 && cfg.Tasks.TrashPurgeInterval > 0 {
+		go services.NewTrashPurger(taskRepo, &cfg.Tasks, bus).Run(context.Background())
+	}
+
+	// Purge expired Idempotency-Keys in the background
+	if cfg.Idempotency.PurgeInterval > 0 {
+		go services.RunPurge(context.Background(), cfg.Idempotency.PurgeInterval, "expired idempotency keys", idempotencyService.Purge)
+	}
+
+	// Deliver task events to webhooks in the background
+	if cfg.Webhooks.DeliveryInterval > 0 {
+		go services.NewWebhookDispatcher(webhookRepo, &cfg.Webhooks).Run(context.Background())
+	}
 
 	// Setup Gin router
 	router := gin.Default()
+	router.Use(middleware.AssignRequestID())
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
-		tasks := v1.Group("/tasks")
+		authRoutes := v1.Group("/auth")
 		{
-			tasks.POST("", taskHandler.CreateTask)
-			tasks.GET("", taskHandler.ListTasks)
-			tasks.GET("/:id", taskHandler.GetTask)
-			tasks.PUT("/:id", taskHandler.UpdateTask)
-			tasks.DELETE("/:id", taskHandler.DeleteTask)
+			authRoutes.POST("/register", authHandler.Register)
+			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/refresh", authHandler.Refresh)
 		}
-	}
 
-	router.GET("/tasks/search", func(c *gin.Context) {
-		c.JSON(200, gin.H{"message": "search not implemented"})
-	})
+		// Personal access tokens can only be managed from a login session
+		apiTokens := v1.Group("/tokens", requireAuth, middleware.RequireSession())
+		{
+			apiTokens.POST("", apiTokenHandler.CreateToken)
+			apiTokens.GET("", apiTokenHandler.ListTokens)
+			apiTokens.DELETE("/:id", apiTokenHandler.RevokeToken)
+		}
+
+		// Account settings can be read with any credential but only changed
+		// from a login session
+		me := v1.Group("/me", requireAuth)
+		{
+			me.GET("", authHandler.GetProfile)
+			me.PUT("", middleware.RequireSession(), authHandler.UpdateProfile)
+		}
+
+		read := middleware.RequireScope(auth.ScopeTasksRead)
+		write := middleware.RequireScope(auth.ScopeTasksWrite)
+		del := middleware.RequireScope(auth.ScopeTasksDelete)
+		// POST requests below can be retried safely with an Idempotency-Key.
+		// Token creation is left out so that new tokens are never stored.
+		idempotent := middleware.Idempotency(idempotencyService)
+		// The board UI's realtime channel
+		v1.GET("/ws", requireAuth, middleware.ResolveTimeZone(authService), read, realtimeHandler.Connect)
+		// The GraphQL API checks the write and delete scopes of mutations
+		v1.POST("/graphql", requireAuth, middleware.ResolveTimeZone(authService), read, idempotent, graphqlHandler.Query)
+
+		tasks := v1.Group("/tasks", requireAuth, middleware.ResolveTimeZone(authService), idempotent)
+		{
+			tasks.POST("", write, taskHandler.CreateTask)
+			tasks.POST("/bulk", write, taskHandler.BulkTasks)
+			tasks.POST("/bulk/complete", write, taskHandler.CompleteMatchingTasks)
+			tasks.POST("/bulk/delete", del, taskHandler.DeleteMatchingTasks)
+			tasks.GET("", read, taskHandler.ListTasks)
+			tasks.GET("/search", read, taskHandler.SearchTasks)
+			tasks.GET("/events", read, eventHandler.Stream)
+			tasks.GET("/overdue", read, taskHandler.OverdueTasks)
+			tasks.GET("/today", read, taskHandler.TodayTasks)
+			tasks.GET("/upcoming", read, taskHandler.UpcomingTasks)
+			tasks.GET("/:id", read, taskHandler.GetTask)
+			tasks.GET("/:id/subtasks", read, taskHandler.ListSubtasks)
+			tasks.GET("/:id/occurrences", read, taskHandler.PreviewOccurrences)
+			tasks.PUT("/:id", write, taskHandler.UpdateTask)
+			tasks.PATCH("/:id", write, taskHandler.PatchTask)
+			tasks.DELETE("/:id/recurrence", write, taskHandler.EndSeries)
+			tasks.DELETE("/:id", del, taskHandler.DeleteTask)
+			tasks.POST("/:id/restore", write, taskHandler.RestoreTask)
+			tasks.GET("/:id/history", read, taskHandler.TaskHistory)
+			tasks.POST("/:id/revert", write, taskHandler.RevertTask)
+		}
+
+		// The audit log lists the history of all of the user's tasks
+		audit := v1.Group("/audit", requireAuth)
+		{
+			audit.GET("", read, taskHandler.ListAudit)
+		}
+
+		// Deleted tasks wait in the trash until restored or purged
+		trash := v1.Group("/trash", requireAuth, idempotent)
+		{
+			trash.GET("", read, taskHandler.ListTrash)
+			trash.DELETE("/:id", del, taskHandler.DeleteTrashedTask)
+		}
+
+		// Projects organize tasks and share the task scopes
+		projects := v1.Group("/projects", requireAuth, idempotent)
+		{
+			projects.POST("", write, projectHandler.CreateProject)
+			projects.GET("", read, projectHandler.ListProjects)
+			projects.GET("/:id", read, projectHandler.GetProject)
+			projects.GET("/:id/tasks", read, taskHandler.ListProjectTasks)
+			projects.PUT("/:id", write, projectHandler.UpdateProject)
+			projects.DELETE("/:id", del, projectHandler.DeleteProject)
+		}
+
+		// Webhooks are notified of changes to tasks and share the task scopes
+		webhooks := v1.Group("/webhooks", requireAuth, idempotent)
+		{
+			webhooks.POST("", write, webhookHandler.CreateWebhook)
+			webhooks.GET("", read, webhookHandler.ListWebhooks)
+			webhooks.GET("/dead-letters", read, webhookHandler.ListDeadLetters)
+			webhooks.GET("/:id", read, webhookHandler.GetWebhook)
+			webhooks.PATCH("/:id", write, webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", del, webhookHandler.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", read, webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", write, webhookHandler.Redeliver)
+		}
 
-	//... router.Run()...
+		// Tags are created through tasks and share the task scopes
+		tags := v1.Group("/tags", requireAuth, idempotent)
+		{
+			tags.GET("", read, tagHandler.ListTags)
+			tags.PUT("/:id", write, tagHandler.RenameTag)
+			tags.POST("/:id/merge", write, tagHandler.MergeTag)
+			tags.DELETE("/:id", del, tagHandler.DeleteTag)
+		}
+	}
+
+	// Serve the gRPC API on its own port
+	if cfg.Server.GRPCPort != "" {
+		listener, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		authenticator := grpcapi.NewAuthenticator(tokens, apiTokenService, authService)
+		grpcServer := grpcapi.NewServer(taskService, bus, authenticator, &cfg.Server, &cfg.Events)
+		log.Printf("Starting gRPC server on port %s", cfg.Server.GRPCPort)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
 
 	// Start server
 	log.Printf("Starting server on port %s", cfg.Server.Port)
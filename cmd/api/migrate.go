@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/database"
+	"github.com/todo-api-go-sda/internal/database/migrations"
+)
+
+// migrateUsage describes the migrate subcommand
+const migrateUsage = "usage: migrate up|down|status|to N"
+
+// runMigrate executes the migrate subcommand with the given arguments
+func runMigrate(cfg *config.DatabaseConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	if cfg.Driver == config.DriverMemory {
+		return fmt.Errorf("the %s driver has no schema to migrate", config.DriverMemory)
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up()
+	case "down":
+		return migrator.Down()
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return migrator.To(version)
+	case "status":
+		return printStatus(migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// printStatus writes a table of migrations and when they were applied
+func printStatus(migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return w.Flush()
+}
+
+// ensureSchema verifies the database schema is current before serving,
+// applying pending migrations only when auto-migrate is enabled
+func ensureSchema(migrator *migrations.Migrator, autoMigrate bool) error {
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+
+	latest := migrator.Latest()
+	switch {
+	case version == latest:
+		return nil
+	case version > latest:
+		return fmt.Errorf("database schema version %d is newer than the latest known migration %d", version, latest)
+	case !autoMigrate:
+		return fmt.Errorf(
+			"database schema is at version %d but version %d is required; run `%s migrate up` or set DB_AUTO_MIGRATE=true",
+			version, latest, os.Args[0],
+		)
+	}
+	return migrator.Up()
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/todo-api-go-sda/pkg/client"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// defaultListLimit is the number of tasks todo ls prints by default
+const defaultListLimit = 50
+
+// maxPageSize is the size of the pages todo ls fetches
+const maxPageSize = 100
+
+// maxWriteAttempts bounds how often todo done retries a task that another
+// client changed in the meantime
+const maxWriteAttempts = 3
+
+// addCommand builds todo add
+func (a *app) addCommand() *cobra.Command {
+	var (
+		due        string
+		tags       []string
+		priority   string
+		projectID  uint
+		parentID   uint
+		recurrence string
+	)
+	cmd := &cobra.Command{
+		Use:   "add TEXT...",
+		Short: "Add a task",
+		Example: `  todo add "Renew passport" --due tomorrow --tag admin
+  todo add Water the plants --due "sat 10:00" --every FREQ=WEEKLY`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &client.CreateTaskRequest{
+				Content:    strings.Join(args, " "),
+				Tags:       tags,
+				Priority:   priority,
+				Recurrence: recurrence,
+			}
+			if cmd.Flags().Changed("due") {
+				dueAt, err := parseDue(due, a.now().In(a.loc))
+				if err != nil {
+					return err
+				}
+				req.DueAt = &dueAt
+			}
+			if cmd.Flags().Changed("project") {
+				req.ProjectID = &projectID
+			}
+			if cmd.Flags().Changed("parent") {
+				req.ParentID = &parentID
+			}
+
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			task, err := c.CreateTask(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return a.printTask(task)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&due, "due", "", "due date: today, tomorrow, a weekday, +3d, +2w or a date, optionally with a time such as 9:00")
+	flags.StringArrayVarP(&tags, "tag", "t", nil, "tag the task; repeat for more tags")
+	flags.StringVarP(&priority, "priority", "p", "", "priority: none, low, medium, high or urgent")
+	flags.UintVar(&projectID, "project", 0, "ID of the project of the task")
+	flags.UintVar(&parentID, "parent", 0, "ID of the task to add a subtask to")
+	flags.StringVar(&recurrence, "every", "", "RFC 5545 recurrence rule such as FREQ=WEEKLY;BYDAY=MO; requires --due")
+	_ = cmd.RegisterFlagCompletionFunc("priority", cobra.FixedCompletions(priorities, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// priorities are the names of the task priorities
+var priorities = []string{"none", "low", "medium", "high", "urgent"}
+
+// listCommand builds todo ls
+func (a *app) listCommand() *cobra.Command {
+	var (
+		done      bool
+		tags      []string
+		anyTag    bool
+		projectID uint
+		parentID  uint
+		search    string
+		sort      string
+		limit     int
+	)
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List tasks",
+		Example: `  todo ls --done=false
+  todo ls --tag work --sort -priority,due_at --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 0 {
+				return fmt.Errorf("--limit must be 0 or more")
+			}
+			opts := &client.ListTasksOptions{Tags: tags, ContentContains: search, Sort: sort, PageSize: maxPageSize}
+			if limit > 0 {
+				opts.PageSize = min(limit, maxPageSize)
+			}
+			if cmd.Flags().Changed("done") {
+				opts.Completed = &done
+			}
+			if anyTag {
+				opts.TagMode = "any"
+			}
+			if cmd.Flags().Changed("project") {
+				opts.ProjectID = &projectID
+			}
+			if cmd.Flags().Changed("parent") {
+				opts.ParentID = &parentID
+			}
+
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			var tasks []client.Task
+			pages := c.ListTasks(cmd.Context(), opts)
+			for (limit == 0 || len(tasks) < limit) && pages.Next() {
+				tasks = append(tasks, pages.Page().Tasks...)
+			}
+			if err := pages.Err(); err != nil {
+				return err
+			}
+			if limit > 0 && len(tasks) > limit {
+				tasks = tasks[:limit]
+			}
+			return a.printTasks(tasks)
+		},
+	}
+	flags := cmd.Flags()
+	flags.BoolVar(&done, "done", false, "only completed tasks, or with --done=false only open ones")
+	flags.StringArrayVarP(&tags, "tag", "t", nil, "only tasks with the tag; repeat for tasks with all the tags")
+	flags.BoolVar(&anyTag, "any", false, "with several --tag, tasks with any of the tags")
+	flags.UintVar(&projectID, "project", 0, "only the tasks of a project, or of the inbox with 0")
+	flags.UintVar(&parentID, "parent", 0, "only the subtasks of a task, or top-level tasks with 0")
+	flags.StringVarP(&search, "search", "s", "", "only tasks whose content contains the text")
+	flags.StringVar(&sort, "sort", "", "comma-separated sort fields, each prefixed with - for descending order, such as -priority,due_at")
+	flags.IntVarP(&limit, "limit", "n", defaultListLimit, "print at most this many tasks; 0 prints all")
+	return cmd
+}
+
+// doneCommand builds todo done
+func (a *app) doneCommand() *cobra.Command {
+	var subtasks bool
+	cmd := &cobra.Command{
+		Use:               "done ID...",
+		Short:             "Complete tasks",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: a.completeTaskIDs(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseTaskIDs(args)
+			if err != nil {
+				return err
+			}
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			var tasks []client.Task
+			for _, id := range ids {
+				task, err := completeTask(cmd.Context(), c, id, subtasks)
+				if err != nil {
+					return err
+				}
+				tasks = append(tasks, *task)
+			}
+			if len(tasks) == 1 {
+				return a.printTask(&tasks[0])
+			}
+			return a.printTasks(tasks)
+		},
+	}
+	cmd.Flags().BoolVar(&subtasks, "subtasks", false, "complete the subtasks of the tasks too")
+	return cmd
+}
+
+// completeTask marks a task completed. The write is conditional on the
+// version that was read, and is retried on the current task when another
+// client changed it in the meantime.
+func completeTask(ctx context.Context, c *client.Client, id uint, subtasks bool) (*client.Task, error) {
+	task, err := c.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		doc := client.NewTaskDocument(task)
+		doc.Completed = true
+		updated, err := c.UpdateTask(ctx, id, &client.ReplaceTaskRequest{TaskDocument: doc, CompleteSubtasks: subtasks}, client.IfVersion(task.Version))
+		var conflict *apperrors.VersionConflictError
+		if attempt < maxWriteAttempts && errors.As(err, &conflict) {
+			if current, ok := conflict.Current.(*client.Task); ok {
+				task = current
+				continue
+			}
+		}
+		return updated, err
+	}
+}
+
+// editCommand builds todo edit
+func (a *app) editCommand() *cobra.Command {
+	var (
+		content    string
+		due        string
+		tags       []string
+		priority   string
+		projectID  uint
+		parentID   uint
+		recurrence string
+		undone     bool
+	)
+	cmd := &cobra.Command{
+		Use:   "edit ID",
+		Short: "Edit a task with flags, or in $EDITOR without them",
+		Long: `Edit a task. The flags change the fields they name; without flags the task
+is opened as JSON in $VISUAL or $EDITOR. The task is only saved if nobody
+changed it in the meantime.`,
+		Example: `  todo edit 42 --due "fri 17:00" --priority high
+  todo edit 42 --due none --tag home --tag garden
+  todo edit 42`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeTaskIDs(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseTaskIDs(args)
+			if err != nil {
+				return err
+			}
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			task, err := c.GetTask(cmd.Context(), ids[0])
+			if err != nil {
+				return err
+			}
+
+			doc := client.NewTaskDocument(task)
+			flags := cmd.Flags()
+			if !localFlagsChanged(cmd) {
+				edited, changed, err := a.editDocument(&doc)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					fmt.Fprintln(a.stderr, "Task unchanged")
+					return nil
+				}
+				doc = *edited
+			}
+			if flags.Changed("content") {
+				doc.Content = content
+			}
+			if flags.Changed("due") {
+				doc.DueAt = nil
+				if !strings.EqualFold(due, dueNone) {
+					dueAt, err := parseDue(due, a.now().In(a.loc))
+					if err != nil {
+						return err
+					}
+					doc.DueAt = &dueAt
+				}
+			}
+			if flags.Changed("tag") {
+				doc.Tags = tags
+			}
+			if flags.Changed("priority") {
+				doc.Priority = priority
+			}
+			if flags.Changed("project") {
+				doc.ProjectID = optionalID(projectID)
+			}
+			if flags.Changed("parent") {
+				doc.ParentID = optionalID(parentID)
+			}
+			if flags.Changed("every") {
+				doc.Recurrence = recurrence
+			}
+			if undone {
+				doc.Completed = false
+			}
+
+			updated, err := c.UpdateTask(cmd.Context(), task.ID, &client.ReplaceTaskRequest{TaskDocument: doc}, client.IfVersion(task.Version))
+			if err != nil {
+				return err
+			}
+			return a.printTask(updated)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&content, "content", "c", "", "new text of the task")
+	flags.StringVar(&due, "due", "", "new due date, like todo add --due, or none to remove it")
+	flags.StringArrayVarP(&tags, "tag", "t", nil, "replace the tags of the task; repeat for more tags")
+	flags.StringVarP(&priority, "priority", "p", "", "priority: none, low, medium, high or urgent")
+	flags.UintVar(&projectID, "project", 0, "move the task to a project, or to the inbox with 0")
+	flags.UintVar(&parentID, "parent", 0, "make the task a subtask of another, or a top-level task with 0")
+	flags.StringVar(&recurrence, "every", "", "RFC 5545 recurrence rule, or an empty rule to end the series")
+	flags.BoolVar(&undone, "undone", false, "reopen a completed task")
+	_ = cmd.RegisterFlagCompletionFunc("priority", cobra.FixedCompletions(priorities, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// localFlagsChanged reports whether any flag of the command itself, rather
+// than a global flag, was set
+func localFlagsChanged(cmd *cobra.Command) bool {
+	changed := false
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		changed = changed || flag.Changed
+	})
+	return changed
+}
+
+// editDocument opens the document of a task in the user's editor and
+// returns the edited document, reporting whether it changed
+func (a *app) editDocument(doc *client.TaskDocument) (*client.TaskDocument, bool, error) {
+	original, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	file, err := os.CreateTemp("", "todo-*.json")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(append(original, '\n')); err != nil {
+		file.Close()
+		return nil, false, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, false, err
+	}
+
+	editor := strings.Fields(firstSet(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi"))
+	run := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	run.Stdin, run.Stdout, run.Stderr = a.stdin, a.stdout, a.stderr
+	if err := run.Run(); err != nil {
+		return nil, false, fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return nil, false, err
+	}
+	if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)) {
+		return doc, false, nil
+	}
+	var result client.TaskDocument
+	decoder := json.NewDecoder(bytes.NewReader(edited))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, false, fmt.Errorf("the edited task is not valid: %w", err)
+	}
+	return &result, true, nil
+}
+
+// removeCommand builds todo rm
+func (a *app) removeCommand() *cobra.Command {
+	var cascade bool
+	cmd := &cobra.Command{
+		Use:               "rm ID...",
+		Aliases:           []string{"delete"},
+		Short:             "Move tasks to the trash",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: a.completeTaskIDs(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseTaskIDs(args)
+			if err != nil {
+				return err
+			}
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			var opts []client.WriteOption
+			if cascade {
+				opts = append(opts, client.Cascade())
+			}
+			for _, id := range ids {
+				if err := c.DeleteTask(cmd.Context(), id, opts...); err != nil {
+					return err
+				}
+				if a.format() != outputJSON {
+					fmt.Fprintf(a.stdout, "Moved task %d to the trash\n", id)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&cascade, "cascade", false, "delete the subtasks of the tasks too")
+	return cmd
+}
+
+// completeTaskIDs completes the IDs of the user's tasks, described by their
+// content: open tasks only, or all tasks when all is set
+func (a *app) completeTaskIDs(all bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if a.cfg == nil {
+			if err := a.load(); err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+		}
+		c, err := a.client()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		opts := &client.ListTasksOptions{PageSize: maxPageSize}
+		if !all {
+			open := false
+			opts.Completed = &open
+		}
+		pages := c.ListTasks(cmd.Context(), opts)
+		if !pages.Next() {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var completions []cobra.Completion
+		for _, task := range pages.Page().Tasks {
+			id := strconv.FormatUint(uint64(task.ID), 10)
+			if strings.HasPrefix(id, toComplete) && !slices.Contains(args, id) {
+				completions = append(completions, cobra.CompletionWithDesc(id, strings.Join(strings.Fields(task.Content), " ")))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// parseTaskIDs parses task IDs given as arguments
+func parseTaskIDs(args []string) ([]uint, error) {
+	ids := make([]uint, len(args))
+	for i, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 32)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("%q is not a task ID", arg)
+		}
+		ids[i] = uint(id)
+	}
+	return ids, nil
+}
+
+// optionalID returns nil for the ID 0, which stands for no project or parent
+func optionalID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/todo-api-go-sda/pkg/client"
+)
+
+// defaultServer is the server used when none is configured
+const defaultServer = "http://localhost:8080"
+
+// Environment variables overriding the config file
+const (
+	envConfig = "TODO_CONFIG"
+	envServer = "TODO_SERVER"
+	envToken  = "TODO_TOKEN"
+)
+
+// config is the config file of the CLI. TimeZone is the IANA time zone
+// that due dates are given in; it defaults to the user's time zone on the
+// server. Output is the default output format, table or json.
+type config struct {
+	Server   string `json:"server,omitempty"`
+	Token    string `json:"token,omitempty"`
+	TimeZone string `json:"time_zone,omitempty"`
+	Output   string `json:"output,omitempty"`
+}
+
+// configKeys validates the values of each key of the config file and sets
+// them
+var configKeys = map[string]func(cfg *config, value string) error{
+	"server": func(cfg *config, value string) error {
+		if _, err := client.New(value); err != nil {
+			return fmt.Errorf("server must be an http or https URL such as %s", defaultServer)
+		}
+		cfg.Server = value
+		return nil
+	},
+	"token": func(cfg *config, value string) error {
+		cfg.Token = value
+		return nil
+	},
+	"time_zone": func(cfg *config, value string) error {
+		if value != "" {
+			if _, err := time.LoadLocation(value); err != nil {
+				return fmt.Errorf("time_zone must be an IANA time zone such as Europe/Paris")
+			}
+		}
+		cfg.TimeZone = value
+		return nil
+	},
+	"output": func(cfg *config, value string) error {
+		if value != "" && value != outputTable && value != outputJSON {
+			return fmt.Errorf("output must be %s or %s", outputTable, outputJSON)
+		}
+		cfg.Output = value
+		return nil
+	},
+}
+
+// defaultConfigPath returns $TODO_CONFIG or todo/config.json in the user's
+// config directory
+func defaultConfigPath() string {
+	if path := os.Getenv(envConfig); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "todo.json"
+	}
+	return filepath.Join(dir, "todo", "config.json")
+}
+
+// loadConfig reads the config file at path; a missing file is an empty
+// config
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("config file %s is not valid JSON: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the config file at path, readable by the user only as
+// it holds a token
+func saveConfig(path string, cfg *config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// configCommand builds todo config, which shows and changes the config file
+func (a *app) configCommand() *cobra.Command {
+	keys := make([]string, 0, len(configKeys))
+	for key := range configKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show or change the config file",
+		Long:  "Show or change the config file at " + defaultConfigPath() + ", or at $" + envConfig + ".\nKeys: " + strings.Join(keys, ", ") + ".",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.showConfig()
+		},
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:       "set KEY VALUE",
+		Short:     "Set a key of the config file; an empty value unsets it",
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: keys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			set, ok := configKeys[args[0]]
+			if !ok {
+				return fmt.Errorf("unknown config key %q; keys are %s", args[0], strings.Join(keys, ", "))
+			}
+			cfg, err := loadConfig(a.configPath)
+			if err != nil {
+				return err
+			}
+			if err := set(cfg, args[1]); err != nil {
+				return err
+			}
+			if err := saveConfig(a.configPath, cfg); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stderr, "Set %s in %s\n", args[0], a.configPath)
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Show the config file, with the token masked",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.showConfig()
+		},
+	})
+	return cmd
+}
+
+// showConfig prints the config file with the token masked
+func (a *app) showConfig() error {
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Token != "" {
+		cfg.Token = maskToken(cfg.Token)
+	}
+	if a.format() == outputJSON {
+		return writeJSON(a.stdout, cfg)
+	}
+	fmt.Fprintf(a.stdout, "path:      %s\n", a.configPath)
+	fmt.Fprintf(a.stdout, "server:    %s\n", orDefault(cfg.Server, defaultServer+" (default)"))
+	fmt.Fprintf(a.stdout, "token:     %s\n", orDefault(cfg.Token, "(not set)"))
+	fmt.Fprintf(a.stdout, "time_zone: %s\n", orDefault(cfg.TimeZone, "(the account's)"))
+	fmt.Fprintf(a.stdout, "output:    %s\n", orDefault(cfg.Output, outputTable+" (default)"))
+	return nil
+}
+
+// maskToken hides all but the first characters of a token
+func maskToken(token string) string {
+	const shown = 6
+	if len(token) <= shown {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:shown] + strings.Repeat("*", 8)
+}
+
+// orDefault returns value, or fallback when value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
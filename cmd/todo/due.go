@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dueNone clears the due date of a task in todo edit
+const dueNone = "none"
+
+// Layouts of the due dates sent to the API, which reads them in the time
+// zone of the caller
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04"
+)
+
+// relativeDay matches due dates given as a number of days or weeks from
+// today, such as +3d or +2w
+var relativeDay = regexp.MustCompile(`^\+(\d{1,3})([dw])$`)
+
+// clockTime matches a time of day such as 9:00 or 17:30
+var clockTime = regexp.MustCompile(`^([01]?\d|2[0-3]):([0-5]\d)$`)
+
+// weekdays maps the names of the days of the week to the days
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// parseDue turns a due date given on the command line into one the API
+// accepts. Days are today, tomorrow, the name of a weekday (its next
+// occurrence after today) or +Nd and +Nw, counted from the date of now and
+// optionally followed by a time of day such as "tomorrow 9:00". Anything
+// else, such as 2030-05-01 or an RFC 3339 timestamp, is sent as is.
+func parseDue(value string, now time.Time) (string, error) {
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("--due must not be empty")
+	}
+	day, ok := resolveDay(fields[0], now)
+	if !ok || len(fields) > 2 {
+		return value, nil
+	}
+	if len(fields) == 1 {
+		return day.Format(dateLayout), nil
+	}
+
+	match := clockTime.FindStringSubmatch(fields[1])
+	if match == nil {
+		return "", fmt.Errorf("--due %q: %q is not a time of day such as 9:00 or 17:30", value, fields[1])
+	}
+	hour, _ := strconv.Atoi(match[1])
+	minute, _ := strconv.Atoi(match[2])
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute).Format(dateTimeLayout), nil
+}
+
+// resolveDay returns the midnight of the day a name stands for, counted
+// from now
+func resolveDay(name string, now time.Time) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch name {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	}
+	if weekday, ok := weekdays[name]; ok {
+		days := (int(weekday)-int(today.Weekday())+6)%7 + 1
+		return today.AddDate(0, 0, days), true
+	}
+	if match := relativeDay.FindStringSubmatch(name); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		if match[2] == "w" {
+			n *= 7
+		}
+		return today.AddDate(0, 0, n), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDue(t *testing.T) {
+	// A Friday evening in Paris, when it is already Saturday in Tokyo
+	now := time.Date(2026, 10, 16, 22, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"today", "2026-10-16"},
+		{"Tomorrow", "2026-10-17"},
+		{"tomorrow 9:00", "2026-10-17T09:00"},
+		{"fri", "2026-10-23"},
+		{"monday", "2026-10-19"},
+		{"sat 17:30", "2026-10-17T17:30"},
+		{"+3d", "2026-10-19"},
+		{"+2w", "2026-10-30"},
+		{"2030-05-01", "2030-05-01"},
+		{"2030-05-01T09:00:00+02:00", "2030-05-01T09:00:00+02:00"},
+		{"next week sometime", "next week sometime"},
+	}
+	for _, tt := range tests {
+		got, err := parseDue(tt.value, now)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+
+	for _, value := range []string{"", "  ", "tomorrow noon", "today 25:00"} {
+		_, err := parseDue(value, now)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/todo-api-go-sda/pkg/client"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// describeError renders an error for the terminal: errors of the API read
+// as a sentence followed by their code, with a hint where one helps
+func describeError(err error) string {
+	var (
+		taskNotFound    *apperrors.TaskNotFoundError
+		projectNotFound *apperrors.ProjectNotFoundError
+		validation      *apperrors.ValidationError
+		unauthorized    *apperrors.UnauthorizedError
+		forbidden       *apperrors.ForbiddenError
+		conflict        *apperrors.ConflictError
+		versionConflict *apperrors.VersionConflictError
+		apiErr          *client.Error
+		urlErr          *url.Error
+	)
+	switch {
+	case errors.As(err, &taskNotFound):
+		return withCode(fmt.Sprintf("task %d does not exist", taskNotFound.ID), apperrors.CodeTaskNotFound)
+	case errors.As(err, &projectNotFound):
+		return withCode(fmt.Sprintf("project %d does not exist", projectNotFound.ID), apperrors.CodeProjectNotFound)
+	case errors.As(err, &validation):
+		return withCode("invalid request: "+validation.Message, apperrors.CodeValidationError)
+	case errors.As(err, &unauthorized):
+		return withCode("not authenticated: "+unauthorized.Message, apperrors.CodeUnauthorized) +
+			"\nSet a token with: todo config set token <token>"
+	case errors.As(err, &forbidden):
+		return withCode("permission denied: "+forbidden.Message, apperrors.CodeForbidden)
+	case errors.As(err, &conflict):
+		return withCode(conflict.Message, apperrors.CodeConflict)
+	case errors.As(err, &versionConflict):
+		message := fmt.Sprintf("task %d was changed by someone else while it was being written", versionConflict.ID)
+		if current, ok := versionConflict.Current.(*client.Task); ok {
+			message += fmt.Sprintf(" (it is at version %d now)", current.Version)
+		}
+		return withCode(message+"; run the command again", apperrors.CodeVersionConflict)
+	case errors.As(err, &apiErr):
+		if apiErr.Code == "" {
+			return fmt.Sprintf("the server responded with HTTP %d: %s", apiErr.StatusCode, apiErr.Message)
+		}
+		return withCode(fmt.Sprintf("the server failed with HTTP %d: %s", apiErr.StatusCode, apiErr.Message), apiErr.Code)
+	case errors.As(err, &urlErr):
+		return fmt.Sprintf("cannot reach the server at %s: %v", urlErr.URL, urlErr.Err)
+	}
+	return err.Error()
+}
+
+// withCode appends the API error code to a message
+func withCode(message, code string) string {
+	return message + " [" + code + "]"
+}
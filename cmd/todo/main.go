@@ -0,0 +1,147 @@
+// Command todo is a command-line client of the todo API:
+//
+//	todo config set server https://todo.example.com
+//	todo config set token todo_pat_...
+//	todo add "Renew passport" --due tomorrow --tag admin
+//	todo ls --done=false
+//	todo done 42
+//
+// The server and token are read from a config file, which todo config
+// changes, and can be overridden with $TODO_SERVER and $TODO_TOKEN or the
+// --server and --token flags. Shell completion scripts are generated by
+// todo completion.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/todo-api-go-sda/pkg/client"
+)
+
+func main() {
+	a := newApp(os.Stdin, os.Stdout, os.Stderr)
+	if err := a.rootCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "todo: "+describeError(err))
+		os.Exit(1)
+	}
+}
+
+// app holds the settings and streams of one run of the CLI
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	now    func() time.Time
+
+	// Set by the global flags
+	configPath string
+	server     string
+	token      string
+	output     string
+	json       bool
+
+	// Loaded before a command runs
+	cfg *config
+	loc *time.Location
+}
+
+// newApp creates an app using the given streams
+func newApp(stdin io.Reader, stdout, stderr io.Writer) *app {
+	return &app{stdin: stdin, stdout: stdout, stderr: stderr, now: time.Now}
+}
+
+// rootCommand builds the todo command and its subcommands
+func (a *app) rootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "todo",
+		Short:         "Manage your tasks from the terminal",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.load()
+		},
+	}
+	root.SetIn(a.stdin)
+	root.SetOut(a.stdout)
+	root.SetErr(a.stderr)
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&a.configPath, "config", defaultConfigPath(), "config file")
+	flags.StringVar(&a.server, "server", "", "URL of the API server (default from the config file or $"+envServer+")")
+	flags.StringVar(&a.token, "token", "", "access token (default from the config file or $"+envToken+")")
+	flags.StringVarP(&a.output, "output", "o", "", "output format: "+outputTable+" or "+outputJSON+" (default from the config file, or "+outputTable+")")
+	flags.BoolVar(&a.json, "json", false, "print JSON; same as --output json")
+
+	root.AddCommand(
+		a.addCommand(),
+		a.listCommand(),
+		a.doneCommand(),
+		a.editCommand(),
+		a.removeCommand(),
+		a.configCommand(),
+	)
+	return root
+}
+
+// load reads the config file and checks the settings of the flags
+func (a *app) load() error {
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return err
+	}
+	a.cfg = cfg
+	if a.output != "" && a.output != outputTable && a.output != outputJSON {
+		return fmt.Errorf("--output must be %s or %s", outputTable, outputJSON)
+	}
+
+	a.loc = time.Local
+	if cfg.TimeZone != "" {
+		if a.loc, err = time.LoadLocation(cfg.TimeZone); err != nil {
+			return fmt.Errorf("time_zone in %s must be an IANA time zone: %w", a.configPath, err)
+		}
+	}
+	return nil
+}
+
+// client returns a client of the configured server. The flags take
+// precedence over the environment, which takes precedence over the config
+// file.
+func (a *app) client() (*client.Client, error) {
+	server := firstSet(a.server, os.Getenv(envServer), a.cfg.Server, defaultServer)
+	opts := []client.Option{}
+	if token := firstSet(a.token, os.Getenv(envToken), a.cfg.Token); token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	if a.cfg.TimeZone != "" {
+		opts = append(opts, client.WithTimeZone(a.cfg.TimeZone))
+	}
+	return client.New(server, opts...)
+}
+
+// format returns the output format selected by the flags or the config file
+func (a *app) format() string {
+	if a.json {
+		return outputJSON
+	}
+	if a.output != "" {
+		return a.output
+	}
+	if a.cfg != nil && a.cfg.Output != "" {
+		return a.cfg.Output
+	}
+	return outputTable
+}
+
+// firstSet returns the first of the values that is not empty
+func firstSet(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/auth"
+	apiconfig "github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/handlers"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	"github.com/todo-api-go-sda/internal/services"
+	"github.com/todo-api-go-sda/pkg/client"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// testCLI runs commands against the tasks routes of the API served in
+// memory to a registered user, with a config file pointing at the server
+type testCLI struct {
+	configPath string
+	now        time.Time
+}
+
+func newTestCLI(t *testing.T) *testCLI {
+	tokens, err := auth.NewTokenManager(&apiconfig.AuthConfig{
+		JWTAlgorithm:    "HS256",
+		JWTSecret:       "test-secret",
+		JWTIssuer:       "todo-api",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+	require.NoError(t, err)
+	authService := services.NewAuthService(repository.NewMemoryUserRepository(), tokens)
+	registered, err := authService.Register(&models.RegisterRequest{Email: "cli@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	taskRepo := repository.NewMemoryTaskRepository()
+	taskService := services.NewTaskService(taskRepo, repository.NewMemoryProjectRepository(taskRepo), repository.NewMemoryTagRepository(taskRepo),
+		&apiconfig.TaskConfig{MaxSubtaskDepth: 2}, events.NewBus(10))
+	taskHandler := handlers.NewTaskHandler(taskService, &apiconfig.ServerConfig{DefaultPageSize: 50, MaxPageSize: 100, CursorSecret: "test-secret"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	tasks := router.Group("/api/v1/tasks", middleware.RequireAuth(tokens, services.NewAPITokenService(repository.NewMemoryAPITokenRepository())),
+		middleware.ResolveTimeZone(authService))
+	{
+		tasks.POST("", taskHandler.CreateTask)
+		tasks.GET("", taskHandler.ListTasks)
+		tasks.GET("/:id", taskHandler.GetTask)
+		tasks.PUT("/:id", taskHandler.UpdateTask)
+		tasks.DELETE("/:id", taskHandler.DeleteTask)
+	}
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	cli := &testCLI{
+		configPath: filepath.Join(t.TempDir(), "config.json"),
+		now:        time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, saveConfig(cli.configPath, &config{Server: server.URL, Token: registered.AccessToken, TimeZone: "UTC"}))
+	return cli
+}
+
+// run runs todo with the given arguments and returns its output
+func (c *testCLI) run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	a := newApp(strings.NewReader(""), &stdout, &stderr)
+	a.now = func() time.Time { return c.now }
+	root := a.rootCommand()
+	root.SetArgs(append([]string{"--config", c.configPath}, args...))
+	err := root.Execute()
+	return stdout.String(), err
+}
+
+// runJSON runs todo with --json and decodes its output into out
+func (c *testCLI) runJSON(t *testing.T, out interface{}, args ...string) {
+	t.Helper()
+	output, err := c.run(t, append(args, "--json")...)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(output), out), output)
+}
+
+func TestAddListDoneRemove(t *testing.T) {
+	cli := newTestCLI(t)
+
+	var task client.Task
+	cli.runJSON(t, &task, "add", "Renew", "passport", "--due", "tomorrow 9:00", "--tag", "admin", "-p", "high")
+	assert.Equal(t, "Renew passport", task.Content)
+	assert.Equal(t, time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC), task.DueAt.UTC())
+	assert.Equal(t, []string{"admin"}, task.Tags)
+	cli.runJSON(t, &client.Task{}, "add", "Water the plants")
+
+	output, err := cli.run(t, "ls", "--sort", "content")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"ID  DONE  PRIORITY  DUE                   TAGS   CONTENT",
+		"1   [ ]   high      Sat 2026-10-17 09:00  admin  Renew passport",
+		"2   [ ]   none      -                     -      Water the plants",
+		"",
+	}, "\n"), output)
+
+	output, err = cli.run(t, "done", "2")
+	require.NoError(t, err)
+	assert.Contains(t, output, "[x]")
+
+	var open []client.Task
+	cli.runJSON(t, &open, "ls", "--done=false")
+	require.Len(t, open, 1)
+	assert.Equal(t, task.ID, open[0].ID)
+
+	var limited []client.Task
+	cli.runJSON(t, &limited, "ls", "-n", "1")
+	assert.Len(t, limited, 1)
+
+	output, err = cli.run(t, "rm", "1", "2")
+	require.NoError(t, err)
+	assert.Equal(t, "Moved task 1 to the trash\nMoved task 2 to the trash\n", output)
+	output, err = cli.run(t, "ls")
+	require.NoError(t, err)
+	assert.Equal(t, "No tasks\n", output)
+}
+
+func TestEdit(t *testing.T) {
+	cli := newTestCLI(t)
+	var task client.Task
+	cli.runJSON(t, &task, "add", "Plant tulips", "--due", "2026-10-20", "--tag", "garden")
+
+	var edited client.Task
+	cli.runJSON(t, &edited, "edit", "1", "--content", "Plant bulbs", "--due", "none", "--tag", "home", "--tag", "autumn")
+	assert.Equal(t, "Plant bulbs", edited.Content)
+	assert.Nil(t, edited.DueAt)
+	assert.Equal(t, []string{"autumn", "home"}, edited.Tags)
+	assert.Equal(t, task.Version+1, edited.Version)
+
+	// Without flags the task is opened in the editor
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(editor, []byte("#!/bin/sh\nsed -i 's/Plant bulbs/Plant daffodils/' \"$1\"\n"), 0o700))
+	t.Setenv("VISUAL", editor)
+	cli.runJSON(t, &edited, "edit", "1")
+	assert.Equal(t, "Plant daffodils", edited.Content)
+	assert.Equal(t, []string{"autumn", "home"}, edited.Tags)
+}
+
+func TestErrors(t *testing.T) {
+	cli := newTestCLI(t)
+
+	_, err := cli.run(t, "done", "42")
+	require.Error(t, err)
+	assert.Equal(t, "task 42 does not exist [TASK_NOT_FOUND]", describeError(err))
+
+	_, err = cli.run(t, "add", "Task", "--every", "FREQ=WEEKLY")
+	require.Error(t, err)
+	assert.Equal(t, "invalid request: recurring tasks require a due date [VALIDATION_ERROR]", describeError(err))
+
+	_, err = cli.run(t, "rm", "x")
+	assert.EqualError(t, err, `"x" is not a task ID`)
+
+	_, err = cli.run(t, "ls", "--token", "not-a-token")
+	require.Error(t, err)
+	assert.Contains(t, describeError(err), "[UNAUTHORIZED]\nSet a token with: todo config set token <token>")
+
+	conflict := &apperrors.VersionConflictError{ID: 7, Current: &client.Task{ID: 7, Version: 4}}
+	assert.Equal(t, "task 7 was changed by someone else while it was being written (it is at version 4 now); run the command again [VERSION_CONFLICT]",
+		describeError(conflict))
+	assert.Equal(t, "the server responded with HTTP 502: Bad Gateway", describeError(&client.Error{StatusCode: 502, Message: "Bad Gateway"}))
+}
+
+func TestConfig(t *testing.T) {
+	cli := newTestCLI(t)
+
+	_, err := cli.run(t, "config", "set", "output", "json")
+	require.NoError(t, err)
+	_, err = cli.run(t, "config", "set", "token", "todo_pat_0123456789")
+	require.NoError(t, err)
+	_, err = cli.run(t, "config", "set", "time_zone", "Mars/Olympus")
+	assert.EqualError(t, err, "time_zone must be an IANA time zone such as Europe/Paris")
+	_, err = cli.run(t, "config", "set", "colour", "red")
+	assert.EqualError(t, err, `unknown config key "colour"; keys are output, server, time_zone, token`)
+
+	// The output of the config file is the default format
+	output, err := cli.run(t, "config", "show")
+	require.NoError(t, err)
+	var shown config
+	require.NoError(t, json.Unmarshal([]byte(output), &shown))
+	assert.Equal(t, "todo_p********", shown.Token)
+	assert.Equal(t, outputJSON, shown.Output)
+
+	info, err := os.Stat(cli.configPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
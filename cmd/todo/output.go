@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/todo-api-go-sda/pkg/client"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// dueLayout formats due dates in tables
+const dueLayout = "Mon 2006-01-02 15:04"
+
+// writeJSON prints a value as indented JSON
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// printTasks prints tasks as a table, or as a JSON array
+func (a *app) printTasks(tasks []client.Task) error {
+	if a.format() == outputJSON {
+		if tasks == nil {
+			tasks = []client.Task{}
+		}
+		return writeJSON(a.stdout, tasks)
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintln(a.stdout, "No tasks")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDONE\tPRIORITY\tDUE\tTAGS\tCONTENT")
+	for _, task := range tasks {
+		done := "[ ]"
+		if task.Completed {
+			done = "[x]"
+		}
+		due := "-"
+		if task.DueAt != nil {
+			due = task.DueAt.In(a.loc).Format(dueLayout)
+		}
+		tags := "-"
+		if len(task.Tags) > 0 {
+			tags = strings.Join(task.Tags, ",")
+		}
+		content := strings.Join(strings.Fields(task.Content), " ")
+		if task.SubtaskCount > 0 {
+			content += fmt.Sprintf(" (%d/%d)", task.CompletedSubtaskCount, task.SubtaskCount)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", task.ID, done, task.Priority, due, tags, content)
+	}
+	return w.Flush()
+}
+
+// printTask prints one task as a table row, or as a JSON object
+func (a *app) printTask(task *client.Task) error {
+	if a.format() == outputJSON {
+		return writeJSON(a.stdout, task)
+	}
+	return a.printTasks([]client.Task{*task})
+}
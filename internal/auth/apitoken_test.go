@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIToken(t *testing.T) {
+	token, hash, prefix, err := GenerateAPIToken()
+	require.NoError(t, err)
+
+	assert.True(t, IsAPIToken(token))
+	assert.True(t, len(token) > len(APITokenPrefix)+40)
+	assert.Equal(t, HashAPIToken(token), hash)
+	assert.NotContains(t, hash, token)
+	assert.Equal(t, token[:len(prefix)], prefix)
+
+	other, otherHash, _, err := GenerateAPIToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+	assert.NotEqual(t, hash, otherHash)
+}
+
+func TestIsScope(t *testing.T) {
+	assert.True(t, IsScope(ScopeTasksRead))
+	assert.True(t, IsScope(ScopeTasksDelete))
+	assert.False(t, IsScope("tasks:admin"))
+	assert.False(t, IsScope(""))
+}
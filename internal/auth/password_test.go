@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, "correct horse", hash)
+	assert.True(t, CheckPassword(hash, "correct horse"))
+	assert.False(t, CheckPassword(hash, "wrong horse"))
+}
+
+func TestCheckPassword_EmptyHash(t *testing.T) {
+	assert.False(t, CheckPassword("", "dummy-password"))
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/todo-api-go-sda/internal/config"
+)
+
+// Token types carried in the "typ" claim
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// ErrInvalidToken is returned when a token fails verification
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims represents the JWT claims issued by the API
+type Claims struct {
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// TokenPair holds a freshly issued access and refresh token
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
+
+// TokenManager issues and verifies signed JWTs
+type TokenManager struct {
+	method     jwt.SigningMethod
+	signKey    interface{}
+	verifyKey  interface{}
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewTokenManager creates a TokenManager for the configured algorithm.
+// HS256 signs with JWT_SECRET, or a random per-process secret when unset;
+// RS256 signs with the PEM private key and verifies with the public key,
+// which is derived from the private key when no public key path is given.
+func NewTokenManager(cfg *config.AuthConfig) (*TokenManager, error) {
+	m := &TokenManager{
+		issuer:     cfg.JWTIssuer,
+		accessTTL:  cfg.AccessTokenTTL,
+		refreshTTL: cfg.RefreshTokenTTL,
+	}
+
+	switch cfg.JWTAlgorithm {
+	case "HS256":
+		secret := []byte(cfg.JWTSecret)
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, fmt.Errorf("generate JWT secret: %w", err)
+			}
+		}
+		m.method = jwt.SigningMethodHS256
+		m.signKey = secret
+		m.verifyKey = secret
+	case "RS256":
+		privateKey, publicKey, err := loadRSAKeys(cfg.JWTPrivateKeyPath, cfg.JWTPublicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		m.method = jwt.SigningMethodRS256
+		m.signKey = privateKey
+		m.verifyKey = publicKey
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.JWTAlgorithm)
+	}
+	return m, nil
+}
+
+// loadRSAKeys reads the PEM-encoded RSA key pair used for RS256
+func loadRSAKeys(privatePath, publicPath string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	if privatePath == "" {
+		return nil, nil, errors.New("RS256 requires JWT_PRIVATE_KEY_PATH")
+	}
+	pem, err := os.ReadFile(privatePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read JWT private key: %w", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse JWT private key: %w", err)
+	}
+	if publicPath == "" {
+		return privateKey, &privateKey.PublicKey, nil
+	}
+
+	pem, err = os.ReadFile(publicPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read JWT public key: %w", err)
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse JWT public key: %w", err)
+	}
+	return privateKey, publicKey, nil
+}
+
+// Issue creates a new access and refresh token for the user
+func (m *TokenManager) Issue(userID uint) (*TokenPair, error) {
+	access, err := m.sign(userID, TokenTypeAccess, m.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := m.sign(userID, TokenTypeRefresh, m.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{AccessToken: access, RefreshToken: refresh, ExpiresIn: m.accessTTL}, nil
+}
+
+// Verify checks the token signature, expiry, issuer and type, and returns
+// the ID of the user it was issued to
+func (m *TokenManager) Verify(token, tokenType string) (uint, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return m.verifyKey, nil
+	},
+		jwt.WithValidMethods([]string{m.method.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Type != tokenType {
+		return 0, ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userID == 0 {
+		return 0, ErrInvalidToken
+	}
+	return uint(userID), nil
+}
+
+// sign creates a signed token of the given type for the user
+func (m *TokenManager) sign(userID uint, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+)
+
+func testAuthConfig() *config.AuthConfig {
+	return &config.AuthConfig{
+		JWTAlgorithm:    "HS256",
+		JWTSecret:       "test-secret",
+		JWTIssuer:       "todo-api",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}
+}
+
+func TestTokenManager_IssueAndVerify(t *testing.T) {
+	tokens, err := NewTokenManager(testAuthConfig())
+	require.NoError(t, err)
+
+	pair, err := tokens.Issue(42)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, pair.ExpiresIn)
+
+	userID, err := tokens.Verify(pair.AccessToken, TokenTypeAccess)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(42), userID)
+
+	userID, err = tokens.Verify(pair.RefreshToken, TokenTypeRefresh)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(42), userID)
+}
+
+func TestTokenManager_RejectsWrongType(t *testing.T) {
+	tokens, err := NewTokenManager(testAuthConfig())
+	require.NoError(t, err)
+
+	pair, err := tokens.Issue(42)
+	require.NoError(t, err)
+
+	_, err = tokens.Verify(pair.RefreshToken, TokenTypeAccess)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = tokens.Verify(pair.AccessToken, TokenTypeRefresh)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestTokenManager_RejectsInvalidTokens(t *testing.T) {
+	tokens, err := NewTokenManager(testAuthConfig())
+	require.NoError(t, err)
+	pair, err := tokens.Issue(42)
+	require.NoError(t, err)
+
+	otherCfg := testAuthConfig()
+	otherCfg.JWTSecret = "other-secret"
+	other, err := NewTokenManager(otherCfg)
+	require.NoError(t, err)
+
+	otherIssuerCfg := testAuthConfig()
+	otherIssuerCfg.JWTIssuer = "someone-else"
+	otherIssuer, err := NewTokenManager(otherIssuerCfg)
+	require.NoError(t, err)
+	foreign, err := otherIssuer.Issue(42)
+	require.NoError(t, err)
+
+	expiredCfg := testAuthConfig()
+	expiredCfg.AccessTokenTTL = -time.Minute
+	expiring, err := NewTokenManager(expiredCfg)
+	require.NoError(t, err)
+	expired, err := expiring.Issue(42)
+	require.NoError(t, err)
+
+	for name, token := range map[string]string{
+		"garbage":      "not-a-token",
+		"tampered":     pair.AccessToken + "x",
+		"other secret": mustIssue(t, other),
+		"other issuer": foreign.AccessToken,
+		"expired":      expired.AccessToken,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := tokens.Verify(token, TokenTypeAccess)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestTokenManager_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+
+	cfg := testAuthConfig()
+	cfg.JWTAlgorithm = "RS256"
+	cfg.JWTPrivateKeyPath = path
+	tokens, err := NewTokenManager(cfg)
+	require.NoError(t, err)
+
+	pair, err := tokens.Issue(7)
+	require.NoError(t, err)
+	userID, err := tokens.Verify(pair.AccessToken, TokenTypeAccess)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), userID)
+
+	hmac, err := NewTokenManager(testAuthConfig())
+	require.NoError(t, err)
+	_, err = tokens.Verify(mustIssue(t, hmac), TokenTypeAccess)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNewTokenManager_InvalidConfig(t *testing.T) {
+	cfg := testAuthConfig()
+	cfg.JWTAlgorithm = "none"
+	_, err := NewTokenManager(cfg)
+	assert.Error(t, err)
+
+	cfg.JWTAlgorithm = "RS256"
+	_, err = NewTokenManager(cfg)
+	assert.Error(t, err)
+}
+
+func mustIssue(t *testing.T, tokens *TokenManager) string {
+	pair, err := tokens.Issue(42)
+	require.NoError(t, err)
+	return pair.AccessToken
+}
@@ -3,44 +3,241 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// localEnvironments are the environments a single process runs in, where
+// secrets that every replica must share may be left unset. The default
+// environment is local, so that the API runs without any configuration;
+// deployments set APP_ENV to another environment.
+var localEnvironments = []string{"development", "local", "test"}
+
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	// Environment names where the application runs, such as production or
+	// development
+	Environment string
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Auth        AuthConfig
+	Tasks       TaskConfig
+	Idempotency IdempotencyConfig
+	Webhooks    WebhookConfig
+	Events      EventsConfig
+	GraphQL     GraphQLConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	Port string
+	Port            string
+	DefaultPageSize int
+	MaxPageSize     int
+	// CursorSecret signs page cursors, so every replica must share it. Left
+	// empty, which is only allowed in local environments, each process
+	// generates its own and cursors stop working after a restart.
+	CursorSecret string
+	// GRPCPort is the port of the gRPC API; it is disabled when empty
+	GRPCPort string
 }
 
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
 // DatabaseConfig holds database-related configuration
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Driver      string
+	SQLitePath  string
+	AutoMigrate bool
+	Host        string
+	Port        string
+	User        string
+	Password    string
+	DBName      string
+	SSLMode     string
+}
+
+// AuthConfig holds authentication-related configuration
+type AuthConfig struct {
+	JWTAlgorithm      string
+	JWTSecret         string
+	JWTPrivateKeyPath string
+	JWTPublicKeyPath  string
+	JWTIssuer         string
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+}
+
+// TaskConfig holds task-related configuration
+type TaskConfig struct {
+	// MaxSubtaskDepth is how many levels of subtasks may be nested below a
+	// top-level task; 0 disables subtasks
+	MaxSubtaskDepth int
+	// TrashRetention is how long deleted tasks stay in the trash before they
+	// are purged; 0 keeps them until they are deleted by hand
+	TrashRetention time.Duration
+	// TrashPurgeInterval is how often the trash is checked for tasks to purge
+	TrashPurgeInterval time.Duration
+}
+
+// IdempotencyConfig holds the settings of Idempotency-Key handling
+type IdempotencyConfig struct {
+	// KeyTTL is how long the response to a request made with an
+	// Idempotency-Key is kept for replaying to retries
+	KeyTTL time.Duration
+	// LockTimeout is how long a request in flight holds its key; a retry
+	// after that runs the request again, in case the first one was lost
+	LockTimeout time.Duration
+	// PurgeInterval is how often expired keys are deleted
+	PurgeInterval time.Duration
+}
+
+// WebhookConfig holds the settings of webhook delivery
+type WebhookConfig struct {
+	// DeliveryInterval is how often the outbox is dispatched and due
+	// deliveries are attempted; 0 disables delivery
+	DeliveryInterval time.Duration
+	// Timeout is how long a webhook has to respond to a delivery
+	Timeout time.Duration
+	// Lease is how long the deliveries a dispatcher has claimed are kept
+	// from the other replicas; attempts are only started while they can
+	// finish within it, so it must be longer than Timeout
+	Lease time.Duration
+	// MaxAttempts is how many times a delivery is attempted before it is
+	// moved to the dead-letter list
+	MaxAttempts int
+	// RetryBaseDelay is the delay before the first retry, doubled for each
+	// retry after it
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the delay between retries
+	RetryMaxDelay time.Duration
+	// BatchSize is how many outbox messages and deliveries are handled at
+	// each interval
+	BatchSize int
+	// Concurrency is how many deliveries are attempted at the same time
+	Concurrency int
+	// ConcurrencyPerWebhook is how many of those may go to the same
+	// webhook, so that a slow one does not hold up the others
+	ConcurrencyPerWebhook int
+}
+
+// EventsConfig holds the settings of the task event stream
+type EventsConfig struct {
+	// ReplayBufferSize is how many recent events are kept for clients
+	// resuming the stream with Last-Event-ID
+	ReplayBufferSize int
+	// HeartbeatInterval is how often an idle stream is sent a heartbeat
+	HeartbeatInterval time.Duration
+	// PostgresBridge fans events out to every replica with LISTEN/NOTIFY;
+	// it only applies to the postgres driver
+	PostgresBridge bool
+	// PostgresChannel is the notification channel used by the bridge
+	PostgresChannel string
+}
+
+// GraphQLConfig holds the limits of GraphQL queries
+type GraphQLConfig struct {
+	// MaxDepth is how deeply the fields of a query may be nested
+	MaxDepth int
+	// MaxComplexity caps the estimated number of fields a query resolves,
+	// counting the fields of each list item once per item requested
+	MaxComplexity int
 }
 
 // Load loads configuration from environment variables with defaults
 func Load() *Config {
 	return &Config{
+		Environment: getEnv("APP_ENV", "development"),
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
+			Port:            getEnv("PORT", "8080"),
+			GRPCPort:        getEnv("GRPC_PORT", ""),
+			DefaultPageSize: getEnvInt("DEFAULT_PAGE_SIZE", 50),
+			MaxPageSize:     getEnvInt("MAX_PAGE_SIZE", 100),
+			CursorSecret:    getEnv("CURSOR_SECRET", ""),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "todoapi"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Driver:      getEnv("DB_DRIVER", DriverPostgres),
+			SQLitePath:  getEnv("SQLITE_PATH", "todoapi.db"),
+			AutoMigrate: getEnvBool("DB_AUTO_MIGRATE", false),
+			Host:        getEnv("DB_HOST", "localhost"),
+			Port:        getEnv("DB_PORT", "5432"),
+			User:        getEnv("DB_USER", "postgres"),
+			Password:    getEnv("DB_PASSWORD", "postgres"),
+			DBName:      getEnv("DB_NAME", "todoapi"),
+			SSLMode:     getEnv("DB_SSLMODE", "disable"),
+		},
+		Auth: AuthConfig{
+			JWTAlgorithm:      getEnv("JWT_ALGORITHM", "HS256"),
+			JWTSecret:         getEnv("JWT_SECRET", ""),
+			JWTPrivateKeyPath: getEnv("JWT_PRIVATE_KEY_PATH", ""),
+			JWTPublicKeyPath:  getEnv("JWT_PUBLIC_KEY_PATH", ""),
+			JWTIssuer:         getEnv("JWT_ISSUER", "todo-api"),
+			AccessTokenTTL:    getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:   getEnvDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+		},
+		Tasks: TaskConfig{
+			MaxSubtaskDepth:    getEnvInt("MAX_SUBTASK_DEPTH", 3),
+			TrashRetention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+			TrashPurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
 		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL:        getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+			LockTimeout:   getEnvDuration("IDEMPOTENCY_LOCK_TIMEOUT", time.Minute),
+			PurgeInterval: getEnvDuration("IDEMPOTENCY_PURGE_INTERVAL", time.Hour),
+		},
+		Webhooks: WebhookConfig{
+			DeliveryInterval:      getEnvDuration("WEBHOOK_DELIVERY_INTERVAL", time.Second),
+			Timeout:               getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			Lease:                 getEnvDuration("WEBHOOK_LEASE", 5*time.Minute),
+			MaxAttempts:           getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryBaseDelay:        getEnvDuration("WEBHOOK_RETRY_BASE_DELAY", 30*time.Second),
+			RetryMaxDelay:         getEnvDuration("WEBHOOK_RETRY_MAX_DELAY", time.Hour),
+			BatchSize:             getEnvInt("WEBHOOK_BATCH_SIZE", 100),
+			Concurrency:           getEnvInt("WEBHOOK_CONCURRENCY", 10),
+			ConcurrencyPerWebhook: getEnvInt("WEBHOOK_CONCURRENCY_PER_WEBHOOK", 2),
+		},
+		Events: EventsConfig{
+			ReplayBufferSize:  getEnvInt("EVENTS_REPLAY_BUFFER", 1024),
+			HeartbeatInterval: getEnvDuration("EVENTS_HEARTBEAT_INTERVAL", 15*time.Second),
+			PostgresBridge:    getEnvBool("EVENTS_POSTGRES_BRIDGE", false),
+			PostgresChannel:   getEnv("EVENTS_POSTGRES_CHANNEL", "task_events"),
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      getEnvInt("GRAPHQL_MAX_DEPTH", 10),
+			MaxComplexity: getEnvInt("GRAPHQL_MAX_COMPLEXITY", 20000),
+		},
+	}
+}
+
+// IsLocal reports whether the application runs in a local environment
+func (c *Config) IsLocal() bool {
+	return slices.Contains(localEnvironments, c.Environment)
+}
+
+// Validate checks the settings that have no safe default: the secrets
+// every replica must share may only be left unset in a local environment
+func (c *Config) Validate() error {
+	if c.IsLocal() {
+		return nil
+	}
+	var missing []string
+	if c.Auth.JWTAlgorithm == "HS256" && c.Auth.JWTSecret == "" {
+		missing = append(missing, "JWT_SECRET")
+	}
+	if c.Server.CursorSecret == "" {
+		missing = append(missing, "CURSOR_SECRET")
 	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s must be set when APP_ENV is %q; only %v may leave them unset",
+			strings.Join(missing, " and "), c.Environment, localEnvironments)
+	}
+	return nil
 }
 
 // getEnv gets an environment variable or returns a default value
@@ -51,6 +248,36 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt gets an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool gets a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration gets a duration environment variable or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
 // DSN returns the PostgreSQL connection string
 func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf(
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_Environment(t *testing.T) {
+	// Setenv restores the variable after the test
+	t.Setenv("APP_ENV", "")
+	os.Unsetenv("APP_ENV")
+	assert.Equal(t, "development", Load().Environment)
+	assert.True(t, Load().IsLocal())
+
+	t.Setenv("APP_ENV", "production")
+	assert.Equal(t, "production", Load().Environment)
+	assert.False(t, Load().IsLocal())
+}
+
+func TestValidate_Secrets(t *testing.T) {
+	tests := []struct {
+		name         string
+		environment  string
+		algorithm    string
+		jwtSecret    string
+		cursorSecret string
+		missing      string
+	}{
+		{name: "production without secrets", environment: "production", algorithm: "HS256", missing: "JWT_SECRET and CURSOR_SECRET must be set"},
+		{name: "staging without secrets", environment: "staging", algorithm: "HS256", missing: "JWT_SECRET and CURSOR_SECRET must be set"},
+		{name: "without JWT secret", environment: "production", algorithm: "HS256", cursorSecret: "s3cret", missing: "JWT_SECRET must be set"},
+		{name: "without cursor secret", environment: "production", algorithm: "HS256", jwtSecret: "s3cret", missing: "CURSOR_SECRET must be set"},
+		{name: "RS256 without cursor secret", environment: "production", algorithm: "RS256", missing: "CURSOR_SECRET must be set"},
+		{name: "production", environment: "production", algorithm: "HS256", jwtSecret: "s3cret", cursorSecret: "s3cret"},
+		{name: "RS256", environment: "production", algorithm: "RS256", cursorSecret: "s3cret"},
+		{name: "development", environment: "development", algorithm: "HS256"},
+		{name: "local", environment: "local", algorithm: "HS256"},
+		{name: "test", environment: "test", algorithm: "HS256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Environment: tt.environment,
+				Server:      ServerConfig{CursorSecret: tt.cursorSecret},
+				Auth:        AuthConfig{JWTAlgorithm: tt.algorithm, JWTSecret: tt.jwtSecret},
+			}
+			err := cfg.Validate()
+			if tt.missing == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.missing)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/todo-api-go-sda/internal/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Open connects to the SQL database selected by the configured driver
+func Open(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		return gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{TranslateError: true})
+	case config.DriverSQLite:
+		return gorm.Open(sqlite.Open(SQLiteDSN(cfg.SQLitePath)), &gorm.Config{TranslateError: true})
+	default:
+		return nil, fmt.Errorf("unsupported SQL database driver %q", cfg.Driver)
+	}
+}
+
+// SQLiteDSN returns the connection string for a SQLite database file with
+// foreign key enforcement enabled
+func SQLiteDSN(path string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "_foreign_keys=on"
+}
@@ -0,0 +1,276 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql
+var files embed.FS
+
+// advisoryLockKey identifies the PostgreSQL advisory lock held while migrating
+const advisoryLockKey = 727274001
+
+// fileNamePattern matches migration files such as 0001_create_tasks.up.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration represents one versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes whether a migration has been applied
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// schemaMigration represents a row of the schema_migrations table
+type schemaMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrator applies and rolls back the embedded migrations for a database
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New creates a Migrator for the dialect of the given database
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// load reads and pairs the embedded up/down files for a dialect
+func load(dialect string) ([]Migration, error) {
+	dir := path.Join("sql", dialect)
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(files, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be contiguous from 1, found %d at position %d", migration.Version, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// Latest returns the newest migration version available
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Version returns the currently applied schema version, 0 for an empty database
+func (m *Migrator) Version() (int, error) {
+	if err := ensureTable(m.db); err != nil {
+		return 0, err
+	}
+	return currentVersion(m.db)
+}
+
+// Status returns every known migration along with when it was applied
+func (m *Migrator) Status() ([]Status, error) {
+	if err := ensureTable(m.db); err != nil {
+		return nil, err
+	}
+
+	var applied []schemaMigration
+	if err := m.db.Raw("SELECT version, name, applied_at FROM schema_migrations ORDER BY version").Scan(&applied).Error; err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int]time.Time, len(applied))
+	for _, row := range applied {
+		appliedAt[row.Version] = row.AppliedAt
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{Version: migration.Version, Name: migration.Name}
+		if at, ok := appliedAt[migration.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down() error {
+	return m.withLock(func(conn *gorm.DB) error {
+		version, err := currentVersion(conn)
+		if err != nil {
+			return err
+		}
+		if version == 0 {
+			return nil
+		}
+		return m.migrate(conn, version, version-1)
+	})
+}
+
+// To migrates the schema up or down until it reaches the target version
+func (m *Migrator) To(target int) error {
+	if target < 0 || target > m.Latest() {
+		return fmt.Errorf("target version %d out of range 0..%d", target, m.Latest())
+	}
+	return m.withLock(func(conn *gorm.DB) error {
+		version, err := currentVersion(conn)
+		if err != nil {
+			return err
+		}
+		return m.migrate(conn, version, target)
+	})
+}
+
+// migrate steps one migration at a time from the current version to the target
+func (m *Migrator) migrate(conn *gorm.DB, from, to int) error {
+	if from > m.Latest() {
+		return fmt.Errorf("database schema version %d is newer than the latest known migration %d", from, m.Latest())
+	}
+	for version := from; version < to; version++ {
+		if err := m.apply(conn, m.migrations[version], true); err != nil {
+			return err
+		}
+	}
+	for version := from; version > to; version-- {
+		if err := m.apply(conn, m.migrations[version-1], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply runs one migration in either direction and records the result in
+// the same transaction
+func (m *Migrator) apply(conn *gorm.DB, migration Migration, up bool) error {
+	script, direction := migration.Down, "down"
+	if up {
+		script, direction = migration.Up, "up"
+	}
+
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if hasStatements(script) {
+			if err := tx.Exec(script).Error; err != nil {
+				return err
+			}
+		}
+		if up {
+			return tx.Exec(
+				"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+				migration.Version, migration.Name, time.Now().UTC(),
+			).Error
+		}
+		return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %04d_%s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+	return nil
+}
+
+// withLock runs fn on a single connection while holding the migration lock.
+// PostgreSQL uses a session advisory lock so concurrent replicas migrate one
+// at a time; SQLite serializes writers on its own.
+func (m *Migrator) withLock(fn func(conn *gorm.DB) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if conn.Dialector.Name() == "postgres" {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", advisoryLockKey).Error; err != nil {
+				return fmt.Errorf("acquire migration lock: %w", err)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", advisoryLockKey)
+		}
+		if err := ensureTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+// ensureTable creates the schema_migrations table when it does not exist
+func ensureTable(conn *gorm.DB) error {
+	return conn.Exec(
+		"CREATE TABLE IF NOT EXISTS schema_migrations (" +
+			"version BIGINT PRIMARY KEY, " +
+			"name VARCHAR(255) NOT NULL, " +
+			"applied_at TIMESTAMP NOT NULL)",
+	).Error
+}
+
+// currentVersion returns the highest applied migration version
+func currentVersion(conn *gorm.DB) (int, error) {
+	var version *int
+	err := conn.Raw("SELECT MAX(version) FROM schema_migrations").Scan(&version).Error
+	if err != nil || version == nil {
+		return 0, err
+	}
+	return *version, nil
+}
+
+// hasStatements reports whether a script contains anything besides comments
+func hasStatements(script string) bool {
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return true
+		}
+	}
+	return false
+}
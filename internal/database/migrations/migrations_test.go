@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	return db
+}
+
+func TestLoad_AllDialectsHaveMatchingMigrations(t *testing.T) {
+	postgres, err := load("postgres")
+	require.NoError(t, err)
+	sqlite, err := load("sqlite")
+	require.NoError(t, err)
+
+	require.Equal(t, len(postgres), len(sqlite))
+	for i := range postgres {
+		assert.Equal(t, postgres[i].Version, sqlite[i].Version)
+		assert.Equal(t, postgres[i].Name, sqlite[i].Name)
+		assert.NotEmpty(t, postgres[i].Up)
+		assert.NotEmpty(t, postgres[i].Down)
+	}
+}
+
+func TestMigrator_UpAndStatus(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := New(db)
+	require.NoError(t, err)
+
+	version, err := migrator.Version()
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	require.NoError(t, migrator.Up())
+
+	version, err = migrator.Version()
+	require.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), version)
+	assert.True(t, db.Migrator().HasTable("tasks"))
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, migrator.Latest())
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt)
+	}
+
+	// Applying again is a no-op
+	require.NoError(t, migrator.Up())
+}
+
+func TestMigrator_DownAndTo(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := New(db)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up())
+
+	require.NoError(t, migrator.Down())
+	version, err := migrator.Version()
+	require.NoError(t, err)
+	assert.Equal(t, migrator.Latest()-1, version)
+
+	require.NoError(t, migrator.To(0))
+	version, err = migrator.Version()
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+	assert.False(t, db.Migrator().HasTable("tasks"))
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	assert.Nil(t, statuses[0].AppliedAt)
+
+	require.NoError(t, migrator.To(1))
+	version, err = migrator.Version()
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	assert.Error(t, migrator.To(migrator.Latest()+1))
+}
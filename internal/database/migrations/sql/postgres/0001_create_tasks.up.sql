@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS tasks (
+    id         BIGSERIAL PRIMARY KEY,
+    content    VARCHAR(1000) NOT NULL,
+    completed  BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_tasks_content_fts ON tasks USING GIN (to_tsvector('english', content));
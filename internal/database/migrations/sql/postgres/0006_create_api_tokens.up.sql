@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(32) NOT NULL,
+    token_hash   VARCHAR(64) NOT NULL,
+    scopes       VARCHAR(255) NOT NULL,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_tokens_token_hash ON api_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens (user_id);
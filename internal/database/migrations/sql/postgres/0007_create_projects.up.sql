@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id         BIGSERIAL PRIMARY KEY,
+    owner_id   BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(100) NOT NULL,
+    color      VARCHAR(7) NOT NULL,
+    archived   BOOLEAN NOT NULL DEFAULT FALSE,
+    position   INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_owner_id ON projects (owner_id);
+
+-- Tasks without a project are in the owner's inbox.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id BIGINT REFERENCES projects (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks (project_id);
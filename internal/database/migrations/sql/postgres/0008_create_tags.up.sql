@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id         BIGSERIAL PRIMARY KEY,
+    owner_id   BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+-- Tag names are unique per owner; this also serves owner lookups.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_owner_id_name ON tags (owner_id, name);
+
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    tag_id  BIGINT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag_id ON task_tags (tag_id);
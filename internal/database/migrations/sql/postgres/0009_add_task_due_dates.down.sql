@@ -0,0 +1,7 @@
+ALTER TABLE users DROP COLUMN IF EXISTS time_zone;
+
+DROP INDEX IF EXISTS idx_tasks_owner_id_due_at;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_at;
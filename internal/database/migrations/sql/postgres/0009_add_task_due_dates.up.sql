@@ -0,0 +1,11 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+-- Tasks completed before completion times were recorded use their last update.
+UPDATE tasks SET completed_at = updated_at WHERE completed AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_id_due_at ON tasks (owner_id, due_at);
+
+-- IANA time zone used to compute "today" for the user.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,4 @@
+-- Subtasks point at their parent task and are deleted along with it.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS parent_id BIGINT REFERENCES tasks (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks (parent_id);
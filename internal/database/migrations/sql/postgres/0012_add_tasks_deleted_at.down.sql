@@ -0,0 +1,6 @@
+-- Trashed tasks cannot be told apart once the column is gone.
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+-- Every write to a task increments its version, which clients send back in
+-- If-Match to detect concurrent changes.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
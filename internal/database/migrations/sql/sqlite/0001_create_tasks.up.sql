@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS tasks (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    content    VARCHAR(1000) NOT NULL,
+    completed  NUMERIC NOT NULL DEFAULT false,
+    created_at DATETIME,
+    updated_at DATETIME
+);
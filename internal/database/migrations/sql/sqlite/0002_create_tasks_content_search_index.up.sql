@@ -0,0 +1 @@
+-- Full-text search is PostgreSQL-only; SQLite searches with LIKE matching.
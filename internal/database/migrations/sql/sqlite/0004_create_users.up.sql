@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS users (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    email         VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at    DATETIME,
+    updated_at    DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
@@ -0,0 +1,5 @@
+-- Tasks created before accounts existed keep a NULL owner and are not
+-- visible to any user.
+ALTER TABLE tasks ADD COLUMN owner_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_id ON tasks (owner_id);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id   INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(100) NOT NULL,
+    color      VARCHAR(7) NOT NULL,
+    archived   BOOLEAN NOT NULL DEFAULT FALSE,
+    position   INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_owner_id ON projects (owner_id);
+
+-- Tasks without a project are in the owner's inbox.
+ALTER TABLE tasks ADD COLUMN project_id INTEGER REFERENCES projects (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks (project_id);
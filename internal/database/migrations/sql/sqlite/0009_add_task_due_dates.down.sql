@@ -0,0 +1,7 @@
+ALTER TABLE users DROP COLUMN time_zone;
+
+DROP INDEX IF EXISTS idx_tasks_owner_id_due_at;
+
+ALTER TABLE tasks DROP COLUMN completed_at;
+ALTER TABLE tasks DROP COLUMN priority;
+ALTER TABLE tasks DROP COLUMN due_at;
@@ -0,0 +1,11 @@
+ALTER TABLE tasks ADD COLUMN due_at DATETIME;
+ALTER TABLE tasks ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN completed_at DATETIME;
+
+-- Tasks completed before completion times were recorded use their last update.
+UPDATE tasks SET completed_at = updated_at WHERE completed AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_id_due_at ON tasks (owner_id, due_at);
+
+-- IANA time zone used to compute "today" for the user.
+ALTER TABLE users ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
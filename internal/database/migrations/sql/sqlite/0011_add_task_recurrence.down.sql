@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_series_id;
+
+ALTER TABLE tasks DROP COLUMN occurrence;
+ALTER TABLE tasks DROP COLUMN series_id;
+ALTER TABLE tasks DROP COLUMN recurrence;
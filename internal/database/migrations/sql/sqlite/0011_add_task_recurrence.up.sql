@@ -0,0 +1,8 @@
+-- Recurring tasks carry their rule; the occurrences of a series share the
+-- ID of its first task, which is not a foreign key so that the series stays
+-- linked when that task is deleted.
+ALTER TABLE tasks ADD COLUMN recurrence VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN series_id INTEGER;
+ALTER TABLE tasks ADD COLUMN occurrence INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_series_id ON tasks (series_id);
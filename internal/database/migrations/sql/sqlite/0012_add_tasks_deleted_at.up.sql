@@ -0,0 +1,4 @@
+-- Deleted tasks stay in the trash until restored or purged.
+ALTER TABLE tasks ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks (deleted_at);
@@ -0,0 +1,18 @@
+-- Requests made with an Idempotency-Key header, keyed per user. The status
+-- is 0 while the first request is in flight, which locks the key until
+-- locked_until; the response is stored once it is done so that retries can
+-- replay it until expires_at.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id         INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint     VARCHAR(64) NOT NULL,
+    status          INTEGER NOT NULL DEFAULT 0,
+    headers         TEXT NOT NULL DEFAULT '',
+    body            BLOB,
+    locked_until    DATETIME NOT NULL,
+    expires_at      DATETIME NOT NULL,
+    created_at      DATETIME,
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
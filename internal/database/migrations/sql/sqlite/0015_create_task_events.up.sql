@@ -0,0 +1,20 @@
+-- Append-only history of the writes to tasks, one row per task written.
+-- state holds the task's document after the write and changes the fields
+-- an update changed, both as JSON. Events have no foreign key to their task
+-- so that they outlive it in the audit log.
+CREATE TABLE IF NOT EXISTS task_events (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id   INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    task_id    INTEGER NOT NULL,
+    type       VARCHAR(20) NOT NULL,
+    version    INTEGER NOT NULL,
+    actor_id   INTEGER NOT NULL,
+    token_id   INTEGER,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    changes    TEXT NOT NULL DEFAULT '{}',
+    state      TEXT NOT NULL,
+    created_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_owner_id ON task_events (owner_id, id);
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id ON task_events (task_id, version);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS outbox_messages;
+DROP TABLE IF EXISTS webhooks;
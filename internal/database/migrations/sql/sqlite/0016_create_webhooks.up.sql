@@ -0,0 +1,49 @@
+-- Webhook subscriptions of users to the events of their tasks. events is a
+-- space-separated list of event types, such as "task.created task.deleted".
+CREATE TABLE IF NOT EXISTS webhooks (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id   INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url        VARCHAR(2048) NOT NULL,
+    secret     VARCHAR(255) NOT NULL,
+    events     VARCHAR(255) NOT NULL,
+    active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_owner_id ON webhooks (owner_id);
+
+-- Transactional outbox: one message per task event, written in the same
+-- transaction as the event and removed once it has been turned into
+-- deliveries to the subscribed webhooks.
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id   INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event_id   INTEGER NOT NULL,
+    type       VARCHAR(40) NOT NULL,
+    payload    TEXT NOT NULL,
+    created_at DATETIME
+);
+
+-- Deliveries of messages to webhooks. Pending deliveries are attempted at
+-- next_attempt_at; dead ones ran out of attempts and wait in the dead-letter
+-- list until redelivered.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id      INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    owner_id        INTEGER NOT NULL,
+    event_id        INTEGER NOT NULL,
+    type            VARCHAR(40) NOT NULL,
+    payload         TEXT NOT NULL,
+    status          VARCHAR(20) NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME,
+    last_attempt_at DATETIME,
+    response_status INTEGER,
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at      DATETIME,
+    updated_at      DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
@@ -0,0 +1,206 @@
+// Package events fans out changes to tasks to the clients streaming them.
+// The task service publishes to a Bus, which keeps a bounded buffer of
+// recent events so that clients can resume where they left off.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Types of the events published on the bus
+const (
+	TaskCreated  = "task.created"
+	TaskUpdated  = "task.updated"
+	TaskDeleted  = "task.deleted"
+	TaskRestored = "task.restored"
+	TaskPurged   = "task.purged"
+)
+
+// subscriptionBuffer is how many events a subscriber may fall behind before
+// it is dropped
+const subscriptionBuffer = 64
+
+// Event is a change to one of a user's tasks. Data is the task as returned
+// by the API, or only its ID when it was deleted or purged. ID is assigned
+// by the bus that delivers the event.
+type Event struct {
+	ID      string          `json:"-"`
+	OwnerID uint            `json:"owner_id"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Publisher is implemented by the destinations of task events
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Forwarder sends published events to every replica's bus, including this
+// one, which delivers them when they come back
+type Forwarder interface {
+	Forward(event Event) error
+}
+
+// Discard is a Publisher that drops every event
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(Event) {}
+
+// Bus delivers events to the subscribers of their owner and keeps the most
+// recent ones for replay. Event IDs are made of an ID of the bus and a
+// sequence number, so that IDs issued by another process or before a
+// restart are recognized as unknown. It is safe for concurrent use.
+type Bus struct {
+	mu        sync.Mutex
+	id        string
+	seq       uint64
+	replay    []Event
+	size      int
+	subs      map[*Subscription]struct{}
+	forwarder Forwarder
+}
+
+// NewBus creates a Bus that keeps up to size events for replay
+func NewBus(size int) *Bus {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return &Bus{
+		id:     hex.EncodeToString(b),
+		size:   size,
+		subs:   make(map[*Subscription]struct{}),
+		replay: make([]Event, 0, size),
+	}
+}
+
+// SetForwarder makes the bus send published events through forwarder
+// instead of delivering them itself
+func (b *Bus) SetForwarder(forwarder Forwarder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.forwarder = forwarder
+}
+
+// Publish delivers an event, through the forwarder when there is one. If
+// forwarding fails the event is delivered to this bus only.
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	forwarder := b.forwarder
+	b.mu.Unlock()
+
+	if forwarder != nil {
+		err := forwarder.Forward(event)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to forward %s event, delivering it to this replica only: %v", event.Type, err)
+	}
+	b.Deliver(event)
+}
+
+// Deliver assigns the event its ID, keeps it for replay and sends it to
+// the subscribers of its owner. Subscribers that have fallen too far behind
+// are dropped; they can resume from the last event they received.
+func (b *Bus) Deliver(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.ID = b.id + "-" + strconv.FormatUint(b.seq, 10)
+	if b.size > 0 {
+		if len(b.replay) == b.size {
+			b.replay = append(b.replay[:0], b.replay[1:]...)
+		}
+		b.replay = append(b.replay, event)
+	}
+
+	for sub := range b.subs {
+		if sub.ownerID != event.OwnerID {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe starts delivering the owner's events to a new subscription.
+// When lastEventID is given, the owner's buffered events after it are
+// returned for replay; resumed is false if events after it may have been
+// missed, because it is unknown or older than the buffer.
+func (b *Bus) Subscribe(ownerID uint, lastEventID string) (sub *Subscription, replay []Event, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &Subscription{bus: b, ownerID: ownerID, events: make(chan Event, subscriptionBuffer)}
+	b.subs[sub] = struct{}{}
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+
+	seq, ok := b.sequence(lastEventID)
+	if !ok || seq > b.seq {
+		return sub, nil, false
+	}
+	resumed = seq == b.seq || (len(b.replay) > 0 && b.sequenceOf(b.replay[0]) <= seq+1)
+	for _, event := range b.replay {
+		if event.OwnerID == ownerID && b.sequenceOf(event) > seq {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, resumed
+}
+
+// drop removes a subscription and closes its channel. The caller must hold
+// the lock.
+func (b *Bus) drop(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+// sequence parses an event ID issued by this bus
+func (b *Bus) sequence(id string) (uint64, bool) {
+	prefix, seq, ok := strings.Cut(id, "-")
+	if !ok || prefix != b.id {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
+
+// sequenceOf returns the sequence number of an event delivered by this bus
+func (b *Bus) sequenceOf(event Event) uint64 {
+	seq, _ := b.sequence(event.ID)
+	return seq
+}
+
+// Subscription receives the events of one owner from a Bus
+type Subscription struct {
+	bus     *Bus
+	ownerID uint
+	events  chan Event
+}
+
+// Events returns the channel the events are received on. It is closed when
+// the subscription is closed or dropped for falling behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
@@ -0,0 +1,140 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func taskEvent(ownerID uint, eventType string, id int) Event {
+	data, _ := json.Marshal(map[string]int{"id": id})
+	return Event{OwnerID: ownerID, Type: eventType, Data: data}
+}
+
+// receive returns the events waiting on a subscription
+func receive(sub *Subscription) []Event {
+	var received []Event
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return received
+			}
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func TestBus_DeliversToOwner(t *testing.T) {
+	bus := NewBus(10)
+	mine, _, _ := bus.Subscribe(1, "")
+	theirs, _, _ := bus.Subscribe(2, "")
+
+	bus.Publish(taskEvent(1, TaskCreated, 5))
+	bus.Publish(taskEvent(2, TaskCreated, 6))
+	bus.Publish(taskEvent(1, TaskDeleted, 5))
+
+	received := receive(mine)
+	require.Len(t, received, 2)
+	assert.Equal(t, TaskCreated, received[0].Type)
+	assert.Equal(t, TaskDeleted, received[1].Type)
+	assert.NotEqual(t, received[0].ID, received[1].ID)
+	assert.Len(t, receive(theirs), 1)
+
+	mine.Close()
+	bus.Publish(taskEvent(1, TaskUpdated, 5))
+	_, open := <-mine.Events()
+	assert.False(t, open)
+}
+
+func TestBus_Resume(t *testing.T) {
+	bus := NewBus(3)
+	bus.Publish(taskEvent(1, TaskCreated, 1))
+	first, _, _ := bus.Subscribe(1, "")
+	bus.Publish(taskEvent(1, TaskCreated, 2))
+	bus.Publish(taskEvent(2, TaskCreated, 3))
+	bus.Publish(taskEvent(1, TaskCreated, 4))
+	seen := receive(first)
+	require.Len(t, seen, 2)
+
+	// Everything after the first event seen is still buffered
+	_, replay, resumed := bus.Subscribe(1, seen[0].ID)
+	assert.True(t, resumed)
+	require.Len(t, replay, 1)
+	assert.Equal(t, seen[1].ID, replay[0].ID)
+
+	_, replay, resumed = bus.Subscribe(1, seen[1].ID)
+	assert.True(t, resumed)
+	assert.Empty(t, replay)
+
+	// The event after this one has been pushed out of the buffer
+	bus.Publish(taskEvent(1, TaskCreated, 5))
+	bus.Publish(taskEvent(1, TaskCreated, 6))
+	_, replay, resumed = bus.Subscribe(1, seen[0].ID)
+	assert.False(t, resumed)
+	assert.Len(t, replay, 3)
+
+	for _, id := range []string{"garbage", "0000-1", NewBus(3).id + "-1"} {
+		_, replay, resumed = bus.Subscribe(1, id)
+		assert.False(t, resumed, id)
+		assert.Empty(t, replay, id)
+	}
+}
+
+func TestBus_DropsSlowSubscribers(t *testing.T) {
+	bus := NewBus(0)
+	slow, _, _ := bus.Subscribe(1, "")
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		bus.Publish(taskEvent(1, TaskUpdated, i))
+	}
+
+	assert.Len(t, receive(slow), subscriptionBuffer)
+	_, open := <-slow.Events()
+	assert.False(t, open)
+	slow.Close()
+}
+
+// failingForwarder refuses every event
+type failingForwarder struct{ forwarded int }
+
+func (f *failingForwarder) Forward(Event) error {
+	f.forwarded++
+	return errors.New("connection refused")
+}
+
+// loopbackForwarder delivers events to every bus it connects, like a
+// notification channel shared by replicas
+type loopbackForwarder struct{ buses []*Bus }
+
+func (f *loopbackForwarder) Forward(event Event) error {
+	for _, bus := range f.buses {
+		bus.Deliver(event)
+	}
+	return nil
+}
+
+func TestBus_Forwarder(t *testing.T) {
+	replicaA, replicaB := NewBus(10), NewBus(10)
+	forwarder := &loopbackForwarder{buses: []*Bus{replicaA, replicaB}}
+	replicaA.SetForwarder(forwarder)
+	replicaB.SetForwarder(forwarder)
+	onA, _, _ := replicaA.Subscribe(1, "")
+	onB, _, _ := replicaB.Subscribe(1, "")
+
+	replicaA.Publish(taskEvent(1, TaskCreated, 1))
+
+	assert.Len(t, receive(onA), 1)
+	assert.Len(t, receive(onB), 1)
+
+	failing := &failingForwarder{}
+	replicaA.SetForwarder(failing)
+	replicaA.Publish(taskEvent(1, TaskCreated, 2))
+	assert.Equal(t, 1, failing.forwarded)
+	assert.Len(t, receive(onA), 1, "delivered locally when forwarding fails")
+}
@@ -0,0 +1,96 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// maxNotifyPayload is the largest payload PostgreSQL accepts for NOTIFY,
+// less some room
+const maxNotifyPayload = 7900
+
+// reconnectDelay is how long the bridge waits before listening again after
+// losing its connection
+const reconnectDelay = 5 * time.Second
+
+// PostgresBridge forwards the events published on a bus to the buses of
+// every replica with PostgreSQL LISTEN/NOTIFY, so that each replica's
+// clients receive the changes made through the others
+type PostgresBridge struct {
+	db      *gorm.DB
+	dsn     string
+	channel string
+	bus     *Bus
+}
+
+// NewPostgresBridge creates a bridge for bus that notifies through db and
+// listens on its own connection to dsn, using channel for its
+// notifications, and makes it the bus's forwarder. Run must be called for
+// events to be delivered.
+func NewPostgresBridge(db *gorm.DB, dsn, channel string, bus *Bus) *PostgresBridge {
+	bridge := &PostgresBridge{db: db, dsn: dsn, channel: channel, bus: bus}
+	bus.SetForwarder(bridge)
+	return bridge
+}
+
+// Forward notifies every replica of an event. Events too large for a
+// notification are refused and left to the caller.
+func (p *PostgresBridge) Forward(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("event of %d bytes is too large to notify", len(payload))
+	}
+	return p.db.Exec("SELECT pg_notify(?, ?)", p.channel, string(payload)).Error
+}
+
+// Run listens for notifications and delivers their events to the bus until
+// ctx is done, reconnecting after failures
+func (p *PostgresBridge) Run(ctx context.Context) {
+	for {
+		err := p.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Lost the event notification connection, listening again in %s: %v", reconnectDelay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// listen delivers the events of notifications on one connection until it
+// fails or ctx is done
+func (p *PostgresBridge) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, p.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{p.channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Printf("Ignoring malformed event notification: %v", err)
+			continue
+		}
+		p.bus.Deliver(event)
+	}
+}
@@ -0,0 +1,173 @@
+// Package graph serves a GraphQL API over tasks and projects, resolved
+// through the services with batched loads of related tasks, subtasks,
+// projects and history, and with limits on the depth and complexity of
+// queries.
+package graph
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/pagination"
+	"github.com/todo-api-go-sda/internal/services"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// Schema executes GraphQL requests against the services. It is safe for
+// concurrent use.
+type Schema struct {
+	schema          graphql.Schema
+	tasks           services.TaskService
+	projects        services.ProjectService
+	cursors         *pagination.Codec
+	defaultPageSize int
+	maxPageSize     int
+	maxDepth        int
+	maxComplexity   int
+}
+
+// NewSchema creates a Schema reading tasks and projects from the services.
+// Connections follow the pagination settings of serverCfg and queries the
+// limits of graphqlCfg.
+func NewSchema(tasks services.TaskService, projects services.ProjectService, serverCfg *config.ServerConfig, graphqlCfg *config.GraphQLConfig) (*Schema, error) {
+	s := &Schema{
+		tasks:           tasks,
+		projects:        projects,
+		cursors:         pagination.NewCodec(serverCfg.CursorSecret),
+		defaultPageSize: min(serverCfg.DefaultPageSize, serverCfg.MaxPageSize),
+		maxPageSize:     serverCfg.MaxPageSize,
+		maxDepth:        graphqlCfg.MaxDepth,
+		maxComplexity:   graphqlCfg.MaxComplexity,
+	}
+	schema, err := s.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// Caller describes who a request is made by: the user making its writes,
+// the time zone due dates are read in and what the credential allows
+type Caller struct {
+	Actor     *models.Actor
+	Location  *time.Location
+	CanWrite  bool
+	CanDelete bool
+}
+
+// Request is a GraphQL request
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// requestKey is the context key of the state of a request
+type requestKey struct{}
+
+// request holds the state of a request shared by its resolvers
+type request struct {
+	caller  Caller
+	loaders *loaders
+}
+
+// requestFrom returns the state of the request being resolved
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// Execute runs a request for caller. Errors carry the error code of the
+// REST API in extensions.code; requests that are malformed, invalid or
+// over the limits fail with VALIDATION_ERROR before anything is resolved.
+func (s *Schema) Execute(ctx context.Context, caller Caller, req Request) *graphql.Result {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: formatErrors(gqlerrors.FormatErrors(err))}
+	}
+	if validation := graphql.ValidateDocument(&s.schema, document, nil); !validation.IsValid {
+		return &graphql.Result{Errors: formatErrors(validation.Errors)}
+	}
+	if err := s.checkLimits(document, req.OperationName, req.Variables); err != nil {
+		return &graphql.Result{Errors: formatErrors(gqlerrors.FormatErrors(err))}
+	}
+
+	ctx = context.WithValue(ctx, requestKey{}, &request{
+		caller:  caller,
+		loaders: newLoaders(s.tasks, s.projects, caller.Actor.UserID),
+	})
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	result.Errors = formatErrors(result.Errors)
+	return result
+}
+
+// formatErrors describes errors like the REST API does. Errors of fields
+// keep the message and code of the service error that caused them, with
+// internal errors masked; the others are errors of the request itself.
+func formatErrors(errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	for i, err := range errs {
+		if len(err.Path) == 0 {
+			err.Extensions = map[string]interface{}{"code": apperrors.CodeValidationError}
+			errs[i] = err
+			continue
+		}
+
+		cause := rootError(err)
+		_, detail := apperrors.Describe(cause)
+		err.Message = detail.Message
+		err.Extensions = map[string]interface{}{"code": detail.Code}
+		// Version conflicts tell the current version so the caller can retry
+		if conflict, ok := cause.(*apperrors.VersionConflictError); ok {
+			if current, ok := conflict.Current.(*models.Task); ok {
+				err.Extensions["currentVersion"] = current.Version
+			}
+		}
+		errs[i] = err
+	}
+	return errs
+}
+
+// rootError unwraps the errors the executor wraps around the error of a
+// resolver
+func rootError(err error) error {
+	for {
+		switch e := err.(type) {
+		case gqlerrors.FormattedError:
+			if e.OriginalError() == nil {
+				return err
+			}
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			if e.OriginalError == nil {
+				return err
+			}
+			err = e.OriginalError
+		default:
+			return err
+		}
+	}
+}
+
+// parseID parses the ID argument or input field called name
+func parseID(name string, value interface{}) (uint, error) {
+	id, err := strconv.ParseUint(value.(string), 10, 32)
+	if err != nil {
+		return 0, &apperrors.ValidationError{Message: name + " must be an ID"}
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,389 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	"github.com/todo-api-go-sda/internal/services"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// testUserID is the user making the requests of these tests
+const testUserID = uint(1)
+
+// testActor makes the writes of these tests
+var testActor = &models.Actor{UserID: testUserID}
+
+// countingTasks counts the batched reads of a TaskService
+type countingTasks struct {
+	services.TaskService
+	calls map[string]int
+}
+
+func (c *countingTasks) GetTasksByIDs(ownerID uint, ids []uint) ([]models.Task, error) {
+	c.calls["GetTasksByIDs"]++
+	return c.TaskService.GetTasksByIDs(ownerID, ids)
+}
+
+func (c *countingTasks) ListSubtasksOf(ownerID uint, ids []uint) ([]models.Task, error) {
+	c.calls["ListSubtasksOf"]++
+	return c.TaskService.ListSubtasksOf(ownerID, ids)
+}
+
+func (c *countingTasks) ListRecentTaskEvents(ownerID uint, ids []uint, limit int) ([]models.TaskEvent, error) {
+	c.calls["ListRecentTaskEvents"]++
+	return c.TaskService.ListRecentTaskEvents(ownerID, ids, limit)
+}
+
+// countingProjects counts the project listings of a ProjectService
+type countingProjects struct {
+	services.ProjectService
+	calls map[string]int
+}
+
+func (c *countingProjects) ListProjects(ownerID uint, includeArchived bool) ([]models.Project, error) {
+	c.calls["ListProjects"]++
+	return c.ProjectService.ListProjects(ownerID, includeArchived)
+}
+
+// testSchema serves the schema over in-memory storage to testUserID
+type testSchema struct {
+	*Schema
+	tasks    services.TaskService
+	projects services.ProjectService
+	calls    map[string]int
+}
+
+func newTestSchema(t *testing.T, limits *config.GraphQLConfig) *testSchema {
+	taskRepo := repository.NewMemoryTaskRepository()
+	projectRepo := repository.NewMemoryProjectRepository(taskRepo)
+	tasks := services.NewTaskService(taskRepo, projectRepo, repository.NewMemoryTagRepository(taskRepo),
+		&config.TaskConfig{MaxSubtaskDepth: 2}, nil)
+	projects := services.NewProjectService(projectRepo)
+	calls := make(map[string]int)
+
+	schema, err := NewSchema(&countingTasks{TaskService: tasks, calls: calls}, &countingProjects{ProjectService: projects, calls: calls},
+		&config.ServerConfig{DefaultPageSize: 50, MaxPageSize: 100}, limits)
+	require.NoError(t, err)
+	return &testSchema{Schema: schema, tasks: tasks, projects: projects, calls: calls}
+}
+
+// fullAccess may read, write and delete tasks
+var fullAccess = Caller{Actor: testActor, Location: time.UTC, CanWrite: true, CanDelete: true}
+
+// defaultLimits are the limits of the default configuration
+var defaultLimits = &config.GraphQLConfig{MaxDepth: 10, MaxComplexity: 20000}
+
+// execute runs a query, decodes its data into data and returns its errors
+func (s *testSchema) execute(t *testing.T, caller Caller, query string, variables map[string]interface{}, data interface{}) []gqlerrors.FormattedError {
+	result := s.Execute(context.Background(), caller, Request{Query: query, Variables: variables})
+	if data != nil && result.Data != nil {
+		body, err := json.Marshal(result.Data)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, data))
+	}
+	return result.Errors
+}
+
+// createTask creates a task of testUserID
+func (s *testSchema) createTask(t *testing.T, req *models.CreateTaskRequest) *models.Task {
+	task, err := s.tasks.CreateTask(testActor, req, time.UTC)
+	require.NoError(t, err)
+	return task
+}
+
+// errorCodes returns the extensions.code of errors
+func errorCodes(errs []gqlerrors.FormattedError) []interface{} {
+	codes := make([]interface{}, len(errs))
+	for i, err := range errs {
+		codes[i] = err.Extensions["code"]
+	}
+	return codes
+}
+
+type taskNode struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+	Tags    []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+	Project *struct {
+		Name string `json:"name"`
+	} `json:"project"`
+	Parent *struct {
+		Content string `json:"content"`
+	} `json:"parent"`
+	Subtasks []taskNode `json:"subtasks"`
+	History  []struct {
+		Type    string `json:"type"`
+		Version int    `json:"version"`
+		State   struct {
+			Content string `json:"content"`
+		} `json:"state"`
+	} `json:"history"`
+}
+
+type taskConnection struct {
+	Edges []struct {
+		Cursor string   `json:"cursor"`
+		Node   taskNode `json:"node"`
+	} `json:"edges"`
+	PageInfo struct {
+		HasNextPage     bool   `json:"hasNextPage"`
+		HasPreviousPage bool   `json:"hasPreviousPage"`
+		StartCursor     string `json:"startCursor"`
+		EndCursor       string `json:"endCursor"`
+	} `json:"pageInfo"`
+	TotalCount *int `json:"totalCount"`
+}
+
+// contents returns the content of the nodes of a connection
+func (c *taskConnection) contents() []string {
+	contents := make([]string, len(c.Edges))
+	for i, edge := range c.Edges {
+		contents[i] = edge.Node.Content
+	}
+	return contents
+}
+
+func TestQuery_NestedFieldsAreBatched(t *testing.T) {
+	s := newTestSchema(t, defaultLimits)
+	project, err := s.projects.CreateProject(testUserID, &models.CreateProjectRequest{Name: "Home"})
+	require.NoError(t, err)
+	for _, content := range []string{"Clean", "Cook", "Shop"} {
+		task := s.createTask(t, &models.CreateTaskRequest{Content: content, ProjectID: &project.ID, Tags: []string{"chores"}})
+		for _, step := range []string{"first", "second"} {
+			subtask := s.createTask(t, &models.CreateTaskRequest{Content: content + " " + step, ParentID: &task.ID})
+			s.createTask(t, &models.CreateTaskRequest{Content: content + " " + step + " detail", ParentID: &subtask.ID})
+		}
+		renamed := content + "!"
+		_, err := s.tasks.UpdateTask(testActor, task.ID, &models.UpdateTaskRequest{Content: &renamed}, time.UTC, nil)
+		require.NoError(t, err)
+	}
+
+	var data struct {
+		Tasks taskConnection `json:"tasks"`
+	}
+	errs := s.execute(t, fullAccess, `{
+		tasks(filter: {parentId: "0"}, orderBy: [{field: CONTENT}]) {
+			edges { node {
+				content
+				tags { name }
+				project { name }
+				history(first: 1) { type version state }
+				subtasks {
+					content
+					parent { content }
+					subtasks { content }
+				}
+			} }
+		}
+	}`, nil, &data)
+
+	require.Empty(t, errs)
+	require.Equal(t, []string{"Clean!", "Cook!", "Shop!"}, data.Tasks.contents())
+	node := data.Tasks.Edges[0].Node
+	assert.Equal(t, "chores", node.Tags[0].Name)
+	assert.Equal(t, "Home", node.Project.Name)
+	require.Len(t, node.History, 1)
+	assert.Equal(t, models.TaskEventUpdated, node.History[0].Type)
+	assert.Equal(t, 2, node.History[0].Version)
+	assert.Equal(t, "Clean!", node.History[0].State.Content)
+	require.Len(t, node.Subtasks, 2)
+	assert.Equal(t, "Clean first", node.Subtasks[0].Content)
+	assert.Equal(t, "Clean!", node.Subtasks[0].Parent.Content)
+	assert.Equal(t, "Clean first detail", node.Subtasks[0].Subtasks[0].Content)
+
+	// One query per level of subtasks and one per kind of related value,
+	// however many tasks there are
+	assert.Equal(t, map[string]int{
+		"ListSubtasksOf":       2,
+		"GetTasksByIDs":        1,
+		"ListRecentTaskEvents": 1,
+		"ListProjects":         1,
+	}, s.calls)
+}
+
+func TestQuery_TasksConnection(t *testing.T) {
+	s := newTestSchema(t, defaultLimits)
+	for _, content := range []string{"A", "B", "C", "D", "E"} {
+		s.createTask(t, &models.CreateTaskRequest{Content: content})
+	}
+	const query = `query ($first: Int, $after: String, $last: Int, $before: String) {
+		tasks(first: $first, after: $after, last: $last, before: $before, orderBy: [{field: CONTENT}]) {
+			edges { cursor node { content } }
+			pageInfo { hasNextPage hasPreviousPage startCursor endCursor }
+			totalCount
+		}
+	}`
+	page := func(variables map[string]interface{}) taskConnection {
+		var data struct {
+			Tasks taskConnection `json:"tasks"`
+		}
+		require.Empty(t, s.execute(t, fullAccess, query, variables, &data))
+		return data.Tasks
+	}
+
+	first := page(map[string]interface{}{"first": 2})
+	assert.Equal(t, []string{"A", "B"}, first.contents())
+	assert.True(t, first.PageInfo.HasNextPage)
+	assert.False(t, first.PageInfo.HasPreviousPage)
+	assert.Equal(t, first.Edges[1].Cursor, first.PageInfo.EndCursor)
+	require.NotNil(t, first.TotalCount)
+	assert.Equal(t, 5, *first.TotalCount)
+
+	second := page(map[string]interface{}{"first": 2, "after": first.PageInfo.EndCursor})
+	assert.Equal(t, []string{"C", "D"}, second.contents())
+	assert.True(t, second.PageInfo.HasNextPage)
+	assert.True(t, second.PageInfo.HasPreviousPage)
+
+	last := page(map[string]interface{}{"first": 2, "after": second.PageInfo.EndCursor})
+	assert.Equal(t, []string{"E"}, last.contents())
+	assert.False(t, last.PageInfo.HasNextPage)
+
+	// Any edge's cursor pages backward from it
+	back := page(map[string]interface{}{"last": 2, "before": second.Edges[1].Cursor})
+	assert.Equal(t, []string{"B", "C"}, back.contents())
+	assert.True(t, back.PageInfo.HasNextPage)
+	assert.True(t, back.PageInfo.HasPreviousPage)
+}
+
+func TestQuery_TasksConnectionErrors(t *testing.T) {
+	s := newTestSchema(t, defaultLimits)
+	for name, query := range map[string]string{
+		"page too large":      `{ tasks(first: 101) { totalCount } }`,
+		"last without before": `{ tasks(last: 2) { totalCount } }`,
+		"tampered cursor":     `{ tasks(after: "nope") { totalCount } }`,
+		"duplicate order":     `{ tasks(orderBy: [{field: CONTENT}, {field: CONTENT, direction: DESC}]) { totalCount } }`,
+		"empty tag":           `{ tasks(filter: {tags: [" "]}) { totalCount } }`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			errs := s.execute(t, fullAccess, query, nil, nil)
+			require.Len(t, errs, 1)
+			assert.Equal(t, apperrors.CodeValidationError, errs[0].Extensions["code"])
+			assert.Equal(t, []interface{}{"tasks"}, errs[0].Path)
+		})
+	}
+}
+
+func TestMutations(t *testing.T) {
+	s := newTestSchema(t, defaultLimits)
+	type taskResult struct {
+		ID        string `json:"id"`
+		Content   string `json:"content"`
+		Completed bool   `json:"completed"`
+		Priority  string `json:"priority"`
+		DueAt     string `json:"dueAt"`
+		Version   int    `json:"version"`
+		Tags      []struct {
+			Name string `json:"name"`
+		} `json:"tags"`
+	}
+	const fields = `id content completed priority dueAt version tags { name }`
+
+	var created struct {
+		CreateTask taskResult `json:"createTask"`
+	}
+	errs := s.execute(t, fullAccess, `mutation ($input: CreateTaskInput!) { createTask(input: $input) { `+fields+` } }`,
+		map[string]interface{}{"input": map[string]interface{}{
+			"content": "Write report", "priority": "HIGH", "dueAt": "2026-10-20", "tags": []interface{}{"Work"},
+		}}, &created)
+	require.Empty(t, errs)
+	task := created.CreateTask
+	assert.Equal(t, "Write report", task.Content)
+	assert.Equal(t, "HIGH", task.Priority)
+	assert.Equal(t, "2026-10-20T23:59:59Z", task.DueAt)
+	assert.Equal(t, "work", task.Tags[0].Name)
+
+	var updated struct {
+		UpdateTask taskResult `json:"updateTask"`
+	}
+	errs = s.execute(t, fullAccess, `mutation ($id: ID!) { updateTask(id: $id, input: {content: "Send report", tags: [], dueAt: ""}, version: 1) { `+fields+` } }`,
+		map[string]interface{}{"id": task.ID}, &updated)
+	require.Empty(t, errs)
+	assert.Equal(t, "Send report", updated.UpdateTask.Content)
+	assert.Empty(t, updated.UpdateTask.Tags)
+	assert.Empty(t, updated.UpdateTask.DueAt)
+	assert.Equal(t, 2, updated.UpdateTask.Version)
+
+	// Writes at a stale version tell the current one
+	errs = s.execute(t, fullAccess, `mutation ($id: ID!) { completeTask(id: $id, version: 1) { id } }`,
+		map[string]interface{}{"id": task.ID}, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, apperrors.CodeVersionConflict, errs[0].Extensions["code"])
+	assert.EqualValues(t, 2, errs[0].Extensions["currentVersion"])
+
+	var completed struct {
+		CompleteTask taskResult `json:"completeTask"`
+	}
+	errs = s.execute(t, fullAccess, `mutation ($id: ID!) { completeTask(id: $id, version: 2) { `+fields+` } }`,
+		map[string]interface{}{"id": task.ID}, &completed)
+	require.Empty(t, errs)
+	assert.True(t, completed.CompleteTask.Completed)
+
+	var deleted struct {
+		DeleteTask string `json:"deleteTask"`
+	}
+	errs = s.execute(t, fullAccess, `mutation ($id: ID!) { deleteTask(id: $id) }`, map[string]interface{}{"id": task.ID}, &deleted)
+	require.Empty(t, errs)
+	assert.Equal(t, task.ID, deleted.DeleteTask)
+
+	errs = s.execute(t, fullAccess, `query ($id: ID!) { task(id: $id) { id } }`, map[string]interface{}{"id": task.ID}, nil)
+	assert.Equal(t, []interface{}{apperrors.CodeTaskNotFound}, errorCodes(errs))
+}
+
+func TestMutations_RequireScopes(t *testing.T) {
+	s := newTestSchema(t, defaultLimits)
+	task := s.createTask(t, &models.CreateTaskRequest{Content: "Read only"})
+	readOnly := Caller{Actor: testActor, Location: time.UTC}
+	variables := map[string]interface{}{"id": formatID(task.ID)}
+
+	for _, query := range []string{
+		`mutation { createTask(input: {content: "Nope"}) { id } }`,
+		`mutation ($id: ID!) { completeTask(id: $id) { id } }`,
+		`mutation ($id: ID!) { deleteTask(id: $id) }`,
+	} {
+		errs := s.execute(t, readOnly, query, variables, nil)
+		assert.Equal(t, []interface{}{apperrors.CodeForbidden}, errorCodes(errs), query)
+	}
+
+	// Reads are allowed
+	var data struct {
+		Task taskNode `json:"task"`
+	}
+	require.Empty(t, s.execute(t, readOnly, `query ($id: ID!) { task(id: $id) { content } }`, variables, &data))
+	assert.Equal(t, "Read only", data.Task.Content)
+}
+
+func TestExecute_RequestErrors(t *testing.T) {
+	s := newTestSchema(t, defaultLimits)
+	for name, query := range map[string]string{
+		"syntax":        `{ tasks {`,
+		"unknown field": `{ tasks { nodes { id } } }`,
+		"bad argument":  `{ task(id: true) { id } }`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			errs := s.execute(t, fullAccess, query, nil, nil)
+			require.NotEmpty(t, errs)
+			assert.Equal(t, apperrors.CodeValidationError, errs[0].Extensions["code"])
+		})
+	}
+
+	errs := s.execute(t, fullAccess, `{ task(id: "x") { id } }`, nil, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "id must be an ID", errs[0].Message)
+	assert.Equal(t, []interface{}{"task"}, errs[0].Path)
+
+	errs = s.execute(t, fullAccess, `mutation { createTask(input: {content: "Task", tags: ["home", "`+strings.Repeat("x", 51)+`"]}) { id } }`, nil, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "tags[1] must be at most 50 characters long", errs[0].Message)
+}
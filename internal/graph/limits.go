@@ -0,0 +1,166 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// analysis walks the selected operation of a validated document
+type analysis struct {
+	schema    *Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkLimits rejects an operation whose fields nest deeper than the
+// maximum depth or whose complexity is over the maximum. Each field costs
+// one plus the cost of its fields, times the number of items it returns:
+// the page size asked for with first or last, or an estimate for other
+// lists. Introspection is free.
+func (s *Schema) checkLimits(document *ast.Document, operationName string, variables map[string]interface{}) error {
+	a := &analysis{schema: s, fragments: make(map[string]*ast.FragmentDefinition), variables: variables}
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	// The executor reports unknown operations
+	if operation == nil {
+		return nil
+	}
+
+	root := s.schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = s.schema.MutationType()
+	}
+	depth, complexity := a.selectionSet(root, operation.SelectionSet)
+	if depth > s.maxDepth {
+		return fmt.Errorf("query is nested %d levels deep, more than the maximum of %d", depth, s.maxDepth)
+	}
+	if complexity > s.maxComplexity {
+		return fmt.Errorf("query has a complexity of %d, more than the maximum of %d", complexity, s.maxComplexity)
+	}
+	return nil
+}
+
+// selectionSet returns the depth and complexity of the fields of an object
+// selected by a selection set
+func (a *analysis) selectionSet(object *graphql.Object, selectionSet *ast.SelectionSet) (depth, complexity int) {
+	if selectionSet == nil {
+		return 0, 0
+	}
+	for _, selection := range selectionSet.Selections {
+		var d, c int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			d, c = a.field(object, selection)
+		case *ast.InlineFragment:
+			d, c = a.selectionSet(object, selection.SelectionSet)
+		case *ast.FragmentSpread:
+			// Validation has rejected unknown and cyclic fragments
+			d, c = a.selectionSet(object, a.fragments[selection.Name.Value].SelectionSet)
+		}
+		depth = max(depth, d)
+		complexity += c
+	}
+	return depth, complexity
+}
+
+// field returns the depth and complexity of a field of an object
+func (a *analysis) field(object *graphql.Object, field *ast.Field) (depth, complexity int) {
+	name := field.Name.Value
+	if strings.HasPrefix(name, "__") {
+		return 0, 0
+	}
+	definition := object.Fields()[name]
+	fieldType, list := unwrapType(definition.Type)
+	child, ok := fieldType.(*graphql.Object)
+	if !ok {
+		return 1, 1
+	}
+
+	depth, complexity = a.selectionSet(child, field.SelectionSet)
+	items := 1
+	if size, ok := a.pageSize(definition, field); ok {
+		items = size
+	} else if list && !strings.HasSuffix(object.Name(), "Connection") {
+		// The edges of a connection are the page counted for its field
+		items = listEstimate
+	}
+	return depth + 1, 1 + items*complexity
+}
+
+// pageSize returns the number of items a field with first or last
+// arguments returns
+func (a *analysis) pageSize(definition *graphql.FieldDefinition, field *ast.Field) (int, bool) {
+	paged := false
+	for _, arg := range definition.Args {
+		if arg.Name() != "first" && arg.Name() != "last" {
+			continue
+		}
+		paged = true
+		for _, given := range field.Arguments {
+			if given.Name.Value == arg.Name() {
+				if size, ok := a.intValue(given.Value); ok {
+					return max(size, 0), true
+				}
+			}
+		}
+	}
+	if !paged {
+		return 0, false
+	}
+	// Without either argument, the page has the default size of first
+	for _, arg := range definition.Args {
+		if arg.Name() == "first" {
+			if size, ok := arg.DefaultValue.(int); ok {
+				return size, true
+			}
+		}
+	}
+	return a.schema.defaultPageSize, true
+}
+
+// intValue returns the value of an integer literal or variable
+func (a *analysis) intValue(value ast.Value) (int, bool) {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, ok := graphql.Int.ParseLiteral(value).(int)
+		return n, ok
+	case *ast.Variable:
+		switch n := a.variables[value.Name.Value].(type) {
+		case int:
+			return n, true
+		case float64:
+			// Variables decoded from JSON are numbers
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// unwrapType returns the named type of a field's type and whether it is a
+// list
+func unwrapType(fieldType graphql.Type) (graphql.Type, bool) {
+	list := false
+	for {
+		switch t := fieldType.(type) {
+		case *graphql.NonNull:
+			fieldType = t.OfType
+		case *graphql.List:
+			list = true
+			fieldType = t.OfType
+		default:
+			return fieldType, list
+		}
+	}
+}
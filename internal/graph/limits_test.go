@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestLimits_Depth(t *testing.T) {
+	s := newTestSchema(t, &config.GraphQLConfig{MaxDepth: 4, MaxComplexity: 20000})
+
+	// tasks > edges > node > id is 4 levels deep
+	require.Empty(t, s.execute(t, fullAccess, `{ tasks { edges { node { id } } } }`, nil, nil))
+
+	errs := s.execute(t, fullAccess, `
+		query { tasks { ...edges } }
+		fragment edges on TaskConnection { edges { node { parent { id } } } }
+	`, nil, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "query is nested 5 levels deep, more than the maximum of 4", errs[0].Message)
+	assert.Equal(t, apperrors.CodeValidationError, errs[0].Extensions["code"])
+	assert.Empty(t, s.calls, "nothing is resolved")
+}
+
+func TestLimits_Complexity(t *testing.T) {
+	s := newTestSchema(t, &config.GraphQLConfig{MaxDepth: 10, MaxComplexity: 500})
+
+	// Each edge costs 14: itself, its node, the node's ID and its subtasks,
+	// 10 of them assumed, each with an ID. The page costs 1 + 20 * 14.
+	const query = `query ($first: Int) { tasks(first: $first) { edges { node { id subtasks { id } } } } }`
+	require.Empty(t, s.execute(t, fullAccess, query, map[string]interface{}{"first": 20}, nil))
+
+	errs := s.execute(t, fullAccess, query, map[string]interface{}{"first": 40}, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "query has a complexity of 561, more than the maximum of 500", errs[0].Message)
+
+	// Without first, pages have the default size of 50
+	errs = s.execute(t, fullAccess, `{ tasks { edges { node { id } } } }`, nil, nil)
+	assert.Empty(t, errs)
+	errs = s.execute(t, fullAccess, `{ tasks { edges { node { id history { id } } } } }`, nil, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "query has a complexity of 701, more than the maximum of 500", errs[0].Message)
+}
+
+func TestLimits_IntrospectionIsFree(t *testing.T) {
+	s := newTestSchema(t, &config.GraphQLConfig{MaxDepth: 2, MaxComplexity: 10})
+
+	var data struct {
+		Schema struct {
+			QueryType struct {
+				Fields []struct {
+					Name string `json:"name"`
+					Type struct {
+						OfType struct {
+							Name string `json:"name"`
+						} `json:"ofType"`
+					} `json:"type"`
+				} `json:"fields"`
+			} `json:"queryType"`
+		} `json:"__schema"`
+	}
+	require.Empty(t, s.execute(t, fullAccess, `{ __schema { queryType { fields { name type { ofType { name } } } } } }`, nil, &data))
+	assert.NotEmpty(t, data.Schema.QueryType.Fields)
+}
@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"sync"
+
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/services"
+)
+
+// loader batches the loads of one kind of value within a request. The
+// executor resolves every field of a level of the query before it calls
+// the thunks returned by load, so the keys requested by a whole level are
+// fetched with a single call when the first of their values is needed.
+// Values are cached for the rest of the request.
+type loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+// newLoader creates a loader fetching values with fetch, which returns the
+// values of the keys it finds; missing keys load the zero value
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:  fetch,
+		queued: make(map[K]bool),
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// load queues key for the next batch and returns a thunk of its value
+func (l *loader[K, V]) load(key K) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		return l.get(key)
+	}
+}
+
+// get fetches the pending keys, if key is one of them, and returns its
+// value
+func (l *loader[K, V]) get(key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.pending) > 0 {
+		if _, done := l.values[key]; !done && l.errs[key] == nil {
+			keys := l.pending
+			l.pending = nil
+			values, err := l.fetch(keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+					continue
+				}
+				l.values[k] = values[k]
+			}
+		}
+	}
+	return l.values[key], l.errs[key]
+}
+
+// historyKey identifies the recent history of a task, of at most limit
+// events
+type historyKey struct {
+	taskID uint
+	limit  int
+}
+
+// loaders holds the loaders of one request, all reading the caller's data
+type loaders struct {
+	tasks    *loader[uint, *models.Task]
+	subtasks *loader[uint, []*models.Task]
+	history  *loader[historyKey, []models.TaskEvent]
+	projects *loader[uint, *models.Project]
+}
+
+// newLoaders creates the loaders of a request made by the given user
+func newLoaders(tasks services.TaskService, projects services.ProjectService, ownerID uint) *loaders {
+	return &loaders{
+		tasks: newLoader(func(ids []uint) (map[uint]*models.Task, error) {
+			found, err := tasks.GetTasksByIDs(ownerID, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uint]*models.Task, len(found))
+			for i := range found {
+				byID[found[i].ID] = &found[i]
+			}
+			return byID, nil
+		}),
+		subtasks: newLoader(func(ids []uint) (map[uint][]*models.Task, error) {
+			found, err := tasks.ListSubtasksOf(ownerID, ids)
+			if err != nil {
+				return nil, err
+			}
+			byParent := make(map[uint][]*models.Task, len(ids))
+			for i := range found {
+				parentID := *found[i].ParentID
+				byParent[parentID] = append(byParent[parentID], &found[i])
+			}
+			return byParent, nil
+		}),
+		history: newLoader(func(keys []historyKey) (map[historyKey][]models.TaskEvent, error) {
+			// Tasks asked for with the same limit share a query
+			byLimit := make(map[int][]uint)
+			for _, key := range keys {
+				byLimit[key.limit] = append(byLimit[key.limit], key.taskID)
+			}
+			byKey := make(map[historyKey][]models.TaskEvent, len(keys))
+			for limit, ids := range byLimit {
+				found, err := tasks.ListRecentTaskEvents(ownerID, ids, limit)
+				if err != nil {
+					return nil, err
+				}
+				for _, event := range found {
+					key := historyKey{taskID: event.TaskID, limit: limit}
+					byKey[key] = append(byKey[key], event)
+				}
+			}
+			return byKey, nil
+		}),
+		// Users have few projects, so they are all read at once
+		projects: newLoader(func([]uint) (map[uint]*models.Project, error) {
+			found, err := projects.ListProjects(ownerID, true)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uint]*models.Project, len(found))
+			for i := range found {
+				byID[found[i].ID] = &found[i]
+			}
+			return byID, nil
+		}),
+	}
+}
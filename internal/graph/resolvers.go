@@ -0,0 +1,390 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/validation"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// connection is a page of tasks in the shape of a Relay connection
+type connection struct {
+	page    *models.TaskPage
+	sort    []models.SortField
+	encode  func(*models.Cursor) string
+	encoded []edge
+}
+
+// edge is one task of a connection with its cursor
+type edge struct {
+	cursor string
+	node   *models.Task
+}
+
+// edges returns the tasks of the page with their cursors, which page
+// forward from their task with after or backward with before
+func (c *connection) edges() []edge {
+	if c.encoded == nil {
+		c.encoded = make([]edge, len(c.page.Tasks))
+		for i := range c.page.Tasks {
+			task := &c.page.Tasks[i]
+			c.encoded[i] = edge{cursor: c.encode(models.NewCursor(task, c.sort, false)), node: task}
+		}
+	}
+	return c.encoded
+}
+
+// resolveTask resolves Query.task
+func (s *Schema) resolveTask(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID("id", p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks.GetTaskByID(requestFrom(p.Context).caller.Actor.UserID, id, false)
+}
+
+// resolveTasks resolves Query.tasks
+func (s *Schema) resolveTasks(p graphql.ResolveParams) (interface{}, error) {
+	filter, err := parseTaskFilter(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.parsePageRequest(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	// Counting the tasks costs a query, so it is only done when asked for
+	page.IncludeTotal = selects(p.Info, p.Info.FieldASTs[0].SelectionSet, "totalCount")
+
+	result, err := s.tasks.ListTasks(requestFrom(p.Context).caller.Actor.UserID, filter, page)
+	if err != nil {
+		return nil, err
+	}
+	return &connection{page: result, sort: filter.Sort, encode: s.cursors.Encode}, nil
+}
+
+// resolveProject resolves Query.project
+func (s *Schema) resolveProject(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID("id", p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	return s.projects.GetProjectByID(requestFrom(p.Context).caller.Actor.UserID, id)
+}
+
+// resolveProjects resolves Query.projects
+func (s *Schema) resolveProjects(p graphql.ResolveParams) (interface{}, error) {
+	projects, err := s.projects.ListProjects(requestFrom(p.Context).caller.Actor.UserID, p.Args["includeArchived"].(bool))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*models.Project, len(projects))
+	for i := range projects {
+		result[i] = &projects[i]
+	}
+	return result, nil
+}
+
+// resolveTaskProject resolves Task.project
+func resolveTaskProject(p graphql.ResolveParams) (interface{}, error) {
+	task := p.Source.(*models.Task)
+	if task.ProjectID == nil {
+		return nil, nil
+	}
+	return requestFrom(p.Context).loaders.projects.load(*task.ProjectID), nil
+}
+
+// resolveTaskParent resolves Task.parent
+func resolveTaskParent(p graphql.ResolveParams) (interface{}, error) {
+	task := p.Source.(*models.Task)
+	if task.ParentID == nil {
+		return nil, nil
+	}
+	return requestFrom(p.Context).loaders.tasks.load(*task.ParentID), nil
+}
+
+// resolveSubtasks resolves Task.subtasks
+func resolveSubtasks(p graphql.ResolveParams) (interface{}, error) {
+	task := p.Source.(*models.Task)
+	return requestFrom(p.Context).loaders.subtasks.load(task.ID), nil
+}
+
+// resolveHistory resolves Task.history
+func (s *Schema) resolveHistory(p graphql.ResolveParams) (interface{}, error) {
+	first := p.Args["first"].(int)
+	if first < 1 || first > s.maxPageSize {
+		return nil, &apperrors.ValidationError{Message: fmt.Sprintf("first must be between 1 and %d", s.maxPageSize)}
+	}
+	task := p.Source.(*models.Task)
+	return requestFrom(p.Context).loaders.history.load(historyKey{taskID: task.ID, limit: first}), nil
+}
+
+// createTask resolves Mutation.createTask
+func (s *Schema) createTask(p graphql.ResolveParams) (interface{}, error) {
+	caller := requestFrom(p.Context).caller
+	if !caller.CanWrite {
+		return nil, &apperrors.ForbiddenError{Message: "token is missing the " + auth.ScopeTasksWrite + " scope"}
+	}
+	input := p.Args["input"].(map[string]interface{})
+	req := &models.CreateTaskRequest{
+		Content:    input["content"].(string),
+		Tags:       stringList(input["tags"]),
+		Recurrence: stringValue(input["recurrence"]),
+	}
+	var err error
+	if req.ProjectID, err = optionalIDInput("projectId", input); err != nil {
+		return nil, err
+	}
+	if req.ParentID, err = optionalIDInput("parentId", input); err != nil {
+		return nil, err
+	}
+	if priority, ok := input["priority"].(models.Priority); ok {
+		req.Priority = priority.String()
+	}
+	if due, ok := input["dueAt"].(string); ok {
+		req.DueAt = &due
+	}
+	if err := validation.Validate(req); err != nil {
+		return nil, err
+	}
+
+	return s.tasks.CreateTask(caller.Actor, req, caller.Location)
+}
+
+// updateTask resolves Mutation.updateTask
+func (s *Schema) updateTask(p graphql.ResolveParams) (interface{}, error) {
+	input := p.Args["input"].(map[string]interface{})
+	changes := &models.UpdateTaskRequest{CompleteSubtasks: p.Args["completeSubtasks"].(bool)}
+	if content, ok := input["content"].(string); ok {
+		changes.Content = &content
+	}
+	if completed, ok := input["completed"].(bool); ok {
+		changes.Completed = &completed
+	}
+	for name, field := range map[string]**uint{"projectId": &changes.ProjectID, "parentId": &changes.ParentID} {
+		id, err := optionalIDInput(name, input)
+		if err != nil {
+			return nil, err
+		}
+		*field = id
+	}
+	if _, ok := input["tags"]; ok {
+		// An empty, non-nil list removes every tag
+		changes.Tags = append([]string{}, stringList(input["tags"])...)
+	}
+	if priority, ok := input["priority"].(models.Priority); ok {
+		name := priority.String()
+		changes.Priority = &name
+	}
+	if due, ok := input["dueAt"].(string); ok {
+		changes.DueAt = &due
+	}
+	if recurrence, ok := input["recurrence"].(string); ok {
+		changes.Recurrence = &recurrence
+	}
+	if err := validation.Validate(changes); err != nil {
+		return nil, err
+	}
+	return s.writeTask(p, changes)
+}
+
+// completeTask resolves Mutation.completeTask
+func (s *Schema) completeTask(p graphql.ResolveParams) (interface{}, error) {
+	completed := true
+	return s.writeTask(p, &models.UpdateTaskRequest{
+		Completed:        &completed,
+		CompleteSubtasks: p.Args["completeSubtasks"].(bool),
+	})
+}
+
+// writeTask applies changes to the task named by the id argument
+func (s *Schema) writeTask(p graphql.ResolveParams, changes *models.UpdateTaskRequest) (interface{}, error) {
+	caller := requestFrom(p.Context).caller
+	if !caller.CanWrite {
+		return nil, &apperrors.ForbiddenError{Message: "token is missing the " + auth.ScopeTasksWrite + " scope"}
+	}
+	id, err := parseID("id", p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks.UpdateTask(caller.Actor, id, changes, caller.Location, versionCondition(p.Args))
+}
+
+// deleteTask resolves Mutation.deleteTask
+func (s *Schema) deleteTask(p graphql.ResolveParams) (interface{}, error) {
+	caller := requestFrom(p.Context).caller
+	if !caller.CanDelete {
+		return nil, &apperrors.ForbiddenError{Message: "token is missing the " + auth.ScopeTasksDelete + " scope"}
+	}
+	id, err := parseID("id", p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tasks.DeleteTask(caller.Actor, id, p.Args["cascade"].(bool), versionCondition(p.Args)); err != nil {
+		return nil, err
+	}
+	return formatID(id), nil
+}
+
+// parsePageRequest parses the pagination arguments of a connection
+func (s *Schema) parsePageRequest(args map[string]interface{}) (*models.PageRequest, error) {
+	first, hasFirst := args["first"].(int)
+	last, hasLast := args["last"].(int)
+	after, hasAfter := args["after"].(string)
+	before, hasBefore := args["before"].(string)
+	if hasAfter && hasBefore {
+		return nil, &apperrors.ValidationError{Message: "after and before cannot be used together"}
+	}
+
+	limit, name := first, "first"
+	if hasLast {
+		if !hasBefore {
+			return nil, &apperrors.ValidationError{Message: "last requires before"}
+		}
+		limit, name = last, "last"
+	} else if !hasFirst {
+		limit = s.defaultPageSize
+	}
+	if limit < 1 || limit > s.maxPageSize {
+		return nil, &apperrors.ValidationError{Message: fmt.Sprintf("%s must be between 1 and %d", name, s.maxPageSize)}
+	}
+	page := &models.PageRequest{Limit: limit}
+
+	cursor, name := after, "after"
+	if hasBefore {
+		cursor, name = before, "before"
+	}
+	if hasAfter || hasBefore {
+		decoded, err := s.cursors.Decode(cursor)
+		if err != nil {
+			return nil, &apperrors.ValidationError{Message: name + " is a malformed or tampered cursor"}
+		}
+		decoded.Backward = hasBefore
+		page.Cursor = decoded
+	}
+	return page, nil
+}
+
+// parseTaskFilter parses the filter and orderBy arguments of Query.tasks
+func parseTaskFilter(args map[string]interface{}) (*models.TaskFilter, error) {
+	filter := &models.TaskFilter{}
+	if input, ok := args["filter"].(map[string]interface{}); ok {
+		var err error
+		if filter.ProjectID, err = optionalIDInput("projectId", input); err != nil {
+			return nil, err
+		}
+		if filter.ParentID, err = optionalIDInput("parentId", input); err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, tag := range stringList(input["tags"]) {
+			name := models.NormalizeTagName(tag)
+			if name == "" {
+				return nil, &apperrors.ValidationError{Message: "tags must not be empty"}
+			}
+			if !seen[name] {
+				seen[name] = true
+				filter.Tags = append(filter.Tags, name)
+			}
+		}
+		filter.TagMode = stringValue(input["tagMode"])
+		if completed, ok := input["completed"].(bool); ok {
+			filter.Completed = &completed
+		}
+		if due, ok := input["dueFrom"].(time.Time); ok {
+			filter.DueFrom = &due
+		}
+		if due, ok := input["dueBefore"].(time.Time); ok {
+			filter.DueBefore = &due
+		}
+		if contains, ok := input["contentContains"].(string); ok {
+			if strings.TrimSpace(contains) == "" {
+				return nil, &apperrors.ValidationError{Message: "contentContains must not be empty"}
+			}
+			filter.ContentContains = contains
+		}
+	}
+
+	seen := make(map[string]bool)
+	orders, _ := args["orderBy"].([]interface{})
+	for _, order := range orders {
+		order := order.(map[string]interface{})
+		field := order["field"].(string)
+		if seen[field] {
+			return nil, &apperrors.ValidationError{Message: fmt.Sprintf("orderBy has field %s more than once", strings.ToUpper(field))}
+		}
+		seen[field] = true
+		desc, _ := order["direction"].(bool)
+		filter.Sort = append(filter.Sort, models.SortField{Field: field, Desc: desc})
+	}
+	return filter, nil
+}
+
+// selects reports whether a selection set selects the field called name,
+// directly or through fragments
+func selects(info graphql.ResolveInfo, selectionSet *ast.SelectionSet, name string) bool {
+	if selectionSet == nil {
+		return false
+	}
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.Name.Value == name {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selects(info, selection.SelectionSet, name) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if fragment, ok := info.Fragments[selection.Name.Value].(*ast.FragmentDefinition); ok && selects(info, fragment.SelectionSet, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// optionalIDInput parses the optional ID input field called name
+func optionalIDInput(name string, input map[string]interface{}) (*uint, error) {
+	value, ok := input[name]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	id, err := parseID(name, value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// versionCondition converts the optional version argument
+func versionCondition(args map[string]interface{}) models.VersionCondition {
+	version, ok := args["version"].(int)
+	if !ok {
+		return nil
+	}
+	return models.VersionCondition{uint(version)}
+}
+
+// stringList converts an optional list of strings
+func stringList(value interface{}) []string {
+	values, _ := value.([]interface{})
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		list = append(list, v.(string))
+	}
+	return list
+}
+
+// stringValue converts an optional string
+func stringValue(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,399 @@
+package graph
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// listEstimate is the number of items assumed of lists without a page
+// size, such as the subtasks of a task, when estimating the complexity of
+// a query
+const listEstimate = 10
+
+// historyPageSize is the number of events of Task.history by default
+const historyPageSize = 10
+
+// priorityEnum maps the priorities of tasks
+var priorityEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "Priority",
+	Values: graphql.EnumValueConfigMap{
+		"NONE":   &graphql.EnumValueConfig{Value: models.PriorityNone},
+		"LOW":    &graphql.EnumValueConfig{Value: models.PriorityLow},
+		"MEDIUM": &graphql.EnumValueConfig{Value: models.PriorityMedium},
+		"HIGH":   &graphql.EnumValueConfig{Value: models.PriorityHigh},
+		"URGENT": &graphql.EnumValueConfig{Value: models.PriorityUrgent},
+	},
+})
+
+// tagModeEnum maps the tag modes of task filters
+var tagModeEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TagMode",
+	Values: graphql.EnumValueConfigMap{
+		"ALL": &graphql.EnumValueConfig{Value: models.TagModeAll, Description: "Tasks carry every tag"},
+		"ANY": &graphql.EnumValueConfig{Value: models.TagModeAny, Description: "Tasks carry any of the tags"},
+	},
+})
+
+// taskOrderFieldEnum maps the sort keys of tasks
+var taskOrderFieldEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TaskOrderField",
+	Values: graphql.EnumValueConfigMap{
+		"CREATED_AT": &graphql.EnumValueConfig{Value: "created_at"},
+		"UPDATED_AT": &graphql.EnumValueConfig{Value: "updated_at"},
+		"CONTENT":    &graphql.EnumValueConfig{Value: "content"},
+		"COMPLETED":  &graphql.EnumValueConfig{Value: "completed"},
+	},
+})
+
+// orderDirectionEnum maps sort directions to whether they are descending
+var orderDirectionEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "OrderDirection",
+	Values: graphql.EnumValueConfigMap{
+		"ASC":  &graphql.EnumValueConfig{Value: false},
+		"DESC": &graphql.EnumValueConfig{Value: true},
+	},
+})
+
+// jsonScalar passes JSON documents through as they are
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "A JSON document",
+	Serialize: func(value interface{}) interface{} {
+		if document, ok := value.(string); ok {
+			return json.RawMessage(document)
+		}
+		return nil
+	},
+	// JSON is only returned, never accepted
+	ParseValue:   func(interface{}) interface{} { return nil },
+	ParseLiteral: func(ast.Value) interface{} { return nil },
+})
+
+// tagType describes a tag of a task
+var tagType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Tag",
+	Fields: graphql.Fields{
+		"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return formatID(p.Source.(models.Tag).ID), nil
+		}},
+		"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(models.Tag).Name, nil
+		}},
+	},
+})
+
+// taskEventType describes an event in the history of a task
+var taskEventType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "TaskEvent",
+	Description: "One write to a task. state holds the task after the write and changes the fields it changed.",
+	Fields: graphql.Fields{
+		"id":        eventField(graphql.NewNonNull(graphql.ID), func(e models.TaskEvent) interface{} { return formatID(e.ID) }),
+		"taskId":    eventField(graphql.NewNonNull(graphql.ID), func(e models.TaskEvent) interface{} { return formatID(e.TaskID) }),
+		"type":      eventField(graphql.NewNonNull(graphql.String), func(e models.TaskEvent) interface{} { return e.Type }),
+		"version":   eventField(graphql.NewNonNull(graphql.Int), func(e models.TaskEvent) interface{} { return e.Version }),
+		"actorId":   eventField(graphql.NewNonNull(graphql.ID), func(e models.TaskEvent) interface{} { return formatID(e.ActorID) }),
+		"requestId": eventField(graphql.String, func(e models.TaskEvent) interface{} { return optionalString(e.RequestID) }),
+		"changes":   eventField(graphql.NewNonNull(jsonScalar), func(e models.TaskEvent) interface{} { return e.Changes }),
+		"state":     eventField(graphql.NewNonNull(jsonScalar), func(e models.TaskEvent) interface{} { return e.State }),
+		"createdAt": eventField(graphql.NewNonNull(graphql.DateTime), func(e models.TaskEvent) interface{} { return e.CreatedAt }),
+	},
+})
+
+// pageInfoType describes the position of a page of a connection
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*connection).page.NextCursor != nil, nil
+		}},
+		"hasPreviousPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*connection).page.PrevCursor != nil, nil
+		}},
+		"startCursor": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			edges := p.Source.(*connection).edges()
+			if len(edges) == 0 {
+				return nil, nil
+			}
+			return edges[0].cursor, nil
+		}},
+		"endCursor": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			edges := p.Source.(*connection).edges()
+			if len(edges) == 0 {
+				return nil, nil
+			}
+			return edges[len(edges)-1].cursor, nil
+		}},
+	},
+})
+
+// buildSchema builds the schema served by s
+func (s *Schema) buildSchema() (graphql.Schema, error) {
+	projectType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Project",
+		Fields: graphql.Fields{
+			"id":        projectField(graphql.NewNonNull(graphql.ID), func(p *models.Project) interface{} { return formatID(p.ID) }),
+			"name":      projectField(graphql.NewNonNull(graphql.String), func(p *models.Project) interface{} { return p.Name }),
+			"color":     projectField(graphql.NewNonNull(graphql.String), func(p *models.Project) interface{} { return p.Color }),
+			"archived":  projectField(graphql.NewNonNull(graphql.Boolean), func(p *models.Project) interface{} { return p.Archived }),
+			"position":  projectField(graphql.NewNonNull(graphql.Int), func(p *models.Project) interface{} { return p.Position }),
+			"createdAt": projectField(graphql.NewNonNull(graphql.DateTime), func(p *models.Project) interface{} { return p.CreatedAt }),
+			"updatedAt": projectField(graphql.NewNonNull(graphql.DateTime), func(p *models.Project) interface{} { return p.UpdatedAt }),
+		},
+	})
+
+	var taskType *graphql.Object
+	taskType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Task",
+		// Tasks nest tasks, so their fields refer to their own type
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          taskField(graphql.NewNonNull(graphql.ID), func(t *models.Task) interface{} { return formatID(t.ID) }),
+				"content":     taskField(graphql.NewNonNull(graphql.String), func(t *models.Task) interface{} { return t.Content }),
+				"completed":   taskField(graphql.NewNonNull(graphql.Boolean), func(t *models.Task) interface{} { return t.Completed }),
+				"priority":    taskField(graphql.NewNonNull(priorityEnum), func(t *models.Task) interface{} { return t.Priority }),
+				"dueAt":       taskField(graphql.DateTime, func(t *models.Task) interface{} { return t.DueAt }),
+				"completedAt": taskField(graphql.DateTime, func(t *models.Task) interface{} { return t.CompletedAt }),
+				"recurrence":  taskField(graphql.String, func(t *models.Task) interface{} { return optionalString(t.Recurrence) }),
+				"seriesId":    taskField(graphql.ID, func(t *models.Task) interface{} { return optionalID(t.SeriesID) }),
+				"occurrence":  taskField(graphql.Int, func(t *models.Task) interface{} { return optionalInt(t.Occurrence) }),
+				"version":     taskField(graphql.NewNonNull(graphql.Int), func(t *models.Task) interface{} { return t.Version }),
+				"tags":        taskField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType))), func(t *models.Task) interface{} { return t.Tags }),
+				"subtaskCount": taskField(graphql.NewNonNull(graphql.Int), func(t *models.Task) interface{} {
+					return t.SubtaskCount
+				}),
+				"completedSubtaskCount": taskField(graphql.NewNonNull(graphql.Int), func(t *models.Task) interface{} {
+					return t.CompletedSubtaskCount
+				}),
+				"createdAt": taskField(graphql.NewNonNull(graphql.DateTime), func(t *models.Task) interface{} { return t.CreatedAt }),
+				"updatedAt": taskField(graphql.NewNonNull(graphql.DateTime), func(t *models.Task) interface{} { return t.UpdatedAt }),
+				"project": &graphql.Field{
+					Type:    projectType,
+					Resolve: resolveTaskProject,
+				},
+				"parent": &graphql.Field{
+					Type:    taskType,
+					Resolve: resolveTaskParent,
+				},
+				"subtasks": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(taskType))),
+					Description: "The direct subtasks of the task, in creation order",
+					Resolve:     resolveSubtasks,
+				},
+				"history": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(taskEventType))),
+					Description: "The latest events in the history of the task, newest first",
+					Args: graphql.FieldConfigArgument{
+						"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: historyPageSize},
+					},
+					Resolve: s.resolveHistory,
+				},
+			}
+		}),
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TaskEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(edge).cursor, nil
+			}},
+			"node": &graphql.Field{Type: graphql.NewNonNull(taskType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(edge).node, nil
+			}},
+		},
+	})
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TaskConnection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*connection).edges(), nil
+			}},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source, nil
+			}},
+			"totalCount": &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*connection).page.Total, nil
+			}},
+		},
+	})
+
+	taskFilterInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TaskFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"projectId":       &graphql.InputObjectFieldConfig{Type: graphql.ID, Description: "A project, or 0 for the inbox"},
+			"parentId":        &graphql.InputObjectFieldConfig{Type: graphql.ID, Description: "A task, or 0 for top-level tasks"},
+			"tags":            &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"tagMode":         &graphql.InputObjectFieldConfig{Type: tagModeEnum, DefaultValue: models.TagModeAll},
+			"completed":       &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"dueFrom":         &graphql.InputObjectFieldConfig{Type: graphql.DateTime, Description: "Inclusive"},
+			"dueBefore":       &graphql.InputObjectFieldConfig{Type: graphql.DateTime, Description: "Exclusive"},
+			"contentContains": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+	taskOrderInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TaskOrder",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"field":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(taskOrderFieldEnum)},
+			"direction": &graphql.InputObjectFieldConfig{Type: orderDirectionEnum, DefaultValue: false},
+		},
+	})
+	createTaskInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateTaskInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"content":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"projectId":  &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"parentId":   &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"tags":       &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"priority":   &graphql.InputObjectFieldConfig{Type: priorityEnum},
+			"dueAt":      &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "An RFC 3339 timestamp, or a date in the caller's time zone"},
+			"recurrence": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+	updateTaskInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "UpdateTaskInput",
+		Description: "The fields to change. A projectId or parentId of 0, or an empty dueAt or recurrence, clears the field. tags replace the task's tags.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"content":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"completed":  &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"projectId":  &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"parentId":   &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"tags":       &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"priority":   &graphql.InputObjectFieldConfig{Type: priorityEnum},
+			"dueAt":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"recurrence": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"task": &graphql.Field{
+				Type:    taskType,
+				Args:    graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: s.resolveTask,
+			},
+			"tasks": &graphql.Field{
+				Type:        graphql.NewNonNull(connectionType),
+				Description: "The caller's tasks, a page at a time. Pages go forward with first and after, or backward with last and before.",
+				Args: graphql.FieldConfigArgument{
+					"first":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: s.defaultPageSize},
+					"after":   &graphql.ArgumentConfig{Type: graphql.String},
+					"last":    &graphql.ArgumentConfig{Type: graphql.Int},
+					"before":  &graphql.ArgumentConfig{Type: graphql.String},
+					"filter":  &graphql.ArgumentConfig{Type: taskFilterInput},
+					"orderBy": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(taskOrderInput))},
+				},
+				Resolve: s.resolveTasks,
+			},
+			"project": &graphql.Field{
+				Type:    projectType,
+				Args:    graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: s.resolveProject,
+			},
+			"projects": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(projectType))),
+				Args: graphql.FieldConfigArgument{
+					"includeArchived": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: s.resolveProjects,
+			},
+		},
+	})
+
+	version := &graphql.ArgumentConfig{Type: graphql.Int, Description: "Only write the task if it is at this version"}
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTask": &graphql.Field{
+				Type:    graphql.NewNonNull(taskType),
+				Args:    graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createTaskInput)}},
+				Resolve: s.createTask,
+			},
+			"updateTask": &graphql.Field{
+				Type: graphql.NewNonNull(taskType),
+				Args: graphql.FieldConfigArgument{
+					"id":               &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input":            &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateTaskInput)},
+					"completeSubtasks": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+					"version":          version,
+				},
+				Resolve: s.updateTask,
+			},
+			"completeTask": &graphql.Field{
+				Type: graphql.NewNonNull(taskType),
+				Args: graphql.FieldConfigArgument{
+					"id":               &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"completeSubtasks": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+					"version":          version,
+				},
+				Resolve: s.completeTask,
+			},
+			"deleteTask": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.ID),
+				Description: "Moves the task to the trash and returns its ID",
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"cascade": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+					"version": version,
+				},
+				Resolve: s.deleteTask,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// taskField defines a field read from a task
+func taskField(fieldType graphql.Output, get func(*models.Task) interface{}) *graphql.Field {
+	return &graphql.Field{Type: fieldType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*models.Task)), nil
+	}}
+}
+
+// projectField defines a field read from a project
+func projectField(fieldType graphql.Output, get func(*models.Project) interface{}) *graphql.Field {
+	return &graphql.Field{Type: fieldType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*models.Project)), nil
+	}}
+}
+
+// eventField defines a field read from a task event
+func eventField(fieldType graphql.Output, get func(models.TaskEvent) interface{}) *graphql.Field {
+	return &graphql.Field{Type: fieldType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(models.TaskEvent)), nil
+	}}
+}
+
+// formatID formats an ID
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// optionalID formats an optional ID, returning nil when it is not set
+func optionalID(id *uint) interface{} {
+	if id == nil {
+		return nil
+	}
+	return formatID(*id)
+}
+
+// optionalString returns nil for an empty string
+func optionalString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// optionalInt returns nil for zero
+func optionalInt(value int) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
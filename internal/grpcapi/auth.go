@@ -0,0 +1,197 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	todov1 "github.com/todo-api-go-sda/pkg/api/todo/v1"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys read from calls. They mirror the headers of the REST API.
+const (
+	authorizationKey = "authorization"
+	timeZoneKey      = "time-zone"
+	requestIDKey     = "x-request-id"
+)
+
+// validRequestID matches the request IDs accepted from clients
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// methodScopes lists the scope each method requires of personal access
+// tokens. Methods missing from it are refused.
+var methodScopes = map[string]string{
+	todov1.TaskService_CreateTask_FullMethodName: auth.ScopeTasksWrite,
+	todov1.TaskService_GetTask_FullMethodName:    auth.ScopeTasksRead,
+	todov1.TaskService_ListTasks_FullMethodName:  auth.ScopeTasksRead,
+	todov1.TaskService_UpdateTask_FullMethodName: auth.ScopeTasksWrite,
+	todov1.TaskService_DeleteTask_FullMethodName: auth.ScopeTasksDelete,
+	todov1.TaskService_WatchTasks_FullMethodName: auth.ScopeTasksRead,
+}
+
+// Authenticator verifies the bearer credentials of calls the same way
+// middleware.RequireAuth does for HTTP requests, and resolves the time
+// zone of callers like middleware.Location
+type Authenticator struct {
+	tokens    *auth.TokenManager
+	apiTokens middleware.APITokenAuthenticator
+	timeZones middleware.TimeZoneLookup
+}
+
+// NewAuthenticator creates a new Authenticator instance
+func NewAuthenticator(tokens *auth.TokenManager, apiTokens middleware.APITokenAuthenticator, timeZones middleware.TimeZoneLookup) *Authenticator {
+	return &Authenticator{tokens: tokens, apiTokens: apiTokens, timeZones: timeZones}
+}
+
+// caller is the authenticated principal of a call
+type caller struct {
+	actor *models.Actor
+	// scopes is nil for session (JWT) credentials, which grant every scope
+	scopes   []string
+	timeZone string
+	lookup   middleware.TimeZoneLookup
+}
+
+// callerKey is the context key holding the caller
+type callerKey struct{}
+
+// callerFrom returns the caller of an authenticated call
+func callerFrom(ctx context.Context) *caller {
+	return ctx.Value(callerKey{}).(*caller)
+}
+
+// location returns the caller's time zone: the time-zone metadata when
+// set, otherwise the user's saved time zone, otherwise UTC
+func (c *caller) location() (*time.Location, error) {
+	if c.timeZone != "" {
+		loc, err := middleware.LoadLocation(c.timeZone)
+		if err != nil {
+			return nil, &apperrors.ValidationError{Message: "invalid time-zone metadata: unknown time zone " + c.timeZone}
+		}
+		return loc, nil
+	}
+	name, err := c.lookup.UserTimeZone(c.actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if loc, err := middleware.LoadLocation(name); err == nil {
+		return loc, nil
+	}
+	return time.UTC, nil
+}
+
+// authenticate resolves the caller of a call to method and checks that its
+// credential grants the scope the method requires
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	token, ok := bearerToken(md)
+	if !ok {
+		return nil, &apperrors.UnauthorizedError{Message: "missing bearer token"}
+	}
+
+	c := &caller{actor: &models.Actor{RequestID: first(md, requestIDKey)}, timeZone: first(md, timeZoneKey), lookup: a.timeZones}
+	if !validRequestID.MatchString(c.actor.RequestID) {
+		c.actor.RequestID = newRequestID()
+	}
+	if auth.IsAPIToken(token) {
+		apiToken, err := a.apiTokens.Authenticate(token)
+		if err != nil {
+			return nil, err
+		}
+		c.actor.UserID = apiToken.UserID
+		c.actor.TokenID = &apiToken.ID
+		c.scopes = apiToken.ScopeList()
+	} else {
+		userID, err := a.tokens.Verify(token, auth.TokenTypeAccess)
+		if err != nil {
+			return nil, &apperrors.UnauthorizedError{Message: "invalid or expired token"}
+		}
+		c.actor.UserID = userID
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		return nil, &apperrors.ForbiddenError{Message: "unknown method " + method}
+	}
+	if c.scopes != nil && !slices.Contains(c.scopes, scope) {
+		return nil, &apperrors.ForbiddenError{Message: "token is missing the " + scope + " scope"}
+	}
+	return context.WithValue(ctx, callerKey{}, c), nil
+}
+
+// UnaryInterceptor authenticates unary calls and converts the errors of
+// their handlers to gRPC statuses
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamInterceptor authenticates streaming calls and converts the errors
+// of their handlers to gRPC statuses
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return toStatus(err)
+		}
+		if err := handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx}); err != nil {
+			return toStatus(err)
+		}
+		return nil
+	}
+}
+
+// authenticatedStream carries the caller in the context of a stream
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context holding the caller
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// bearerToken extracts the token from "authorization: Bearer" metadata
+func bearerToken(md metadata.MD) (string, bool) {
+	scheme, token, ok := strings.Cut(first(md, authorizationKey), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// first returns the first value of a metadata key, or ""
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// newRequestID returns a random request ID of 32 hex digits
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
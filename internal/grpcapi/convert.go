@@ -0,0 +1,234 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+	todov1 "github.com/todo-api-go-sda/pkg/api/todo/v1"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxID is the largest ID a message may carry: IDs are uint64 in messages
+// but uint, which is 32 bits wide on some platforms, in the services
+var maxID uint64 = math.MaxUint
+
+// eventTypes maps the event types of the bus to those of TaskEvent
+var eventTypes = map[string]todov1.TaskEvent_Type{
+	events.TaskCreated:  todov1.TaskEvent_TYPE_CREATED,
+	events.TaskUpdated:  todov1.TaskEvent_TYPE_UPDATED,
+	events.TaskDeleted:  todov1.TaskEvent_TYPE_DELETED,
+	events.TaskRestored: todov1.TaskEvent_TYPE_RESTORED,
+	events.TaskPurged:   todov1.TaskEvent_TYPE_PURGED,
+}
+
+// toTask converts a task to its message
+func toTask(task *models.TaskResponse) *todov1.Task {
+	return &todov1.Task{
+		Id:                    uint64(task.ID),
+		ProjectId:             toOptionalID(task.ProjectID),
+		ParentId:              toOptionalID(task.ParentID),
+		Content:               task.Content,
+		Completed:             task.Completed,
+		Priority:              toPriority(task.Priority),
+		DueAt:                 toTimestamp(task.DueAt),
+		CompletedAt:           toTimestamp(task.CompletedAt),
+		Recurrence:            task.Recurrence,
+		SeriesId:              toOptionalID(task.SeriesID),
+		Occurrence:            int32(task.Occurrence),
+		Version:               uint64(task.Version),
+		Tags:                  task.Tags,
+		SubtaskCount:          task.SubtaskCount,
+		CompletedSubtaskCount: task.CompletedSubtaskCount,
+		CreatedAt:             timestamppb.New(task.CreatedAt),
+		UpdatedAt:             timestamppb.New(task.UpdatedAt),
+	}
+}
+
+// toTaskEvent converts an event of the bus to its message
+func toTaskEvent(event events.Event) (*todov1.TaskEvent, error) {
+	message := &todov1.TaskEvent{Id: event.ID, Type: eventTypes[event.Type]}
+	if event.Type == events.TaskDeleted || event.Type == events.TaskPurged {
+		var deleted struct {
+			ID uint `json:"id"`
+		}
+		if err := json.Unmarshal(event.Data, &deleted); err != nil {
+			return nil, err
+		}
+		message.TaskId = uint64(deleted.ID)
+		return message, nil
+	}
+
+	var task models.TaskResponse
+	if err := json.Unmarshal(event.Data, &task); err != nil {
+		return nil, err
+	}
+	message.Task = toTask(&task)
+	message.TaskId = message.Task.Id
+	return message, nil
+}
+
+// toCreateRequest converts the fields of a new task
+func toCreateRequest(task *todov1.Task) (*models.CreateTaskRequest, error) {
+	priority, err := priorityName(task.Priority)
+	if err != nil {
+		return nil, err
+	}
+	projectID, err := fromOptionalID("project_id", task.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	parentID, err := fromOptionalID("parent_id", task.ParentId)
+	if err != nil {
+		return nil, err
+	}
+	req := &models.CreateTaskRequest{
+		Content:    task.Content,
+		ProjectID:  projectID,
+		ParentID:   parentID,
+		Tags:       task.Tags,
+		Priority:   priority,
+		Recurrence: task.Recurrence,
+	}
+	if task.DueAt != nil {
+		due, err := formatTimestamp(task.DueAt)
+		if err != nil {
+			return nil, err
+		}
+		req.DueAt = &due
+	}
+	return req, nil
+}
+
+// toUpdateRequest converts the fields of a task named by an update mask
+func toUpdateRequest(req *todov1.UpdateTaskRequest) (*models.UpdateTaskRequest, error) {
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		return nil, &apperrors.ValidationError{Message: "update_mask is required"}
+	}
+
+	task := req.Task
+	changes := &models.UpdateTaskRequest{CompleteSubtasks: req.CompleteSubtasks}
+	for _, path := range paths {
+		switch path {
+		case "content":
+			content := task.Content
+			changes.Content = &content
+		case "completed":
+			completed := task.Completed
+			changes.Completed = &completed
+		case "priority":
+			priority, err := priorityName(task.Priority)
+			if err != nil {
+				return nil, err
+			}
+			if priority == "" {
+				priority = models.PriorityNone.String()
+			}
+			changes.Priority = &priority
+		case "due_at":
+			due := ""
+			if task.DueAt != nil {
+				formatted, err := formatTimestamp(task.DueAt)
+				if err != nil {
+					return nil, err
+				}
+				due = formatted
+			}
+			changes.DueAt = &due
+		case "recurrence":
+			recurrence := task.Recurrence
+			changes.Recurrence = &recurrence
+		case "project_id":
+			projectID, err := fromID(path, task.GetProjectId())
+			if err != nil {
+				return nil, err
+			}
+			changes.ProjectID = &projectID
+		case "parent_id":
+			parentID, err := fromID(path, task.GetParentId())
+			if err != nil {
+				return nil, err
+			}
+			changes.ParentID = &parentID
+		case "tags":
+			// An empty, non-nil list removes every tag
+			changes.Tags = append([]string{}, task.Tags...)
+		default:
+			return nil, &apperrors.ValidationError{Message: fmt.Sprintf("update_mask path %q cannot be updated", path)}
+		}
+	}
+	return changes, nil
+}
+
+// toPriority converts the API name of a priority to its enum value
+func toPriority(name string) todov1.Priority {
+	priority, ok := models.ParsePriority(name)
+	if !ok {
+		return todov1.Priority_PRIORITY_UNSPECIFIED
+	}
+	return todov1.Priority(priority + 1)
+}
+
+// priorityName returns the API name of a priority, or "" when it is
+// unspecified
+func priorityName(priority todov1.Priority) (string, error) {
+	if priority == todov1.Priority_PRIORITY_UNSPECIFIED {
+		return "", nil
+	}
+	if _, ok := todov1.Priority_name[int32(priority)]; !ok {
+		return "", &apperrors.ValidationError{Message: fmt.Sprintf("unknown priority %d", priority)}
+	}
+	return models.Priority(priority - 1).String(), nil
+}
+
+// formatTimestamp formats a timestamp in RFC 3339, as the services expect
+// due dates
+func formatTimestamp(ts *timestamppb.Timestamp) (string, error) {
+	if err := ts.CheckValid(); err != nil {
+		return "", &apperrors.ValidationError{Message: "due_at is not a valid timestamp"}
+	}
+	return ts.AsTime().Format(time.RFC3339Nano), nil
+}
+
+// toTimestamp converts an optional time
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// toOptionalID converts an optional ID
+func toOptionalID(id *uint) *uint64 {
+	if id == nil {
+		return nil
+	}
+	v := uint64(*id)
+	return &v
+}
+
+// fromID converts the ID in the field of a message, rejecting IDs that do
+// not fit in a uint
+func fromID(field string, id uint64) (uint, error) {
+	if id > maxID {
+		return 0, &apperrors.ValidationError{Message: fmt.Sprintf("%s %d is out of range", field, id)}
+	}
+	return uint(id), nil
+}
+
+// fromOptionalID converts the optional ID in the field of a message
+func fromOptionalID(field string, id *uint64) (*uint, error) {
+	if id == nil {
+		return nil, nil
+	}
+	v, err := fromID(field, *id)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,65 @@
+package grpcapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorDomain is the domain of the ErrorInfo detail of errors, whose
+// reason is the error code of the REST API, such as TASK_NOT_FOUND
+const ErrorDomain = "todo-api"
+
+// statusCodes maps the HTTP statuses of apperrors.Describe to gRPC codes
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:         codes.InvalidArgument,
+	http.StatusUnauthorized:       codes.Unauthenticated,
+	http.StatusForbidden:          codes.PermissionDenied,
+	http.StatusNotFound:           codes.NotFound,
+	http.StatusConflict:           codes.FailedPrecondition,
+	http.StatusPreconditionFailed: codes.Aborted,
+	http.StatusFailedDependency:   codes.Aborted,
+}
+
+// toStatus converts an error to a gRPC status error. Errors that already
+// are status errors, such as those of a cancelled stream, are kept.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	httpStatus, detail := apperrors.Describe(err)
+	code, ok := statusCodes[httpStatus]
+	if !ok {
+		code = codes.Internal
+	}
+
+	info := &errdetails.ErrorInfo{Reason: detail.Code, Domain: ErrorDomain}
+	// Version conflicts tell the current version so the caller can retry
+	if conflict, ok := err.(*apperrors.VersionConflictError); ok {
+		if current, ok := conflict.Current.(*models.Task); ok {
+			info.Metadata = map[string]string{"current_version": strconv.FormatUint(uint64(current.Version), 10)}
+		}
+	}
+	details := []protoadapt.MessageV1{info}
+	// Invalid fields of a request are reported as field violations
+	if validationErr, ok := err.(*apperrors.ValidationError); ok && len(validationErr.Violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range validationErr.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field: violation.Field, Description: violation.Description,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	st, detailErr := status.New(code, detail.Message).WithDetails(details...)
+	if detailErr != nil {
+		return status.Error(code, detail.Message)
+	}
+	return st.Err()
+}
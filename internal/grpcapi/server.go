@@ -0,0 +1,250 @@
+// Package grpcapi serves the todo.v1.TaskService gRPC API on top of the
+// task service, with the credentials, scopes and error codes of the REST
+// API.
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/pagination"
+	"github.com/todo-api-go-sda/internal/services"
+	"github.com/todo-api-go-sda/internal/validation"
+	todov1 "github.com/todo-api-go-sda/pkg/api/todo/v1"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// taskServer implements todov1.TaskServiceServer
+type taskServer struct {
+	todov1.UnimplementedTaskServiceServer
+	tasks           services.TaskService
+	bus             *events.Bus
+	cursors         *pagination.Codec
+	defaultPageSize int
+	maxPageSize     int
+}
+
+// NewServer creates a gRPC server serving todo.v1.TaskService from tasks
+// and streaming task events from bus. Callers are authenticated by
+// authenticator, pages follow the pagination settings of serverCfg and
+// idle connections are pinged at the heartbeat interval of eventsCfg.
+func NewServer(tasks services.TaskService, bus *events.Bus, authenticator *Authenticator, serverCfg *config.ServerConfig, eventsCfg *config.EventsConfig) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(authenticator.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(authenticator.StreamInterceptor()),
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: eventsCfg.HeartbeatInterval}),
+	)
+	todov1.RegisterTaskServiceServer(server, &taskServer{
+		tasks:           tasks,
+		bus:             bus,
+		cursors:         pagination.NewCodec(serverCfg.CursorSecret),
+		defaultPageSize: min(serverCfg.DefaultPageSize, serverCfg.MaxPageSize),
+		maxPageSize:     serverCfg.MaxPageSize,
+	})
+	return server
+}
+
+// CreateTask implements todov1.TaskServiceServer
+func (s *taskServer) CreateTask(ctx context.Context, req *todov1.CreateTaskRequest) (*todov1.Task, error) {
+	caller := callerFrom(ctx)
+	if req.Task == nil {
+		return nil, &apperrors.ValidationError{Message: "task is required"}
+	}
+	create, err := toCreateRequest(req.Task)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Validate(create); err != nil {
+		return nil, err
+	}
+	loc, err := caller.location()
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.tasks.CreateTask(caller.actor, create, loc)
+	if err != nil {
+		return nil, err
+	}
+	return taskMessage(task), nil
+}
+
+// GetTask implements todov1.TaskServiceServer
+func (s *taskServer) GetTask(ctx context.Context, req *todov1.GetTaskRequest) (*todov1.Task, error) {
+	id, err := fromID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.tasks.GetTaskByID(callerFrom(ctx).actor.UserID, id, false)
+	if err != nil {
+		return nil, err
+	}
+	return taskMessage(task), nil
+}
+
+// ListTasks implements todov1.TaskServiceServer
+func (s *taskServer) ListTasks(ctx context.Context, req *todov1.ListTasksRequest) (*todov1.ListTasksResponse, error) {
+	page := &models.PageRequest{Limit: s.defaultPageSize}
+	switch {
+	case req.PageSize < 0:
+		return nil, &apperrors.ValidationError{Message: "page_size must not be negative"}
+	case req.PageSize > 0:
+		page.Limit = min(int(req.PageSize), s.maxPageSize)
+	}
+	if req.PageToken != "" {
+		cursor, err := s.cursors.Decode(req.PageToken)
+		if err != nil {
+			return nil, &apperrors.ValidationError{Message: "page_token is malformed or tampered with"}
+		}
+		page.Cursor = cursor
+	}
+
+	projectID, err := fromOptionalID("project_id", req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	filter := &models.TaskFilter{ProjectID: projectID, Completed: req.Completed}
+	for _, tag := range req.Tags {
+		name := models.NormalizeTagName(tag)
+		if name == "" {
+			return nil, &apperrors.ValidationError{Message: "tags must not be empty"}
+		}
+		filter.Tags = append(filter.Tags, name)
+	}
+
+	result, err := s.tasks.ListTasks(callerFrom(ctx).actor.UserID, filter, page)
+	if err != nil {
+		return nil, err
+	}
+	response := &todov1.ListTasksResponse{Tasks: make([]*todov1.Task, 0, len(result.Tasks))}
+	for i := range result.Tasks {
+		response.Tasks = append(response.Tasks, taskMessage(&result.Tasks[i]))
+	}
+	if result.NextCursor != nil {
+		response.NextPageToken = s.cursors.Encode(result.NextCursor)
+	}
+	return response, nil
+}
+
+// UpdateTask implements todov1.TaskServiceServer
+func (s *taskServer) UpdateTask(ctx context.Context, req *todov1.UpdateTaskRequest) (*todov1.Task, error) {
+	caller := callerFrom(ctx)
+	if req.Task == nil {
+		return nil, &apperrors.ValidationError{Message: "task is required"}
+	}
+	id, err := fromID("task.id", req.Task.Id)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := toUpdateRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Validate(changes); err != nil {
+		return nil, err
+	}
+	ifMatch, err := versionCondition(req.Version)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := caller.location()
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.tasks.UpdateTask(caller.actor, id, changes, loc, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+	return taskMessage(task), nil
+}
+
+// DeleteTask implements todov1.TaskServiceServer
+func (s *taskServer) DeleteTask(ctx context.Context, req *todov1.DeleteTaskRequest) (*emptypb.Empty, error) {
+	id, err := fromID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	ifMatch, err := versionCondition(req.Version)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tasks.DeleteTask(callerFrom(ctx).actor, id, req.Cascade, ifMatch); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// WatchTasks implements todov1.TaskServiceServer. A caller resuming from
+// an event first receives the events it missed, or a reset event when they
+// are no longer buffered. Response headers are sent once the subscription
+// is active. The stream ends with codes.Unavailable when the caller falls
+// too far behind, and the caller is expected to resume from the last event
+// it received.
+func (s *taskServer) WatchTasks(req *todov1.WatchTasksRequest, stream todov1.TaskService_WatchTasksServer) error {
+	ctx := stream.Context()
+	sub, replay, resumed := s.bus.Subscribe(callerFrom(ctx).actor.UserID, req.ResumeToken)
+	defer sub.Close()
+	// Headers tell the caller that no change after this point will be missed
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	if !resumed {
+		if err := stream.Send(&todov1.TaskEvent{Type: todov1.TaskEvent_TYPE_RESET}); err != nil {
+			return err
+		}
+	}
+	for _, event := range replay {
+		if err := s.send(stream, event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				return status.Error(codes.Unavailable, "the stream fell behind; resume from the last event received")
+			}
+			if err := s.send(stream, event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send writes one event of the bus to the stream
+func (s *taskServer) send(stream todov1.TaskService_WatchTasksServer, event events.Event) error {
+	message, err := toTaskEvent(event)
+	if err != nil {
+		return err
+	}
+	return stream.Send(message)
+}
+
+// taskMessage converts a task to its message
+func taskMessage(task *models.Task) *todov1.Task {
+	response := task.ToResponse()
+	return toTask(&response)
+}
+
+// versionCondition converts the optional version of a request, which is
+// range checked like an ID
+func versionCondition(version *uint64) (models.VersionCondition, error) {
+	v, err := fromOptionalID("version", version)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return models.VersionCondition{*v}, nil
+}
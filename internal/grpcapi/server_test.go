@@ -0,0 +1,348 @@
+package grpcapi
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	"github.com/todo-api-go-sda/internal/services"
+	todov1 "github.com/todo-api-go-sda/pkg/api/todo/v1"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testServer serves the API in memory to a registered user
+type testServer struct {
+	client    todov1.TaskServiceClient
+	tasks     services.TaskService
+	apiTokens services.APITokenService
+	userID    uint
+	token     string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	tokens, err := auth.NewTokenManager(&config.AuthConfig{
+		JWTAlgorithm:    "HS256",
+		JWTSecret:       "test-secret",
+		JWTIssuer:       "todo-api",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+	require.NoError(t, err)
+	authService := services.NewAuthService(repository.NewMemoryUserRepository(), tokens)
+	registered, err := authService.Register(&models.RegisterRequest{Email: "grpc@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	bus := events.NewBus(10)
+	taskRepo := repository.NewMemoryTaskRepository()
+	tasks := services.NewTaskService(taskRepo, repository.NewMemoryProjectRepository(taskRepo), repository.NewMemoryTagRepository(taskRepo),
+		&config.TaskConfig{MaxSubtaskDepth: 2}, bus)
+	apiTokens := services.NewAPITokenService(repository.NewMemoryAPITokenRepository())
+
+	server := NewServer(tasks, bus, NewAuthenticator(tokens, apiTokens, authService),
+		&config.ServerConfig{DefaultPageSize: 50, MaxPageSize: 100}, &config.EventsConfig{HeartbeatInterval: time.Second})
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return &testServer{
+		client:    todov1.NewTaskServiceClient(conn),
+		tasks:     tasks,
+		apiTokens: apiTokens,
+		userID:    registered.User.ID,
+		token:     registered.AccessToken,
+	}
+}
+
+// context returns a context carrying the token of the user
+func (s *testServer) context(t *testing.T, token string) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+token)
+}
+
+// assertStatus checks the code of an error and the reason of its ErrorInfo
+func assertStatus(t *testing.T, err error, code codes.Code, reason string) *errdetails.ErrorInfo {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok, "not a status error: %v", err)
+	assert.Equal(t, code, st.Code(), st.Message())
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			assert.Equal(t, reason, info.Reason)
+			assert.Equal(t, ErrorDomain, info.Domain)
+			return info
+		}
+	}
+	t.Fatalf("no ErrorInfo in %v", st.Details())
+	return nil
+}
+
+func TestTaskService_CreateGetUpdateDelete(t *testing.T) {
+	server := newTestServer(t)
+	ctx := server.context(t, server.token)
+
+	due := time.Date(2030, 5, 1, 9, 0, 0, 0, time.UTC)
+	created, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{
+		Content:  "Ship the gRPC API",
+		Priority: todov1.Priority_PRIORITY_HIGH,
+		Tags:     []string{"work"},
+		DueAt:    timestamppb.New(due),
+	}})
+	require.NoError(t, err)
+	assert.NotZero(t, created.Id)
+	assert.Equal(t, todov1.Priority_PRIORITY_HIGH, created.Priority)
+	assert.Equal(t, []string{"work"}, created.Tags)
+	assert.True(t, due.Equal(created.DueAt.AsTime()))
+	assert.Nil(t, created.ProjectId)
+
+	got, err := server.client.GetTask(ctx, &todov1.GetTaskRequest{Id: created.Id})
+	require.NoError(t, err)
+	assert.Equal(t, "Ship the gRPC API", got.Content)
+
+	// Fields missing from the mask are left alone, those in it are cleared
+	// when unset
+	updated, err := server.client.UpdateTask(ctx, &todov1.UpdateTaskRequest{
+		Task:       &todov1.Task{Id: created.Id, Content: "Ship it", Priority: todov1.Priority_PRIORITY_LOW},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"content", "due_at"}},
+		Version:    &created.Version,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Ship it", updated.Content)
+	assert.Nil(t, updated.DueAt)
+	assert.Equal(t, todov1.Priority_PRIORITY_HIGH, updated.Priority)
+	assert.Equal(t, created.Version+1, updated.Version)
+
+	_, err = server.client.UpdateTask(ctx, &todov1.UpdateTaskRequest{
+		Task:       &todov1.Task{Id: created.Id, Completed: true},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"completed"}},
+		Version:    &created.Version,
+	})
+	info := assertStatus(t, err, codes.Aborted, apperrors.CodeVersionConflict)
+	assert.Equal(t, strconv.FormatUint(updated.Version, 10), info.Metadata["current_version"])
+
+	_, err = server.client.DeleteTask(ctx, &todov1.DeleteTaskRequest{Id: created.Id})
+	require.NoError(t, err)
+	_, err = server.client.GetTask(ctx, &todov1.GetTaskRequest{Id: created.Id})
+	assertStatus(t, err, codes.NotFound, apperrors.CodeTaskNotFound)
+}
+
+func TestTaskService_InvalidArguments(t *testing.T) {
+	server := newTestServer(t)
+	ctx := server.context(t, server.token)
+	task, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{Content: "Valid"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"missing task", func() error {
+			_, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{})
+			return err
+		}},
+		{"empty content", func() error {
+			_, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{}})
+			return err
+		}},
+		{"unknown priority", func() error {
+			_, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{Content: "x", Priority: 42}})
+			return err
+		}},
+		{"missing update mask", func() error {
+			_, err := server.client.UpdateTask(ctx, &todov1.UpdateTaskRequest{Task: &todov1.Task{Id: task.Id, Content: "x"}})
+			return err
+		}},
+		{"unknown mask path", func() error {
+			_, err := server.client.UpdateTask(ctx, &todov1.UpdateTaskRequest{
+				Task:       &todov1.Task{Id: task.Id},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"version"}},
+			})
+			return err
+		}},
+		{"invalid page token", func() error {
+			_, err := server.client.ListTasks(ctx, &todov1.ListTasksRequest{PageToken: "forged"})
+			return err
+		}},
+		{"unknown time zone", func() error {
+			ctx := metadata.AppendToOutgoingContext(ctx, timeZoneKey, "Mars/Olympus_Mons")
+			_, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{Content: "x"}})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertStatus(t, tt.call(), codes.InvalidArgument, apperrors.CodeValidationError)
+		})
+	}
+}
+
+func TestTaskService_FieldViolations(t *testing.T) {
+	server := newTestServer(t)
+	ctx := server.context(t, server.token)
+
+	_, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{
+		Content: "Task",
+		Tags:    []string{"home", strings.Repeat("x", 51)},
+	}})
+	assertStatus(t, err, codes.InvalidArgument, apperrors.CodeValidationError)
+	st, _ := status.FromError(err)
+	assert.Equal(t, "tags[1] must be at most 50 characters long", st.Message())
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.FieldViolations
+		}
+	}
+	require.Len(t, violations, 1)
+	assert.Equal(t, "tags[1]", violations[0].Field)
+	assert.Equal(t, "must be at most 50 characters long", violations[0].Description)
+}
+
+func TestTaskService_IDsOutOfRange(t *testing.T) {
+	server := newTestServer(t)
+	ctx := server.context(t, server.token)
+	task, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{Content: "Task"}})
+	require.NoError(t, err)
+
+	// As on a platform where uint is 32 bits wide, an ID that would wrap
+	// around to the task's ID is rejected
+	defer func(max uint64) { maxID = max }(maxID)
+	maxID = math.MaxUint32
+	wrapped := task.Id + math.MaxUint32 + 1
+
+	_, err = server.client.GetTask(ctx, &todov1.GetTaskRequest{Id: wrapped})
+	assertStatus(t, err, codes.InvalidArgument, apperrors.CodeValidationError)
+	_, err = server.client.DeleteTask(ctx, &todov1.DeleteTaskRequest{Id: wrapped})
+	assertStatus(t, err, codes.InvalidArgument, apperrors.CodeValidationError)
+	_, err = server.client.UpdateTask(ctx, &todov1.UpdateTaskRequest{
+		Task:       &todov1.Task{Id: wrapped, Content: "Changed"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"content"}},
+	})
+	assertStatus(t, err, codes.InvalidArgument, apperrors.CodeValidationError)
+	_, err = server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{Content: "Subtask", ParentId: &wrapped}})
+	assertStatus(t, err, codes.InvalidArgument, apperrors.CodeValidationError)
+
+	got, err := server.client.GetTask(ctx, &todov1.GetTaskRequest{Id: task.Id})
+	require.NoError(t, err)
+	assert.Equal(t, "Task", got.Content)
+}
+
+func TestTaskService_ListTasks(t *testing.T) {
+	server := newTestServer(t)
+	ctx := server.context(t, server.token)
+	for _, content := range []string{"First", "Second", "Third"} {
+		_, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{Content: content, Tags: []string{"home"}}})
+		require.NoError(t, err)
+	}
+	_, err := server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{Content: "Untagged"}})
+	require.NoError(t, err)
+
+	first, err := server.client.ListTasks(ctx, &todov1.ListTasksRequest{PageSize: 2, Tags: []string{"Home"}})
+	require.NoError(t, err)
+	require.Len(t, first.Tasks, 2)
+	assert.Equal(t, "Third", first.Tasks[0].Content)
+	require.NotEmpty(t, first.NextPageToken)
+
+	second, err := server.client.ListTasks(ctx, &todov1.ListTasksRequest{PageSize: 2, PageToken: first.NextPageToken, Tags: []string{"home"}})
+	require.NoError(t, err)
+	require.Len(t, second.Tasks, 1)
+	assert.Equal(t, "First", second.Tasks[0].Content)
+	assert.Empty(t, second.NextPageToken)
+}
+
+func TestTaskService_Authentication(t *testing.T) {
+	server := newTestServer(t)
+
+	_, err := server.client.ListTasks(context.Background(), &todov1.ListTasksRequest{})
+	assertStatus(t, err, codes.Unauthenticated, apperrors.CodeUnauthorized)
+	_, err = server.client.ListTasks(server.context(t, "not-a-token"), &todov1.ListTasksRequest{})
+	assertStatus(t, err, codes.Unauthenticated, apperrors.CodeUnauthorized)
+
+	_, token, err := server.apiTokens.CreateToken(server.userID, &models.CreateAPITokenRequest{Name: "reader", Scopes: []string{auth.ScopeTasksRead}})
+	require.NoError(t, err)
+	ctx := server.context(t, token)
+	_, err = server.client.ListTasks(ctx, &todov1.ListTasksRequest{})
+	require.NoError(t, err)
+	_, err = server.client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{Content: "Not allowed"}})
+	assertStatus(t, err, codes.PermissionDenied, apperrors.CodeForbidden)
+}
+
+func TestTaskService_WatchTasks(t *testing.T) {
+	server := newTestServer(t)
+	ctx := server.context(t, server.token)
+	actor := &models.Actor{UserID: server.userID}
+
+	stream, err := server.client.WatchTasks(ctx, &todov1.WatchTasksRequest{})
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	task, err := server.tasks.CreateTask(actor, &models.CreateTaskRequest{Content: "Watched"}, time.UTC)
+	require.NoError(t, err)
+	require.NoError(t, server.tasks.DeleteTask(actor, task.ID, false, nil))
+	_, err = server.tasks.RestoreTask(actor, task.ID)
+	require.NoError(t, err)
+	require.NoError(t, server.tasks.DeleteTask(actor, task.ID, false, nil))
+	require.NoError(t, server.tasks.DeleteTaskPermanently(actor, task.ID))
+
+	created, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, todov1.TaskEvent_TYPE_CREATED, created.Type)
+	assert.Equal(t, "Watched", created.Task.Content)
+	assert.NotEmpty(t, created.Id)
+	deleted, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, todov1.TaskEvent_TYPE_DELETED, deleted.Type)
+	assert.Equal(t, uint64(task.ID), deleted.TaskId)
+	restored, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, todov1.TaskEvent_TYPE_RESTORED, restored.Type)
+	assert.Equal(t, "Watched", restored.Task.Content)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	purged, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, todov1.TaskEvent_TYPE_PURGED, purged.Type)
+	assert.Equal(t, uint64(task.ID), purged.TaskId)
+	assert.Nil(t, purged.Task)
+
+	// Resuming replays the events missed since the token
+	resumed, err := server.client.WatchTasks(ctx, &todov1.WatchTasksRequest{ResumeToken: created.Id})
+	require.NoError(t, err)
+	event, err := resumed.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, deleted.Id, event.Id)
+
+	// An unknown token cannot be resumed from
+	reset, err := server.client.WatchTasks(ctx, &todov1.WatchTasksRequest{ResumeToken: "unknown-1"})
+	require.NoError(t, err)
+	event, err = reset.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, todov1.TaskEvent_TYPE_RESET, event.Type)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/services"
+	"github.com/todo-api-go-sda/internal/validation"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// APITokenHandler handles HTTP requests for personal access tokens
+type APITokenHandler struct {
+	service services.APITokenService
+}
+
+// NewAPITokenHandler creates a new APITokenHandler instance
+func NewAPITokenHandler(service services.APITokenService) *APITokenHandler {
+	return &APITokenHandler{service: service}
+}
+
+// CreateToken handles POST /api/v1/tokens
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	var req models.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	token, plain, err := h.service.CreateToken(middleware.UserID(c), &req)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreateAPITokenResponse{
+		APITokenResponse: token.ToResponse(),
+		Token:            plain,
+	})
+}
+
+// ListTokens handles GET /api/v1/tokens
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	tokens, err := h.service.ListTokens(middleware.UserID(c))
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToAPITokenListResponse(tokens))
+}
+
+// RevokeToken handles DELETE /api/v1/tokens/:id
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid token ID")
+		return
+	}
+
+	if err := h.service.RevokeToken(middleware.UserID(c), id); err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
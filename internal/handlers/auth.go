@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/services"
+	"github.com/todo-api-go-sda/internal/validation"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// AuthHandler handles HTTP requests for accounts and tokens
+type AuthHandler struct {
+	service services.AuthService
+}
+
+// NewAuthHandler creates a new AuthHandler instance
+func NewAuthHandler(service services.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// Register handles POST /api/v1/auth/register
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req models.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	response, err := h.service.Register(&req)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// Login handles POST /api/v1/auth/login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	response, err := h.service.Login(&req)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Refresh handles POST /api/v1/auth/refresh
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	response, err := h.service.Refresh(&req)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetProfile handles GET /api/v1/me
+func (h *AuthHandler) GetProfile(c *gin.Context) {
+	user, err := h.service.GetProfile(middleware.UserID(c))
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// UpdateProfile handles PUT /api/v1/me
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	user, err := h.service.UpdateProfile(middleware.UserID(c), &req)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
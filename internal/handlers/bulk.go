@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/validation"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// bulkOpStatus is the status of each bulk operation when it succeeds, as
+// it would be for the request of its own
+var bulkOpStatus = map[string]int{
+	models.BulkOpCreate:   http.StatusCreated,
+	models.BulkOpUpdate:   http.StatusOK,
+	models.BulkOpComplete: http.StatusOK,
+	models.BulkOpDelete:   http.StatusNoContent,
+}
+
+// BulkTasks handles POST /api/v1/tasks/bulk, applying a list of task
+// operations. With atomic=true they are applied all or nothing; otherwise
+// each one is applied on its own. The response lists the result of each
+// operation and is 200 OK when all of them succeeded, or 207 Multi-Status.
+// Delete operations require the tasks:delete scope.
+func (h *TaskHandler) BulkTasks(c *gin.Context) {
+	atomic := false
+	if value, ok := c.GetQuery("atomic"); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			apperrors.HandleError(c, invalidParam("atomic", "must be true or false"))
+			return
+		}
+		atomic = parsed
+	}
+
+	var req models.BulkTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+	for _, op := range req.Operations {
+		if op.Op == models.BulkOpDelete && !middleware.HasScope(c, auth.ScopeTasksDelete) {
+			apperrors.HandleError(c, &apperrors.ForbiddenError{Message: "token is missing the " + auth.ScopeTasksDelete + " scope"})
+			return
+		}
+	}
+
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	outcomes, err := h.service.BulkTasks(middleware.Actor(c), req.Operations, atomic, loc)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	response := models.BulkTaskResponse{Atomic: atomic, Results: make([]models.BulkTaskResult, len(outcomes))}
+	for i, outcome := range outcomes {
+		op := req.Operations[i]
+		result := models.BulkTaskResult{Op: op.Op, ID: op.ID, Status: bulkOpStatus[op.Op]}
+		if outcome.Err != nil {
+			status, detail := apperrors.Describe(outcome.Err)
+			result.Status = status
+			result.Error = &models.ErrorDetail{Code: detail.Code, Message: detail.Message}
+			response.Failed++
+		} else {
+			if outcome.Task != nil {
+				task := outcome.Task.ToResponse()
+				result.ID = task.ID
+				result.Task = &task
+			}
+			response.Succeeded++
+		}
+		response.Results[i] = result
+	}
+
+	status := http.StatusOK
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, response)
+}
+
+// CompleteMatchingTasks handles POST /api/v1/tasks/bulk/complete, completing
+// every incomplete task that matches the filters of GET /api/v1/tasks
+func (h *TaskHandler) CompleteMatchingTasks(c *gin.Context) {
+	filter, err := parseTaskFilter(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	count, err := h.service.CompleteMatchingTasks(middleware.Actor(c), filter, loc)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.BulkActionResponse{Count: count})
+}
+
+// DeleteMatchingTasks handles POST /api/v1/tasks/bulk/delete, moving every
+// task that matches the filters of GET /api/v1/tasks to the trash. The
+// subtasks query parameter works like for DELETE /api/v1/tasks/:id.
+func (h *TaskHandler) DeleteMatchingTasks(c *gin.Context) {
+	filter, err := parseTaskFilter(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	cascade, err := parseCascade(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	count, err := h.service.DeleteMatchingTasks(middleware.Actor(c), filter, cascade)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.BulkActionResponse{Count: count})
+}
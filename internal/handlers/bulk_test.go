@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestBulkTasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	ops := []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "New"}},
+		{Op: models.BulkOpComplete, ID: 1},
+		{Op: models.BulkOpDelete, ID: 2},
+	}
+	mockService.On("BulkTasks", testActor, ops, false, time.UTC).Return([]models.BulkTaskOutcome{
+		{Task: &models.Task{ID: 3, Content: "New"}},
+		{Task: &models.Task{ID: 1, Content: "Done", Completed: true}},
+		{Err: &apperrors.TaskNotFoundError{ID: 2}},
+	}, nil)
+	mockService.On("BulkTasks", testActor, ops, true, time.UTC).Return([]models.BulkTaskOutcome{
+		{Task: &models.Task{ID: 3, Content: "New"}},
+		{Task: &models.Task{ID: 1, Content: "Done", Completed: true}},
+		{},
+	}, nil)
+
+	body, _ := json.Marshal(models.BulkTaskRequest{Operations: ops})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/bulk", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	var response models.BulkTaskResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.False(t, response.Atomic)
+	assert.Equal(t, 2, response.Succeeded)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, uint(3), response.Results[0].ID)
+	assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+	assert.Equal(t, "New", response.Results[0].Task.Content)
+	assert.Equal(t, http.StatusOK, response.Results[1].Status)
+	assert.True(t, response.Results[1].Task.Completed)
+	assert.Equal(t, http.StatusNotFound, response.Results[2].Status)
+	assert.Equal(t, apperrors.CodeTaskNotFound, response.Results[2].Error.Code)
+	assert.Nil(t, response.Results[2].Task)
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/tasks/bulk?atomic=true", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.True(t, response.Atomic)
+	assert.Equal(t, 3, response.Succeeded)
+	assert.Equal(t, http.StatusNoContent, response.Results[2].Status)
+	assert.Equal(t, uint(2), response.Results[2].ID)
+	mockService.AssertExpectations(t)
+}
+
+func TestBulkTasks_InvalidRequest(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	tooMany := make([]map[string]interface{}, 101)
+	for i := range tooMany {
+		tooMany[i] = map[string]interface{}{"op": "complete", "id": i + 1}
+	}
+	for name, body := range map[string]interface{}{
+		"no operations": map[string]interface{}{"operations": []interface{}{}},
+		"unknown op":    map[string]interface{}{"operations": []interface{}{map[string]interface{}{"op": "archive", "id": 1}}},
+		"invalid task":  map[string]interface{}{"operations": []interface{}{map[string]interface{}{"op": "create", "task": map[string]interface{}{}}}},
+		"too many":      map[string]interface{}{"operations": tooMany},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, _ := json.Marshal(body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/bulk", bytes.NewBuffer(data))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/bulk?atomic=maybe", bytes.NewBufferString(`{"operations":[{"op":"complete","id":1}]}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "BulkTasks", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBulkTasks_InvalidFieldsAreNamed(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"no operations", `{"operations":[]}`, "operations must have at least 1 items"},
+		{"unknown op", `{"operations":[{"op":"archive","id":1}]}`, "operations[0].op must be one of create, update, complete, delete"},
+		{"unknown priority", `{"operations":[{"op":"complete","id":1},{"op":"create","task":{"content":"Task","priority":"asap"}}]}`,
+			"operations[1].task.priority must be one of none, low, medium, high, urgent"},
+		{"wrong type", `{"operations":[{"op":"update","id":1,"changes":{"due_at":42}}]}`, "operations[0].changes.due_at must be a string, not number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			handler := NewTaskHandler(mockService, testServerConfig)
+			router := setupTestRouter(handler)
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/bulk", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response models.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, apperrors.CodeValidationError, response.Error.Code)
+			assert.Equal(t, tt.message, response.Error.Message)
+			mockService.AssertNotCalled(t, "BulkTasks", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCompleteMatchingTasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("CompleteMatchingTasks", testActor, &models.TaskFilter{Tags: []string{"errands"}}, time.UTC).Return(4, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/bulk/complete?tag=Errands", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.BulkActionResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 4, response.Count)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteMatchingTasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	completed := true
+	filter := &models.TaskFilter{Completed: &completed}
+	mockService.On("DeleteMatchingTasks", testActor, filter, true).Return(2, nil)
+	mockService.On("DeleteMatchingTasks", testActor, filter, false).
+		Return(0, &apperrors.ConflictError{Message: "task 1 has 1 subtasks; delete them first or cascade the deletion"})
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/bulk/delete?completed=true&subtasks=cascade", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.BulkActionResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 2, response.Count)
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/tasks/bulk/delete?completed=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/tasks/bulk/delete?completed=true&subtasks=all", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
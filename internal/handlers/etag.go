@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// Conditional requests on a task use its version as a strong entity tag,
+// such as "3". Writes honor If-Match and reads honor If-None-Match.
+
+// errMalformedETags is returned for headers that are not "*" or a list of
+// entity tags
+var errMalformedETags = errors.New("malformed entity tag list")
+
+// entityTag is an entity tag from a conditional request header
+type entityTag struct {
+	value string
+	weak  bool
+}
+
+// version returns the task version the tag names, if any
+func (t entityTag) version() (uint, bool) {
+	version, err := strconv.ParseUint(t.value, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(version), true
+}
+
+// taskETag returns the entity tag of a version of a task
+func taskETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// parseETags parses an If-Match or If-None-Match header, reporting whether
+// it is the wildcard "*" that matches any version
+func parseETags(header string) ([]entityTag, bool, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return nil, true, nil
+	}
+	var tags []entityTag
+	rest := header
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if rest == "" {
+			break
+		}
+		var tag entityTag
+		if strings.HasPrefix(rest, "W/") {
+			tag.weak = true
+			rest = rest[len("W/"):]
+		}
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, false, errMalformedETags
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, false, errMalformedETags
+		}
+		tag.value = rest[1 : end+1]
+		rest = rest[end+2:]
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		return nil, false, errMalformedETags
+	}
+	return tags, false, nil
+}
+
+// ifMatch reads the If-Match header of a write to a task into the versions
+// the write may apply to. Weak tags never match, as If-Match compares tags
+// strongly.
+func ifMatch(c *gin.Context) (models.VersionCondition, error) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return nil, nil
+	}
+	tags, wildcard, err := parseETags(header)
+	if err != nil {
+		return nil, &apperrors.ValidationError{Message: "If-Match must be * or a list of entity tags"}
+	}
+	if wildcard {
+		return nil, nil
+	}
+	versions := models.VersionCondition{}
+	for _, tag := range tags {
+		if version, ok := tag.version(); ok && !tag.weak {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+// notModified reports whether the If-None-Match header of a read matches
+// the version of the task. Tags are compared weakly and malformed headers
+// are ignored.
+func notModified(c *gin.Context, version uint) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	tags, wildcard, err := parseETags(header)
+	if err != nil {
+		return false
+	}
+	if wildcard {
+		return true
+	}
+	for _, tag := range tags {
+		if tagVersion, ok := tag.version(); ok && tagVersion == version {
+			return true
+		}
+	}
+	return false
+}
+
+// respondWithTask sends a task along with its entity tag
+func respondWithTask(c *gin.Context, status int, task *models.Task) {
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(status, task.ToResponse())
+}
+
+// handleTaskError is HandleError for writes to a task. A version conflict
+// is sent with the task's current representation and entity tag.
+func handleTaskError(c *gin.Context, err error) {
+	if conflict, ok := err.(*apperrors.VersionConflictError); ok {
+		if task, ok := conflict.Current.(*models.Task); ok {
+			c.Header("ETag", taskETag(task.Version))
+			err = &apperrors.VersionConflictError{ID: conflict.ID, Current: task.ToResponse()}
+		}
+	}
+	apperrors.HandleError(c, err)
+}
+
+// respondNotModified ends a read whose If-None-Match matched the task
+func respondNotModified(c *gin.Context, task *models.Task) {
+	c.Header("ETag", taskETag(task.Version))
+	c.Status(http.StatusNotModified)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestParseETags(t *testing.T) {
+	tests := []struct {
+		header   string
+		tags     []entityTag
+		wildcard bool
+		valid    bool
+	}{
+		{header: `"3"`, tags: []entityTag{{value: "3"}}, valid: true},
+		{header: ` "3", W/"4" ,"x"`, tags: []entityTag{{value: "3"}, {value: "4", weak: true}, {value: "x"}}, valid: true},
+		{header: `*`, wildcard: true, valid: true},
+		{header: `3`},
+		{header: `"3`},
+		{header: `,`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			tags, wildcard, err := parseETags(tt.header)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.tags, tags)
+			assert.Equal(t, tt.wildcard, wildcard)
+		})
+	}
+}
+
+func TestGetTask_ETag(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("GetTaskByID", testUserID, uint(1), false).Return(&models.Task{ID: 1, Content: "Task", Version: 3}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	for header, status := range map[string]int{
+		`"3"`:        http.StatusNotModified,
+		`W/"3"`:      http.StatusNotModified,
+		`"2", "3"`:   http.StatusNotModified,
+		`*`:          http.StatusNotModified,
+		`"2"`:        http.StatusOK,
+		`not a list`: http.StatusOK,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/1", nil)
+		req.Header.Set("If-None-Match", header)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, header)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"), header)
+		if status == http.StatusNotModified {
+			assert.Empty(t, w.Body.String(), header)
+		}
+	}
+}
+
+func TestUpdateTask_IfMatch(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	current := &models.Task{ID: 1, Content: "Changed elsewhere", Version: 4}
+	mockService.On("UpdateTask", testActor, uint(1), mock.AnythingOfType("*models.UpdateTaskRequest"), time.UTC, models.VersionCondition{3}).
+		Return(&models.Task{ID: 1, Content: "Mine", Version: 4}, nil)
+	mockService.On("UpdateTask", testActor, uint(1), mock.AnythingOfType("*models.UpdateTaskRequest"), time.UTC, models.VersionCondition{}).
+		Return(nil, &apperrors.VersionConflictError{ID: 1, Current: current})
+
+	update := func(ifMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/tasks/1", strings.NewReader(`{"content":"Mine"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := update(`"3"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+
+	// Weak tags never match If-Match
+	w = update(`W/"3"`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	var response models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, apperrors.CodeVersionConflict, response.Error.Code)
+	if assert.NotNil(t, response.Current) {
+		assert.Equal(t, "Changed elsewhere", response.Current.Content)
+		assert.Equal(t, uint(4), response.Current.Version)
+	}
+
+	w = update(`3`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNumberOfCalls(t, "UpdateTask", 2)
+}
+
+func TestDeleteTask_IfMatch(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("DeleteTask", testActor, uint(1), false, models.VersionCondition{2}).
+		Return(&apperrors.VersionConflictError{ID: 1, Current: &models.Task{ID: 1, Version: 5}})
+	mockService.On("DeleteTask", testActor, uint(1), false, models.VersionCondition(nil)).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/tasks/1", nil)
+	req.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/tasks/1", nil)
+	req.Header.Set("If-Match", `*`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
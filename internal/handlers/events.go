@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/middleware"
+)
+
+// resetEvent tells a client that events may have been missed since the
+// Last-Event-ID it sent, so that it reloads its tasks
+const resetEvent = "reset"
+
+// EventHandler streams changes to the user's tasks as Server-Sent Events
+type EventHandler struct {
+	bus       *events.Bus
+	heartbeat time.Duration
+}
+
+// NewEventHandler creates a new EventHandler instance
+func NewEventHandler(bus *events.Bus, cfg *config.EventsConfig) *EventHandler {
+	return &EventHandler{bus: bus, heartbeat: cfg.HeartbeatInterval}
+}
+
+// Stream handles GET /api/v1/tasks/events. A client reconnecting with
+// Last-Event-ID first receives the events it missed, or a reset event when
+// they are no longer buffered. Idle streams are sent a comment as a
+// heartbeat. The stream ends when the client falls too far behind, and the
+// client is expected to reconnect from the last event it received.
+func (h *EventHandler) Stream(c *gin.Context) {
+	sub, replay, resumed := h.bus.Subscribe(middleware.UserID(c), c.GetHeader("Last-Event-ID"))
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", sse.ContentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !resumed {
+		c.Render(-1, sse.Event{Event: resetEvent, Data: "{}"})
+	}
+	for _, event := range replay {
+		h.send(c, event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			h.send(c, event)
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(":heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// send writes one event of the bus to the stream
+func (h *EventHandler) send(c *gin.Context, event events.Event) {
+	c.Render(-1, sse.Event{Id: event.ID, Event: event.Type, Data: []byte(event.Data)})
+}
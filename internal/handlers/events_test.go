@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/middleware"
+)
+
+func setupEventServer(t *testing.T, bus *events.Bus) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		middleware.SetUserID(c, testUserID)
+	})
+	handler := NewEventHandler(bus, &config.EventsConfig{HeartbeatInterval: 50 * time.Millisecond})
+	router.GET("/api/v1/tasks/events", handler.Stream)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// openStream starts streaming events and returns a reader of its lines
+func openStream(t *testing.T, server *httptest.Server, lastEventID string) (*http.Response, *bufio.Scanner) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/tasks/events", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, bufio.NewScanner(resp.Body)
+}
+
+// readEvent reads the lines of the next message of a stream
+func readEvent(t *testing.T, lines *bufio.Scanner) []string {
+	var message []string
+	for lines.Scan() {
+		if lines.Text() == "" {
+			return message
+		}
+		message = append(message, lines.Text())
+	}
+	t.Fatalf("stream ended: %v", lines.Err())
+	return nil
+}
+
+// publishTask publishes an event about a task of the user
+func publishTask(bus *events.Bus, ownerID uint, eventType string, id int) {
+	data, _ := json.Marshal(map[string]int{"id": id})
+	bus.Publish(events.Event{OwnerID: ownerID, Type: eventType, Data: data})
+}
+
+func TestStreamEvents(t *testing.T) {
+	bus := events.NewBus(10)
+	server := setupEventServer(t, bus)
+
+	resp, lines := openStream(t, server, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	publishTask(bus, testUserID+1, events.TaskCreated, 1)
+	publishTask(bus, testUserID, events.TaskCreated, 2)
+	message := readEvent(t, lines)
+	require.Len(t, message, 3)
+	assert.True(t, strings.HasPrefix(message[0], "id:"))
+	assert.Equal(t, "event:task.created", message[1])
+	assert.Equal(t, `data:{"id":2}`, message[2])
+
+	// Idle streams are sent heartbeats
+	assert.Equal(t, []string{":heartbeat"}, readEvent(t, lines))
+}
+
+func TestStreamEvents_Resume(t *testing.T) {
+	bus := events.NewBus(10)
+	server := setupEventServer(t, bus)
+
+	_, lines := openStream(t, server, "")
+	publishTask(bus, testUserID, events.TaskCreated, 1)
+	first := readEvent(t, lines)
+	publishTask(bus, testUserID, events.TaskUpdated, 1)
+	publishTask(bus, testUserID, events.TaskDeleted, 1)
+	readEvent(t, lines)
+	readEvent(t, lines)
+
+	_, lines = openStream(t, server, strings.TrimPrefix(first[0], "id:"))
+	assert.Equal(t, "event:task.updated", readEvent(t, lines)[1])
+	assert.Equal(t, "event:task.deleted", readEvent(t, lines)[1])
+
+	_, lines = openStream(t, server, "unknown-1")
+	assert.Equal(t, []string{"event:reset", "data:{}"}, readEvent(t, lines))
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/pagination"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// dateLayout is accepted for time filters in addition to RFC 3339
+const dateLayout = "2006-01-02"
+
+// parseTaskFilter parses the list query parameters into a TaskFilter
+func parseTaskFilter(c *gin.Context) (*models.TaskFilter, error) {
+	filter := &models.TaskFilter{}
+
+	if value, ok := c.GetQuery("project_id"); ok {
+		projectID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, invalidParam("project_id", "must be a project ID, or 0 for the inbox")
+		}
+		id := uint(projectID)
+		filter.ProjectID = &id
+	}
+
+	if value, ok := c.GetQuery("parent_id"); ok {
+		parentID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, invalidParam("parent_id", "must be a task ID, or 0 for top-level tasks")
+		}
+		id := uint(parentID)
+		filter.ParentID = &id
+	}
+
+	if value, ok := c.GetQuery("series_id"); ok {
+		seriesID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || seriesID == 0 {
+			return nil, invalidParam("series_id", "must be the ID of a recurring series")
+		}
+		id := uint(seriesID)
+		filter.SeriesID = &id
+	}
+
+	if values, ok := c.GetQueryArray("tag"); ok {
+		seen := make(map[string]bool, len(values))
+		for _, value := range values {
+			name := models.NormalizeTagName(value)
+			if name == "" {
+				return nil, invalidParam("tag", "must not be empty")
+			}
+			if !seen[name] {
+				seen[name] = true
+				filter.Tags = append(filter.Tags, name)
+			}
+		}
+	}
+
+	if value, ok := c.GetQuery("tag_mode"); ok {
+		if value != models.TagModeAll && value != models.TagModeAny {
+			return nil, invalidParam("tag_mode", "must be all or any")
+		}
+		filter.TagMode = value
+	}
+
+	if value, ok := c.GetQuery("completed"); ok {
+		completed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, invalidParam("completed", "must be true or false")
+		}
+		filter.Completed = &completed
+	}
+
+	if value, ok := c.GetQuery("created_after"); ok {
+		t, err := parseTimeParam(value)
+		if err != nil {
+			return nil, invalidParam("created_after", "must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		filter.CreatedAfter = &t
+	}
+
+	if value, ok := c.GetQuery("updated_before"); ok {
+		t, err := parseTimeParam(value)
+		if err != nil {
+			return nil, invalidParam("updated_before", "must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		filter.UpdatedBefore = &t
+	}
+
+	if value, ok := c.GetQuery("content_contains"); ok {
+		if strings.TrimSpace(value) == "" {
+			return nil, invalidParam("content_contains", "must not be empty")
+		}
+		filter.ContentContains = value
+	}
+
+	if value, ok := c.GetQuery("sort"); ok {
+		sort, err := parseSort(value)
+		if err != nil {
+			return nil, err
+		}
+		filter.Sort = sort
+	}
+
+	return filter, nil
+}
+
+// pager holds the pagination settings of the handlers of paginated lists
+type pager struct {
+	cursors         *pagination.Codec
+	defaultPageSize int
+	maxPageSize     int
+}
+
+// newPager creates a pager using the pagination settings of cfg
+func newPager(cfg *config.ServerConfig) pager {
+	return pager{
+		cursors:         pagination.NewCodec(cfg.CursorSecret),
+		defaultPageSize: min(cfg.DefaultPageSize, cfg.MaxPageSize),
+		maxPageSize:     cfg.MaxPageSize,
+	}
+}
+
+// parsePageRequest parses the pagination query parameters into a PageRequest
+func (h *pager) parsePageRequest(c *gin.Context) (*models.PageRequest, error) {
+	page := &models.PageRequest{Limit: h.defaultPageSize}
+
+	if value, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > h.maxPageSize {
+			return nil, invalidParam("limit", fmt.Sprintf("must be an integer between 1 and %d", h.maxPageSize))
+		}
+		page.Limit = limit
+	}
+
+	if value, ok := c.GetQuery("cursor"); ok {
+		cursor, err := h.cursors.Decode(value)
+		if err != nil {
+			return nil, invalidParam("cursor", "malformed or tampered cursor")
+		}
+		page.Cursor = cursor
+	}
+
+	if value, ok := c.GetQuery("include_total"); ok {
+		includeTotal, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, invalidParam("include_total", "must be true or false")
+		}
+		page.IncludeTotal = includeTotal
+	}
+
+	return page, nil
+}
+
+// parseSort parses a comma-separated list of sort keys, each optionally
+// prefixed with "-" for descending order
+func parseSort(value string) ([]models.SortField, error) {
+	seen := make(map[string]bool)
+	var fields []models.SortField
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+		if _, ok := models.TaskSortColumns[key]; !ok {
+			return nil, invalidParam("sort", fmt.Sprintf("unknown sort field %q", key))
+		}
+		if seen[key] {
+			return nil, invalidParam("sort", fmt.Sprintf("duplicate sort field %q", key))
+		}
+		seen[key] = true
+		fields = append(fields, models.SortField{Field: key, Desc: desc})
+	}
+	return fields, nil
+}
+
+// parseTimeParam parses an RFC 3339 timestamp or a plain date in UTC
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(dateLayout, value)
+}
+
+// invalidParam builds a validation error naming the offending query parameter
+func invalidParam(name, reason string) error {
+	return &apperrors.ValidationError{Message: fmt.Sprintf("invalid query parameter %s: %s", name, reason)}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/graph"
+	"github.com/todo-api-go-sda/internal/middleware"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// GraphQLHandler serves the GraphQL API
+type GraphQLHandler struct {
+	schema *graph.Schema
+}
+
+// NewGraphQLHandler creates a new GraphQLHandler instance
+func NewGraphQLHandler(schema *graph.Schema) *GraphQLHandler {
+	return &GraphQLHandler{schema: schema}
+}
+
+// Query handles POST /api/v1/graphql. Requests that can be parsed are
+// answered with 200 OK, with the errors of the query in the response.
+// Mutations need the scopes their REST counterparts need.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graph.Request
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Query) == "" {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError,
+			"the body must be a JSON object with a query")
+		return
+	}
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.schema.Execute(c.Request.Context(), graph.Caller{
+		Actor:     middleware.Actor(c),
+		Location:  loc,
+		CanWrite:  middleware.HasScope(c, auth.ScopeTasksWrite),
+		CanDelete: middleware.HasScope(c, auth.ScopeTasksDelete),
+	}, req))
+}
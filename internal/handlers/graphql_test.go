@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/graph"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func setupGraphQLRouter(t *testing.T, mockService *MockTaskService) *gin.Engine {
+	schema, err := graph.NewSchema(mockService, new(MockProjectService), testServerConfig,
+		&config.GraphQLConfig{MaxDepth: 10, MaxComplexity: 20000})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		middleware.SetUserID(c, testUserID)
+	})
+	router.POST("/api/v1/graphql", NewGraphQLHandler(schema).Query)
+	return router
+}
+
+// graphQLResponse is the body of a GraphQL response
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraphQL(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/graphql", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGraphQLQuery_Success(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupGraphQLRouter(t, mockService)
+	mockService.On("GetTaskByID", testUserID, uint(1), false).Return(&models.Task{ID: 1, OwnerID: testUserID, Content: "Task", Priority: models.PriorityHigh}, nil)
+
+	w := postGraphQL(router, `{"query": "query ($id: ID!) { task(id: $id) { id content priority } }", "variables": {"id": "1"}}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response graphQLResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Errors)
+	assert.JSONEq(t, `{"task": {"id": "1", "content": "Task", "priority": "HIGH"}}`, string(response.Data))
+	mockService.AssertExpectations(t)
+}
+
+func TestGraphQLQuery_FieldError(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupGraphQLRouter(t, mockService)
+	mockService.On("GetTaskByID", testUserID, uint(9), false).Return(nil, &apperrors.TaskNotFoundError{ID: 9})
+
+	w := postGraphQL(router, `{"query": "{ task(id: 9) { id } }"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response graphQLResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.JSONEq(t, `{"task": null}`, string(response.Data))
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, apperrors.CodeTaskNotFound, response.Errors[0].Extensions["code"])
+	assert.Equal(t, []interface{}{"task"}, response.Errors[0].Path)
+}
+
+func TestGraphQLQuery_InvalidBody(t *testing.T) {
+	router := setupGraphQLRouter(t, new(MockTaskService))
+
+	for _, body := range []string{`not json`, `{"query": " "}`} {
+		w := postGraphQL(router, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), apperrors.CodeValidationError)
+	}
+}
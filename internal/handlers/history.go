@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/validation"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// TaskHistory handles GET /api/v1/tasks/:id/history, listing the events of
+// a task newest first
+func (h *TaskHandler) TaskHistory(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid task ID")
+		return
+	}
+
+	page, err := h.parsePageRequest(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	result, err := h.service.ListTaskHistory(middleware.UserID(c), id, page)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	h.respondWithEvents(c, result)
+}
+
+// ListAudit handles GET /api/v1/audit, listing the events of all of the
+// user's tasks newest first
+func (h *TaskHandler) ListAudit(c *gin.Context) {
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	page, err := h.parsePageRequest(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	result, err := h.service.ListTaskEvents(middleware.UserID(c), filter, page)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	h.respondWithEvents(c, result)
+}
+
+// RevertTask handles POST /api/v1/tasks/:id/revert, setting the task back
+// to a version in its history. If-Match is honored.
+func (h *TaskHandler) RevertTask(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid task ID")
+		return
+	}
+
+	var req models.RevertTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	versions, err := ifMatch(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	task, err := h.service.RevertTask(middleware.Actor(c), id, req.Version, loc, versions)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	respondWithTask(c, http.StatusOK, task)
+}
+
+// respondWithEvents responds with one page of task events
+func (h *TaskHandler) respondWithEvents(c *gin.Context, result *models.TaskEventPage) {
+	response := models.ToEventListResponse(result.Events)
+	if result.NextCursor != nil {
+		response.NextCursor = h.cursors.Encode(result.NextCursor)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// parseEventFilter parses the audit log query parameters into a
+// TaskEventFilter
+func parseEventFilter(c *gin.Context) (*models.TaskEventFilter, error) {
+	filter := &models.TaskEventFilter{}
+
+	if value, ok := c.GetQuery("task_id"); ok {
+		taskID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || taskID == 0 {
+			return nil, invalidParam("task_id", "must be a task ID")
+		}
+		id := uint(taskID)
+		filter.TaskID = &id
+	}
+
+	if values, ok := c.GetQueryArray("type"); ok {
+		for _, value := range values {
+			for _, eventType := range strings.Split(value, ",") {
+				eventType = strings.TrimSpace(eventType)
+				if !slices.Contains(models.TaskEventTypes, eventType) {
+					return nil, invalidParam("type", "must be created, updated, completed, deleted, restored or purged")
+				}
+				if !slices.Contains(filter.Types, eventType) {
+					filter.Types = append(filter.Types, eventType)
+				}
+			}
+		}
+	}
+
+	if value, ok := c.GetQuery("token_id"); ok {
+		tokenID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || tokenID == 0 {
+			return nil, invalidParam("token_id", "must be a personal access token ID")
+		}
+		id := uint(tokenID)
+		filter.TokenID = &id
+	}
+
+	if value, ok := c.GetQuery("request_id"); ok {
+		if value == "" {
+			return nil, invalidParam("request_id", "must not be empty")
+		}
+		filter.RequestID = value
+	}
+
+	if value, ok := c.GetQuery("since"); ok {
+		t, err := parseTimeParam(value)
+		if err != nil {
+			return nil, invalidParam("since", "must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		filter.Since = &t
+	}
+
+	if value, ok := c.GetQuery("until"); ok {
+		t, err := parseTimeParam(value)
+		if err != nil {
+			return nil, invalidParam("until", "must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		filter.Until = &t
+	}
+
+	return filter, nil
+}
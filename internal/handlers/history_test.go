@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestTaskHistory(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	createdAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	mockService.On("ListTaskHistory", testUserID, uint(1), &models.PageRequest{Limit: 2}).Return(&models.TaskEventPage{
+		Events: []models.TaskEvent{
+			{ID: 2, TaskID: 1, Type: models.TaskEventUpdated, Version: 2, ActorID: testUserID, RequestID: "req-2",
+				Changes: `{"content":{"from":"Draft","to":"Final"}}`, State: `{"content":"Final"}`, CreatedAt: createdAt},
+			{ID: 1, TaskID: 1, Type: models.TaskEventCreated, Version: 1, ActorID: testUserID,
+				Changes: `{}`, State: `{"content":"Draft"}`, CreatedAt: createdAt},
+		},
+		NextCursor: &models.Cursor{CreatedAt: createdAt, ID: 1},
+	}, nil)
+	mockService.On("ListTaskHistory", testUserID, uint(9), mock.Anything).Return(nil, &apperrors.TaskNotFoundError{ID: 9})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/1/history?limit=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TaskEventListResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 2, response.Count)
+	assert.NotEmpty(t, response.NextCursor)
+	assert.Equal(t, "updated", response.Events[0].Type)
+	assert.Equal(t, "req-2", response.Events[0].RequestID)
+	assert.JSONEq(t, `{"content":{"from":"Draft","to":"Final"}}`, string(response.Events[0].Changes))
+	assert.JSONEq(t, `{"content":"Draft"}`, string(response.Events[1].State))
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/tasks/9/history", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListAudit_Filters(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	expected := &models.TaskEventFilter{
+		TaskID: ptr(uint(4)), Types: []string{"created", "deleted", "restored"}, TokenID: ptr(uint(3)),
+		RequestID: "req-1", Since: &since, Until: &until,
+	}
+	mockService.On("ListTaskEvents", testUserID, expected, &models.PageRequest{Limit: 20}).
+		Return(&models.TaskEventPage{Events: []models.TaskEvent{}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit?task_id=4&type=created,deleted&type=restored&type=created"+
+		"&token_id=3&request_id=req-1&since=2026-10-01&until=2026-10-17T12:00:00Z", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"events": [], "count": 0}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestListAudit_InvalidFilters(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	for _, query := range []string{"type=renamed", "task_id=0", "token_id=abc", "request_id=", "since=yesterday", "until=17-10-2026", "limit=0"} {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockService.AssertNotCalled(t, "ListTaskEvents", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRevertTask(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("RevertTask", testActor, uint(1), uint(2), time.UTC, models.VersionCondition{4}).
+		Return(&models.Task{ID: 1, Content: "Draft", Version: 5}, nil)
+	mockService.On("RevertTask", testActor, uint(1), uint(9), time.UTC, models.VersionCondition(nil)).
+		Return(nil, &apperrors.VersionNotFoundError{ID: 1, Version: 9})
+
+	body, _ := json.Marshal(models.RevertTaskRequest{Version: 2})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/1/revert", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"4"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+	var response models.TaskResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "Draft", response.Content)
+
+	body, _ = json.Marshal(models.RevertTaskRequest{Version: 9})
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/tasks/1/revert", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), apperrors.CodeVersionNotFound)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRevertTask_ValidationError(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"missing version", `{}`, "version is required"},
+		{"wrong type", `{"version":"2"}`, "version must be an integer, not string"},
+		{"not JSON", `version=2`, "the body is not valid JSON: invalid character 'v' looking for beginning of value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			router := setupTestRouter(NewTaskHandler(mockService, testServerConfig))
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/1/revert", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assertValidationError(t, w, tt.message)
+			assert.Empty(t, mockService.Calls)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// patchedTask returns a task of the user to patch, at version 3
+func patchedTask() *models.Task {
+	projectID := uint(2)
+	dueAt := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	return &models.Task{
+		ID: 1, OwnerID: testUserID, ProjectID: &projectID, Content: "Buy milk", Priority: models.PriorityHigh,
+		DueAt: &dueAt, Tags: []models.Tag{{ID: 4, Name: "home"}}, Version: 3,
+	}
+}
+
+func patchRequest(router http.Handler, contentType, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/tasks/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPatchTask_MergePatch(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("GetTaskByID", testUserID, uint(1), false).Return(patchedTask(), nil)
+	var update *models.UpdateTaskRequest
+	mockService.On("UpdateTask", testActor, uint(1), mock.AnythingOfType("*models.UpdateTaskRequest"), time.UTC, models.VersionCondition{3}).
+		Run(func(args mock.Arguments) { update = args.Get(2).(*models.UpdateTaskRequest) }).
+		Return(&models.Task{ID: 1, Content: "Buy oat milk", Version: 4}, nil)
+
+	w := patchRequest(router, mergePatchMediaType, `{"content":"Buy oat milk","due_at":null,"project_id":null}`)
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	if assert.NotNil(t, update) {
+		assert.Equal(t, "Buy oat milk", *update.Content)
+		assert.Equal(t, "", *update.DueAt)
+		assert.Equal(t, uint(0), *update.ProjectID)
+		assert.Equal(t, "high", *update.Priority)
+		assert.Equal(t, []string{"home"}, update.Tags)
+	}
+}
+
+func TestPatchTask_JSONPatch(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("GetTaskByID", testUserID, uint(1), false).Return(patchedTask(), nil)
+	var update *models.UpdateTaskRequest
+	mockService.On("UpdateTask", testActor, uint(1), mock.AnythingOfType("*models.UpdateTaskRequest"), time.UTC, models.VersionCondition{3}).
+		Run(func(args mock.Arguments) { update = args.Get(2).(*models.UpdateTaskRequest) }).
+		Return(&models.Task{ID: 1, Version: 4}, nil)
+
+	w := patchRequest(router, jsonPatchMediaType, `[
+		{"op":"test","path":"/content","value":"Buy milk"},
+		{"op":"add","path":"/tags/-","value":"errands"},
+		{"op":"replace","path":"/completed","value":true}
+	]`)
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	if assert.NotNil(t, update) {
+		assert.Equal(t, []string{"home", "errands"}, update.Tags)
+		assert.True(t, *update.Completed)
+		assert.Equal(t, "2026-10-20T09:00:00Z", *update.DueAt)
+	}
+
+	// A failed test leaves the task unchanged
+	w = patchRequest(router, jsonPatchMediaType, `[{"op":"test","path":"/content","value":"Buy bread"}]`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertNumberOfCalls(t, "UpdateTask", 1)
+}
+
+func TestPatchTask_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		code        string
+	}{
+		{"unsupported media type", "application/json", `{"content":"Buy oat milk"}`, http.StatusUnsupportedMediaType, apperrors.CodeUnsupportedMediaType},
+		{"malformed merge patch", mergePatchMediaType, `{"content":`, http.StatusBadRequest, apperrors.CodeValidationError},
+		{"removed content", mergePatchMediaType, `{"content":null}`, http.StatusBadRequest, apperrors.CodeValidationError},
+		{"invalid priority", mergePatchMediaType, `{"priority":"someday"}`, http.StatusBadRequest, apperrors.CodeValidationError},
+		{"unknown field", mergePatchMediaType, `{"owner_id":8}`, http.StatusBadRequest, apperrors.CodeValidationError},
+		{"wrong type", mergePatchMediaType, `{"completed":"yes"}`, http.StatusBadRequest, apperrors.CodeValidationError},
+		{"missing path", jsonPatchMediaType, `[{"op":"remove","path":"/missing"}]`, http.StatusBadRequest, apperrors.CodeValidationError},
+		{"not an array", jsonPatchMediaType, `{"op":"remove","path":"/tags"}`, http.StatusBadRequest, apperrors.CodeValidationError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			handler := NewTaskHandler(mockService, testServerConfig)
+			router := setupTestRouter(handler)
+			mockService.On("GetTaskByID", testUserID, uint(1), false).Return(patchedTask(), nil)
+
+			w := patchRequest(router, tt.contentType, tt.body)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			var response models.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			assert.Equal(t, tt.code, response.Error.Code)
+			mockService.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPatchTask_IfMatch(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("GetTaskByID", testUserID, uint(1), false).Return(patchedTask(), nil)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/tasks/1", strings.NewReader(`{"content":"Buy oat milk"}`))
+	req.Header.Set("Content-Type", mergePatchMediaType)
+	req.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	mockService.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_ReplacesEveryField(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	var update *models.UpdateTaskRequest
+	mockService.On("UpdateTask", testActor, uint(1), mock.AnythingOfType("*models.UpdateTaskRequest"), time.UTC, models.VersionCondition(nil)).
+		Run(func(args mock.Arguments) { update = args.Get(2).(*models.UpdateTaskRequest) }).
+		Return(&models.Task{ID: 1, Content: "Buy bread", Version: 2}, nil)
+
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/tasks/1", strings.NewReader(`{"content":"Buy bread"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	if assert.NotNil(t, update) {
+		assert.Equal(t, models.UpdateTaskRequest{
+			Content: ptr("Buy bread"), Completed: ptr(false), ProjectID: ptr(uint(0)), ParentID: ptr(uint(0)),
+			Tags: []string{}, Priority: ptr("none"), DueAt: ptr(""), Recurrence: ptr(""),
+		}, *update)
+	}
+
+	// Content is required since a replacement without it would clear it
+	req, _ = http.NewRequest(http.MethodPut, "/api/v1/tasks/1", strings.NewReader(`{"completed":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNumberOfCalls(t, "UpdateTask", 1)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/services"
+	"github.com/todo-api-go-sda/internal/validation"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// Values of the tasks query parameter accepted when deleting a project
+const (
+	projectTasksInbox   = "inbox"
+	projectTasksCascade = "cascade"
+)
+
+// ProjectHandler handles HTTP requests for projects
+type ProjectHandler struct {
+	service services.ProjectService
+}
+
+// NewProjectHandler creates a new ProjectHandler instance
+func NewProjectHandler(service services.ProjectService) *ProjectHandler {
+	return &ProjectHandler{service: service}
+}
+
+// CreateProject handles POST /api/v1/projects
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	var req models.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	project, err := h.service.CreateProject(middleware.UserID(c), &req)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, project.ToResponse())
+}
+
+// ListProjects handles GET /api/v1/projects
+func (h *ProjectHandler) ListProjects(c *gin.Context) {
+	includeArchived := false
+	if value, ok := c.GetQuery("include_archived"); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			apperrors.HandleError(c, invalidParam("include_archived", "must be true or false"))
+			return
+		}
+		includeArchived = parsed
+	}
+
+	projects, err := h.service.ListProjects(middleware.UserID(c), includeArchived)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToProjectListResponse(projects))
+}
+
+// GetProject handles GET /api/v1/projects/:id
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid project ID")
+		return
+	}
+
+	project, err := h.service.GetProjectByID(middleware.UserID(c), id)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project.ToResponse())
+}
+
+// UpdateProject handles PUT /api/v1/projects/:id
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid project ID")
+		return
+	}
+
+	var req models.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	project, err := h.service.UpdateProject(middleware.UserID(c), id, &req)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project.ToResponse())
+}
+
+// DeleteProject handles DELETE /api/v1/projects/:id. The tasks query
+// parameter selects what happens to the project's tasks: "inbox" (the
+// default) moves them out of the project, "cascade" deletes them.
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid project ID")
+		return
+	}
+
+	deleteTasks := false
+	switch c.DefaultQuery("tasks", projectTasksInbox) {
+	case projectTasksInbox:
+	case projectTasksCascade:
+		deleteTasks = true
+	default:
+		apperrors.HandleError(c, invalidParam("tasks", "must be inbox or cascade"))
+		return
+	}
+
+	if err := h.service.DeleteProject(middleware.UserID(c), id, deleteTasks); err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
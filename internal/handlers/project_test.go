@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// MockProjectService is a mock implementation of ProjectService
+type MockProjectService struct {
+	mock.Mock
+}
+
+func (m *MockProjectService) CreateProject(ownerID uint, req *models.CreateProjectRequest) (*models.Project, error) {
+	args := m.Called(ownerID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+func (m *MockProjectService) ListProjects(ownerID uint, includeArchived bool) ([]models.Project, error) {
+	args := m.Called(ownerID, includeArchived)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Project), args.Error(1)
+}
+
+func (m *MockProjectService) GetProjectByID(ownerID, id uint) (*models.Project, error) {
+	args := m.Called(ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+func (m *MockProjectService) UpdateProject(ownerID, id uint, req *models.UpdateProjectRequest) (*models.Project, error) {
+	args := m.Called(ownerID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+func (m *MockProjectService) DeleteProject(ownerID, id uint, deleteTasks bool) error {
+	args := m.Called(ownerID, id, deleteTasks)
+	return args.Error(0)
+}
+
+func setupProjectRouter(handler *ProjectHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		middleware.SetUserID(c, testUserID)
+	})
+	projects := router.Group("/api/v1/projects")
+	projects.POST("", handler.CreateProject)
+	projects.GET("", handler.ListProjects)
+	projects.GET("/:id", handler.GetProject)
+	projects.PUT("/:id", handler.UpdateProject)
+	projects.DELETE("/:id", handler.DeleteProject)
+	return router
+}
+
+func TestCreateProject_Success(t *testing.T) {
+	mockService := new(MockProjectService)
+	router := setupProjectRouter(NewProjectHandler(mockService))
+
+	project := &models.Project{ID: 1, Name: "Work", Color: "#ff0000"}
+	mockService.On("CreateProject", testUserID, mock.AnythingOfType("*models.CreateProjectRequest")).Return(project, nil)
+
+	body, _ := json.Marshal(models.CreateProjectRequest{Name: "Work", Color: "#ff0000"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/projects", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateProject_InvalidColor(t *testing.T) {
+	mockService := new(MockProjectService)
+	router := setupProjectRouter(NewProjectHandler(mockService))
+
+	body, _ := json.Marshal(models.CreateProjectRequest{Name: "Work", Color: "red"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/projects", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assertValidationError(t, w, "color must be a hex color such as #ff0000")
+	mockService.AssertNotCalled(t, "CreateProject", mock.Anything, mock.Anything)
+}
+
+func TestProject_ValidationError(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		message string
+	}{
+		{"create without name", http.MethodPost, "/api/v1/projects", `{"color":"#ff0000"}`, "name is required"},
+		{"create at negative position", http.MethodPost, "/api/v1/projects", `{"name":"Work","position":-1}`, "position must be at least 0"},
+		{"update with long name", http.MethodPut, "/api/v1/projects/1", `{"name":"` + strings.Repeat("x", 101) + `"}`,
+			"name must be at most 100 characters long"},
+		{"update with wrong type", http.MethodPut, "/api/v1/projects/1", `{"archived":"yes"}`, "archived must be a boolean, not string"},
+		{"update with malformed JSON", http.MethodPut, "/api/v1/projects/1", `{"name":`, "the body is not valid JSON: unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProjectService)
+			router := setupProjectRouter(NewProjectHandler(mockService))
+
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assertValidationError(t, w, tt.message)
+			assert.Empty(t, mockService.Calls)
+		})
+	}
+}
+
+// assertValidationError checks that a response reports a validation error
+// with the given message
+func assertValidationError(t *testing.T, w *httptest.ResponseRecorder, message string) {
+	t.Helper()
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response models.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, apperrors.CodeValidationError, response.Error.Code)
+	assert.Equal(t, message, response.Error.Message)
+}
+
+func TestListProjects_IncludeArchived(t *testing.T) {
+	mockService := new(MockProjectService)
+	router := setupProjectRouter(NewProjectHandler(mockService))
+
+	mockService.On("ListProjects", testUserID, true).Return([]models.Project{{ID: 1, Name: "Work"}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/projects?include_archived=true", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ProjectListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteProject_TasksParameter(t *testing.T) {
+	tests := []struct {
+		query       string
+		status      int
+		deleteTasks bool
+	}{
+		{"", http.StatusNoContent, false},
+		{"?tasks=inbox", http.StatusNoContent, false},
+		{"?tasks=cascade", http.StatusNoContent, true},
+		{"?tasks=archive", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			mockService := new(MockProjectService)
+			router := setupProjectRouter(NewProjectHandler(mockService))
+			mockService.On("DeleteProject", testUserID, uint(1), tt.deleteTasks).Return(nil)
+
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/projects/1"+tt.query, nil)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusNoContent {
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "DeleteProject", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/realtime"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// RealtimeHandler upgrades requests to the WebSocket channel
+type RealtimeHandler struct {
+	hub      *realtime.Hub
+	upgrader websocket.Upgrader
+}
+
+// NewRealtimeHandler creates a new RealtimeHandler instance
+func NewRealtimeHandler(hub *realtime.Hub) *RealtimeHandler {
+	return &RealtimeHandler{
+		hub: hub,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{realtime.Protocol},
+			// Connections are authenticated with a bearer token rather
+			// than cookies, so pages from other origins cannot open one
+			// on the user's behalf
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+}
+
+// Connect handles GET /api/v1/ws. Browsers, which cannot set the
+// Authorization header, offer their token as a subprotocol along with
+// realtime.Protocol.
+func (h *RealtimeHandler) Connect(c *gin.Context) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError,
+			"this endpoint requires a WebSocket upgrade")
+		return
+	}
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already responded
+		return
+	}
+	h.hub.Serve(conn, realtime.Client{
+		Actor:     middleware.Actor(c),
+		Location:  loc,
+		CanWrite:  middleware.HasScope(c, auth.ScopeTasksWrite),
+		CanDelete: middleware.HasScope(c, auth.ScopeTasksDelete),
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/realtime"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func setupRealtimeServer(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		middleware.SetUserID(c, testUserID)
+	})
+	hub := realtime.NewHub(new(MockTaskService), nil, events.NewBus(10), &config.EventsConfig{HeartbeatInterval: time.Second})
+	router.GET("/api/v1/ws", NewRealtimeHandler(hub).Connect)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRealtimeConnect(t *testing.T) {
+	server := setupRealtimeServer(t)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/ws"
+
+	dialer := websocket.Dialer{Subprotocols: []string{realtime.Protocol}}
+	conn, resp, err := dialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, realtime.Protocol, resp.Header.Get("Sec-WebSocket-Protocol"))
+
+	var welcome realtime.Message
+	require.NoError(t, conn.ReadJSON(&welcome))
+	assert.Equal(t, realtime.TypeWelcome, welcome.Type)
+	assert.NotEmpty(t, welcome.ConnectionID)
+
+	require.NoError(t, conn.WriteJSON(realtime.Request{ID: "p", Type: realtime.TypePing}))
+	var reply realtime.Message
+	require.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, realtime.Message{Type: realtime.TypeReply, ReplyTo: "p"}, reply)
+}
+
+func TestRealtimeConnect_RequiresUpgrade(t *testing.T) {
+	server := setupRealtimeServer(t)
+
+	resp, err := http.Get(server.URL + "/api/v1/ws")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	body := new(strings.Builder)
+	_, _ = io.Copy(body, resp.Body)
+	assert.Contains(t, body.String(), apperrors.CodeValidationError)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestPreviewOccurrences(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	occurrences := []time.Time{
+		time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 22, 9, 0, 0, 0, time.UTC),
+	}
+	mockService.On("PreviewOccurrences", testUserID, uint(1), defaultOccurrenceCount, time.UTC).Return(occurrences, nil)
+	mockService.On("PreviewOccurrences", testUserID, uint(1), 2, time.UTC).Return(occurrences, nil)
+	mockService.On("PreviewOccurrences", testUserID, uint(2), defaultOccurrenceCount, time.UTC).
+		Return(nil, &apperrors.ValidationError{Message: "task 2 does not recur"})
+
+	for _, path := range []string{"/api/v1/tasks/1/occurrences", "/api/v1/tasks/1/occurrences?count=2"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		var response models.OccurrencesResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, 2, response.Count)
+		assert.Equal(t, occurrences, response.Occurrences)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/2/occurrences", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/tasks/1/occurrences?count=many", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNumberOfCalls(t, "PreviewOccurrences", 3)
+}
+
+func TestEndSeries(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	seriesID := uint(1)
+	mockService.On("EndSeries", testActor, uint(1)).Return(&models.Task{ID: 2, SeriesID: &seriesID, Occurrence: 2}, nil)
+	mockService.On("EndSeries", testActor, uint(3)).
+		Return(nil, &apperrors.ConflictError{Message: "the series of task 3 has already ended"})
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/tasks/1/recurrence", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TaskResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, uint(2), response.ID)
+	assert.Empty(t, response.Recurrence)
+	assert.Equal(t, &seriesID, response.SeriesID)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/tasks/3/recurrence", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestListTasks_SeriesFilter(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	seriesID := uint(4)
+	mockService.On("ListTasks", testUserID, &models.TaskFilter{SeriesID: &seriesID}, mock.AnythingOfType("*models.PageRequest")).
+		Return(&models.TaskPage{Tasks: []models.Task{}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks?series_id=4", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/tasks?series_id=0", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNumberOfCalls(t, "ListTasks", 1)
+}
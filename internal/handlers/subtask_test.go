@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestGetTask_IncludeSubtasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	parentID := uint(1)
+	task := &models.Task{ID: 1, Content: "Parent", SubtaskCount: 1, Subtasks: []models.Task{
+		{ID: 2, Content: "Step", ParentID: &parentID, Subtasks: []models.Task{}},
+	}}
+	mockService.On("GetTaskTree", testUserID, uint(1)).Return(task, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/1?include=subtasks", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TaskResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, int64(1), response.SubtaskCount)
+	assert.Len(t, response.Subtasks, 1)
+	assert.Equal(t, &parentID, response.Subtasks[0].ParentID)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTask_InvalidInclude(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/1?include=comments", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetTaskByID")
+}
+
+func TestListSubtasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	parentID := uint(1)
+	mockService.On("ListSubtasks", testUserID, uint(1)).Return([]models.Task{{ID: 2, ParentID: &parentID}}, nil)
+	mockService.On("ListSubtasks", testUserID, uint(9)).Return(nil, &apperrors.TaskNotFoundError{ID: 9})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/1/subtasks", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TaskListResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 1, response.Count)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/tasks/9/subtasks", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteTask_SubtasksPolicy(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("DeleteTask", testActor, uint(1), false, models.VersionCondition(nil)).
+		Return(&apperrors.ConflictError{Message: "task 1 has 2 subtasks; delete them first or cascade the deletion"})
+	mockService.On("DeleteTask", testActor, uint(1), true, models.VersionCondition(nil)).Return(nil)
+
+	for path, status := range map[string]int{
+		"/api/v1/tasks/1":                    http.StatusConflict,
+		"/api/v1/tasks/1?subtasks=refuse":    http.StatusConflict,
+		"/api/v1/tasks/1?subtasks=cascade":   http.StatusNoContent,
+		"/api/v1/tasks/1?subtasks=sometimes": http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest(http.MethodDelete, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+}
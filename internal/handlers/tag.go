@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/services"
+	"github.com/todo-api-go-sda/internal/validation"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// TagHandler handles HTTP requests for tags
+type TagHandler struct {
+	service services.TagService
+}
+
+// NewTagHandler creates a new TagHandler instance
+func NewTagHandler(service services.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// ListTags handles GET /api/v1/tags
+func (h *TagHandler) ListTags(c *gin.Context) {
+	tags, err := h.service.ListTags(middleware.UserID(c))
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToTagListResponse(tags))
+}
+
+// RenameTag handles PUT /api/v1/tags/:id
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid tag ID")
+		return
+	}
+
+	var req models.RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	tag, err := h.service.RenameTag(middleware.UserID(c), id, req.Name)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag.ToResponse())
+}
+
+// MergeTag handles POST /api/v1/tags/:id/merge. The tasks carrying the tag
+// are re-pointed to the target tag and the tag is deleted.
+func (h *TagHandler) MergeTag(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid tag ID")
+		return
+	}
+
+	var req models.MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	tag, err := h.service.MergeTag(middleware.UserID(c), id, req.TargetID)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag.ToResponse())
+}
+
+// DeleteTag handles DELETE /api/v1/tags/:id
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid tag ID")
+		return
+	}
+
+	if err := h.service.DeleteTag(middleware.UserID(c), id); err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// MockTagService is a mock implementation of TagService
+type MockTagService struct {
+	mock.Mock
+}
+
+func (m *MockTagService) ListTags(ownerID uint) ([]models.TagSummary, error) {
+	args := m.Called(ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TagSummary), args.Error(1)
+}
+
+func (m *MockTagService) RenameTag(ownerID, id uint, name string) (*models.Tag, error) {
+	args := m.Called(ownerID, id, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Tag), args.Error(1)
+}
+
+func (m *MockTagService) MergeTag(ownerID, sourceID, targetID uint) (*models.Tag, error) {
+	args := m.Called(ownerID, sourceID, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Tag), args.Error(1)
+}
+
+func (m *MockTagService) DeleteTag(ownerID, id uint) error {
+	args := m.Called(ownerID, id)
+	return args.Error(0)
+}
+
+func setupTagRouter(handler *TagHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		middleware.SetUserID(c, testUserID)
+	})
+	tags := router.Group("/api/v1/tags")
+	tags.GET("", handler.ListTags)
+	tags.PUT("/:id", handler.RenameTag)
+	tags.POST("/:id/merge", handler.MergeTag)
+	tags.DELETE("/:id", handler.DeleteTag)
+	return router
+}
+
+func TestListTags_Success(t *testing.T) {
+	mockService := new(MockTagService)
+	router := setupTagRouter(NewTagHandler(mockService))
+
+	mockService.On("ListTags", testUserID).Return([]models.TagSummary{
+		{Tag: models.Tag{ID: 1, Name: "backend"}, TaskCount: 3},
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tags", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TagListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "backend", response.Tags[0].Name)
+	assert.Equal(t, int64(3), response.Tags[0].TaskCount)
+}
+
+func TestRenameTag_Conflict(t *testing.T) {
+	mockService := new(MockTagService)
+	router := setupTagRouter(NewTagHandler(mockService))
+
+	mockService.On("RenameTag", testUserID, uint(1), "backend").
+		Return(nil, &apperrors.ConflictError{Message: `tag "backend" already exists`})
+
+	body, _ := json.Marshal(models.RenameTagRequest{Name: "backend"})
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/tags/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestMergeTag_Success(t *testing.T) {
+	mockService := new(MockTagService)
+	router := setupTagRouter(NewTagHandler(mockService))
+
+	mockService.On("MergeTag", testUserID, uint(2), uint(1)).Return(&models.Tag{ID: 1, Name: "bug"}, nil)
+
+	body, _ := json.Marshal(models.MergeTagRequest{TargetID: 1})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tags/2/merge", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TagResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "bug", response.Name)
+	mockService.AssertExpectations(t)
+}
+
+func TestMergeTag_MissingTarget(t *testing.T) {
+	mockService := new(MockTagService)
+	router := setupTagRouter(NewTagHandler(mockService))
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tags/2/merge", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assertValidationError(t, w, "target_id is required")
+	mockService.AssertNotCalled(t, "MergeTag", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTag_ValidationError(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		message string
+	}{
+		{"rename without name", http.MethodPut, "/api/v1/tags/1", `{}`, "name is required"},
+		{"rename with long name", http.MethodPut, "/api/v1/tags/1", `{"name":"` + strings.Repeat("x", 51) + `"}`,
+			"name must be at most 50 characters long"},
+		{"merge with wrong type", http.MethodPost, "/api/v1/tags/1/merge", `{"target_id":"2"}`, "target_id must be an integer, not string"},
+		{"merge with malformed JSON", http.MethodPost, "/api/v1/tags/1/merge", `{"target_id":`, "the body is not valid JSON: unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTagService)
+			router := setupTagRouter(NewTagHandler(mockService))
+
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assertValidationError(t, w, tt.message)
+			assert.Empty(t, mockService.Calls)
+		})
+	}
+}
+
+func TestDeleteTag_NotFound(t *testing.T) {
+	mockService := new(MockTagService)
+	router := setupTagRouter(NewTagHandler(mockService))
+
+	mockService.On("DeleteTag", testUserID, uint(9)).Return(&apperrors.TagNotFoundError{ID: 9})
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/tags/9", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response apperrors.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, apperrors.CodeTagNotFound, response.Error.Code)
+}
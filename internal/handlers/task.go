@@ -1,45 +1,160 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/middleware"
 	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/patch"
 	"github.com/todo-api-go-sda/internal/services"
+	"github.com/todo-api-go-sda/internal/validation"
 	apperrors "github.com/todo-api-go-sda/pkg/errors"
 )
 
+// defaultUpcomingDays is the window of the upcoming view when days is omitted
+const defaultUpcomingDays = 7
+
+// defaultOccurrenceCount is the number of occurrences previewed by default
+const defaultOccurrenceCount = 5
+
+// Media types of the patches accepted by PATCH /api/v1/tasks/:id
+const (
+	mergePatchMediaType = "application/merge-patch+json"
+	jsonPatchMediaType  = "application/json-patch+json"
+)
+
+// Values of the subtasks query parameter when deleting a task
+const (
+	taskSubtasksRefuse  = "refuse"
+	taskSubtasksCascade = "cascade"
+)
+
+// Values of the include query parameter when getting a task: subtasks
+// expands the task into its subtask tree and trashed also finds the task
+// when it is in the trash
+const (
+	includeSubtasks = "subtasks"
+	includeTrashed  = "trashed"
+)
+
 // TaskHandler handles HTTP requests for tasks
 type TaskHandler struct {
+	pager
 	service services.TaskService
 }
 
 // NewTaskHandler creates a new TaskHandler instance
-func NewTaskHandler(service services.TaskService) *TaskHandler {
-	return &TaskHandler{service: service}
+func NewTaskHandler(service services.TaskService, cfg *config.ServerConfig) *TaskHandler {
+	return &TaskHandler{
+		pager:   newPager(cfg),
+		service: service,
+	}
 }
 
 // CreateTask handles POST /api/v1/tasks
 func (h *TaskHandler) CreateTask(c *gin.Context) {
 	var req models.CreateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "content is required")
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
 		return
 	}
 
-	task, err := h.service.CreateTask(&req)
+	task, err := h.service.CreateTask(middleware.Actor(c), &req, loc)
 	if err != nil {
 		apperrors.HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, task.ToResponse())
+	respondWithTask(c, http.StatusCreated, task)
 }
 
 // ListTasks handles GET /api/v1/tasks
 func (h *TaskHandler) ListTasks(c *gin.Context) {
-	tasks, err := h.service.GetAllTasks()
+	filter, err := parseTaskFilter(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	h.listTasks(c, filter)
+}
+
+// ListProjectTasks handles GET /api/v1/projects/:id/tasks
+func (h *TaskHandler) ListProjectTasks(c *gin.Context) {
+	projectID, err := parseID(c)
+	if err != nil || projectID == 0 {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid project ID")
+		return
+	}
+
+	filter, err := parseTaskFilter(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+	filter.ProjectID = &projectID
+
+	h.listTasks(c, filter)
+}
+
+// listTasks responds with one page of the user's tasks matching the filter
+func (h *TaskHandler) listTasks(c *gin.Context, filter *models.TaskFilter) {
+	page, err := h.parsePageRequest(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	result, err := h.service.ListTasks(middleware.UserID(c), filter, page)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	response := models.ToListResponse(result.Tasks)
+	if result.NextCursor != nil {
+		response.NextCursor = h.cursors.Encode(result.NextCursor)
+	}
+	if result.PrevCursor != nil {
+		response.PrevCursor = h.cursors.Encode(result.PrevCursor)
+	}
+	response.Total = result.Total
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchTasks handles GET /api/v1/tasks/search
+func (h *TaskHandler) SearchTasks(c *gin.Context) {
+	results, err := h.service.SearchTasks(middleware.UserID(c), c.Query("q"))
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToSearchResponse(results))
+}
+
+// OverdueTasks handles GET /api/v1/tasks/overdue
+func (h *TaskHandler) OverdueTasks(c *gin.Context) {
+	filter, err := parseTaskFilter(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	tasks, err := h.service.ListOverdueTasks(middleware.UserID(c), filter)
 	if err != nil {
 		apperrors.HandleError(c, err)
 		return
@@ -48,7 +163,64 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, models.ToListResponse(tasks))
 }
 
-// GetTask handles GET /api/v1/tasks/:id
+// TodayTasks handles GET /api/v1/tasks/today
+func (h *TaskHandler) TodayTasks(c *gin.Context) {
+	filter, err := parseTaskFilter(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	tasks, err := h.service.ListTodayTasks(middleware.UserID(c), filter, loc)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToListResponse(tasks))
+}
+
+// UpcomingTasks handles GET /api/v1/tasks/upcoming
+func (h *TaskHandler) UpcomingTasks(c *gin.Context) {
+	filter, err := parseTaskFilter(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	days := defaultUpcomingDays
+	if value, ok := c.GetQuery("days"); ok {
+		days, err = strconv.Atoi(value)
+		if err != nil {
+			apperrors.HandleError(c, invalidParam("days", "must be an integer"))
+			return
+		}
+	}
+
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	tasks, err := h.service.ListUpcomingTasks(middleware.UserID(c), filter, days, loc)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToListResponse(tasks))
+}
+
+// GetTask handles GET /api/v1/tasks/:id. With include=subtasks the task's
+// subtasks are nested below it, recursively; with include=trashed tasks in
+// the trash are found too. A single task honors If-None-Match.
 func (h *TaskHandler) GetTask(c *gin.Context) {
 	id, err := parseID(c)
 	if err != nil {
@@ -56,16 +228,117 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.service.GetTaskByID(id)
+	tree, trashed := false, false
+	if value, ok := c.GetQuery("include"); ok {
+		for _, include := range strings.Split(value, ",") {
+			switch strings.TrimSpace(include) {
+			case includeSubtasks:
+				tree = true
+			case includeTrashed:
+				trashed = true
+			default:
+				apperrors.HandleError(c, invalidParam("include", "must be subtasks or trashed"))
+				return
+			}
+		}
+	}
+	if tree && trashed {
+		apperrors.HandleError(c, invalidParam("include", "subtasks and trashed cannot be combined"))
+		return
+	}
+
+	if tree {
+		// The tree changes with its subtasks, so it has no entity tag
+		task, err := h.service.GetTaskTree(middleware.UserID(c), id)
+		if err != nil {
+			apperrors.HandleError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, task.ToResponse())
+		return
+	}
+
+	task, err := h.service.GetTaskByID(middleware.UserID(c), id, trashed)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+	if notModified(c, task.Version) {
+		respondNotModified(c, task)
+		return
+	}
+
+	respondWithTask(c, http.StatusOK, task)
+}
+
+// ListSubtasks handles GET /api/v1/tasks/:id/subtasks
+func (h *TaskHandler) ListSubtasks(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid task ID")
+		return
+	}
+
+	tasks, err := h.service.ListSubtasks(middleware.UserID(c), id)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToListResponse(tasks))
+}
+
+// PreviewOccurrences handles GET /api/v1/tasks/:id/occurrences
+func (h *TaskHandler) PreviewOccurrences(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid task ID")
+		return
+	}
+
+	count := defaultOccurrenceCount
+	if value, ok := c.GetQuery("count"); ok {
+		count, err = strconv.Atoi(value)
+		if err != nil {
+			apperrors.HandleError(c, invalidParam("count", "must be an integer"))
+			return
+		}
+	}
+
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	occurrences, err := h.service.PreviewOccurrences(middleware.UserID(c), id, count, loc)
 	if err != nil {
 		apperrors.HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, task.ToResponse())
+	c.JSON(http.StatusOK, models.OccurrencesResponse{Occurrences: occurrences, Count: len(occurrences)})
+}
+
+// EndSeries handles DELETE /api/v1/tasks/:id/recurrence
+func (h *TaskHandler) EndSeries(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid task ID")
+		return
+	}
+
+	task, err := h.service.EndSeries(middleware.Actor(c), id)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	respondWithTask(c, http.StatusOK, task)
 }
 
-// UpdateTask handles PUT /api/v1/tasks/:id
+// UpdateTask handles PUT /api/v1/tasks/:id, replacing every writable field
+// of the task with the request body. If-Match is honored.
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	id, err := parseID(c)
 	if err != nil {
@@ -73,22 +346,103 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	var req models.UpdateTaskRequest
+	var req models.ReplaceTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, err.Error())
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
 		return
 	}
 
-	task, err := h.service.UpdateTask(id, &req)
+	versions, err := ifMatch(c)
 	if err != nil {
 		apperrors.HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, task.ToResponse())
+	update := req.UpdateRequest()
+	update.CompleteSubtasks = req.CompleteSubtasks
+	task, err := h.service.UpdateTask(middleware.Actor(c), id, update, loc, versions)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	respondWithTask(c, http.StatusOK, task)
 }
 
-// DeleteTask handles DELETE /api/v1/tasks/:id
+// PatchTask handles PATCH /api/v1/tasks/:id, applying a JSON Merge Patch or
+// a JSON Patch, selected by Content-Type, to the task's document. The
+// patched document is validated like a PUT body and only saved if the task
+// has not changed since it was read. If-Match is honored.
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid task ID")
+		return
+	}
+
+	apply := patch.Merge
+	switch c.ContentType() {
+	case mergePatchMediaType:
+	case jsonPatchMediaType:
+		apply = patch.Apply
+	default:
+		apperrors.RespondWithError(c, http.StatusUnsupportedMediaType, apperrors.CodeUnsupportedMediaType,
+			"Content-Type must be "+mergePatchMediaType+" or "+jsonPatchMediaType)
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid request body")
+		return
+	}
+
+	loc, err := middleware.Location(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	versions, err := ifMatch(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	task, err := h.service.GetTaskByID(middleware.UserID(c), id, false)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+	if !versions.Allows(task.Version) {
+		handleTaskError(c, &apperrors.VersionConflictError{ID: id, Current: task})
+		return
+	}
+
+	doc, err := patchDocument(task, body, apply)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	task, err = h.service.UpdateTask(middleware.Actor(c), id, doc.UpdateRequest(), loc, models.VersionCondition{task.Version})
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	respondWithTask(c, http.StatusOK, task)
+}
+
+// DeleteTask handles DELETE /api/v1/tasks/:id, moving the task to the trash.
+// The subtasks query parameter selects what happens when the task has
+// subtasks: "refuse" (the default) rejects the deletion, "cascade" deletes
+// the subtasks too. If-Match is honored.
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	id, err := parseID(c)
 	if err != nil {
@@ -96,14 +450,109 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteTask(id); err != nil {
+	cascade, err := parseCascade(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	versions, err := ifMatch(c)
+	if err != nil {
 		apperrors.HandleError(c, err)
 		return
 	}
 
+	if err := h.service.DeleteTask(middleware.Actor(c), id, cascade, versions); err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
 	c.Status(http.StatusNoContent)
 }
 
+// ListTrash handles GET /api/v1/trash
+func (h *TaskHandler) ListTrash(c *gin.Context) {
+	tasks, err := h.service.ListTrash(middleware.UserID(c))
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToListResponse(tasks))
+}
+
+// RestoreTask handles POST /api/v1/tasks/:id/restore
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid task ID")
+		return
+	}
+
+	task, err := h.service.RestoreTask(middleware.Actor(c), id)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	respondWithTask(c, http.StatusOK, task)
+}
+
+// DeleteTrashedTask handles DELETE /api/v1/trash/:id
+func (h *TaskHandler) DeleteTrashedTask(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid task ID")
+		return
+	}
+
+	if err := h.service.DeleteTaskPermanently(middleware.Actor(c), id); err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// patchDocument applies a patch to the document of a task and validates the
+// result like a PUT body. A failed JSON Patch test is a conflict.
+func patchDocument(task *models.Task, body []byte, apply func(doc, patch []byte) ([]byte, error)) (*models.TaskDocument, error) {
+	doc, err := json.Marshal(task.Document())
+	if err != nil {
+		return nil, err
+	}
+	patched, err := apply(doc, body)
+	if err != nil {
+		if patchErr, ok := err.(*patch.Error); ok && patchErr.TestFailed {
+			return nil, &apperrors.ConflictError{Message: patchErr.Message}
+		}
+		return nil, &apperrors.ValidationError{Message: "invalid patch: " + err.Error()}
+	}
+
+	var result models.TaskDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, &apperrors.ValidationError{Message: "invalid patched task: " + err.Error()}
+	}
+	if err := binding.Validator.ValidateStruct(&result); err != nil {
+		return nil, &apperrors.ValidationError{Message: err.Error()}
+	}
+	return &result, nil
+}
+
+// parseCascade parses the subtasks query parameter of a deletion, reporting
+// whether subtasks are deleted along with their parent
+func parseCascade(c *gin.Context) (bool, error) {
+	switch c.DefaultQuery("subtasks", taskSubtasksRefuse) {
+	case taskSubtasksRefuse:
+		return false, nil
+	case taskSubtasksCascade:
+		return true, nil
+	}
+	return false, invalidParam("subtasks", "must be refuse or cascade")
+}
+
 // parseID parses the ID from the URL parameter
 func parseID(c *gin.Context) (uint, error) {
 	idStr := c.Param("id")
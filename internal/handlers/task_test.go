@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/middleware"
 	"github.com/todo-api-go-sda/internal/models"
 	apperrors "github.com/todo-api-go-sda/pkg/errors"
 )
@@ -19,60 +23,244 @@ type MockTaskService struct {
 	mock.Mock
 }
 
-func (m *MockTaskService) CreateTask(req *models.CreateTaskRequest) (*models.Task, error) {
-	args := m.Called(req)
+func (m *MockTaskService) CreateTask(actor *models.Actor, req *models.CreateTaskRequest, loc *time.Location) (*models.Task, error) {
+	args := m.Called(actor, req, loc)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Task), args.Error(1)
 }
 
-func (m *MockTaskService) GetAllTasks() ([]models.Task, error) {
-	args := m.Called()
+func (m *MockTaskService) ListTasks(ownerID uint, filter *models.TaskFilter, page *models.PageRequest) (*models.TaskPage, error) {
+	args := m.Called(ownerID, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskPage), args.Error(1)
+}
+
+func (m *MockTaskService) ListOverdueTasks(ownerID uint, filter *models.TaskFilter) ([]models.Task, error) {
+	args := m.Called(ownerID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) ListTodayTasks(ownerID uint, filter *models.TaskFilter, loc *time.Location) ([]models.Task, error) {
+	args := m.Called(ownerID, filter, loc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) ListUpcomingTasks(ownerID uint, filter *models.TaskFilter, days int, loc *time.Location) ([]models.Task, error) {
+	args := m.Called(ownerID, filter, days, loc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetTaskByID(ownerID, id uint, includeTrashed bool) (*models.Task, error) {
+	args := m.Called(ownerID, id, includeTrashed)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetTasksByIDs(ownerID uint, ids []uint) ([]models.Task, error) {
+	args := m.Called(ownerID, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) UpdateTask(actor *models.Actor, id uint, req *models.UpdateTaskRequest, loc *time.Location, ifMatch models.VersionCondition) (*models.Task, error) {
+	args := m.Called(actor, id, req, loc, ifMatch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetTaskTree(ownerID, id uint) (*models.Task, error) {
+	args := m.Called(ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) ListSubtasks(ownerID, id uint) ([]models.Task, error) {
+	args := m.Called(ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) ListSubtasksOf(ownerID uint, ids []uint) ([]models.Task, error) {
+	args := m.Called(ownerID, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
-func (m *MockTaskService) GetTaskByID(id uint) (*models.Task, error) {
-	args := m.Called(id)
+func (m *MockTaskService) PreviewOccurrences(ownerID, id uint, count int, loc *time.Location) ([]time.Time, error) {
+	args := m.Called(ownerID, id, count, loc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (m *MockTaskService) EndSeries(actor *models.Actor, id uint) (*models.Task, error) {
+	args := m.Called(actor, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Task), args.Error(1)
 }
 
-func (m *MockTaskService) UpdateTask(id uint, req *models.UpdateTaskRequest) (*models.Task, error) {
-	args := m.Called(id, req)
+func (m *MockTaskService) ListTrash(ownerID uint) ([]models.Task, error) {
+	args := m.Called(ownerID)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) RestoreTask(actor *models.Actor, id uint) (*models.Task, error) {
+	args := m.Called(actor, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Task), args.Error(1)
 }
 
-func (m *MockTaskService) DeleteTask(id uint) error {
-	args := m.Called(id)
+func (m *MockTaskService) DeleteTaskPermanently(actor *models.Actor, id uint) error {
+	args := m.Called(actor, id)
 	return args.Error(0)
 }
 
+func (m *MockTaskService) DeleteTask(actor *models.Actor, id uint, cascade bool, ifMatch models.VersionCondition) error {
+	args := m.Called(actor, id, cascade, ifMatch)
+	return args.Error(0)
+}
+
+func (m *MockTaskService) BulkTasks(actor *models.Actor, ops []models.BulkTaskOperation, atomic bool, loc *time.Location) ([]models.BulkTaskOutcome, error) {
+	args := m.Called(actor, ops, atomic, loc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BulkTaskOutcome), args.Error(1)
+}
+
+func (m *MockTaskService) CompleteMatchingTasks(actor *models.Actor, filter *models.TaskFilter, loc *time.Location) (int, error) {
+	args := m.Called(actor, filter, loc)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskService) DeleteMatchingTasks(actor *models.Actor, filter *models.TaskFilter, cascade bool) (int, error) {
+	args := m.Called(actor, filter, cascade)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskService) SearchTasks(ownerID uint, query string) ([]models.TaskSearchResult, error) {
+	args := m.Called(ownerID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TaskSearchResult), args.Error(1)
+}
+
+func (m *MockTaskService) ListTaskHistory(ownerID, id uint, page *models.PageRequest) (*models.TaskEventPage, error) {
+	args := m.Called(ownerID, id, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskEventPage), args.Error(1)
+}
+
+func (m *MockTaskService) ListTaskEvents(ownerID uint, filter *models.TaskEventFilter, page *models.PageRequest) (*models.TaskEventPage, error) {
+	args := m.Called(ownerID, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskEventPage), args.Error(1)
+}
+
+func (m *MockTaskService) ListRecentTaskEvents(ownerID uint, ids []uint, limit int) ([]models.TaskEvent, error) {
+	args := m.Called(ownerID, ids, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TaskEvent), args.Error(1)
+}
+
+func (m *MockTaskService) RevertTask(actor *models.Actor, id, version uint, loc *time.Location, ifMatch models.VersionCondition) (*models.Task, error) {
+	args := m.Called(actor, id, version, loc, ifMatch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// testUserID is the authenticated user for every request in these tests
+const testUserID = uint(7)
+
+// testActor makes the writes of every request in these tests
+var testActor = &models.Actor{UserID: testUserID}
+
+var testServerConfig = &config.ServerConfig{DefaultPageSize: 20, MaxPageSize: 100, CursorSecret: "test-secret"}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func setupTestRouter(handler *TaskHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		middleware.SetUserID(c, testUserID)
+	})
 	v1 := router.Group("/api/v1")
 	tasks := v1.Group("/tasks")
 	tasks.POST("", handler.CreateTask)
+	tasks.POST("/bulk", handler.BulkTasks)
+	tasks.POST("/bulk/complete", handler.CompleteMatchingTasks)
+	tasks.POST("/bulk/delete", handler.DeleteMatchingTasks)
 	tasks.GET("", handler.ListTasks)
+	tasks.GET("/search", handler.SearchTasks)
+	tasks.GET("/overdue", handler.OverdueTasks)
+	tasks.GET("/today", handler.TodayTasks)
+	tasks.GET("/upcoming", handler.UpcomingTasks)
 	tasks.GET("/:id", handler.GetTask)
+	tasks.GET("/:id/subtasks", handler.ListSubtasks)
+	tasks.GET("/:id/occurrences", handler.PreviewOccurrences)
 	tasks.PUT("/:id", handler.UpdateTask)
+	tasks.PATCH("/:id", handler.PatchTask)
+	tasks.DELETE("/:id/recurrence", handler.EndSeries)
 	tasks.DELETE("/:id", handler.DeleteTask)
+	tasks.POST("/:id/restore", handler.RestoreTask)
+	tasks.GET("/:id/history", handler.TaskHistory)
+	tasks.POST("/:id/revert", handler.RevertTask)
+	v1.GET("/audit", handler.ListAudit)
+	v1.GET("/trash", handler.ListTrash)
+	v1.DELETE("/trash/:id", handler.DeleteTrashedTask)
+	v1.GET("/projects/:id/tasks", handler.ListProjectTasks)
 	return router
 }
 
 func TestCreateTask_Success(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewTaskHandler(mockService)
+	handler := NewTaskHandler(mockService, testServerConfig)
 	router := setupTestRouter(handler)
 
 	task := &models.Task{ID: 1, Content: "Test task", Completed: false}
-	mockService.On("CreateTask", mock.AnythingOfType("*models.CreateTaskRequest")).Return(task, nil)
+	mockService.On("CreateTask", testActor, mock.AnythingOfType("*models.CreateTaskRequest"), time.UTC).Return(task, nil)
 
 	body, _ := json.Marshal(models.CreateTaskRequest{Content: "Test task"})
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks", bytes.NewBuffer(body))
@@ -86,44 +274,203 @@ func TestCreateTask_Success(t *testing.T) {
 }
 
 func TestCreateTask_ValidationError(t *testing.T) {
+	tooManyTags, _ := json.Marshal(models.CreateTaskRequest{Content: "Task", Tags: strings.Fields(strings.Repeat("tag ", 21))})
+	longTag, _ := json.Marshal(models.CreateTaskRequest{Content: "Task", Tags: []string{strings.Repeat("x", 51)}})
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"missing content", `{}`, "content is required"},
+		{"too many tags", string(tooManyTags), "tags must have at most 20 items"},
+		{"tag too long", string(longTag), "tags[0] must be at most 50 characters long"},
+		{"unknown priority", `{"content":"Task","priority":"asap"}`, "priority must be one of none, low, medium, high, urgent"},
+		{"wrong type", `{"content":42}`, "content must be a string, not number"},
+		{"malformed JSON", `{"content":`, "the body is not valid JSON: unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			handler := NewTaskHandler(mockService, testServerConfig)
+			router := setupTestRouter(handler)
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response models.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, apperrors.CodeValidationError, response.Error.Code)
+			assert.Equal(t, tt.message, response.Error.Message)
+			mockService.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestListTasks_Success(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewTaskHandler(mockService)
+	handler := NewTaskHandler(mockService, testServerConfig)
 	router := setupTestRouter(handler)
 
-	body, _ := json.Marshal(map[string]interface{}{})
-	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
+	result := &models.TaskPage{Tasks: []models.Task{{ID: 1, Content: "Task 1"}}}
+	mockService.On("ListTasks", testUserID, &models.TaskFilter{}, &models.PageRequest{Limit: 20}).Return(result, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
 }
 
-func TestListTasks_Success(t *testing.T) {
+func TestListTasks_WithFilters(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewTaskHandler(mockService)
+	handler := NewTaskHandler(mockService, testServerConfig)
 	router := setupTestRouter(handler)
 
-	tasks := []models.Task{{ID: 1, Content: "Task 1"}}
-	mockService.On("GetAllTasks").Return(tasks, nil)
+	completed := false
+	createdAfter := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected := &models.TaskFilter{
+		Completed:       &completed,
+		CreatedAfter:    &createdAfter,
+		ContentContains: "milk",
+		Sort: []models.SortField{
+			{Field: "created_at"},
+			{Field: "updated_at", Desc: true},
+		},
+	}
+	mockService.On("ListTasks", testUserID, expected, mock.AnythingOfType("*models.PageRequest")).
+		Return(&models.TaskPage{Tasks: []models.Task{}}, nil)
 
-	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	req, _ := http.NewRequest(http.MethodGet,
+		"/api/v1/tasks?completed=false&created_after=2025-01-01&content_contains=milk&sort=created_at,-updated_at", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListTasks_TagFilter(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	expected := &models.TaskFilter{Tags: []string{"urgent", "backend"}, TagMode: models.TagModeAny}
+	mockService.On("ListTasks", testUserID, expected, mock.AnythingOfType("*models.PageRequest")).
+		Return(&models.TaskPage{Tasks: []models.Task{
+			{ID: 1, Content: "Fix login", Tags: []models.Tag{{ID: 2, Name: "backend"}, {ID: 1, Name: "urgent"}}},
+		}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks?tag=Urgent&tag=backend&tag=urgent&tag_mode=any", nil)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TaskListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"backend", "urgent"}, response.Tasks[0].Tags)
 	mockService.AssertExpectations(t)
 }
 
+func TestListTasks_Pagination(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	cursor := &models.Cursor{CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), ID: 7}
+	next := &models.Cursor{CreatedAt: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), ID: 5}
+	prev := &models.Cursor{CreatedAt: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), ID: 6, Backward: true}
+	total := int64(12)
+	result := &models.TaskPage{
+		Tasks:      []models.Task{{ID: 6, Content: "Task 6"}, {ID: 5, Content: "Task 5"}},
+		NextCursor: next,
+		PrevCursor: prev,
+		Total:      &total,
+	}
+	mockService.On("ListTasks", testUserID, &models.TaskFilter{}, mock.MatchedBy(func(page *models.PageRequest) bool {
+		return page.Limit == 2 && page.IncludeTotal && page.Cursor != nil && page.Cursor.ID == 7
+	})).Return(result, nil)
+
+	encoded := handler.cursors.Encode(cursor)
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks?limit=2&include_total=true&cursor="+encoded, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TaskListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, int64(12), *response.Total)
+
+	decodedNext, err := handler.cursors.Decode(response.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), decodedNext.ID)
+	decodedPrev, err := handler.cursors.Decode(response.PrevCursor)
+	assert.NoError(t, err)
+	assert.True(t, decodedPrev.Backward)
+	mockService.AssertExpectations(t)
+}
+
+func TestListTasks_InvalidParameters(t *testing.T) {
+	tests := []struct {
+		query string
+		param string
+	}{
+		{"project_id=work", "project_id"},
+		{"parent_id=-1", "parent_id"},
+		{"tag=", "tag"},
+		{"tag=urgent&tag_mode=none", "tag_mode"},
+		{"completed=maybe", "completed"},
+		{"created_after=yesterday", "created_after"},
+		{"updated_before=2025-13-01", "updated_before"},
+		{"content_contains=", "content_contains"},
+		{"sort=priority", "sort"},
+		{"sort=content,-content", "sort"},
+		{"limit=0", "limit"},
+		{"limit=101", "limit"},
+		{"cursor=forged", "cursor"},
+		{"include_total=yes", "include_total"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			handler := NewTaskHandler(mockService, testServerConfig)
+			router := setupTestRouter(handler)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks?"+tt.query, nil)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, apperrors.CodeValidationError, response.Error.Code)
+			assert.Contains(t, response.Error.Message, tt.param)
+			mockService.AssertNotCalled(t, "ListTasks", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestGetTask_Success(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewTaskHandler(mockService)
+	handler := NewTaskHandler(mockService, testServerConfig)
 	router := setupTestRouter(handler)
 
 	task := &models.Task{ID: 1, Content: "Test task"}
-	mockService.On("GetTaskByID", uint(1)).Return(task, nil)
+	mockService.On("GetTaskByID", testUserID, uint(1), false).Return(task, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/1", nil)
 
@@ -136,10 +483,10 @@ func TestGetTask_Success(t *testing.T) {
 
 func TestGetTask_NotFound(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewTaskHandler(mockService)
+	handler := NewTaskHandler(mockService, testServerConfig)
 	router := setupTestRouter(handler)
 
-	mockService.On("GetTaskByID", uint(999)).Return(nil, &apperrors.TaskNotFoundError{ID: 999})
+	mockService.On("GetTaskByID", testUserID, uint(999), false).Return(nil, &apperrors.TaskNotFoundError{ID: 999})
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/999", nil)
 
@@ -152,10 +499,10 @@ func TestGetTask_NotFound(t *testing.T) {
 
 func TestDeleteTask_Success(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewTaskHandler(mockService)
+	handler := NewTaskHandler(mockService, testServerConfig)
 	router := setupTestRouter(handler)
 
-	mockService.On("DeleteTask", uint(1)).Return(nil)
+	mockService.On("DeleteTask", testActor, uint(1), false, models.VersionCondition(nil)).Return(nil)
 
 	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/tasks/1", nil)
 
@@ -165,3 +512,138 @@ func TestDeleteTask_Success(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestSearchTasks_Success(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	results := []models.TaskSearchResult{
+		{Task: models.Task{ID: 1, Content: "Buy milk"}, Rank: 1, Snippet: "Buy <mark>milk</mark>"},
+	}
+	mockService.On("SearchTasks", testUserID, "milk").Return(results, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/search?q=milk", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TaskListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "Buy <mark>milk</mark>", response.Tasks[0].Snippet)
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchTasks_ValidationError(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("SearchTasks", testUserID, "").Return(nil, &apperrors.ValidationError{Message: "q is required"})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/search", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListProjectTasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	completed := false
+	projectID := uint(4)
+	expected := &models.TaskFilter{ProjectID: &projectID, Completed: &completed}
+	mockService.On("ListTasks", testUserID, expected, mock.AnythingOfType("*models.PageRequest")).
+		Return(&models.TaskPage{Tasks: []models.Task{{ID: 1, ProjectID: &projectID, Content: "Report"}}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/projects/4/tasks?completed=false", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"project_id":4`)
+	mockService.AssertExpectations(t)
+}
+
+func TestListProjectTasks_ProjectNotFound(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("ListTasks", testUserID, mock.Anything, mock.Anything).
+		Return(nil, &apperrors.ProjectNotFoundError{ID: 9})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/projects/9/tasks", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "PROJECT_NOT_FOUND")
+}
+
+func TestDueViews(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+	tasks := []models.Task{{ID: 1, Content: "File taxes", Priority: models.PriorityHigh}}
+	mockService.On("ListOverdueTasks", testUserID, &models.TaskFilter{}).Return(tasks, nil)
+	mockService.On("ListTodayTasks", testUserID, &models.TaskFilter{}, tokyo).Return(tasks, nil)
+	mockService.On("ListUpcomingTasks", testUserID, &models.TaskFilter{}, 7, time.UTC).Return(tasks, nil)
+	mockService.On("ListUpcomingTasks", testUserID, &models.TaskFilter{}, 30, time.UTC).Return(tasks, nil)
+
+	for _, tt := range []struct{ path, timeZone string }{
+		{"/api/v1/tasks/overdue", ""},
+		{"/api/v1/tasks/today", "Asia/Tokyo"},
+		{"/api/v1/tasks/upcoming", ""},
+		{"/api/v1/tasks/upcoming?days=30", ""},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.timeZone != "" {
+			req.Header.Set(middleware.TimeZoneHeader, tt.timeZone)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.path)
+		var response models.TaskListResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Len(t, response.Tasks, 1)
+		assert.Equal(t, "high", response.Tasks[0].Priority)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestDueViews_InvalidParameters(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	for _, tt := range []struct{ path, timeZone string }{
+		{"/api/v1/tasks/upcoming?days=soon", ""},
+		{"/api/v1/tasks/today", "Nowhere/Special"},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.timeZone != "" {
+			req.Header.Set(middleware.TimeZoneHeader, tt.timeZone)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, tt.path)
+	}
+	mockService.AssertNotCalled(t, "ListTodayTasks")
+	mockService.AssertNotCalled(t, "ListUpcomingTasks")
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func TestListTrash(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	deletedAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	mockService.On("ListTrash", testUserID).Return([]models.Task{
+		{ID: 1, Content: "Deleted task", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/trash", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TaskListResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, &deletedAt, response.Tasks[0].DeletedAt)
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreTask(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("RestoreTask", testActor, uint(1)).Return(&models.Task{ID: 1, Content: "Restored task"}, nil)
+	mockService.On("RestoreTask", testActor, uint(2)).
+		Return(nil, &apperrors.ConflictError{Message: "task 2 is a subtask of task 1, which is in the trash; restore that task first"})
+	mockService.On("RestoreTask", testActor, uint(3)).Return(nil, &apperrors.TaskNotFoundError{ID: 3})
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/1/restore", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TaskResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, uint(1), response.ID)
+	assert.Nil(t, response.DeletedAt)
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/tasks/2/restore", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/tasks/3/restore", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteTrashedTask(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	mockService.On("DeleteTaskPermanently", testActor, uint(1)).Return(nil)
+	mockService.On("DeleteTaskPermanently", testActor, uint(2)).Return(&apperrors.TaskNotFoundError{ID: 2})
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/trash/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/trash/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTask_IncludeTrashed(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, testServerConfig)
+	router := setupTestRouter(handler)
+
+	deletedAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	mockService.On("GetTaskByID", testUserID, uint(1), true).
+		Return(&models.Task{ID: 1, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/1?include=trashed", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TaskResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, &deletedAt, response.DeletedAt)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/tasks/1?include=subtasks,trashed", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNumberOfCalls(t, "GetTaskByID", 1)
+	mockService.AssertNotCalled(t, "GetTaskTree")
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/services"
+	"github.com/todo-api-go-sda/internal/validation"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// WebhookHandler handles HTTP requests for webhooks and their deliveries
+type WebhookHandler struct {
+	pager
+	service services.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler instance
+func NewWebhookHandler(service services.WebhookService, cfg *config.ServerConfig) *WebhookHandler {
+	return &WebhookHandler{
+		pager:   newPager(cfg),
+		service: service,
+	}
+}
+
+// CreateWebhook handles POST /api/v1/webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(middleware.UserID(c), &req)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreateWebhookResponse{
+		WebhookResponse: webhook.ToResponse(),
+		Secret:          webhook.Secret,
+	})
+}
+
+// ListWebhooks handles GET /api/v1/webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.service.ListWebhooks(middleware.UserID(c))
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToWebhookListResponse(webhooks))
+}
+
+// GetWebhook handles GET /api/v1/webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid webhook ID")
+		return
+	}
+
+	webhook, err := h.service.GetWebhook(middleware.UserID(c), id)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook.ToResponse())
+}
+
+// UpdateWebhook handles PATCH /api/v1/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid webhook ID")
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperrors.HandleError(c, validation.Error(&req, err))
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(middleware.UserID(c), id, &req)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook.ToResponse())
+}
+
+// DeleteWebhook handles DELETE /api/v1/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.service.DeleteWebhook(middleware.UserID(c), id); err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /api/v1/webhooks/:id/deliveries, the delivery
+// log of a webhook, newest first
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid webhook ID")
+		return
+	}
+
+	filter := &models.WebhookDeliveryFilter{WebhookID: &id}
+	if value, ok := c.GetQuery("status"); ok {
+		switch value {
+		case models.DeliveryPending, models.DeliverySucceeded, models.DeliveryDead:
+			filter.Status = value
+		default:
+			apperrors.HandleError(c, invalidParam("status", "must be pending, succeeded or dead"))
+			return
+		}
+	}
+
+	h.listDeliveries(c, filter)
+}
+
+// ListDeadLetters handles GET /api/v1/webhooks/dead-letters, the deliveries
+// of all of the user's webhooks that ran out of attempts, newest first
+func (h *WebhookHandler) ListDeadLetters(c *gin.Context) {
+	h.listDeliveries(c, &models.WebhookDeliveryFilter{Status: models.DeliveryDead})
+}
+
+// Redeliver handles POST /api/v1/webhooks/:id/deliveries/:delivery_id/redeliver
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid webhook ID")
+		return
+	}
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 32)
+	if err != nil {
+		apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid delivery ID")
+		return
+	}
+
+	delivery, err := h.service.Redeliver(middleware.UserID(c), id, uint(deliveryID))
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery.ToResponse())
+}
+
+// listDeliveries responds with one page of the user's deliveries matching
+// the filter
+func (h *WebhookHandler) listDeliveries(c *gin.Context, filter *models.WebhookDeliveryFilter) {
+	page, err := h.parsePageRequest(c)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	result, err := h.service.ListDeliveries(middleware.UserID(c), filter, page)
+	if err != nil {
+		apperrors.HandleError(c, err)
+		return
+	}
+
+	response := models.ToDeliveryListResponse(result.Deliveries)
+	if result.NextCursor != nil {
+		response.NextCursor = h.cursors.Encode(result.NextCursor)
+	}
+	c.JSON(http.StatusOK, response)
+}
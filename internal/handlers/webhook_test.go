@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// MockWebhookService is a mock implementation of WebhookService
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateWebhook(ownerID uint, req *models.CreateWebhookRequest) (*models.Webhook, error) {
+	args := m.Called(ownerID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) ListWebhooks(ownerID uint) ([]models.Webhook, error) {
+	args := m.Called(ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhook(ownerID, id uint) (*models.Webhook, error) {
+	args := m.Called(ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) UpdateWebhook(ownerID, id uint, req *models.UpdateWebhookRequest) (*models.Webhook, error) {
+	args := m.Called(ownerID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(ownerID, id uint) error {
+	args := m.Called(ownerID, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ownerID uint, filter *models.WebhookDeliveryFilter, page *models.PageRequest) (*models.WebhookDeliveryPage, error) {
+	args := m.Called(ownerID, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDeliveryPage), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(ownerID, webhookID, deliveryID uint) (*models.WebhookDelivery, error) {
+	args := m.Called(ownerID, webhookID, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func setupWebhookRouter(handler *WebhookHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		middleware.SetUserID(c, testUserID)
+	})
+	webhooks := router.Group("/api/v1/webhooks")
+	webhooks.POST("", handler.CreateWebhook)
+	webhooks.GET("", handler.ListWebhooks)
+	webhooks.GET("/dead-letters", handler.ListDeadLetters)
+	webhooks.GET("/:id", handler.GetWebhook)
+	webhooks.PATCH("/:id", handler.UpdateWebhook)
+	webhooks.DELETE("/:id", handler.DeleteWebhook)
+	webhooks.GET("/:id/deliveries", handler.ListDeliveries)
+	webhooks.POST("/:id/deliveries/:delivery_id/redeliver", handler.Redeliver)
+	return router
+}
+
+func TestCreateWebhook_ShowsSecretOnce(t *testing.T) {
+	mockService := new(MockWebhookService)
+	router := setupWebhookRouter(NewWebhookHandler(mockService, testServerConfig))
+	webhook := &models.Webhook{ID: 1, URL: "https://example.com/hook", Secret: "whsec_abc", Events: "task.created", Active: true}
+	mockService.On("CreateWebhook", testUserID, &models.CreateWebhookRequest{URL: "https://example.com/hook", Events: []string{"task.created"}}).Return(webhook, nil)
+	mockService.On("GetWebhook", testUserID, uint(1)).Return(webhook, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"url": "https://example.com/hook", "events": []string{"task.created"}})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.CreateWebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "whsec_abc", created.Secret)
+	assert.Equal(t, []string{"task.created"}, created.Events)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/webhooks/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "whsec_abc")
+}
+
+func TestCreateWebhook_MissingURL(t *testing.T) {
+	mockService := new(MockWebhookService)
+	router := setupWebhookRouter(NewWebhookHandler(mockService, testServerConfig))
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewBufferString(`{"events":["task.created"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assertValidationError(t, w, "url is required")
+	mockService.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything)
+}
+
+func TestWebhook_ValidationError(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		message string
+	}{
+		{"create with long secret", http.MethodPost, "/api/v1/webhooks", `{"url":"https://example.com","secret":"` + strings.Repeat("x", 256) + `"}`,
+			"secret must be at most 255 characters long"},
+		{"create with bad event", http.MethodPost, "/api/v1/webhooks", `{"url":"https://example.com","events":["task.created",1]}`,
+			"events[1] must be a string, not number"},
+		{"create with malformed JSON", http.MethodPost, "/api/v1/webhooks", `{"url":`, "the body is not valid JSON: unexpected EOF"},
+		{"update with long url", http.MethodPatch, "/api/v1/webhooks/1", `{"url":"https://example.com/` + strings.Repeat("x", 2048) + `"}`,
+			"url must be at most 2048 characters long"},
+		{"update with wrong type", http.MethodPatch, "/api/v1/webhooks/1", `{"active":"no"}`, "active must be a boolean, not string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			router := setupWebhookRouter(NewWebhookHandler(mockService, testServerConfig))
+
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assertValidationError(t, w, tt.message)
+			assert.Empty(t, mockService.Calls)
+		})
+	}
+}
+
+func TestGetWebhook_NotFound(t *testing.T) {
+	mockService := new(MockWebhookService)
+	router := setupWebhookRouter(NewWebhookHandler(mockService, testServerConfig))
+	mockService.On("GetWebhook", testUserID, uint(9)).Return(nil, &apperrors.WebhookNotFoundError{ID: 9})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/webhooks/9", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), apperrors.CodeWebhookNotFound)
+}
+
+func TestListDeliveries(t *testing.T) {
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService, testServerConfig)
+	router := setupWebhookRouter(handler)
+	status := http.StatusInternalServerError
+	result := &models.WebhookDeliveryPage{
+		Deliveries: []models.WebhookDelivery{{
+			ID: 4, WebhookID: 2, EventID: 10, Type: models.WebhookEventTaskCreated, Status: models.DeliveryPending,
+			Attempts: 1, ResponseStatus: &status, LastError: "webhook responded with status 500", Payload: `{"id":10}`,
+		}},
+		NextCursor: &models.Cursor{ID: 4},
+	}
+	filter := &models.WebhookDeliveryFilter{WebhookID: ptr(uint(2)), Status: models.DeliveryPending}
+	mockService.On("ListDeliveries", testUserID, filter, &models.PageRequest{Limit: 1}).Return(result, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/webhooks/2/deliveries?status=pending&limit=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.WebhookDeliveryListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Deliveries, 1)
+	assert.JSONEq(t, `{"id":10}`, string(response.Deliveries[0].Payload))
+	assert.Equal(t, &status, response.Deliveries[0].ResponseStatus)
+	cursor, err := handler.cursors.Decode(response.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), cursor.ID)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/webhooks/2/deliveries?status=failed", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestListDeadLetters(t *testing.T) {
+	mockService := new(MockWebhookService)
+	router := setupWebhookRouter(NewWebhookHandler(mockService, testServerConfig))
+	filter := &models.WebhookDeliveryFilter{Status: models.DeliveryDead}
+	mockService.On("ListDeliveries", testUserID, filter, &models.PageRequest{Limit: 20}).
+		Return(&models.WebhookDeliveryPage{Deliveries: []models.WebhookDelivery{}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/webhooks/dead-letters", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"deliveries":[],"count":0}`, w.Body.String())
+}
+
+func TestRedeliver(t *testing.T) {
+	mockService := new(MockWebhookService)
+	router := setupWebhookRouter(NewWebhookHandler(mockService, testServerConfig))
+	delivery := &models.WebhookDelivery{ID: 4, WebhookID: 2, Status: models.DeliveryPending, Payload: `{}`}
+	mockService.On("Redeliver", testUserID, uint(2), uint(4)).Return(delivery, nil)
+	mockService.On("Redeliver", testUserID, uint(2), uint(5)).Return(nil, &apperrors.DeliveryNotFoundError{ID: 5})
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/webhooks/2/deliveries/4/redeliver", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/webhooks/2/deliveries/5/redeliver", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), apperrors.CodeDeliveryNotFound)
+}
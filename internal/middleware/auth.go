@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// Gin context keys holding the authenticated principal
+const (
+	userIDKey  = "auth.userID"
+	scopesKey  = "auth.scopes"
+	tokenIDKey = "auth.tokenID"
+)
+
+// WebSocketTokenPrefix marks the Sec-WebSocket-Protocol entry that carries
+// the bearer token of a WebSocket handshake, since browsers cannot send an
+// Authorization header with one
+const WebSocketTokenPrefix = "bearer."
+
+// APITokenAuthenticator resolves personal access tokens
+type APITokenAuthenticator interface {
+	Authenticate(token string) (*models.APIToken, error)
+}
+
+// RequireAuth rejects requests without a valid bearer credential and stores
+// the authenticated user ID in the context. The credential is either a JWT
+// access token, which grants every scope, or a personal access token, which
+// grants only its own scopes.
+func RequireAuth(tokens *auth.TokenManager, apiTokens APITokenAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c)
+		if !ok {
+			abortUnauthorized(c, "missing bearer token")
+			return
+		}
+
+		if auth.IsAPIToken(token) {
+			apiToken, err := apiTokens.Authenticate(token)
+			if err != nil {
+				if _, ok := err.(*apperrors.UnauthorizedError); ok {
+					abortUnauthorized(c, err.Error())
+				} else {
+					apperrors.HandleError(c, err)
+					c.Abort()
+				}
+				return
+			}
+			SetUserID(c, apiToken.UserID)
+			c.Set(scopesKey, apiToken.ScopeList())
+			c.Set(tokenIDKey, apiToken.ID)
+			c.Next()
+			return
+		}
+
+		userID, err := tokens.Verify(token, auth.TokenTypeAccess)
+		if err != nil {
+			abortUnauthorized(c, "invalid or expired token")
+			return
+		}
+
+		SetUserID(c, userID)
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose credential does not grant the scope
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasScope(c, scope) {
+			apperrors.HandleError(c, &apperrors.ForbiddenError{Message: "token is missing the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession rejects requests authenticated with a personal access token,
+// for operations reserved to interactively signed-in users
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(scopesKey); ok {
+			apperrors.HandleError(c, &apperrors.ForbiddenError{Message: "personal access tokens cannot be used for this operation"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// SetUserID stores the authenticated user ID in the context
+func SetUserID(c *gin.Context, userID uint) {
+	c.Set(userIDKey, userID)
+}
+
+// UserID returns the authenticated user ID, or 0 for anonymous requests
+func UserID(c *gin.Context) uint {
+	return c.GetUint(userIDKey)
+}
+
+// Actor returns who makes the request: the authenticated user, the personal
+// access token they authenticated with, if any, and the request ID
+func Actor(c *gin.Context) *models.Actor {
+	actor := &models.Actor{UserID: UserID(c), RequestID: RequestID(c)}
+	if tokenID, ok := c.Get(tokenIDKey); ok {
+		id := tokenID.(uint)
+		actor.TokenID = &id
+	}
+	return actor
+}
+
+// HasScope reports whether the request's credential grants the scope.
+// Session (JWT) credentials grant every scope.
+func HasScope(c *gin.Context, scope string) bool {
+	scopes, ok := c.Get(scopesKey)
+	if !ok {
+		return true
+	}
+	for _, granted := range scopes.([]string) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header,
+// or from the Sec-WebSocket-Protocol header of a WebSocket handshake
+// without one
+func bearerToken(c *gin.Context) (string, bool) {
+	authorization := c.GetHeader("Authorization")
+	if authorization == "" && strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		return webSocketToken(c)
+	}
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// webSocketToken extracts the token from the subprotocols a WebSocket
+// client offers, where it is prefixed with WebSocketTokenPrefix
+func webSocketToken(c *gin.Context) (string, bool) {
+	for _, header := range c.Request.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if token, ok := strings.CutPrefix(strings.TrimSpace(protocol), WebSocketTokenPrefix); ok && token != "" {
+				return token, true
+			}
+		}
+	}
+	return "", false
+}
+
+// abortUnauthorized stops the request with a 401 UNAUTHORIZED response
+func abortUnauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="todo-api"`)
+	apperrors.HandleError(c, &apperrors.UnauthorizedError{Message: message})
+	c.Abort()
+}
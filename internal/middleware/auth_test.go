@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	"github.com/todo-api-go-sda/internal/services"
+)
+
+func setupAuthRouter(t *testing.T) (*gin.Engine, *auth.TokenManager, services.APITokenService) {
+	tokens, err := auth.NewTokenManager(&config.AuthConfig{
+		JWTAlgorithm:    "HS256",
+		JWTSecret:       "test-secret",
+		JWTIssuer:       "todo-api",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+	require.NoError(t, err)
+	apiTokens := services.NewAPITokenService(repository.NewMemoryAPITokenRepository())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	me := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": UserID(c)})
+	}
+	requireAuth := RequireAuth(tokens, apiTokens)
+	router.GET("/me", requireAuth, me)
+	router.DELETE("/me", requireAuth, RequireScope(auth.ScopeTasksDelete), me)
+	router.POST("/session", requireAuth, RequireSession(), me)
+	router.GET("/actor", AssignRequestID(), requireAuth, func(c *gin.Context) {
+		c.JSON(http.StatusOK, Actor(c))
+	})
+	return router, tokens, apiTokens
+}
+
+func mintAPIToken(t *testing.T, apiTokens services.APITokenService, scopes ...string) string {
+	_, plain, err := apiTokens.CreateToken(5, &models.CreateAPITokenRequest{Name: "script", Scopes: scopes})
+	require.NoError(t, err)
+	return plain
+}
+
+func serve(router *gin.Engine, method, path, authorization string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireAuth_ValidToken(t *testing.T) {
+	router, tokens, _ := setupAuthRouter(t)
+	pair, err := tokens.Issue(5)
+	require.NoError(t, err)
+
+	w := serve(router, http.MethodGet, "/me", "Bearer "+pair.AccessToken)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":5}`, w.Body.String())
+}
+
+func TestRequireAuth_Rejected(t *testing.T) {
+	router, tokens, _ := setupAuthRouter(t)
+	pair, err := tokens.Issue(5)
+	require.NoError(t, err)
+
+	for name, header := range map[string]string{
+		"missing":         "",
+		"wrong scheme":    "Basic " + pair.AccessToken,
+		"refresh token":   "Bearer " + pair.RefreshToken,
+		"garbage":         "Bearer not-a-token",
+		"unknown api key": "Bearer " + auth.APITokenPrefix + "unknown",
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/me", header)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), "UNAUTHORIZED")
+			assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestRequireAuth_APIToken(t *testing.T) {
+	router, _, apiTokens := setupAuthRouter(t)
+	token := mintAPIToken(t, apiTokens, auth.ScopeTasksRead)
+
+	w := serve(router, http.MethodGet, "/me", "Bearer "+token)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":5}`, w.Body.String())
+}
+
+func TestRequireAuth_WebSocketProtocol(t *testing.T) {
+	router, tokens, _ := setupAuthRouter(t)
+	pair, err := tokens.Issue(5)
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Protocol", "todo.v1, "+WebSocketTokenPrefix+pair.AccessToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":5}`, w.Body.String())
+
+	// Only WebSocket handshakes may carry the token there
+	req.Header.Del("Upgrade")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestActor(t *testing.T) {
+	router, tokens, apiTokens := setupAuthRouter(t)
+	pair, err := tokens.Issue(5)
+	require.NoError(t, err)
+	created, plain, err := apiTokens.CreateToken(5, &models.CreateAPITokenRequest{Name: "script", Scopes: []string{auth.ScopeTasksRead}})
+	require.NoError(t, err)
+
+	w := serve(router, http.MethodGet, "/actor", "Bearer "+pair.AccessToken)
+	var actor models.Actor
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actor))
+	assert.Equal(t, uint(5), actor.UserID)
+	assert.Nil(t, actor.TokenID)
+	assert.Equal(t, w.Header().Get(RequestIDHeader), actor.RequestID)
+
+	w = serve(router, http.MethodGet, "/actor", "Bearer "+plain)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actor))
+	assert.Equal(t, uint(5), actor.UserID)
+	assert.Equal(t, &created.ID, actor.TokenID)
+}
+
+func TestRequireScope(t *testing.T) {
+	router, tokens, apiTokens := setupAuthRouter(t)
+	pair, err := tokens.Issue(5)
+	require.NoError(t, err)
+
+	w := serve(router, http.MethodDelete, "/me", "Bearer "+mintAPIToken(t, apiTokens, auth.ScopeTasksRead))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "FORBIDDEN")
+
+	w = serve(router, http.MethodDelete, "/me", "Bearer "+mintAPIToken(t, apiTokens, auth.ScopeTasksDelete))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(router, http.MethodDelete, "/me", "Bearer "+pair.AccessToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireSession(t *testing.T) {
+	router, tokens, apiTokens := setupAuthRouter(t)
+	pair, err := tokens.Issue(5)
+	require.NoError(t, err)
+
+	w := serve(router, http.MethodPost, "/session", "Bearer "+mintAPIToken(t, apiTokens, auth.Scopes...))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serve(router, http.MethodPost, "/session", "Bearer "+pair.AccessToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// IdempotencyKeyHeader carries a client-chosen key that makes retries of a
+// POST request safe: the request runs once and retries get its response
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response replayed to a retry
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength is the length of the longest key that is stored
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyStore claims Idempotency-Keys and keeps their responses
+type IdempotencyStore interface {
+	Begin(userID uint, key, fingerprint string) (*models.StoredResponse, error)
+	Finish(userID uint, key, fingerprint string, response *models.StoredResponse) error
+	Abandon(userID uint, key string) error
+}
+
+// Idempotency honors the Idempotency-Key header on POST requests of the
+// authenticated user. The first request with a key runs and its response is
+// stored; retries with the same key and request get that response replayed,
+// while a key reused for a different request, or still in flight, is a
+// conflict. Server errors and requests that a later middleware rejected,
+// such as for a missing scope, are not stored, so that they can be retried.
+func Idempotency(store IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			apperrors.HandleError(c, &apperrors.ValidationError{Message: "Idempotency-Key must be at most 255 characters"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apperrors.RespondWithError(c, http.StatusBadRequest, apperrors.CodeValidationError, "Invalid request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID := UserID(c)
+		fingerprint := requestFingerprint(c.Request, body)
+		stored, err := store.Begin(userID, key, fingerprint)
+		if err != nil {
+			apperrors.HandleError(c, err)
+			c.Abort()
+			return
+		}
+		if stored != nil {
+			replay(c, stored)
+			return
+		}
+
+		// Release the key if the handler panics
+		finished := false
+		defer func() {
+			if !finished {
+				if err := store.Abandon(userID, key); err != nil {
+					log.Printf("Failed to release Idempotency-Key: %v", err)
+				}
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		finished = true
+
+		if recorder.Status() >= http.StatusInternalServerError || c.IsAborted() {
+			err = store.Abandon(userID, key)
+		} else {
+			err = store.Finish(userID, key, fingerprint, &models.StoredResponse{
+				Status: recorder.Status(),
+				Header: recorder.Header().Clone(),
+				Body:   recorder.body.Bytes(),
+			})
+		}
+		if err != nil {
+			log.Printf("Failed to store the response for Idempotency-Key: %v", err)
+		}
+	}
+}
+
+// requestFingerprint identifies a request by its method, URL and body, so
+// that a key reused for a different request is detected
+func requestFingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, req.Method+" "+req.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replay writes a stored response and stops the chain
+func replay(c *gin.Context, stored *models.StoredResponse) {
+	for name, values := range stored.Header {
+		c.Writer.Header()[name] = values
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(stored.Status)
+	c.Writer.Write(stored.Body)
+	c.Abort()
+}
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/repository"
+	"github.com/todo-api-go-sda/internal/services"
+)
+
+// setupIdempotencyRouter serves POST /tasks, counting the tasks it creates.
+// Content "fail" makes it fail with a server error and content "wait" makes
+// it signal started and block until release is closed.
+func setupIdempotencyRouter(created *atomic.Int32, started, release chan struct{}) *gin.Engine {
+	store := services.NewIdempotencyService(repository.NewMemoryIdempotencyRepository(), &config.IdempotencyConfig{
+		KeyTTL:      time.Hour,
+		LockTimeout: time.Minute,
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authenticate := func(c *gin.Context) {
+		SetUserID(c, 5)
+		c.Next()
+	}
+	router.POST("/tasks", authenticate, Idempotency(store), func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		switch string(body) {
+		case "fail":
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+			return
+		case "wait":
+			started <- struct{}{}
+			<-release
+		}
+		id := created.Add(1)
+		c.Header("Location", fmt.Sprintf("/tasks/%d", id))
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	})
+	router.GET("/tasks", authenticate, Idempotency(store), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"count": created.Load()})
+	})
+	return router
+}
+
+func post(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysRetries(t *testing.T) {
+	var created atomic.Int32
+	router := setupIdempotencyRouter(&created, nil, nil)
+
+	first := post(router, "retry-1", "milk")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+	retry := post(router, "retry-1", "milk")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, first.Header().Get("Location"), retry.Header().Get("Location"))
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, int32(1), created.Load())
+
+	// Other keys, and requests without a key, run as usual
+	assert.Equal(t, http.StatusCreated, post(router, "retry-2", "milk").Code)
+	assert.Equal(t, http.StatusCreated, post(router, "", "milk").Code)
+	assert.Equal(t, http.StatusCreated, post(router, "", "milk").Code)
+	assert.Equal(t, int32(4), created.Load())
+}
+
+func TestIdempotency_KeyReusedForDifferentRequest(t *testing.T) {
+	var created atomic.Int32
+	router := setupIdempotencyRouter(&created, nil, nil)
+
+	assert.Equal(t, http.StatusCreated, post(router, "retry-1", "milk").Code)
+	w := post(router, "retry-1", "bread")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "CONFLICT")
+	assert.Equal(t, int32(1), created.Load())
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	var created atomic.Int32
+	router := setupIdempotencyRouter(&created, nil, nil)
+
+	assert.Equal(t, http.StatusInternalServerError, post(router, "retry-1", "fail").Code)
+	assert.Equal(t, http.StatusInternalServerError, post(router, "retry-1", "fail").Code)
+	assert.Equal(t, int32(0), created.Load())
+}
+
+func TestIdempotency_ConcurrentDuplicate(t *testing.T) {
+	var created atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	router := setupIdempotencyRouter(&created, started, release)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post(router, "retry-1", "wait") }()
+	<-started
+
+	// The duplicate is rejected while the first request is in flight
+	assert.Equal(t, http.StatusConflict, post(router, "retry-1", "wait").Code)
+
+	close(release)
+	first := <-done
+	assert.Equal(t, http.StatusCreated, first.Code)
+	retry := post(router, "retry-1", "wait")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, int32(1), created.Load())
+}
+
+func TestIdempotency_Validation(t *testing.T) {
+	var created atomic.Int32
+	router := setupIdempotencyRouter(&created, nil, nil)
+
+	assert.Equal(t, http.StatusBadRequest, post(router, strings.Repeat("k", 256), "milk").Code)
+
+	// Only POST requests are affected
+	req, _ := http.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set(IdempotencyKeyHeader, "retry-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusCreated, post(router, "retry-1", "milk").Code)
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID of a request, which is echoed back in the
+// response and recorded in the history of the tasks the request writes
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the gin context key holding the request ID
+const requestIDKey = "request.id"
+
+// validRequestID matches the request IDs accepted from clients
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// AssignRequestID assigns every request an ID: the client's X-Request-ID
+// header when it is a valid ID, otherwise a random one
+func AssignRequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestID returns the ID of the request, or "" when none was assigned
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// newRequestID returns a random request ID of 32 hex digits
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
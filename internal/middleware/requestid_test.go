@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveRequestID(header string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AssignRequestID())
+	router.GET("/id", func(c *gin.Context) {
+		c.String(http.StatusOK, RequestID(c))
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/id", nil)
+	if header != "" {
+		req.Header.Set(RequestIDHeader, header)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAssignRequestID_FromClient(t *testing.T) {
+	w := serveRequestID("build-42.retry_1")
+
+	assert.Equal(t, "build-42.retry_1", w.Body.String())
+	assert.Equal(t, "build-42.retry_1", w.Header().Get(RequestIDHeader))
+}
+
+func TestAssignRequestID_Generated(t *testing.T) {
+	for name, header := range map[string]string{
+		"missing":   "",
+		"too long":  strings.Repeat("a", 65),
+		"forbidden": "id with spaces",
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := serveRequestID(header)
+
+			assert.Len(t, w.Body.String(), 32)
+			assert.Equal(t, w.Body.String(), w.Header().Get(RequestIDHeader))
+			assert.NotEqual(t, w.Body.String(), serveRequestID(header).Body.String())
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// TimeZoneHeader carries an IANA time zone name overriding the user's
+// saved time zone for a single request
+const TimeZoneHeader = "Time-Zone"
+
+// Gin context keys holding the time zone resolution state
+const (
+	timeZoneLookupKey = "tz.lookup"
+	locationKey       = "tz.location"
+)
+
+// TimeZoneLookup resolves the saved time zone of a user
+type TimeZoneLookup interface {
+	UserTimeZone(userID uint) (string, error)
+}
+
+// ResolveTimeZone lets Location fall back to the authenticated user's saved
+// time zone. The lookup only happens when a handler asks for the location.
+func ResolveTimeZone(lookup TimeZoneLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(timeZoneLookupKey, lookup)
+		c.Next()
+	}
+}
+
+// Location returns the caller's time zone: the Time-Zone header when set,
+// otherwise the user's saved time zone, otherwise UTC
+func Location(c *gin.Context) (*time.Location, error) {
+	if cached, ok := c.Get(locationKey); ok {
+		return cached.(*time.Location), nil
+	}
+
+	loc := time.UTC
+	if name := c.GetHeader(TimeZoneHeader); name != "" {
+		parsed, err := LoadLocation(name)
+		if err != nil {
+			return nil, &apperrors.ValidationError{Message: "invalid " + TimeZoneHeader + " header: unknown time zone " + name}
+		}
+		loc = parsed
+	} else if lookup, ok := c.Get(timeZoneLookupKey); ok {
+		name, err := lookup.(TimeZoneLookup).UserTimeZone(UserID(c))
+		if err != nil {
+			return nil, err
+		}
+		if parsed, err := LoadLocation(name); err == nil {
+			loc = parsed
+		}
+	}
+
+	c.Set(locationKey, loc)
+	return loc, nil
+}
+
+// LoadLocation loads an IANA time zone by name, rejecting the server's
+// "Local" zone and the empty name
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, &apperrors.ValidationError{Message: "unknown time zone " + name}
+	}
+	return time.LoadLocation(name)
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubTimeZones serves saved time zones from a map, counting lookups
+type stubTimeZones struct {
+	zones   map[uint]string
+	lookups int
+}
+
+func (s *stubTimeZones) UserTimeZone(userID uint) (string, error) {
+	s.lookups++
+	zone, ok := s.zones[userID]
+	if !ok {
+		return "", errors.New("unknown user")
+	}
+	return zone, nil
+}
+
+func serveLocation(lookup TimeZoneLookup, header string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handlers := []gin.HandlerFunc{func(c *gin.Context) { SetUserID(c, 5) }}
+	if lookup != nil {
+		handlers = append(handlers, ResolveTimeZone(lookup))
+	}
+	handlers = append(handlers, func(c *gin.Context) {
+		loc, err := Location(c)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		// A second call is served from the context
+		loc, _ = Location(c)
+		c.String(http.StatusOK, loc.String())
+	})
+	router.GET("/tz", handlers...)
+
+	req, _ := http.NewRequest(http.MethodGet, "/tz", nil)
+	if header != "" {
+		req.Header.Set(TimeZoneHeader, header)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLocation(t *testing.T) {
+	lookup := &stubTimeZones{zones: map[uint]string{5: "Asia/Tokyo"}}
+
+	w := serveLocation(lookup, "")
+	assert.Equal(t, "Asia/Tokyo", w.Body.String())
+	assert.Equal(t, 1, lookup.lookups)
+
+	w = serveLocation(lookup, "Europe/Paris")
+	assert.Equal(t, "Europe/Paris", w.Body.String())
+	assert.Equal(t, 1, lookup.lookups)
+
+	w = serveLocation(nil, "")
+	assert.Equal(t, "UTC", w.Body.String())
+
+	for _, header := range []string{"Mars/Olympus_Mons", "Local"} {
+		w = serveLocation(lookup, header)
+		assert.Equal(t, http.StatusBadRequest, w.Code, header)
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIToken represents a long-lived personal access token. Only the hash of
+// the token is stored.
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(32);not null" json:"prefix"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Scopes     string     `gorm:"type:varchar(255);not null" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the APIToken model
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// ScopeList returns the scopes granted to the token
+func (t *APIToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// Expired reports whether the token has expired at the given time
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
@@ -1,36 +1,397 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
-// CreateTaskRequest represents the request body for creating a task
+// CreateTaskRequest represents the request body for creating a task.
+// Tags that do not exist yet are created. DueAt is an RFC 3339 timestamp,
+// or a date-time or date without offset in the caller's time zone. A
+// ParentID creates the task as a subtask of another task. Recurrence is an
+// RFC 5545 rule such as "FREQ=WEEKLY;BYDAY=MO" and requires a due date.
 type CreateTaskRequest struct {
-	Content string `json:"content" binding:"required,min=1,max=1000"`
+	Content    string   `json:"content" binding:"required,min=1,max=1000"`
+	ProjectID  *uint    `json:"project_id,omitempty"`
+	ParentID   *uint    `json:"parent_id,omitempty"`
+	Tags       []string `json:"tags,omitempty" binding:"max=20,dive,max=50"`
+	Priority   string   `json:"priority,omitempty" binding:"omitempty,oneof=none low medium high urgent"`
+	DueAt      *string  `json:"due_at,omitempty"`
+	Recurrence string   `json:"recurrence,omitempty" binding:"max=255"`
 }
 
-// UpdateTaskRequest represents the request body for updating a task
+// UpdateTaskRequest represents a partial update of a task: only the fields
+// that are set change. A ProjectID of 0 moves the task to the inbox. Tags,
+// when present, replace the task's tags; an empty list removes them all. An
+// empty DueAt removes the due date. A ParentID of 0 makes the task a
+// top-level task. An empty Recurrence ends the task's series after it.
+// CompleteSubtasks completes every subtask of a completed task along with it.
 type UpdateTaskRequest struct {
-	Content   *string `json:"content,omitempty" binding:"omitempty,min=1,max=1000"`
-	Completed *bool   `json:"completed,omitempty"`
+	Content          *string  `json:"content,omitempty" binding:"omitempty,min=1,max=1000"`
+	Completed        *bool    `json:"completed,omitempty"`
+	CompleteSubtasks bool     `json:"complete_subtasks,omitempty"`
+	ProjectID        *uint    `json:"project_id,omitempty"`
+	ParentID         *uint    `json:"parent_id,omitempty"`
+	Tags             []string `json:"tags,omitempty" binding:"max=20,dive,max=50"`
+	Priority         *string  `json:"priority,omitempty" binding:"omitempty,oneof=none low medium high urgent"`
+	DueAt            *string  `json:"due_at,omitempty"`
+	Recurrence       *string  `json:"recurrence,omitempty" binding:"omitempty,max=255"`
 }
 
-// TaskResponse represents a task in API responses
+// TaskDocument holds the writable fields of a task. It is the document that
+// PATCH requests apply to and, with CompleteSubtasks, the request body that
+// replaces a task. Fields that are missing or null are cleared, except that
+// content is required.
+type TaskDocument struct {
+	Content    string   `json:"content" binding:"required,min=1,max=1000"`
+	Completed  bool     `json:"completed"`
+	ProjectID  *uint    `json:"project_id"`
+	ParentID   *uint    `json:"parent_id"`
+	Tags       []string `json:"tags" binding:"max=20,dive,max=50"`
+	Priority   string   `json:"priority" binding:"omitempty,oneof=none low medium high urgent"`
+	DueAt      *string  `json:"due_at"`
+	Recurrence string   `json:"recurrence" binding:"max=255"`
+}
+
+// ReplaceTaskRequest represents the request body for replacing a task.
+// CompleteSubtasks completes every subtask of a completed task along with it.
+type ReplaceTaskRequest struct {
+	TaskDocument
+	CompleteSubtasks bool `json:"complete_subtasks,omitempty"`
+}
+
+// TaskResponse represents a task in API responses. Subtasks is only present
+// when the task tree was requested and DeletedAt only for tasks in the trash.
 type TaskResponse struct {
+	ID                    uint           `json:"id"`
+	ProjectID             *uint          `json:"project_id"`
+	ParentID              *uint          `json:"parent_id"`
+	Content               string         `json:"content"`
+	Completed             bool           `json:"completed"`
+	Priority              string         `json:"priority"`
+	DueAt                 *time.Time     `json:"due_at"`
+	CompletedAt           *time.Time     `json:"completed_at"`
+	Recurrence            string         `json:"recurrence,omitempty"`
+	SeriesID              *uint          `json:"series_id"`
+	Occurrence            int            `json:"occurrence,omitempty"`
+	Version               uint           `json:"version"`
+	Tags                  []string       `json:"tags"`
+	SubtaskCount          int64          `json:"subtask_count"`
+	CompletedSubtaskCount int64          `json:"completed_subtask_count"`
+	Subtasks              []TaskResponse `json:"subtasks,omitempty"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             *time.Time     `json:"deleted_at,omitempty"`
+	Snippet               string         `json:"snippet,omitempty"`
+	Rank                  float64        `json:"rank,omitempty"`
+}
+
+// TaskListResponse represents a list of tasks in API responses
+type TaskListResponse struct {
+	Tasks      []TaskResponse `json:"tasks"`
+	Count      int            `json:"count"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PrevCursor string         `json:"prev_cursor,omitempty"`
+	Total      *int64         `json:"total,omitempty"`
+}
+
+// OccurrencesResponse represents the upcoming occurrences of a recurring
+// task in API responses
+type OccurrencesResponse struct {
+	Occurrences []time.Time `json:"occurrences"`
+	Count       int         `json:"count"`
+}
+
+// Operations of a bulk task request
+const (
+	BulkOpCreate   = "create"
+	BulkOpUpdate   = "update"
+	BulkOpComplete = "complete"
+	BulkOpDelete   = "delete"
+)
+
+// BulkTaskOperation represents one operation of a bulk task request. Create
+// takes Task; update takes the ID of the task and its Changes; complete and
+// delete take the ID. Cascade completes or deletes the task's subtasks along
+// with it. Version, when set, must be the task's current version, like an
+// If-Match header.
+type BulkTaskOperation struct {
+	Op      string             `json:"op" binding:"required,oneof=create update complete delete"`
+	ID      uint               `json:"id,omitempty"`
+	Version *uint              `json:"version,omitempty"`
+	Task    *CreateTaskRequest `json:"task,omitempty"`
+	Changes *UpdateTaskRequest `json:"changes,omitempty"`
+	Cascade bool               `json:"cascade,omitempty"`
+}
+
+// BulkTaskRequest represents the request body for bulk task operations
+type BulkTaskRequest struct {
+	Operations []BulkTaskOperation `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+// BulkTaskResult represents the result of one operation of a bulk request
+// in API responses: the status it would have had as a request of its own,
+// and either the task it wrote or its error
+type BulkTaskResult struct {
+	Op     string        `json:"op"`
+	ID     uint          `json:"id,omitempty"`
+	Status int           `json:"status"`
+	Task   *TaskResponse `json:"task,omitempty"`
+	Error  *ErrorDetail  `json:"error,omitempty"`
+}
+
+// BulkTaskResponse represents the results of a bulk request in API
+// responses, in the order of its operations
+type BulkTaskResponse struct {
+	Atomic    bool             `json:"atomic"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkTaskResult `json:"results"`
+}
+
+// BulkActionResponse represents the number of tasks a filter-based bulk
+// action applied to in API responses
+type BulkActionResponse struct {
+	Count int `json:"count"`
+}
+
+// RevertTaskRequest represents the request body for reverting a task to a
+// version in its history
+type RevertTaskRequest struct {
+	Version uint `json:"version" binding:"required"`
+}
+
+// TaskEventResponse represents an event in the history of a task in API
+// responses. Changes maps each field an update or completion changed to its
+// values before and after; State is the task's document after the event.
+type TaskEventResponse struct {
+	ID        uint            `json:"id"`
+	TaskID    uint            `json:"task_id"`
+	Type      string          `json:"type"`
+	Version   uint            `json:"version"`
+	ActorID   uint            `json:"actor_id"`
+	TokenID   *uint           `json:"token_id,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Changes   json.RawMessage `json:"changes"`
+	State     json.RawMessage `json:"state"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// TaskEventListResponse represents a list of task events in API responses
+type TaskEventListResponse struct {
+	Events     []TaskEventResponse `json:"events"`
+	Count      int                 `json:"count"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// CreateProjectRequest represents the request body for creating a project
+type CreateProjectRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=100"`
+	Color    string `json:"color,omitempty" binding:"omitempty,hexcolor"`
+	Position *int   `json:"position,omitempty" binding:"omitempty,min=0"`
+}
+
+// UpdateProjectRequest represents the request body for updating a project
+type UpdateProjectRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Color    *string `json:"color,omitempty" binding:"omitempty,hexcolor"`
+	Archived *bool   `json:"archived,omitempty"`
+	Position *int    `json:"position,omitempty" binding:"omitempty,min=0"`
+}
+
+// ProjectResponse represents a project in API responses
+type ProjectResponse struct {
 	ID        uint      `json:"id"`
-	Content   string    `json:"content"`
-	Completed bool      `json:"completed"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Archived  bool      `json:"archived"`
+	Position  int       `json:"position"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// TaskListResponse represents a list of tasks in API responses
-type TaskListResponse struct {
-	Tasks []TaskResponse `json:"tasks"`
-	Count int            `json:"count"`
+// ProjectListResponse represents a list of projects in API responses
+type ProjectListResponse struct {
+	Projects []ProjectResponse `json:"projects"`
+	Count    int               `json:"count"`
+}
+
+// RenameTagRequest represents the request body for renaming a tag
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+// MergeTagRequest represents the request body for merging a tag into another
+type MergeTagRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
+// TagResponse represents a tag in API responses
+type TagResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TagSummaryResponse represents a tag with its task count in API responses
+type TagSummaryResponse struct {
+	TagResponse
+	TaskCount int64 `json:"task_count"`
+}
+
+// TagListResponse represents a list of tags in API responses
+type TagListResponse struct {
+	Tags  []TagSummaryResponse `json:"tags"`
+	Count int                  `json:"count"`
+}
+
+// RegisterRequest represents the request body for creating a user account
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	TimeZone string `json:"time_zone,omitempty" binding:"omitempty,timezone"`
+}
+
+// LoginRequest represents the request body for logging in
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest represents the request body for refreshing an access token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// UpdateProfileRequest represents the request body for updating the
+// signed-in user's settings
+type UpdateProfileRequest struct {
+	TimeZone string `json:"time_zone" binding:"required,timezone"`
+}
+
+// UserResponse represents a user in API responses
+type UserResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	TimeZone  string    `json:"time_zone"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuthResponse represents the tokens issued after authentication
+type AuthResponse struct {
+	AccessToken  string       `json:"access_token"`
+	RefreshToken string       `json:"refresh_token"`
+	TokenType    string       `json:"token_type"`
+	ExpiresIn    int          `json:"expires_in"`
+	User         UserResponse `json:"user"`
+}
+
+// CreateAPITokenRequest represents the request body for minting a personal
+// access token
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APITokenResponse represents a personal access token in API responses
+type APITokenResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPITokenResponse represents a newly minted personal access token;
+// the token itself is only ever returned here
+type CreateAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
+
+// APITokenListResponse represents a list of personal access tokens in API responses
+type APITokenListResponse struct {
+	Tokens []APITokenResponse `json:"tokens"`
+	Count  int                `json:"count"`
+}
+
+// CreateWebhookRequest represents the request body for subscribing a
+// webhook. A secret is generated when none is given, and omitting Events
+// subscribes to every event type.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,max=2048"`
+	Secret string   `json:"secret" binding:"max=255"`
+	Events []string `json:"events"`
+}
+
+// UpdateWebhookRequest represents the request body for changing a webhook.
+// Omitted fields are left unchanged.
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url,omitempty" binding:"omitempty,max=2048"`
+	Secret *string  `json:"secret,omitempty" binding:"omitempty,max=255"`
+	Events []string `json:"events,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// WebhookResponse represents a webhook in API responses. The secret is not
+// included.
+type WebhookResponse struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateWebhookResponse represents a newly subscribed webhook in API
+// responses, the only time its secret is shown
+type CreateWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+// WebhookListResponse represents a list of webhooks in API responses
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+	Count    int               `json:"count"`
+}
+
+// WebhookDeliveryResponse represents a webhook delivery in API responses.
+// ResponseStatus and LastError describe the last attempt.
+type WebhookDeliveryResponse struct {
+	ID             uint            `json:"id"`
+	WebhookID      uint            `json:"webhook_id"`
+	EventID        uint            `json:"event_id"`
+	Type           string          `json:"type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	ResponseStatus *int            `json:"response_status"`
+	LastError      string          `json:"last_error,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
 }
 
-// ErrorResponse represents an error in API responses
+// WebhookDeliveryListResponse represents a list of webhook deliveries in
+// API responses
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Count      int                       `json:"count"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
+}
+
+// ErrorResponse represents an error in API responses. Current is the
+// task's current state, returned with VERSION_CONFLICT errors.
 type ErrorResponse struct {
-	Error ErrorDetail `json:"error"`
+	Error   ErrorDetail   `json:"error"`
+	Current *TaskResponse `json:"current,omitempty"`
 }
 
 // ErrorDetail contains error details
@@ -39,14 +400,102 @@ type ErrorDetail struct {
 	Message string `json:"message"`
 }
 
-// ToResponse converts a Task model to TaskResponse
+// ToResponse converts a Task model to TaskResponse, including its subtask
+// tree when loaded
 func (t *Task) ToResponse() TaskResponse {
-	return TaskResponse{
-		ID:        t.ID,
-		Content:   t.Content,
-		Completed: t.Completed,
-		CreatedAt: t.CreatedAt,
-		UpdatedAt: t.UpdatedAt,
+	response := TaskResponse{
+		ID:                    t.ID,
+		ProjectID:             t.ProjectID,
+		ParentID:              t.ParentID,
+		Content:               t.Content,
+		Completed:             t.Completed,
+		Priority:              t.Priority.String(),
+		DueAt:                 t.DueAt,
+		CompletedAt:           t.CompletedAt,
+		Recurrence:            t.Recurrence,
+		SeriesID:              t.SeriesID,
+		Occurrence:            t.Occurrence,
+		Version:               t.Version,
+		Tags:                  t.TagNames(),
+		SubtaskCount:          t.SubtaskCount,
+		CompletedSubtaskCount: t.CompletedSubtaskCount,
+		CreatedAt:             t.CreatedAt,
+		UpdatedAt:             t.UpdatedAt,
+	}
+	if t.DeletedAt.Valid {
+		deletedAt := t.DeletedAt.Time
+		response.DeletedAt = &deletedAt
+	}
+	if t.Subtasks != nil {
+		response.Subtasks = make([]TaskResponse, len(t.Subtasks))
+		for i := range t.Subtasks {
+			response.Subtasks[i] = t.Subtasks[i].ToResponse()
+		}
+	}
+	return response
+}
+
+// Document returns the writable fields of the task as a TaskDocument, with
+// the due date in RFC 3339 form
+func (t *Task) Document() TaskDocument {
+	doc := TaskDocument{
+		Content:    t.Content,
+		Completed:  t.Completed,
+		ProjectID:  t.ProjectID,
+		ParentID:   t.ParentID,
+		Tags:       t.TagNames(),
+		Priority:   t.Priority.String(),
+		Recurrence: t.Recurrence,
+	}
+	if t.DueAt != nil {
+		dueAt := t.DueAt.UTC().Format(time.RFC3339Nano)
+		doc.DueAt = &dueAt
+	}
+	return doc
+}
+
+// UpdateRequest returns the update that sets every writable field of a task
+// to the document's value
+func (d *TaskDocument) UpdateRequest() *UpdateTaskRequest {
+	var projectID, parentID uint
+	if d.ProjectID != nil {
+		projectID = *d.ProjectID
+	}
+	if d.ParentID != nil {
+		parentID = *d.ParentID
+	}
+	tags := d.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	priority := d.Priority
+	if priority == "" {
+		priority = PriorityNone.String()
+	}
+	dueAt := ""
+	if d.DueAt != nil {
+		dueAt = *d.DueAt
+	}
+	recurrence := d.Recurrence
+	return &UpdateTaskRequest{
+		Content:    &d.Content,
+		Completed:  &d.Completed,
+		ProjectID:  &projectID,
+		ParentID:   &parentID,
+		Tags:       tags,
+		Priority:   &priority,
+		DueAt:      &dueAt,
+		Recurrence: &recurrence,
+	}
+}
+
+// ToResponse converts a User model to UserResponse
+func (u *User) ToResponse() UserResponse {
+	return UserResponse{
+		ID:        u.ID,
+		Email:     u.Email,
+		TimeZone:  u.TimeZone,
+		CreatedAt: u.CreatedAt,
 	}
 }
 
@@ -61,3 +510,173 @@ func ToListResponse(tasks []Task) TaskListResponse {
 		Count: len(responses),
 	}
 }
+
+// ToSearchResponse converts ranked search results to TaskListResponse
+func ToSearchResponse(results []TaskSearchResult) TaskListResponse {
+	responses := make([]TaskResponse, len(results))
+	for i, result := range results {
+		responses[i] = result.Task.ToResponse()
+		responses[i].Snippet = result.Snippet
+		responses[i].Rank = result.Rank
+	}
+	return TaskListResponse{
+		Tasks: responses,
+		Count: len(responses),
+	}
+}
+
+// ToResponse converts a TaskEvent model to TaskEventResponse
+func (e *TaskEvent) ToResponse() TaskEventResponse {
+	return TaskEventResponse{
+		ID:        e.ID,
+		TaskID:    e.TaskID,
+		Type:      e.Type,
+		Version:   e.Version,
+		ActorID:   e.ActorID,
+		TokenID:   e.TokenID,
+		RequestID: e.RequestID,
+		Changes:   json.RawMessage(e.Changes),
+		State:     json.RawMessage(e.State),
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// ToEventListResponse converts a slice of TaskEvents to TaskEventListResponse
+func ToEventListResponse(events []TaskEvent) TaskEventListResponse {
+	responses := make([]TaskEventResponse, len(events))
+	for i := range events {
+		responses[i] = events[i].ToResponse()
+	}
+	return TaskEventListResponse{
+		Events: responses,
+		Count:  len(responses),
+	}
+}
+
+// ToResponse converts an APIToken model to APITokenResponse
+func (t *APIToken) ToResponse() APITokenResponse {
+	return APITokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.ScopeList(),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// ToAPITokenListResponse converts a slice of APITokens to APITokenListResponse
+func ToAPITokenListResponse(tokens []APIToken) APITokenListResponse {
+	responses := make([]APITokenResponse, len(tokens))
+	for i, token := range tokens {
+		responses[i] = token.ToResponse()
+	}
+	return APITokenListResponse{
+		Tokens: responses,
+		Count:  len(responses),
+	}
+}
+
+// ToResponse converts a Webhook model to WebhookResponse
+func (w *Webhook) ToResponse() WebhookResponse {
+	return WebhookResponse{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    w.EventList(),
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// ToWebhookListResponse converts a slice of Webhooks to WebhookListResponse
+func ToWebhookListResponse(webhooks []Webhook) WebhookListResponse {
+	responses := make([]WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = webhook.ToResponse()
+	}
+	return WebhookListResponse{
+		Webhooks: responses,
+		Count:    len(responses),
+	}
+}
+
+// ToResponse converts a WebhookDelivery model to WebhookDeliveryResponse
+func (d *WebhookDelivery) ToResponse() WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		Type:           d.Type,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastAttemptAt:  d.LastAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		Payload:        json.RawMessage(d.Payload),
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+// ToDeliveryListResponse converts a slice of WebhookDeliveries to
+// WebhookDeliveryListResponse
+func ToDeliveryListResponse(deliveries []WebhookDelivery) WebhookDeliveryListResponse {
+	responses := make([]WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = deliveries[i].ToResponse()
+	}
+	return WebhookDeliveryListResponse{
+		Deliveries: responses,
+		Count:      len(responses),
+	}
+}
+
+// ToResponse converts a Project model to ProjectResponse
+func (p *Project) ToResponse() ProjectResponse {
+	return ProjectResponse{
+		ID:        p.ID,
+		Name:      p.Name,
+		Color:     p.Color,
+		Archived:  p.Archived,
+		Position:  p.Position,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// ToProjectListResponse converts a slice of Projects to ProjectListResponse
+func ToProjectListResponse(projects []Project) ProjectListResponse {
+	responses := make([]ProjectResponse, len(projects))
+	for i, project := range projects {
+		responses[i] = project.ToResponse()
+	}
+	return ProjectListResponse{
+		Projects: responses,
+		Count:    len(responses),
+	}
+}
+
+// ToResponse converts a Tag model to TagResponse
+func (t *Tag) ToResponse() TagResponse {
+	return TagResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+// ToTagListResponse converts a slice of TagSummaries to TagListResponse
+func ToTagListResponse(tags []TagSummary) TagListResponse {
+	responses := make([]TagSummaryResponse, len(tags))
+	for i, tag := range tags {
+		responses[i] = TagSummaryResponse{TagResponse: tag.Tag.ToResponse(), TaskCount: tag.TaskCount}
+	}
+	return TagListResponse{
+		Tags:  responses,
+		Count: len(responses),
+	}
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// Types of task events
+const (
+	TaskEventCreated   = "created"
+	TaskEventUpdated   = "updated"
+	TaskEventCompleted = "completed"
+	TaskEventDeleted   = "deleted"
+	TaskEventRestored  = "restored"
+	TaskEventPurged    = "purged"
+)
+
+// TaskEventTypes lists the types of task events in the order of a task's
+// life
+var TaskEventTypes = []string{
+	TaskEventCreated, TaskEventUpdated, TaskEventCompleted, TaskEventDeleted, TaskEventRestored, TaskEventPurged,
+}
+
+// Actor identifies who made a write: the authenticated user, whose tasks
+// are written, the personal access token they used, if any, and the ID of
+// the request
+type Actor struct {
+	UserID    uint
+	TokenID   *uint
+	RequestID string
+}
+
+// TaskEvent records one write to a task in its append-only history. State
+// holds the task's document after the write and Changes, for updates and
+// completions, the fields the write changed with their values before and
+// after, both as JSON. Version is the task's version after the write.
+// Events outlive their task, so that deleting it for good keeps its history.
+type TaskEvent struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	OwnerID   uint   `gorm:"not null;index"`
+	TaskID    uint   `gorm:"not null;index"`
+	Type      string `gorm:"type:varchar(20);not null"`
+	Version   uint   `gorm:"not null"`
+	ActorID   uint   `gorm:"not null"`
+	TokenID   *uint
+	RequestID string    `gorm:"type:varchar(64);not null;default:''"`
+	Changes   string    `gorm:"type:text;not null;default:'{}'"`
+	State     string    `gorm:"type:text;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for the TaskEvent model
+func (TaskEvent) TableName() string {
+	return "task_events"
+}
+
+// FieldChange holds the JSON values of a task field before and after an
+// update
+type FieldChange struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+// NewTaskEvent builds the event of a write that took a task from before,
+// which is nil for a created task, to after. Updates and completions record
+// the fields that changed. Without an actor, the write is attributed to the
+// task's owner.
+func NewTaskEvent(eventType string, before, after *Task, actor *Actor) TaskEvent {
+	state := eventDocument(after)
+	event := TaskEvent{
+		OwnerID: after.OwnerID,
+		TaskID:  after.ID,
+		Type:    eventType,
+		Version: after.Version,
+		ActorID: after.OwnerID,
+		Changes: "{}",
+	}
+	if actor != nil {
+		event.ActorID, event.TokenID, event.RequestID = actor.UserID, actor.TokenID, actor.RequestID
+	}
+	encoded, _ := json.Marshal(state)
+	event.State = string(encoded)
+	if before != nil && (eventType == TaskEventUpdated || eventType == TaskEventCompleted) {
+		encoded, _ = json.Marshal(diffDocuments(eventDocument(before), state))
+		event.Changes = string(encoded)
+	}
+	return event
+}
+
+// UpdateEventType returns the type of the event of an update that took a
+// task from before to after
+func UpdateEventType(before, after *Task) string {
+	if after.Completed && !before.Completed {
+		return TaskEventCompleted
+	}
+	return TaskEventUpdated
+}
+
+// Document returns the task's document as recorded by the event
+func (e *TaskEvent) Document() (*TaskDocument, error) {
+	var doc TaskDocument
+	if err := json.Unmarshal([]byte(e.State), &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// eventDocument returns the document of a task as recorded in events, with
+// its tags sorted so that their order is never a change
+func eventDocument(task *Task) TaskDocument {
+	doc := task.Document()
+	slices.Sort(doc.Tags)
+	return doc
+}
+
+// diffDocuments returns the fields that differ between two documents,
+// keyed by their JSON name
+func diffDocuments(before, after TaskDocument) map[string]FieldChange {
+	var from, to map[string]json.RawMessage
+	encoded, _ := json.Marshal(before)
+	json.Unmarshal(encoded, &from)
+	encoded, _ = json.Marshal(after)
+	json.Unmarshal(encoded, &to)
+
+	changes := make(map[string]FieldChange)
+	for field, value := range to {
+		if !bytes.Equal(from[field], value) {
+			changes[field] = FieldChange{From: from[field], To: value}
+		}
+	}
+	return changes
+}
+
+// TaskEventFilter represents the filtering options for listing task events.
+// Since is inclusive and Until exclusive.
+type TaskEventFilter struct {
+	TaskID    *uint
+	Types     []string
+	TokenID   *uint
+	RequestID string
+	Since     *time.Time
+	Until     *time.Time
+}
+
+// TaskEventPage represents one page of task events, newest first.
+// NextCursor is positioned at the last event when more events follow.
+type TaskEventPage struct {
+	Events     []TaskEvent
+	NextCursor *Cursor
+}
@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// TaskSortColumns maps the sort keys accepted by the API to task columns
+var TaskSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"content":    "content",
+	"completed":  "completed",
+}
+
+// SortField represents a single ordering criterion for task queries
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// TaskFilter represents the filtering and ordering options for listing tasks.
+// A ProjectID of 0 selects the tasks that are not in any project (the inbox)
+// and a ParentID of 0 selects top-level tasks. ParentIDs selects the
+// subtasks of any of the given tasks. SeriesID selects the occurrences of a
+// recurring series.
+// Tasks must carry every one of Tags, or any of them when TagMode is
+// TagModeAny; tag names are expected in normalized form. DueFrom is
+// inclusive and DueBefore exclusive; either one excludes tasks without a
+// due date.
+type TaskFilter struct {
+	ProjectID       *uint
+	ParentID        *uint
+	ParentIDs       []uint
+	SeriesID        *uint
+	Tags            []string
+	TagMode         string
+	Completed       *bool
+	DueFrom         *time.Time
+	DueBefore       *time.Time
+	CreatedAfter    *time.Time
+	UpdatedBefore   *time.Time
+	ContentContains string
+	Sort            []SortField
+}
+
+// Empty reports whether the filter selects every task, ignoring its order
+func (f *TaskFilter) Empty() bool {
+	return f.ProjectID == nil && f.ParentID == nil && len(f.ParentIDs) == 0 && f.SeriesID == nil && len(f.Tags) == 0 &&
+		f.Completed == nil && f.DueFrom == nil && f.DueBefore == nil && f.CreatedAfter == nil &&
+		f.UpdatedBefore == nil && f.ContentContains == ""
+}
+
+// Cursor identifies the position of a task within an ordered listing.
+// CreatedAt and ID are always set; the remaining values are only set when
+// the listing is sorted by the corresponding field.
+type Cursor struct {
+	CreatedAt time.Time  `json:"c"`
+	ID        uint       `json:"i"`
+	UpdatedAt *time.Time `json:"u,omitempty"`
+	Content   *string    `json:"t,omitempty"`
+	Completed *bool      `json:"d,omitempty"`
+	Backward  bool       `json:"b,omitempty"`
+}
+
+// NewCursor creates a cursor positioned at the task for the given ordering
+func NewCursor(task *Task, sort []SortField, backward bool) *Cursor {
+	cursor := &Cursor{CreatedAt: task.CreatedAt, ID: task.ID, Backward: backward}
+	for _, field := range sort {
+		switch field.Field {
+		case "updated_at":
+			updatedAt := task.UpdatedAt
+			cursor.UpdatedAt = &updatedAt
+		case "content":
+			content := task.Content
+			cursor.Content = &content
+		case "completed":
+			completed := task.Completed
+			cursor.Completed = &completed
+		}
+	}
+	return cursor
+}
+
+// PageRequest represents the pagination options for listing tasks
+type PageRequest struct {
+	Limit        int
+	Cursor       *Cursor
+	IncludeTotal bool
+}
+
+// TaskPage represents one page of a task listing
+type TaskPage struct {
+	Tasks      []Task
+	NextCursor *Cursor
+	PrevCursor *Cursor
+	Total      *int64
+}
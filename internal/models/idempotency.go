@@ -0,0 +1,39 @@
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyKey records a request made with an Idempotency-Key header. The
+// key locks out duplicates while the request is in flight, with a zero
+// Status, and then holds its response for replaying to retries.
+type IdempotencyKey struct {
+	UserID      uint   `gorm:"primaryKey;autoIncrement:false"`
+	Key         string `gorm:"column:idempotency_key;primaryKey;type:varchar(255)"`
+	Fingerprint string `gorm:"type:varchar(64);not null"`
+	Status      int    `gorm:"not null;default:0"`
+	Headers     string `gorm:"type:text;not null;default:''"`
+	Body        []byte
+	LockedUntil time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for the IdempotencyKey model
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// InFlight reports whether the request is still being processed
+func (k *IdempotencyKey) InFlight() bool {
+	return k.Status == 0
+}
+
+// StoredResponse is the response to a request made with an Idempotency-Key,
+// as replayed to its retries
+type StoredResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Project represents a named list that groups a user's tasks
+type Project struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OwnerID   uint      `gorm:"index;not null" json:"owner_id"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	Color     string    `gorm:"type:varchar(7);not null" json:"color"`
+	Archived  bool      `gorm:"default:false;not null" json:"archived"`
+	Position  int       `gorm:"default:0;not null" json:"position"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Project model
+func (Project) TableName() string {
+	return "projects"
+}
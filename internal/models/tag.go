@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Tag match modes for filtering tasks by several tags
+const (
+	TagModeAll = "all"
+	TagModeAny = "any"
+)
+
+// MaxTagNameLength is the longest tag name accepted, in characters
+const MaxTagNameLength = 50
+
+// Tag represents a label attached to any number of a user's tasks.
+// Tag names are unique per owner and stored in normalized form.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OwnerID   uint      `gorm:"index;not null" json:"owner_id"`
+	Name      string    `gorm:"type:varchar(50);not null" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Tag model
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TagSummary represents a tag together with the number of tasks it labels
+type TagSummary struct {
+	Tag       `gorm:"embedded"`
+	TaskCount int64 `gorm:"column:task_count"`
+}
+
+// NormalizeTagName returns the canonical form of a tag name: trimmed,
+// lower-cased and with inner whitespace collapsed to single spaces
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -1,19 +1,175 @@
 package models
 
 import (
+	"slices"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Priority represents the urgency of a task; higher values are more urgent
+type Priority int
+
+// Task priorities
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
 )
 
-// Task represents a todo item in the database
+// priorityNames are the API names of the priorities, indexed by value
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// String returns the API name of the priority
+func (p Priority) String() string {
+	if p < PriorityNone || int(p) >= len(priorityNames) {
+		return priorityNames[PriorityNone]
+	}
+	return priorityNames[p]
+}
+
+// ParsePriority returns the priority with the given API name
+func ParsePriority(name string) (Priority, bool) {
+	for i, priorityName := range priorityNames {
+		if priorityName == name {
+			return Priority(i), true
+		}
+	}
+	return PriorityNone, false
+}
+
+// Task represents a todo item in the database. Subtasks point at their
+// parent through ParentID. SubtaskCount and CompletedSubtaskCount roll up
+// the task's direct subtasks and are filled in when the task is read;
+// Subtasks is only filled in when the task is read as a tree.
+// Recurring tasks carry an RFC 5545 Recurrence rule; completing one creates
+// the next occurrence, which takes the rule over. The occurrences of a
+// series share the SeriesID, the ID of its first task, and are numbered by
+// Occurrence from 1. Deleted tasks are kept in the trash, with DeletedAt
+// set, until they are restored or purged. Version starts at 1 and is
+// incremented by every write to the task.
 type Task struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Content   string    `gorm:"type:varchar(1000);not null" json:"content"`
-	Completed bool      `gorm:"default:false;not null" json:"completed"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                    uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	OwnerID               uint           `gorm:"index" json:"owner_id"`
+	ProjectID             *uint          `gorm:"index" json:"project_id"`
+	ParentID              *uint          `gorm:"index" json:"parent_id"`
+	Content               string         `gorm:"type:varchar(1000);not null" json:"content"`
+	Completed             bool           `gorm:"default:false;not null" json:"completed"`
+	Priority              Priority       `gorm:"type:smallint;default:0;not null" json:"priority"`
+	DueAt                 *time.Time     `json:"due_at"`
+	CompletedAt           *time.Time     `json:"completed_at"`
+	Recurrence            string         `gorm:"type:varchar(255);default:'';not null" json:"recurrence"`
+	SeriesID              *uint          `gorm:"index" json:"series_id"`
+	Occurrence            int            `gorm:"default:0;not null" json:"occurrence"`
+	Version               uint           `gorm:"default:1;not null" json:"version"`
+	Tags                  []Tag          `gorm:"many2many:task_tags" json:"tags"`
+	CreatedAt             time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt             time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	SubtaskCount          int64          `gorm:"->;-:migration" json:"subtask_count"`
+	CompletedSubtaskCount int64          `gorm:"->;-:migration" json:"completed_subtask_count"`
+	Subtasks              []Task         `gorm:"-" json:"subtasks,omitempty"`
 }
 
 // TableName specifies the table name for the Task model
 func (Task) TableName() string {
 	return "tasks"
 }
+
+// TagNames returns the names of the task's tags in order
+func (t *Task) TagNames() []string {
+	names := make([]string, len(t.Tags))
+	for i, tag := range t.Tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// VersionCondition lists the versions of a task a write may apply to, as
+// given by an If-Match header. A nil VersionCondition allows any version.
+type VersionCondition []uint
+
+// Allows reports whether a write may apply to the given version
+func (c VersionCondition) Allows(version uint) bool {
+	return c == nil || slices.Contains(c, version)
+}
+
+// TaskUpdate is the write of one updated task within a TaskBatch.
+// CompleteSubtasks and Next complete the task like TaskRepository.Complete.
+type TaskUpdate struct {
+	Task             *Task
+	CompleteSubtasks bool
+	Next             *Task
+}
+
+// TaskBatch collects writes to one owner's tasks that are applied together
+// in one transaction, in the order of its fields. A recurring task created
+// without a series starts its own. Completions are tasks without recurrence
+// that are marked completed at CompletedAt regardless of their version, and
+// Deletes are tasks moved to the trash along with their subtasks. The
+// writes are recorded in the history of the tasks as made by Actor.
+// NewTags names the tags the written tasks carry without an ID, which are
+// found or created in the same transaction before the writes.
+type TaskBatch struct {
+	OwnerID     uint
+	Actor       *Actor
+	NewTags     []string
+	Creates     []*Task
+	Updates     []TaskUpdate
+	Completions []uint
+	CompletedAt time.Time
+	Deletes     []uint
+}
+
+// Empty reports whether the batch writes nothing
+func (b *TaskBatch) Empty() bool {
+	return len(b.Creates) == 0 && len(b.Updates) == 0 && len(b.Completions) == 0 && len(b.Deletes) == 0
+}
+
+// AddNewTags lists the tags of the task that have no ID yet in NewTags
+func (b *TaskBatch) AddNewTags(task *Task) {
+	for _, tag := range task.Tags {
+		if tag.ID == 0 && !slices.Contains(b.NewTags, tag.Name) {
+			b.NewTags = append(b.NewTags, tag.Name)
+		}
+	}
+}
+
+// SetTagIDs gives the tags of the written tasks that have no ID yet the ID
+// of the tag with the same name
+func (b *TaskBatch) SetTagIDs(tags []Tag) {
+	ids := make(map[string]uint, len(tags))
+	for _, tag := range tags {
+		ids[tag.Name] = tag.ID
+	}
+	tasks := slices.Clone(b.Creates)
+	for _, update := range b.Updates {
+		tasks = append(tasks, update.Task)
+		if update.Next != nil {
+			tasks = append(tasks, update.Next)
+		}
+	}
+	for _, task := range tasks {
+		for i := range task.Tags {
+			if task.Tags[i].ID == 0 {
+				task.Tags[i].ID = ids[task.Tags[i].Name]
+			}
+		}
+	}
+}
+
+// BulkTaskOutcome is the result of one operation of a bulk request: the
+// task it wrote, if any, or the error it failed with
+type BulkTaskOutcome struct {
+	Task *Task
+	Err  error
+}
+
+// TaskSearchResult represents a task matched by a full-text search
+type TaskSearchResult struct {
+	Task    `gorm:"embedded"`
+	Rank    float64 `gorm:"column:rank"`
+	Snippet string  `gorm:"column:snippet"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// DefaultTimeZone is the time zone of users who have not chosen one
+const DefaultTimeZone = "UTC"
+
+// User represents an account that owns tasks
+type User struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Email        string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
+	TimeZone     string    `gorm:"type:varchar(64);not null;default:UTC" json:"time_zone"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the User model
+func (User) TableName() string {
+	return "users"
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Types of the events delivered to webhooks, one for each type of task event
+const (
+	WebhookEventTaskCreated   = "task.created"
+	WebhookEventTaskUpdated   = "task.updated"
+	WebhookEventTaskCompleted = "task.completed"
+	WebhookEventTaskDeleted   = "task.deleted"
+	WebhookEventTaskRestored  = "task.restored"
+	WebhookEventTaskPurged    = "task.purged"
+)
+
+// WebhookEventTypes lists the types of webhook events in canonical order
+var WebhookEventTypes = []string{
+	WebhookEventTaskCreated, WebhookEventTaskUpdated, WebhookEventTaskCompleted,
+	WebhookEventTaskDeleted, WebhookEventTaskRestored, WebhookEventTaskPurged,
+}
+
+// Statuses of a webhook delivery. Dead deliveries ran out of attempts and
+// form the dead-letter list.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// Webhook represents a subscription of a user to the events of their tasks.
+// Payloads are signed with Secret, which is only shown when it is generated.
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	OwnerID   uint      `gorm:"not null;index"`
+	URL       string    `gorm:"type:varchar(2048);not null"`
+	Secret    string    `gorm:"type:varchar(255);not null"`
+	Events    string    `gorm:"type:varchar(255);not null"`
+	Active    bool      `gorm:"not null;default:true"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// EventList returns the types of the events the webhook subscribes to
+func (w *Webhook) EventList() []string {
+	return strings.Fields(w.Events)
+}
+
+// Subscribes reports whether the webhook receives events of the type
+func (w *Webhook) Subscribes(eventType string) bool {
+	return w.Active && slices.Contains(w.EventList(), eventType)
+}
+
+// OutboxMessage is a webhook event waiting to be turned into deliveries.
+// It is written in the same transaction as the task event it announces, so
+// that every committed write is delivered and no rolled back one is.
+type OutboxMessage struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	OwnerID   uint      `gorm:"not null"`
+	EventID   uint      `gorm:"not null"`
+	Type      string    `gorm:"type:varchar(40);not null"`
+	Payload   string    `gorm:"type:text;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for the OutboxMessage model
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
+
+// WebhookPayload is the JSON body POSTed to webhooks
+type WebhookPayload struct {
+	ID        uint              `json:"id"`
+	Type      string            `json:"type"`
+	CreatedAt time.Time         `json:"created_at"`
+	Data      TaskEventResponse `json:"data"`
+}
+
+// NewOutboxMessage builds the outbox message announcing a recorded task
+// event, whose ID and creation time must be set
+func NewOutboxMessage(event *TaskEvent) OutboxMessage {
+	eventType := "task." + event.Type
+	payload, _ := json.Marshal(WebhookPayload{
+		ID:        event.ID,
+		Type:      eventType,
+		CreatedAt: event.CreatedAt,
+		Data:      event.ToResponse(),
+	})
+	return OutboxMessage{
+		OwnerID:   event.OwnerID,
+		EventID:   event.ID,
+		Type:      eventType,
+		Payload:   string(payload),
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+// WebhookDelivery represents the delivery of an event to a webhook and its
+// outcome so far. ResponseStatus and LastError describe the last attempt.
+type WebhookDelivery struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement"`
+	WebhookID      uint   `gorm:"not null;index"`
+	OwnerID        uint   `gorm:"not null"`
+	EventID        uint   `gorm:"not null"`
+	Type           string `gorm:"type:varchar(40);not null"`
+	Payload        string `gorm:"type:text;not null"`
+	Status         string `gorm:"type:varchar(20);not null"`
+	Attempts       int    `gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time
+	LastAttemptAt  *time.Time
+	ResponseStatus *int
+	LastError      string    `gorm:"type:text;not null;default:''"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+	Webhook        *Webhook  `gorm:"foreignKey:WebhookID"`
+}
+
+// TableName specifies the table name for the WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookDeliveryFilter represents the filtering options for listing
+// webhook deliveries
+type WebhookDeliveryFilter struct {
+	WebhookID *uint
+	Status    string
+}
+
+// WebhookDeliveryPage represents one page of webhook deliveries, newest
+// first. NextCursor is positioned at the last delivery when more follow.
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDelivery
+	NextCursor *Cursor
+}
@@ -0,0 +1,71 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// ErrInvalidCursor is returned when a cursor is malformed or has been tampered with
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Codec encodes cursors into opaque, signed strings and decodes them back
+type Codec struct {
+	secret []byte
+}
+
+// NewCodec creates a Codec signing cursors with the given secret. When the
+// secret is empty a random one is generated, so cursors stop being valid
+// when the process restarts.
+func NewCodec(secret string) *Codec {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic("pagination: failed to generate cursor secret: " + err.Error())
+		}
+	}
+	return &Codec{secret: key}
+}
+
+// Encode serializes and signs a cursor
+func (c *Codec) Encode(cursor *models.Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// Decode verifies the signature of an encoded cursor and deserializes it
+func (c *Codec) Decode(value string) (*models.Cursor, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor models.Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// sign computes the HMAC-SHA256 of the payload
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
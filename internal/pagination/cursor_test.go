@@ -0,0 +1,48 @@
+package pagination
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := NewCodec("secret")
+
+	content := "Buy milk"
+	cursor := &models.Cursor{
+		CreatedAt: time.Date(2025, 11, 22, 10, 0, 0, 123456000, time.UTC),
+		ID:        42,
+		Content:   &content,
+		Backward:  true,
+	}
+
+	decoded, err := codec.Decode(codec.Encode(cursor))
+
+	assert.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+	assert.Equal(t, content, *decoded.Content)
+	assert.True(t, decoded.Backward)
+}
+
+func TestCodec_RejectsTamperedCursor(t *testing.T) {
+	codec := NewCodec("secret")
+	encoded := codec.Encode(&models.Cursor{ID: 1})
+
+	forged := NewCodec("other").Encode(&models.Cursor{ID: 2})
+	payload, _, _ := strings.Cut(forged, ".")
+	_, signature, _ := strings.Cut(encoded, ".")
+
+	_, err := codec.Decode(payload + "." + signature)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = codec.Decode(forged)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = codec.Decode("not-a-cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -0,0 +1,337 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents to JSON documents.
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Error is returned for a patch that cannot be applied. TestFailed is set
+// when a JSON Patch test operation did not match the document, as opposed
+// to a malformed patch or an operation on a location that does not exist.
+type Error struct {
+	Message    string
+	TestFailed bool
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errorf(format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+// Merge applies a JSON Merge Patch to a JSON document. Members of the patch
+// replace those of the document, recursively for objects, and null members
+// remove them.
+func Merge(doc, patch []byte) ([]byte, error) {
+	var target, changes interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, errorf("merge patch is not valid JSON")
+	}
+	return json.Marshal(merge(target, changes))
+}
+
+func merge(target, changes interface{}) interface{} {
+	members, ok := changes.(map[string]interface{})
+	if !ok {
+		return changes
+	}
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{}, len(members))
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(object, name)
+		} else {
+			object[name] = merge(object[name], value)
+		}
+	}
+	return object
+}
+
+// operation is a JSON Patch operation. Value is kept raw so that a missing
+// value can be told apart from null.
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Apply applies a JSON Patch to a JSON document. The operations are applied
+// in order and the patch fails as a whole when any of them does.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	var operations []operation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, errorf("JSON patch must be an array of operations")
+	}
+
+	for i, op := range operations {
+		var err *Error
+		root, err = op.apply(root)
+		if err != nil {
+			err.Message = fmt.Sprintf("operation %d: %s", i, err.Message)
+			return nil, err
+		}
+	}
+	return json.Marshal(root)
+}
+
+// apply applies the operation to the document and returns the new document
+func (op *operation) apply(root interface{}) (interface{}, *Error) {
+	if op.Path == nil {
+		return nil, errorf("path is required")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, errorf("value is required for %s", op.Op)
+		}
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, errorf("value is not valid JSON")
+		}
+		switch op.Op {
+		case "add":
+			return add(root, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			if root, err = remove(root, path); err != nil {
+				return nil, err
+			}
+			return add(root, path, value)
+		default:
+			current, err := get(root, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, &Error{Message: fmt.Sprintf("test failed at %s", *op.Path), TestFailed: true}
+			}
+			return root, nil
+		}
+
+	case "remove":
+		return remove(root, path)
+
+	case "move", "copy":
+		if op.From == nil {
+			return nil, errorf("from is required for %s", op.Op)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(root, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, errorf("cannot move %s into one of its children", *op.From)
+			}
+			if root, err = remove(root, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+		return add(root, path, value)
+	}
+	return nil, errorf("unknown op %q", op.Op)
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens
+func parsePointer(pointer string) ([]string, *Error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errorf("path %q must be empty or start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// get returns the value at path
+func get(root interface{}, path []string) (interface{}, *Error) {
+	value := root
+	for _, token := range path {
+		switch container := value.(type) {
+		case map[string]interface{}:
+			member, ok := container[token]
+			if !ok {
+				return nil, errorf("%s does not exist", pointerString(path))
+			}
+			value = member
+		case []interface{}:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			value = container[index]
+		default:
+			return nil, errorf("%s does not exist", pointerString(path))
+		}
+	}
+	return value, nil
+}
+
+// add adds value at path, replacing an object member or inserting into an
+// array, and returns the new document
+func add(root interface{}, path []string, value interface{}) (interface{}, *Error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		container[last] = value
+		return root, nil
+	case []interface{}:
+		index := len(container)
+		if last != "-" {
+			if index, err = arrayIndex(last, len(container), true); err != nil {
+				return nil, err
+			}
+		}
+		container = append(container, nil)
+		copy(container[index+1:], container[index:])
+		container[index] = value
+		return setParent(root, path[:len(path)-1], container)
+	}
+	return nil, errorf("%s does not exist", pointerString(path[:len(path)-1]))
+}
+
+// remove removes the value at path and returns the new document
+func remove(root interface{}, path []string) (interface{}, *Error) {
+	if len(path) == 0 {
+		return nil, errorf("the whole document cannot be removed")
+	}
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := container[last]; !ok {
+			return nil, errorf("%s does not exist", pointerString(path))
+		}
+		delete(container, last)
+		return root, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(container), false)
+		if err != nil {
+			return nil, err
+		}
+		container = append(container[:index], container[index+1:]...)
+		return setParent(root, path[:len(path)-1], container)
+	}
+	return nil, errorf("%s does not exist", pointerString(path))
+}
+
+// setParent stores an array that was resized at path, since resizing may
+// have moved it
+func setParent(root interface{}, path []string, array []interface{}) (interface{}, *Error) {
+	if len(path) == 0 {
+		return array, nil
+	}
+	grandparent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch container := grandparent.(type) {
+	case map[string]interface{}:
+		container[last] = array
+	case []interface{}:
+		index, _ := strconv.Atoi(last)
+		container[index] = array
+	}
+	return root, nil
+}
+
+// arrayIndex parses an array index token. Adding may also target the
+// position just past the last element.
+func arrayIndex(token string, length int, adding bool) (int, *Error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, errorf("%q is not an array index", token)
+	}
+	if index > length || (index == length && !adding) {
+		return 0, errorf("array index %d is out of range", index)
+	}
+	return index, nil
+}
+
+// isPrefix reports whether prefix is a prefix of path
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// deepCopy copies a decoded JSON value so that copies can be changed
+// independently
+func deepCopy(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for name, member := range value {
+			copied[name] = deepCopy(member)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, element := range value {
+			copied[i] = deepCopy(element)
+		}
+		return copied
+	}
+	return value
+}
+
+// pointerString formats path tokens back into a JSON Pointer
+func pointerString(path []string) string {
+	var b strings.Builder
+	for _, token := range path {
+		b.WriteString("/")
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package realtime
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/services"
+)
+
+// Hub runs the sessions of the channel and tracks which connections view
+// which tasks. Presence is tracked per process: viewers connected to
+// another replica are not listed. It is safe for concurrent use.
+type Hub struct {
+	tasks     services.TaskService
+	projects  services.ProjectService
+	bus       *events.Bus
+	heartbeat time.Duration
+
+	mu      sync.Mutex
+	viewers map[uint]map[*session]struct{}
+}
+
+// NewHub creates a Hub whose sessions read task events from bus and ping
+// idle clients at the heartbeat interval of cfg
+func NewHub(tasks services.TaskService, projects services.ProjectService, bus *events.Bus, cfg *config.EventsConfig) *Hub {
+	return &Hub{
+		tasks:     tasks,
+		projects:  projects,
+		bus:       bus,
+		heartbeat: cfg.HeartbeatInterval,
+		viewers:   make(map[uint]map[*session]struct{}),
+	}
+}
+
+// Client describes who a session is opened for: the user making its
+// writes, the time zone due dates are read in and what the credential
+// allows
+type Client struct {
+	Actor     *models.Actor
+	Location  *time.Location
+	CanWrite  bool
+	CanDelete bool
+}
+
+// Serve runs a session on an upgraded connection until the client
+// disconnects or falls behind
+func (h *Hub) Serve(conn *websocket.Conn, client Client) {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	newSession(h, conn, client, hex.EncodeToString(b)).run()
+}
+
+// view adds a session to the viewers of a task, notifies the others and
+// returns them all
+func (h *Hub) view(s *session, taskID uint) []Viewer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.viewers[taskID] == nil {
+		h.viewers[taskID] = make(map[*session]struct{})
+	}
+	h.viewers[taskID][s] = struct{}{}
+	return h.announce(taskID, s)
+}
+
+// leave removes a session from the viewers of tasks and notifies the
+// remaining ones
+func (h *Hub) leave(s *session, taskIDs ...uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, taskID := range taskIDs {
+		if _, ok := h.viewers[taskID][s]; !ok {
+			continue
+		}
+		delete(h.viewers[taskID], s)
+		if len(h.viewers[taskID]) == 0 {
+			delete(h.viewers, taskID)
+			continue
+		}
+		h.announce(taskID, nil)
+	}
+}
+
+// announce sends the viewers of a task to each of them but skip, and
+// returns them. The caller must hold the lock.
+func (h *Hub) announce(taskID uint, skip *session) []Viewer {
+	viewers := make([]Viewer, 0, len(h.viewers[taskID]))
+	for s := range h.viewers[taskID] {
+		viewers = append(viewers, Viewer{ConnectionID: s.id, UserID: s.client.Actor.UserID})
+	}
+	slices.SortFunc(viewers, func(a, b Viewer) int {
+		return strings.Compare(a.ConnectionID, b.ConnectionID)
+	})
+	id := taskID
+	for s := range h.viewers[taskID] {
+		if s == skip {
+			continue
+		}
+		s.notify(Message{Type: TypePresence, TaskID: &id, Viewers: viewers})
+	}
+	return viewers
+}
@@ -0,0 +1,186 @@
+package realtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	"github.com/todo-api-go-sda/internal/services"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// testUserID is the user connected in these tests
+const testUserID = uint(1)
+
+// testChannel serves the channel to testUserID, who may create and update
+// but not delete tasks
+type testChannel struct {
+	*httptest.Server
+	hub   *Hub
+	tasks services.TaskService
+}
+
+func newTestChannel(t *testing.T) *testChannel {
+	bus := events.NewBus(10)
+	taskRepo := repository.NewMemoryTaskRepository()
+	tasks := services.NewTaskService(taskRepo, repository.NewMemoryProjectRepository(taskRepo), repository.NewMemoryTagRepository(taskRepo),
+		&config.TaskConfig{MaxSubtaskDepth: 2}, bus)
+	projects := services.NewProjectService(repository.NewMemoryProjectRepository(taskRepo))
+	hub := NewHub(tasks, projects, bus, &config.EventsConfig{HeartbeatInterval: time.Second})
+
+	upgrader := websocket.Upgrader{Subprotocols: []string{Protocol}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		hub.Serve(conn, Client{Actor: &models.Actor{UserID: testUserID}, Location: time.UTC, CanWrite: true})
+	}))
+	t.Cleanup(server.Close)
+	return &testChannel{Server: server, hub: hub, tasks: tasks}
+}
+
+// connect opens a connection and returns it with its ID
+func (c *testChannel) connect(t *testing.T) (*websocket.Conn, string) {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(c.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	welcome := receive(t, conn)
+	require.Equal(t, TypeWelcome, welcome.Type)
+	return conn, welcome.ConnectionID
+}
+
+// receive reads the next message of a connection
+func receive(t *testing.T, conn *websocket.Conn) Message {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var message Message
+	require.NoError(t, conn.ReadJSON(&message))
+	return message
+}
+
+// request sends a request and returns its reply, skipping other messages
+func request(t *testing.T, conn *websocket.Conn, req Request) Message {
+	t.Helper()
+	require.NoError(t, conn.WriteJSON(req))
+	for {
+		if message := receive(t, conn); message.ReplyTo == req.ID {
+			return message
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestChannel_SubscribeAndMutate(t *testing.T) {
+	channel := newTestChannel(t)
+	conn, _ := channel.connect(t)
+
+	reply := request(t, conn, Request{ID: "1", Type: TypeSubscribe, ProjectID: ptr(uint(0))})
+	assert.Equal(t, TypeReply, reply.Type)
+
+	reply = request(t, conn, Request{ID: "2", Type: TypeCreateTask, Task: &models.CreateTaskRequest{Content: "Draw the board"}})
+	require.Equal(t, TypeReply, reply.Type, reply.Error)
+	require.NotNil(t, reply.Task)
+	assert.Equal(t, "Draw the board", reply.Task.Content)
+	event := receive(t, conn)
+	assert.Equal(t, events.TaskCreated, event.Type)
+	assert.NotEmpty(t, event.EventID)
+	assert.Contains(t, string(event.Data), `"content":"Draw the board"`)
+
+	// Changes made elsewhere reach the subscribers too
+	content := "Draw the whole board"
+	_, err := channel.tasks.UpdateTask(&models.Actor{UserID: testUserID}, reply.Task.ID, &models.UpdateTaskRequest{Content: &content}, time.UTC, nil)
+	require.NoError(t, err)
+	event = receive(t, conn)
+	assert.Equal(t, events.TaskUpdated, event.Type)
+
+	reply = request(t, conn, Request{ID: "3", Type: TypeUpdateTask, TaskID: &reply.Task.ID, Version: ptr(uint(1)), Changes: &models.UpdateTaskRequest{Content: &content}})
+	assert.Equal(t, TypeError, reply.Type)
+	assert.Equal(t, apperrors.CodeVersionConflict, reply.Error.Code)
+	assert.NotNil(t, reply.Current)
+
+	reply = request(t, conn, Request{ID: "4", Type: TypeDeleteTask, TaskID: ptr(uint(1))})
+	assert.Equal(t, TypeError, reply.Type)
+	assert.Equal(t, apperrors.CodeForbidden, reply.Error.Code)
+
+	reply = request(t, conn, Request{ID: "5", Type: TypeCreateTask, Task: &models.CreateTaskRequest{Content: "x", Priority: "extreme"}})
+	assert.Equal(t, apperrors.CodeValidationError, reply.Error.Code)
+	assert.Equal(t, "priority must be one of none, low, medium, high, urgent", reply.Error.Message)
+
+	reply = request(t, conn, Request{ID: "6", Type: "shout"})
+	assert.Equal(t, apperrors.CodeValidationError, reply.Error.Code)
+
+	reply = request(t, conn, Request{ID: "7", Type: TypeSubscribe, TaskID: ptr(uint(99))})
+	assert.Equal(t, apperrors.CodeTaskNotFound, reply.Error.Code)
+}
+
+func TestChannel_TaskSubscriptions(t *testing.T) {
+	channel := newTestChannel(t)
+	actor := &models.Actor{UserID: testUserID}
+	watched, err := channel.tasks.CreateTask(actor, &models.CreateTaskRequest{Content: "Watched"}, time.UTC)
+	require.NoError(t, err)
+	other, err := channel.tasks.CreateTask(actor, &models.CreateTaskRequest{Content: "Other"}, time.UTC)
+	require.NoError(t, err)
+	conn, _ := channel.connect(t)
+
+	reply := request(t, conn, Request{ID: "1", Type: TypeSubscribe, TaskID: &watched.ID})
+	require.NotNil(t, reply.Task)
+	assert.Equal(t, "Watched", reply.Task.Content)
+
+	require.NoError(t, channel.tasks.DeleteTask(actor, other.ID, false, nil))
+	require.NoError(t, channel.tasks.DeleteTask(actor, watched.ID, false, nil))
+	event := receive(t, conn)
+	assert.Equal(t, events.TaskDeleted, event.Type)
+	assert.JSONEq(t, `{"id":1,"project_id":null}`, string(event.Data))
+}
+
+func TestChannel_Presence(t *testing.T) {
+	channel := newTestChannel(t)
+	task, err := channel.tasks.CreateTask(&models.Actor{UserID: testUserID}, &models.CreateTaskRequest{Content: "Shared"}, time.UTC)
+	require.NoError(t, err)
+	first, firstID := channel.connect(t)
+	second, secondID := channel.connect(t)
+
+	reply := request(t, first, Request{ID: "1", Type: TypeSubscribe, TaskID: &task.ID})
+	assert.Equal(t, []Viewer{{ConnectionID: firstID, UserID: testUserID}}, reply.Viewers)
+	reply = request(t, second, Request{ID: "1", Type: TypeSubscribe, TaskID: &task.ID})
+	assert.Len(t, reply.Viewers, 2)
+
+	presence := receive(t, first)
+	assert.Equal(t, TypePresence, presence.Type)
+	assert.Equal(t, task.ID, *presence.TaskID)
+	assert.ElementsMatch(t, []Viewer{{ConnectionID: firstID, UserID: testUserID}, {ConnectionID: secondID, UserID: testUserID}}, presence.Viewers)
+
+	second.Close()
+	presence = receive(t, first)
+	assert.Equal(t, TypePresence, presence.Type)
+	assert.Equal(t, []Viewer{{ConnectionID: firstID, UserID: testUserID}}, presence.Viewers)
+}
+
+func TestSession_DisconnectsSlowClients(t *testing.T) {
+	s := newSession(&Hub{}, nil, Client{Actor: &models.Actor{UserID: testUserID}}, "slow")
+
+	for i := 0; i < sendBuffer; i++ {
+		s.notify(Message{Type: events.TaskUpdated})
+	}
+	select {
+	case <-s.done:
+		t.Fatal("closed before the queue was full")
+	default:
+	}
+
+	s.notify(Message{Type: events.TaskUpdated})
+	<-s.done
+	assert.Equal(t, websocket.CloseTryAgainLater, s.closeCode)
+	assert.False(t, s.reply(Message{Type: TypeReply}), "replies are not queued once the session has ended")
+}
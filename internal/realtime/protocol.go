@@ -0,0 +1,81 @@
+// Package realtime runs the WebSocket channel of the board UI. Clients
+// subscribe to projects and tasks, receive their changes from the event bus,
+// see who else is viewing a task, and change tasks through the task service.
+package realtime
+
+import (
+	"encoding/json"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// Protocol is the WebSocket subprotocol of the channel
+const Protocol = "todo.v1"
+
+// Types of the requests clients send
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypeCreateTask  = "create_task"
+	TypeUpdateTask  = "update_task"
+	TypeDeleteTask  = "delete_task"
+	TypePing        = "ping"
+)
+
+// Types of the messages the server sends, along with the types of task
+// events
+const (
+	TypeWelcome  = "welcome"
+	TypeReply    = "reply"
+	TypeError    = "error"
+	TypePresence = "presence"
+)
+
+// Request is a message from a client. Its ID is echoed in the reply so that
+// clients can match them; the other fields depend on the type. A
+// subscription names either a task or a project, 0 meaning the inbox.
+type Request struct {
+	ID        string                    `json:"id"`
+	Type      string                    `json:"type"`
+	TaskID    *uint                     `json:"task_id,omitempty"`
+	ProjectID *uint                     `json:"project_id,omitempty"`
+	Version   *uint                     `json:"version,omitempty"`
+	Cascade   bool                      `json:"cascade,omitempty"`
+	Task      *models.CreateTaskRequest `json:"task,omitempty"`
+	Changes   *models.UpdateTaskRequest `json:"changes,omitempty"`
+}
+
+// Message is a message to a client: a reply to one of its requests, which
+// carries the request ID in ReplyTo, a task event or a presence update
+type Message struct {
+	Type         string                 `json:"type"`
+	ReplyTo      string                 `json:"reply_to,omitempty"`
+	ConnectionID string                 `json:"connection_id,omitempty"`
+	EventID      string                 `json:"event_id,omitempty"`
+	TaskID       *uint                  `json:"task_id,omitempty"`
+	Task         *models.TaskResponse   `json:"task,omitempty"`
+	Data         json.RawMessage        `json:"data,omitempty"`
+	Viewers      []Viewer               `json:"viewers,omitempty"`
+	Error        *apperrors.ErrorDetail `json:"error,omitempty"`
+	Current      interface{}            `json:"current,omitempty"`
+}
+
+// Viewer is a connection subscribed to a task
+type Viewer struct {
+	ConnectionID string `json:"connection_id"`
+	UserID       uint   `json:"user_id"`
+}
+
+// errorMessage is the reply to a request that failed
+func errorMessage(replyTo string, err error) Message {
+	_, detail := apperrors.Describe(err)
+	message := Message{Type: TypeError, ReplyTo: replyTo, Error: &detail}
+	if conflict, ok := err.(*apperrors.VersionConflictError); ok {
+		if task, ok := conflict.Current.(*models.Task); ok {
+			response := task.ToResponse()
+			message.Current = &response
+		}
+	}
+	return message
+}
@@ -0,0 +1,362 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/validation"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// sendBuffer is how many messages may wait for a client before it is
+// disconnected for falling behind
+const sendBuffer = 64
+
+// maxRequestSize is the largest request a client may send, in bytes
+const maxRequestSize = 64 << 10
+
+// maxSubscriptions is how many tasks and projects a session may subscribe
+// to
+const maxSubscriptions = 100
+
+// writeTimeout is how long a write to a client may take
+const writeTimeout = 10 * time.Second
+
+// session is one client's connection to the channel. Requests are handled
+// in order by the reading goroutine, whose replies wait for room in the
+// send queue, so a client that stops reading stops being read from. Task
+// events and presence updates cannot wait for a slow client: when they do
+// not fit in the queue, the client is disconnected and expected to
+// reconnect and subscribe again.
+type session struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	client Client
+	id     string
+	send   chan Message
+
+	closeOnce   sync.Once
+	done        chan struct{}
+	closeCode   int
+	closeReason string
+
+	mu       sync.Mutex
+	tasks    map[uint]struct{}
+	projects map[uint]struct{}
+}
+
+func newSession(hub *Hub, conn *websocket.Conn, client Client, id string) *session {
+	return &session{
+		hub:      hub,
+		conn:     conn,
+		client:   client,
+		id:       id,
+		send:     make(chan Message, sendBuffer),
+		done:     make(chan struct{}),
+		tasks:    make(map[uint]struct{}),
+		projects: make(map[uint]struct{}),
+	}
+}
+
+// run serves the session until the connection ends
+func (s *session) run() {
+	sub, _, _ := s.hub.bus.Subscribe(s.client.Actor.UserID, "")
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		s.write()
+	}()
+	go s.forward(sub)
+
+	s.reply(Message{Type: TypeWelcome, ConnectionID: s.id})
+	s.read()
+
+	s.close(websocket.CloseNormalClosure, "")
+	sub.Close()
+	s.mu.Lock()
+	viewed := make([]uint, 0, len(s.tasks))
+	for id := range s.tasks {
+		viewed = append(viewed, id)
+	}
+	s.mu.Unlock()
+	s.hub.leave(s, viewed...)
+	<-written
+}
+
+// close ends the session, telling the client why
+func (s *session) close(code int, reason string) {
+	s.closeOnce.Do(func() {
+		s.closeCode = code
+		s.closeReason = reason
+		close(s.done)
+	})
+}
+
+// read handles the client's requests until the connection fails or the
+// session ends
+func (s *session) read() {
+	wait := 2 * s.hub.heartbeat
+	s.conn.SetReadLimit(maxRequestSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(wait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wait))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = s.conn.SetReadDeadline(time.Now().Add(wait))
+
+		var req Request
+		if err := json.Unmarshal(data, &req); err != nil {
+			s.reply(errorMessage("", &apperrors.ValidationError{Message: "messages must be JSON objects"}))
+			continue
+		}
+		reply, err := s.handle(&req)
+		if err != nil {
+			reply = errorMessage(req.ID, err)
+		}
+		if !s.reply(reply) {
+			return
+		}
+	}
+}
+
+// write sends queued messages and pings to the client until the session
+// ends, then closes the connection
+func (s *session) write() {
+	ping := time.NewTicker(s.hub.heartbeat)
+	defer ping.Stop()
+	defer s.conn.Close()
+
+	for {
+		select {
+		case message := <-s.send:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := s.conn.WriteJSON(message); err != nil {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ping.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-s.done:
+			if s.closeCode != websocket.CloseAbnormalClosure {
+				message := websocket.FormatCloseMessage(s.closeCode, s.closeReason)
+				_ = s.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeTimeout))
+			}
+			return
+		}
+	}
+}
+
+// forward notifies the client of the events of the tasks and projects it
+// subscribes to
+func (s *session) forward(sub *events.Subscription) {
+	for {
+		select {
+		case <-s.done:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				s.close(websocket.CloseTryAgainLater, "falling behind; reconnect and subscribe again")
+				return
+			}
+			if s.follows(event) {
+				s.notify(Message{Type: event.Type, EventID: event.ID, Data: event.Data})
+			}
+		}
+	}
+}
+
+// follows reports whether the client subscribes to the task of an event or
+// to its project
+func (s *session) follows(event events.Event) bool {
+	var task struct {
+		ID        uint  `json:"id"`
+		ProjectID *uint `json:"project_id"`
+	}
+	if err := json.Unmarshal(event.Data, &task); err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tasks[task.ID]; ok {
+		return true
+	}
+	_, ok := s.projects[projectKey(task.ProjectID)]
+	return ok
+}
+
+// reply queues the reply to a request, waiting for room. It returns false
+// if the session has ended.
+func (s *session) reply(message Message) bool {
+	select {
+	case s.send <- message:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// notify queues a message the client did not ask for, ending the session
+// if the client has fallen too far behind to take it
+func (s *session) notify(message Message) {
+	select {
+	case s.send <- message:
+	default:
+		s.close(websocket.CloseTryAgainLater, "falling behind; reconnect and subscribe again")
+	}
+}
+
+// handle carries out a request and returns its reply
+func (s *session) handle(req *Request) (Message, error) {
+	reply := Message{Type: TypeReply, ReplyTo: req.ID}
+	switch req.Type {
+	case TypeSubscribe:
+		return s.subscribe(req, reply)
+	case TypeUnsubscribe:
+		return s.unsubscribe(req, reply)
+	case TypeCreateTask, TypeUpdateTask, TypeDeleteTask:
+		return s.mutate(req, reply)
+	case TypePing:
+		return reply, nil
+	}
+	return reply, &apperrors.ValidationError{Message: fmt.Sprintf("unknown message type %q", req.Type)}
+}
+
+// subscribe starts notifying the client of the changes to a task of the
+// user, whose viewers it joins, or to the tasks of one of their projects
+func (s *session) subscribe(req *Request, reply Message) (Message, error) {
+	ownerID := s.client.Actor.UserID
+	if err := checkTarget(req); err != nil {
+		return reply, err
+	}
+	s.mu.Lock()
+	full := len(s.tasks)+len(s.projects) >= maxSubscriptions
+	s.mu.Unlock()
+	if full {
+		return reply, &apperrors.ValidationError{Message: fmt.Sprintf("at most %d subscriptions are allowed per connection", maxSubscriptions)}
+	}
+
+	if req.ProjectID != nil {
+		if *req.ProjectID != 0 {
+			if _, err := s.hub.projects.GetProjectByID(ownerID, *req.ProjectID); err != nil {
+				return reply, err
+			}
+		}
+		s.mu.Lock()
+		s.projects[*req.ProjectID] = struct{}{}
+		s.mu.Unlock()
+		return reply, nil
+	}
+
+	task, err := s.hub.tasks.GetTaskByID(ownerID, *req.TaskID, false)
+	if err != nil {
+		return reply, err
+	}
+	s.mu.Lock()
+	s.tasks[task.ID] = struct{}{}
+	s.mu.Unlock()
+	response := task.ToResponse()
+	reply.Task = &response
+	reply.Viewers = s.hub.view(s, task.ID)
+	return reply, nil
+}
+
+// unsubscribe stops notifying the client of a task or project
+func (s *session) unsubscribe(req *Request, reply Message) (Message, error) {
+	if err := checkTarget(req); err != nil {
+		return reply, err
+	}
+	s.mu.Lock()
+	if req.ProjectID != nil {
+		delete(s.projects, *req.ProjectID)
+		s.mu.Unlock()
+		return reply, nil
+	}
+	delete(s.tasks, *req.TaskID)
+	s.mu.Unlock()
+	s.hub.leave(s, *req.TaskID)
+	return reply, nil
+}
+
+// mutate creates, updates or deletes a task of the user like the REST API,
+// with the same validation and scopes. Updates and deletions only apply at
+// the version given, if any.
+func (s *session) mutate(req *Request, reply Message) (Message, error) {
+	client := s.client
+	scope, allowed := auth.ScopeTasksWrite, client.CanWrite
+	if req.Type == TypeDeleteTask {
+		scope, allowed = auth.ScopeTasksDelete, client.CanDelete
+	}
+	if !allowed {
+		return reply, &apperrors.ForbiddenError{Message: "token is missing the " + scope + " scope"}
+	}
+	if req.Type != TypeCreateTask && req.TaskID == nil {
+		return reply, &apperrors.ValidationError{Message: "task_id is required"}
+	}
+	var ifMatch models.VersionCondition
+	if req.Version != nil {
+		ifMatch = models.VersionCondition{*req.Version}
+	}
+
+	var task *models.Task
+	var err error
+	switch req.Type {
+	case TypeCreateTask:
+		if req.Task == nil {
+			return reply, &apperrors.ValidationError{Message: "task is required"}
+		}
+		if err := validation.Validate(req.Task); err != nil {
+			return reply, err
+		}
+		task, err = s.hub.tasks.CreateTask(client.Actor, req.Task, client.Location)
+	case TypeUpdateTask:
+		if req.Changes == nil {
+			return reply, &apperrors.ValidationError{Message: "changes are required"}
+		}
+		if err := validation.Validate(req.Changes); err != nil {
+			return reply, err
+		}
+		task, err = s.hub.tasks.UpdateTask(client.Actor, *req.TaskID, req.Changes, client.Location, ifMatch)
+	default:
+		err = s.hub.tasks.DeleteTask(client.Actor, *req.TaskID, req.Cascade, ifMatch)
+	}
+	if err != nil {
+		return reply, err
+	}
+	if task != nil {
+		response := task.ToResponse()
+		reply.Task = &response
+	}
+	return reply, nil
+}
+
+// checkTarget validates that a subscription names either a task or a
+// project
+func checkTarget(req *Request) error {
+	if (req.TaskID == nil) == (req.ProjectID == nil) {
+		return &apperrors.ValidationError{Message: "either task_id or project_id is required"}
+	}
+	return nil
+}
+
+// projectKey identifies the project of a task in subscriptions, 0 meaning
+// the inbox
+func projectKey(projectID *uint) uint {
+	if projectID == nil {
+		return 0
+	}
+	return *projectID
+}
@@ -0,0 +1,346 @@
+// Package recurrence parses and expands the subset of RFC 5545 recurrence
+// rules supported for recurring tasks: FREQ (DAILY, WEEKLY, MONTHLY or
+// YEARLY), INTERVAL, BYDAY, COUNT and UNTIL.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base period of a recurrence rule
+type Frequency string
+
+// Supported frequencies
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Layouts of the UNTIL value, either a UTC date-time or a date
+const (
+	untilDateTimeLayout = "20060102T150405Z"
+	untilDateLayout     = "20060102"
+)
+
+// maxPeriods bounds the number of periods searched for the next occurrence
+// so that rules that rarely match (the 29th of February) still terminate
+const maxPeriods = 1000
+
+// weekdayNames are the RFC 5545 weekday codes, indexed by time.Weekday
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Day is a BYDAY entry. N selects the Nth such weekday of the month,
+// counting from the end when negative; 0 selects every such weekday.
+type Day struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// String returns the RFC 5545 form of the day
+func (d Day) String() string {
+	if d.N == 0 {
+		return weekdayNames[d.Weekday]
+	}
+	return strconv.Itoa(d.N) + weekdayNames[d.Weekday]
+}
+
+// Rule is a parsed recurrence rule. Until is set when the series ends at a
+// point in time; when UntilDate is set only its date is significant and it
+// is compared against the local date of the occurrences.
+type Rule struct {
+	Freq      Frequency
+	Interval  int
+	ByDay     []Day
+	Count     int
+	Until     *time.Time
+	UntilDate bool
+}
+
+// Parse parses a recurrence rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// A leading "RRULE:" is accepted; names and values are case-insensitive.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimSpace(value)
+	if prefix, rest, ok := strings.Cut(value, ":"); ok && strings.EqualFold(prefix, "RRULE") {
+		value = rest
+	}
+	if value == "" {
+		return nil, errors.New("rule is empty")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if !ok || name == "" || val == "" {
+			return nil, fmt.Errorf("malformed part %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is given more than once", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			err = rule.parseFreq(val)
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(name, val)
+		case "COUNT":
+			rule.Count, err = parsePositive(name, val)
+		case "BYDAY":
+			err = rule.parseByDay(val)
+		case "UNTIL":
+			err = rule.parseUntil(val)
+		default:
+			err = fmt.Errorf("%s is not supported", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("COUNT and UNTIL cannot be combined")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return nil, errors.New("BYDAY ordinals are only supported with FREQ=MONTHLY")
+		}
+	}
+	if len(rule.ByDay) > 0 && rule.Freq == Yearly {
+		return nil, errors.New("BYDAY is not supported with FREQ=YEARLY")
+	}
+	return rule, nil
+}
+
+func (r *Rule) parseFreq(value string) error {
+	switch freq := Frequency(value); freq {
+	case Daily, Weekly, Monthly, Yearly:
+		r.Freq = freq
+		return nil
+	}
+	return fmt.Errorf("FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY, not %s", value)
+}
+
+func (r *Rule) parseByDay(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 2 {
+			return fmt.Errorf("invalid BYDAY value %q", item)
+		}
+		code, ordinal := item[len(item)-2:], item[:len(item)-2]
+		weekday := slices.Index(weekdayNames, code)
+		if weekday < 0 {
+			return fmt.Errorf("invalid BYDAY value %q", item)
+		}
+		day := Day{Weekday: time.Weekday(weekday)}
+		if ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return fmt.Errorf("invalid BYDAY value %q", item)
+			}
+			day.N = n
+		}
+		if slices.Contains(r.ByDay, day) {
+			return fmt.Errorf("BYDAY value %q is given more than once", item)
+		}
+		r.ByDay = append(r.ByDay, day)
+	}
+	// Keep the days in week order, starting on Monday as RFC 5545 does by
+	// default, so that the canonical form is stable
+	slices.SortFunc(r.ByDay, func(a, b Day) int {
+		if a.Weekday != b.Weekday {
+			return weekdayOffset(a.Weekday) - weekdayOffset(b.Weekday)
+		}
+		return a.N - b.N
+	})
+	return nil
+}
+
+func (r *Rule) parseUntil(value string) error {
+	if t, err := time.Parse(untilDateTimeLayout, value); err == nil {
+		r.Until = &t
+		return nil
+	}
+	if t, err := time.Parse(untilDateLayout, value); err == nil {
+		r.Until = &t
+		r.UntilDate = true
+		return nil
+	}
+	return fmt.Errorf("UNTIL must be a date (YYYYMMDD) or a UTC date-time (YYYYMMDDTHHMMSSZ), not %s", value)
+}
+
+func parsePositive(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return n, nil
+}
+
+// String returns the canonical form of the rule
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		if r.UntilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(untilDateLayout))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilDateTimeLayout))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// Following returns up to n occurrences after start, which must itself be
+// the occurrence-th (counting from 1) occurrence of the series. Occurrences
+// keep the wall clock time of start in its location and stop at COUNT and
+// UNTIL, so fewer than n are returned when the series ends.
+func (r *Rule) Following(start time.Time, occurrence, n int) []time.Time {
+	result := make([]time.Time, 0, n)
+	for period := 0; period <= maxPeriods && len(result) < n; period++ {
+		for _, candidate := range r.period(start, period) {
+			if !candidate.After(start) {
+				continue
+			}
+			if r.Count > 0 && occurrence+len(result) >= r.Count {
+				return result
+			}
+			if r.ended(candidate) {
+				return result
+			}
+			result = append(result, candidate)
+			if len(result) == n {
+				return result
+			}
+		}
+	}
+	return result
+}
+
+// ended reports whether an occurrence falls after UNTIL
+func (r *Rule) ended(t time.Time) bool {
+	if r.Until == nil {
+		return false
+	}
+	if r.UntilDate {
+		return t.Format(untilDateLayout) > r.Until.Format(untilDateLayout)
+	}
+	return t.After(*r.Until)
+}
+
+// period returns the candidate occurrences, in order, of the index-th
+// period counted in intervals from the one containing start
+func (r *Rule) period(start time.Time, index int) []time.Time {
+	year, month, day := start.Date()
+	step := index * r.Interval
+	switch r.Freq {
+	case Daily:
+		t := at(start, year, month, day+step)
+		if len(r.ByDay) > 0 && !r.hasWeekday(t.Weekday()) {
+			return nil
+		}
+		return []time.Time{t}
+
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{at(start, year, month, day+7*step)}
+		}
+		monday := day - weekdayOffset(start.Weekday()) + 7*step
+		days := make([]time.Time, len(r.ByDay))
+		for i, byDay := range r.ByDay {
+			days[i] = at(start, year, month, monday+weekdayOffset(byDay.Weekday))
+		}
+		return days
+
+	case Monthly:
+		first := at(start, year, month+time.Month(step), 1)
+		if len(r.ByDay) == 0 {
+			return validDate(at(start, first.Year(), first.Month(), day), day)
+		}
+		return r.monthDays(start, first.Year(), first.Month())
+
+	case Yearly:
+		t := at(start, year+step, month, day)
+		if t.Month() != month {
+			// The 29th of February only occurs in leap years
+			return nil
+		}
+		return []time.Time{t}
+	}
+	return nil
+}
+
+// monthDays returns the days of the month matching BYDAY in order
+func (r *Rule) monthDays(start time.Time, year int, month time.Month) []time.Time {
+	last := at(start, year, month+1, 0).Day()
+	var days []time.Time
+	for d := 1; d <= last; d++ {
+		t := at(start, year, month, d)
+		for _, byDay := range r.ByDay {
+			if byDay.Weekday != t.Weekday() {
+				continue
+			}
+			nth := (d-1)/7 + 1
+			nthFromEnd := -((last-d)/7 + 1)
+			if byDay.N == 0 || byDay.N == nth || byDay.N == nthFromEnd {
+				days = append(days, t)
+				break
+			}
+		}
+	}
+	return days
+}
+
+func (r *Rule) hasWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// at returns the given date at the wall clock time of start, normalizing
+// out-of-range days the way time.Date does
+func at(start time.Time, year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+}
+
+// validDate drops dates that time.Date rolled over into the next month,
+// since RFC 5545 skips months without the requested day
+func validDate(t time.Time, day int) []time.Time {
+	if t.Day() != day {
+		return nil
+	}
+	return []time.Time{t}
+}
+
+// weekdayOffset returns the number of days from Monday to the weekday
+func weekdayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
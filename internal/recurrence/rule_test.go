@@ -0,0 +1,173 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Canonical(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;byday=we,mo;interval=2", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{"FREQ=WEEKLY;BYDAY=SU,MO", "FREQ=WEEKLY;BYDAY=MO,SU"},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=6", "FREQ=MONTHLY;BYDAY=-1FR;COUNT=6"},
+		{"FREQ=YEARLY;INTERVAL=1;UNTIL=20301231", "FREQ=YEARLY;UNTIL=20301231"},
+		{"FREQ=DAILY;UNTIL=20261231T120000Z", "FREQ=DAILY;UNTIL=20261231T120000Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rule, err := Parse(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule.String())
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":            "",
+		"missing freq":     "INTERVAL=2",
+		"unsupported freq": "FREQ=HOURLY",
+		"unknown part":     "FREQ=DAILY;BYMONTH=1",
+		"malformed part":   "FREQ=DAILY;COUNT",
+		"duplicate part":   "FREQ=DAILY;FREQ=WEEKLY",
+		"zero interval":    "FREQ=DAILY;INTERVAL=0",
+		"negative count":   "FREQ=DAILY;COUNT=-1",
+		"count and until":  "FREQ=DAILY;COUNT=3;UNTIL=20300101",
+		"bad until":        "FREQ=DAILY;UNTIL=2030-01-01",
+		"bad weekday":      "FREQ=WEEKLY;BYDAY=XX",
+		"duplicate day":    "FREQ=WEEKLY;BYDAY=MO,MO",
+		"weekly ordinal":   "FREQ=WEEKLY;BYDAY=1MO",
+		"ordinal range":    "FREQ=MONTHLY;BYDAY=6MO",
+		"yearly byday":     "FREQ=YEARLY;BYDAY=MO",
+	}
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRule_Following(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		rule       string
+		start      time.Time
+		occurrence int
+		n          int
+		want       []time.Time
+	}{
+		{
+			name:  "daily interval",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC),
+			n:     3,
+			want: []time.Time{
+				time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 5, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "daily on weekdays",
+			rule:  "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			start: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), // Friday
+			n:     2,
+			want: []time.Time{
+				time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "every other week on monday and wednesday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			start: time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), // Wednesday
+			n:     3,
+			want: []time.Time{
+				time.Date(2026, 10, 26, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 10, 28, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 9, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "weekly keeps the wall clock across daylight saving time",
+			rule:  "FREQ=WEEKLY",
+			start: time.Date(2026, 10, 20, 9, 0, 0, 0, paris),
+			n:     1,
+			want:  []time.Time{time.Date(2026, 10, 27, 9, 0, 0, 0, paris)},
+		},
+		{
+			name:  "monthly skips months without the day",
+			rule:  "FREQ=MONTHLY",
+			start: time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC),
+			n:     2,
+			want: []time.Time{
+				time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 5, 31, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "last friday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: time.Date(2026, 10, 30, 9, 0, 0, 0, time.UTC),
+			n:     2,
+			want: []time.Time{
+				time.Date(2026, 11, 27, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 12, 25, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "yearly on leap day",
+			rule:  "FREQ=YEARLY",
+			start: time.Date(2028, 2, 29, 9, 0, 0, 0, time.UTC),
+			n:     1,
+			want:  []time.Time{time.Date(2032, 2, 29, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:       "count counts the earlier occurrences",
+			rule:       "FREQ=DAILY;COUNT=3",
+			start:      time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC),
+			occurrence: 2,
+			n:          5,
+			want:       []time.Time{time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "until date includes the whole day",
+			rule:  "FREQ=DAILY;UNTIL=20261019",
+			start: time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC),
+			n:     5,
+			want: []time.Time{
+				time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC),
+				time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "until date-time",
+			rule:  "FREQ=DAILY;UNTIL=20261019T120000Z",
+			start: time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC),
+			n:     5,
+			want:  []time.Time{time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			require.NoError(t, err)
+			occurrence := tt.occurrence
+			if occurrence == 0 {
+				occurrence = 1
+			}
+			assert.Equal(t, tt.want, rule.Following(tt.start, occurrence, tt.n))
+		})
+	}
+}
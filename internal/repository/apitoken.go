@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ErrAPITokenNotFound is returned when no personal access token matches a hash
+var ErrAPITokenNotFound = errors.New("api token not found")
+
+// APITokenRepository defines the interface for personal access token data access
+type APITokenRepository interface {
+	Create(token *models.APIToken) error
+	FindByUser(userID uint) ([]models.APIToken, error)
+	FindByHash(hash string) (*models.APIToken, error)
+	TouchLastUsed(id uint, at time.Time) error
+	Delete(userID, id uint) error
+}
+
+// apiTokenRepository implements APITokenRepository using GORM
+type apiTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository creates a new APITokenRepository instance
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+// Create stores a new personal access token
+func (r *apiTokenRepository) Create(token *models.APIToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindByUser retrieves the user's tokens, newest first
+func (r *apiTokenRepository) FindByUser(userID uint) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// FindByHash retrieves the token stored under the hash
+func (r *apiTokenRepository) FindByHash(hash string) (*models.APIToken, error) {
+	var token models.APIToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPITokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// TouchLastUsed records when the token was last used
+func (r *apiTokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&models.APIToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// Delete revokes one of the user's tokens
+func (r *apiTokenRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.APIToken{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.TokenNotFoundError{ID: id}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// memoryAPITokenRepository implements APITokenRepository in process memory.
+// It is safe for concurrent use.
+type memoryAPITokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[uint]models.APIToken
+	byHash map[string]uint
+	nextID uint
+}
+
+// NewMemoryAPITokenRepository creates a new in-memory APITokenRepository instance
+func NewMemoryAPITokenRepository() APITokenRepository {
+	return &memoryAPITokenRepository{
+		tokens: make(map[uint]models.APIToken),
+		byHash: make(map[string]uint),
+		nextID: 1,
+	}
+}
+
+// Create stores a new personal access token, assigning its ID and timestamp
+func (r *memoryAPITokenRepository) Create(token *models.APIToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = r.nextID
+	r.nextID++
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	r.tokens[token.ID] = *token
+	r.byHash[token.TokenHash] = token.ID
+	return nil
+}
+
+// FindByUser retrieves the user's tokens, newest first
+func (r *memoryAPITokenRepository) FindByUser(userID uint) ([]models.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := []models.APIToken{}
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID > tokens[j].ID
+	})
+	return tokens, nil
+}
+
+// FindByHash retrieves the token stored under the hash
+func (r *memoryAPITokenRepository) FindByHash(hash string) (*models.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byHash[hash]
+	if !ok {
+		return nil, ErrAPITokenNotFound
+	}
+	token := r.tokens[id]
+	return &token, nil
+}
+
+// TouchLastUsed records when the token was last used
+func (r *memoryAPITokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return nil
+	}
+	token.LastUsedAt = &at
+	r.tokens[id] = token
+	return nil
+}
+
+// Delete revokes one of the user's tokens
+func (r *memoryAPITokenRepository) Delete(userID, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.UserID != userID {
+		return &apperrors.TokenNotFoundError{ID: id}
+	}
+	delete(r.tokens, id)
+	delete(r.byHash, token.TokenHash)
+	return nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestAPITokenRepository(t *testing.T) {
+	backends := map[string]func(t *testing.T) APITokenRepository{
+		"sqlite": func(t *testing.T) APITokenRepository {
+			return NewAPITokenRepository(setupTestDB(t))
+		},
+		"memory": func(t *testing.T) APITokenRepository {
+			return NewMemoryAPITokenRepository()
+		},
+	}
+	for name, newRepo := range backends {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			first := &models.APIToken{UserID: testOwnerID, Name: "first", Prefix: "todo_pat_aaaaaa", TokenHash: "hash-1", Scopes: "tasks:read"}
+			second := &models.APIToken{UserID: testOwnerID, Name: "second", Prefix: "todo_pat_bbbbbb", TokenHash: "hash-2", Scopes: "tasks:write"}
+			other := &models.APIToken{UserID: otherOwnerID, Name: "other", Prefix: "todo_pat_cccccc", TokenHash: "hash-3", Scopes: "tasks:read"}
+			for _, token := range []*models.APIToken{first, second, other} {
+				require.NoError(t, repo.Create(token))
+				assert.NotZero(t, token.ID)
+			}
+
+			tokens, err := repo.FindByUser(testOwnerID)
+			require.NoError(t, err)
+			require.Len(t, tokens, 2)
+			assert.Equal(t, "second", tokens[0].Name)
+
+			found, err := repo.FindByHash("hash-1")
+			require.NoError(t, err)
+			assert.Equal(t, first.ID, found.ID)
+			assert.Nil(t, found.LastUsedAt)
+
+			usedAt := time.Now().UTC().Truncate(time.Second)
+			require.NoError(t, repo.TouchLastUsed(first.ID, usedAt))
+			found, err = repo.FindByHash("hash-1")
+			require.NoError(t, err)
+			require.NotNil(t, found.LastUsedAt)
+			assert.True(t, usedAt.Equal(*found.LastUsedAt))
+
+			assert.IsType(t, &apperrors.TokenNotFoundError{}, repo.Delete(testOwnerID, other.ID))
+			require.NoError(t, repo.Delete(testOwnerID, first.ID))
+			_, err = repo.FindByHash("hash-1")
+			assert.ErrorIs(t, err, ErrAPITokenNotFound)
+		})
+	}
+}
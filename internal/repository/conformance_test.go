@@ -0,0 +1,782 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/database/migrations"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// repositoryFactory creates an empty TaskRepository for a single test
+type repositoryFactory func(t *testing.T) TaskRepository
+
+// conformanceBackends returns the TaskRepository implementations under test.
+// PostgreSQL is included when TEST_POSTGRES_DSN is set.
+func conformanceBackends() map[string]repositoryFactory {
+	backends := map[string]repositoryFactory{
+		"sqlite": func(t *testing.T) TaskRepository {
+			return NewTaskRepository(setupTestDB(t))
+		},
+		"memory": func(t *testing.T) TaskRepository {
+			return NewMemoryTaskRepository()
+		},
+	}
+	if dsn := os.Getenv("TEST_POSTGRES_DSN"); dsn != "" {
+		backends["postgres"] = func(t *testing.T) TaskRepository {
+			db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+			require.NoError(t, err)
+			migrator, err := migrations.New(db)
+			require.NoError(t, err)
+			require.NoError(t, migrator.Up())
+			require.NoError(t, db.Exec("DELETE FROM tasks").Error)
+			require.NoError(t, db.Exec("DELETE FROM users").Error)
+			seedOwners(t, db)
+			return NewTaskRepository(db)
+		}
+	}
+	return backends
+}
+
+func TestTaskRepositoryConformance(t *testing.T) {
+	for name, newRepo := range conformanceBackends() {
+		t.Run(name, func(t *testing.T) {
+			t.Run("CreateAndFindByID", func(t *testing.T) { testCreateAndFindByID(t, newRepo(t)) })
+			t.Run("FindByIDNotFound", func(t *testing.T) { testFindByIDNotFound(t, newRepo(t)) })
+			t.Run("Update", func(t *testing.T) { testUpdate(t, newRepo(t)) })
+			t.Run("Version", func(t *testing.T) { testVersion(t, newRepo(t)) })
+			t.Run("Delete", func(t *testing.T) { testDelete(t, newRepo(t)) })
+			t.Run("DeleteChecks", func(t *testing.T) { testDeleteChecks(t, newRepo(t)) })
+			t.Run("TrashAndRestore", func(t *testing.T) { testTrashAndRestore(t, newRepo(t)) })
+			t.Run("DeletePermanently", func(t *testing.T) { testDeletePermanently(t, newRepo(t)) })
+			t.Run("Purge", func(t *testing.T) { testPurge(t, newRepo(t)) })
+			t.Run("FindAllFiltered", func(t *testing.T) { testFindAllFiltered(t, newRepo(t)) })
+			t.Run("FindAllSorted", func(t *testing.T) { testFindAllSorted(t, newRepo(t)) })
+			t.Run("FindPage", func(t *testing.T) { testFindPage(t, newRepo(t)) })
+			t.Run("FindDue", func(t *testing.T) { testFindDue(t, newRepo(t)) })
+			t.Run("Subtasks", func(t *testing.T) { testSubtasks(t, newRepo(t)) })
+			t.Run("CompleteTree", func(t *testing.T) { testCompleteTree(t, newRepo(t)) })
+			t.Run("CompleteOccurrence", func(t *testing.T) { testCompleteOccurrence(t, newRepo(t)) })
+			t.Run("FindByIDs", func(t *testing.T) { testFindByIDs(t, newRepo(t)) })
+			t.Run("ApplyBatch", func(t *testing.T) { testApplyBatch(t, newRepo(t)) })
+			t.Run("ApplyBatchRollback", func(t *testing.T) { testApplyBatchRollback(t, newRepo(t)) })
+			t.Run("Count", func(t *testing.T) { testCount(t, newRepo(t)) })
+			t.Run("Search", func(t *testing.T) { testSearch(t, newRepo(t)) })
+			t.Run("OwnerIsolation", func(t *testing.T) { testOwnerIsolation(t, newRepo(t)) })
+			t.Run("History", func(t *testing.T) { testHistory(t, newRepo(t)) })
+			t.Run("RecentEvents", func(t *testing.T) { testRecentEvents(t, newRepo(t)) })
+		})
+	}
+}
+
+func TestMemoryTaskRepository_ConcurrentAccess(t *testing.T) {
+	repo := NewMemoryTaskRepository()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			task := &models.Task{OwnerID: testOwnerID, Content: fmt.Sprintf("Task %d", i)}
+			assert.NoError(t, repo.Create(task, nil))
+			task.Completed = true
+			assert.NoError(t, repo.Update(task, nil))
+			_, err := repo.FindAll(testOwnerID, nil)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	count, err := repo.Count(testOwnerID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), count)
+}
+
+func testCreateAndFindByID(t *testing.T, repo TaskRepository) {
+	task := &models.Task{OwnerID: testOwnerID, Content: "Test task"}
+	require.NoError(t, repo.Create(task, nil))
+	assert.NotZero(t, task.ID)
+	assert.False(t, task.CreatedAt.IsZero())
+
+	found, err := repo.FindByID(testOwnerID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Test task", found.Content)
+	assert.False(t, found.Completed)
+}
+
+func testFindByIDNotFound(t *testing.T, repo TaskRepository) {
+	task, err := repo.FindByID(testOwnerID, 999)
+
+	assert.Nil(t, task)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+}
+
+func testUpdate(t *testing.T, repo TaskRepository) {
+	task := &models.Task{OwnerID: testOwnerID, Content: "Old content"}
+	require.NoError(t, repo.Create(task, nil))
+
+	task.Content = "New content"
+	task.Completed = true
+	require.NoError(t, repo.Update(task, nil))
+
+	found, err := repo.FindByID(testOwnerID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "New content", found.Content)
+	assert.True(t, found.Completed)
+}
+
+func testVersion(t *testing.T, repo TaskRepository) {
+	task := &models.Task{OwnerID: testOwnerID, Content: "Versioned"}
+	require.NoError(t, repo.Create(task, nil))
+	assert.Equal(t, uint(1), task.Version)
+
+	stale, err := repo.FindByID(testOwnerID, task.ID)
+	require.NoError(t, err)
+	task.Content = "First write"
+	require.NoError(t, repo.Update(task, nil))
+	assert.Equal(t, uint(2), task.Version)
+
+	// A write based on the version read before the first write is rejected
+	stale.Content = "Second write"
+	assert.IsType(t, &apperrors.VersionConflictError{}, repo.Update(stale, nil))
+	assert.Equal(t, uint(1), stale.Version)
+	found, err := repo.FindByID(testOwnerID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First write", found.Content)
+	assert.Equal(t, uint(2), found.Version)
+
+	// Trashing and restoring are writes too
+	require.NoError(t, repo.Delete(task, true, nil))
+	require.NoError(t, repo.Restore(testOwnerID, task.ID, nil))
+	found, err = repo.FindByID(testOwnerID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), found.Version)
+	assert.IsType(t, &apperrors.VersionConflictError{}, repo.Update(task, nil))
+}
+
+func testDelete(t *testing.T, repo TaskRepository) {
+	task := &models.Task{OwnerID: testOwnerID, Content: "Task to delete"}
+	require.NoError(t, repo.Create(task, nil))
+
+	require.NoError(t, repo.Delete(task, true, nil))
+
+	_, err := repo.FindByID(testOwnerID, task.ID)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.Delete(task, true, nil))
+}
+
+func testDeleteChecks(t *testing.T, repo TaskRepository) {
+	tree := createTree(t, repo)
+	parent := tree[0]
+
+	assert.IsType(t, &apperrors.ConflictError{}, repo.Delete(parent, false, nil))
+
+	// A write since the task was read fails the deletion
+	stale := *parent
+	require.NoError(t, repo.Update(parent, nil))
+	assert.IsType(t, &apperrors.VersionConflictError{}, repo.Delete(&stale, true, nil))
+	found, err := repo.FindByID(testOwnerID, parent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, parent.Version, found.Version)
+
+	require.NoError(t, repo.Delete(parent, true, nil))
+	trash, err := repo.FindTrash(testOwnerID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Parent"}, contents(trash))
+}
+
+func testTrashAndRestore(t *testing.T, repo TaskRepository) {
+	tree := createTree(t, repo)
+	parent, first, nested := tree[0], tree[1], tree[3]
+
+	require.NoError(t, repo.Delete(nested, true, nil))
+	require.NoError(t, repo.Delete(first, true, nil))
+
+	// Nested is below trashed First, so only First is listed
+	trash, err := repo.FindTrash(testOwnerID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"First"}, contents(trash))
+	assert.True(t, trash[0].DeletedAt.Valid)
+
+	_, err = repo.FindByID(testOwnerID, first.ID)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+	trashed, err := repo.FindTrashed(testOwnerID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First", trashed.Content)
+	_, err = repo.FindTrashed(testOwnerID, parent.ID)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+	_, err = repo.FindTrashed(otherOwnerID, first.ID)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+
+	found, err := repo.FindByID(testOwnerID, parent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), found.SubtaskCount)
+	count, err := repo.Count(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.Update(first, nil))
+
+	// Nested was trashed on its own before First and stays in the trash
+	require.NoError(t, repo.Restore(testOwnerID, first.ID, nil))
+	trash, err = repo.FindTrash(testOwnerID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Nested"}, contents(trash))
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.Restore(testOwnerID, parent.ID, nil))
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.Restore(otherOwnerID, nested.ID, nil))
+	require.NoError(t, repo.Restore(testOwnerID, nested.ID, nil))
+
+	// A subtree trashed at once is restored at once
+	require.NoError(t, repo.Delete(parent, true, nil))
+	count, err = repo.Count(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+	require.NoError(t, repo.Restore(testOwnerID, parent.ID, nil))
+	count, err = repo.Count(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+	trash, err = repo.FindTrash(testOwnerID)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+}
+
+func testDeletePermanently(t *testing.T, repo TaskRepository) {
+	tree := createTree(t, repo)
+	parent := tree[0]
+
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.DeletePermanently(testOwnerID, parent.ID, nil))
+	require.NoError(t, repo.Delete(parent, true, nil))
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.DeletePermanently(otherOwnerID, parent.ID, nil))
+	require.NoError(t, repo.DeletePermanently(testOwnerID, parent.ID, &models.Actor{UserID: testOwnerID, RequestID: "req-1"}))
+
+	_, err := repo.FindTrashed(testOwnerID, parent.ID)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+	_, err = repo.FindTrashed(testOwnerID, tree[3].ID)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.Restore(testOwnerID, parent.ID, nil))
+
+	// The purge of the task and its subtasks is kept in their history
+	page, err := repo.FindEvents(testOwnerID, &models.TaskEventFilter{Types: []string{"purged"}}, &models.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Events, len(tree))
+	for _, event := range page.Events {
+		assert.Equal(t, uint(3), event.Version)
+		assert.Equal(t, "req-1", event.RequestID)
+	}
+}
+
+func testPurge(t *testing.T, repo TaskRepository) {
+	trashed := &models.Task{OwnerID: testOwnerID, Content: "Trashed"}
+	require.NoError(t, repo.Create(trashed, nil))
+	kept := &models.Task{OwnerID: testOwnerID, Content: "Kept"}
+	require.NoError(t, repo.Create(kept, nil))
+	require.NoError(t, repo.Delete(trashed, true, nil))
+
+	purged, err := repo.Purge(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, purged)
+
+	purged, err = repo.Purge(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, purged, 1)
+	assert.Equal(t, trashed.ID, purged[0].ID)
+	_, err = repo.FindTrashed(testOwnerID, trashed.ID)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+	_, err = repo.FindByID(testOwnerID, kept.ID)
+	assert.NoError(t, err)
+
+	event, err := repo.FindEvent(testOwnerID, trashed.ID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "purged", event.Type)
+	assert.Equal(t, testOwnerID, event.ActorID)
+}
+
+func testFindAllFiltered(t *testing.T, repo TaskRepository) {
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Buy milk", Completed: true}, nil))
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Buy bread"}, nil))
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Walk the dog", CreatedAt: old, UpdatedAt: old}, nil))
+
+	completed := false
+	tasks, err := repo.FindAll(testOwnerID, &models.TaskFilter{Completed: &completed, ContentContains: "BUY"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Buy bread"}, contents(tasks))
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	tasks, err = repo.FindAll(testOwnerID, &models.TaskFilter{CreatedAfter: &cutoff})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	tasks, err = repo.FindAll(testOwnerID, &models.TaskFilter{UpdatedBefore: &cutoff})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Walk the dog"}, contents(tasks))
+}
+
+func testFindAllSorted(t *testing.T, repo TaskRepository) {
+	for _, content := range []string{"b", "c", "a"} {
+		require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: content}, nil))
+	}
+
+	tasks, err := repo.FindAll(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c", "b"}, contents(tasks))
+
+	tasks, err = repo.FindAll(testOwnerID, &models.TaskFilter{Sort: []models.SortField{{Field: "content"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, contents(tasks))
+
+	tasks, err = repo.FindAll(testOwnerID, &models.TaskFilter{Sort: []models.SortField{{Field: "content", Desc: true}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "b", "a"}, contents(tasks))
+}
+
+func testFindDue(t *testing.T, repo TaskRepository) {
+	now := time.Now().UTC().Truncate(time.Second)
+	yesterday, tomorrow := now.Add(-24*time.Hour), now.Add(24*time.Hour)
+	tasks := []*models.Task{
+		{OwnerID: testOwnerID, Content: "Tomorrow", DueAt: &tomorrow},
+		{OwnerID: testOwnerID, Content: "Yesterday low", DueAt: &yesterday, Priority: models.PriorityLow},
+		{OwnerID: testOwnerID, Content: "Yesterday urgent", DueAt: &yesterday, Priority: models.PriorityUrgent},
+		{OwnerID: testOwnerID, Content: "Yesterday done", DueAt: &yesterday, Completed: true},
+		{OwnerID: testOwnerID, Content: "No due date"},
+	}
+	for _, task := range tasks {
+		require.NoError(t, repo.Create(task, nil))
+	}
+
+	due, err := repo.FindDue(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Yesterday urgent", "Yesterday low", "Yesterday done", "Tomorrow"}, contents(due))
+
+	completed := false
+	due, err = repo.FindDue(testOwnerID, &models.TaskFilter{Completed: &completed, DueBefore: &now})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Yesterday urgent", "Yesterday low"}, contents(due))
+
+	due, err = repo.FindDue(testOwnerID, &models.TaskFilter{DueFrom: &tomorrow})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Tomorrow"}, contents(due))
+
+	found, err := repo.FindByID(testOwnerID, tasks[0].ID)
+	require.NoError(t, err)
+	assert.True(t, found.DueAt.Equal(tomorrow))
+}
+
+// createTree creates a parent task with two subtasks, the first of which has
+// a subtask of its own, and returns them in that order
+func createTree(t *testing.T, repo TaskRepository) []*models.Task {
+	parent := &models.Task{OwnerID: testOwnerID, Content: "Parent"}
+	require.NoError(t, repo.Create(parent, nil))
+	first := &models.Task{OwnerID: testOwnerID, Content: "First", ParentID: &parent.ID}
+	require.NoError(t, repo.Create(first, nil))
+	second := &models.Task{OwnerID: testOwnerID, Content: "Second", ParentID: &parent.ID, Completed: true}
+	require.NoError(t, repo.Create(second, nil))
+	nested := &models.Task{OwnerID: testOwnerID, Content: "Nested", ParentID: &first.ID}
+	require.NoError(t, repo.Create(nested, nil))
+	return []*models.Task{parent, first, second, nested}
+}
+
+func testSubtasks(t *testing.T, repo TaskRepository) {
+	tree := createTree(t, repo)
+	parent := tree[0]
+
+	found, err := repo.FindByID(testOwnerID, parent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), found.SubtaskCount)
+	assert.Equal(t, int64(1), found.CompletedSubtaskCount)
+
+	descendants, err := repo.FindDescendants(testOwnerID, parent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"First", "Second", "Nested"}, contents(descendants))
+	assert.Equal(t, int64(1), descendants[0].SubtaskCount)
+
+	topLevel := uint(0)
+	roots, err := repo.FindAll(testOwnerID, &models.TaskFilter{ParentID: &topLevel})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Parent"}, contents(roots))
+	assert.Equal(t, int64(2), roots[0].SubtaskCount)
+
+	children, err := repo.FindAll(testOwnerID, &models.TaskFilter{ParentID: &parent.ID, Sort: []models.SortField{{Field: "content"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"First", "Second"}, contents(children))
+
+	children, err = repo.FindAll(testOwnerID, &models.TaskFilter{ParentIDs: []uint{parent.ID, tree[1].ID}, Sort: []models.SortField{{Field: "content"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"First", "Nested", "Second"}, contents(children))
+
+	require.NoError(t, repo.Delete(parent, true, nil))
+	count, err := repo.Count(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func testCompleteTree(t *testing.T, repo TaskRepository) {
+	tree := createTree(t, repo)
+	before, err := repo.FindByID(testOwnerID, tree[2].ID)
+	require.NoError(t, err)
+
+	first := tree[1]
+	completedAt := time.Now().UTC().Truncate(time.Second)
+	first.Completed, first.CompletedAt = true, &completedAt
+	require.NoError(t, repo.Complete(first, true, nil, nil))
+
+	for _, task := range tree[1:] {
+		found, err := repo.FindByID(testOwnerID, task.ID)
+		require.NoError(t, err)
+		assert.True(t, found.Completed, task.Content)
+	}
+	nested, err := repo.FindByID(testOwnerID, tree[3].ID)
+	require.NoError(t, err)
+	assert.True(t, nested.CompletedAt.Equal(completedAt))
+	second, err := repo.FindByID(testOwnerID, tree[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, before.CompletedAt, second.CompletedAt)
+
+	parent, err := repo.FindByID(testOwnerID, tree[0].ID)
+	require.NoError(t, err)
+	assert.False(t, parent.Completed)
+	assert.Equal(t, int64(2), parent.CompletedSubtaskCount)
+}
+
+func testCompleteOccurrence(t *testing.T, repo TaskRepository) {
+	dueAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	first := &models.Task{OwnerID: testOwnerID, Content: "Standup", DueAt: &dueAt, Recurrence: "FREQ=DAILY", Occurrence: 1}
+	require.NoError(t, repo.Create(first, nil))
+	first.SeriesID = &first.ID
+	require.NoError(t, repo.Update(first, nil))
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Unrelated"}, nil))
+
+	completedAt := time.Now().UTC().Truncate(time.Second)
+	nextDueAt := dueAt.AddDate(0, 0, 1)
+	first.Completed, first.CompletedAt, first.Recurrence = true, &completedAt, ""
+	next := &models.Task{
+		OwnerID: testOwnerID, Content: "Standup", DueAt: &nextDueAt,
+		Recurrence: "FREQ=DAILY", SeriesID: first.SeriesID, Occurrence: 2,
+	}
+	require.NoError(t, repo.Complete(first, false, next, nil))
+	require.NotZero(t, next.ID)
+
+	series, err := repo.FindAll(testOwnerID, &models.TaskFilter{
+		SeriesID: first.SeriesID,
+		Sort:     []models.SortField{{Field: "created_at"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	assert.True(t, series[0].Completed)
+	assert.Empty(t, series[0].Recurrence)
+	assert.False(t, series[1].Completed)
+	assert.Equal(t, "FREQ=DAILY", series[1].Recurrence)
+	assert.Equal(t, 2, series[1].Occurrence)
+	assert.True(t, series[1].DueAt.Equal(nextDueAt))
+
+	missing := &models.Task{ID: 999, OwnerID: testOwnerID, Content: "Missing"}
+	orphan := &models.Task{OwnerID: testOwnerID, Content: "Orphan"}
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.Complete(missing, false, orphan, nil))
+	count, err := repo.Count(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func testFindByIDs(t *testing.T, repo TaskRepository) {
+	tree := createTree(t, repo)
+	other := &models.Task{OwnerID: otherOwnerID, Content: "Other"}
+	require.NoError(t, repo.Create(other, nil))
+	require.NoError(t, repo.Delete(tree[3], true, nil))
+
+	found, err := repo.FindByIDs(testOwnerID, []uint{tree[2].ID, tree[0].ID, tree[3].ID, other.ID, 999})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Parent", "Second"}, contents(found))
+	assert.Equal(t, int64(2), found[0].SubtaskCount)
+}
+
+func testApplyBatch(t *testing.T, repo TaskRepository) {
+	tree := createTree(t, repo)
+	parent, first, second := tree[0], tree[1], tree[2]
+	plain := &models.Task{OwnerID: testOwnerID, Content: "Plain"}
+	require.NoError(t, repo.Create(plain, nil))
+	done := &models.Task{OwnerID: testOwnerID, Content: "Done"}
+	require.NoError(t, repo.Create(done, nil))
+
+	completedAt := time.Now().UTC().Truncate(time.Second)
+	dueAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	created := &models.Task{OwnerID: testOwnerID, Content: "Created"}
+	recurring := &models.Task{OwnerID: testOwnerID, Content: "Recurring", DueAt: &dueAt, Recurrence: "FREQ=DAILY", Occurrence: 1}
+	first.Content = "First renamed"
+	done.Completed, done.CompletedAt = true, &completedAt
+	require.NoError(t, repo.ApplyBatch(&models.TaskBatch{
+		OwnerID:     testOwnerID,
+		Creates:     []*models.Task{created, recurring},
+		Updates:     []models.TaskUpdate{{Task: first}, {Task: done}},
+		Completions: []uint{plain.ID, second.ID},
+		CompletedAt: completedAt,
+		Deletes:     []uint{parent.ID},
+	}))
+
+	require.NotZero(t, created.ID)
+	assert.Nil(t, created.SeriesID)
+	require.NotNil(t, recurring.SeriesID)
+	assert.Equal(t, recurring.ID, *recurring.SeriesID)
+	found, err := repo.FindByID(testOwnerID, recurring.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.SeriesID)
+	assert.Equal(t, recurring.ID, *found.SeriesID)
+
+	found, err = repo.FindByID(testOwnerID, plain.ID)
+	require.NoError(t, err)
+	assert.True(t, found.Completed)
+	assert.True(t, found.CompletedAt.Equal(completedAt))
+	assert.Equal(t, uint(2), found.Version)
+	found, err = repo.FindByID(testOwnerID, done.ID)
+	require.NoError(t, err)
+	assert.True(t, found.Completed)
+
+	// The deleted parent took its subtasks, including the updated one, along
+	remaining, err := repo.FindAll(testOwnerID, &models.TaskFilter{Sort: []models.SortField{{Field: "content"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Created", "Done", "Plain", "Recurring"}, contents(remaining))
+	trashed, err := repo.FindTrashed(testOwnerID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First renamed", trashed.Content)
+}
+
+func testApplyBatchRollback(t *testing.T, repo TaskRepository) {
+	task := &models.Task{OwnerID: testOwnerID, Content: "Task"}
+	require.NoError(t, repo.Create(task, nil))
+	stale := *task
+	require.NoError(t, repo.Update(task, nil))
+	doomed := &models.Task{OwnerID: testOwnerID, Content: "Doomed"}
+	require.NoError(t, repo.Create(doomed, nil))
+
+	created := &models.Task{OwnerID: testOwnerID, Content: "Created"}
+	err := repo.ApplyBatch(&models.TaskBatch{
+		OwnerID: testOwnerID,
+		Creates: []*models.Task{created},
+		Deletes: []uint{doomed.ID, 999},
+	})
+	assert.Equal(t, &apperrors.TaskNotFoundError{ID: 999}, err)
+
+	stale.Content = "Stale"
+	err = repo.ApplyBatch(&models.TaskBatch{
+		OwnerID: testOwnerID,
+		Updates: []models.TaskUpdate{{Task: &stale}},
+		Deletes: []uint{doomed.ID},
+	})
+	assert.IsType(t, &apperrors.VersionConflictError{}, err)
+
+	remaining, err := repo.FindAll(testOwnerID, &models.TaskFilter{Sort: []models.SortField{{Field: "content"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Doomed", "Task"}, contents(remaining))
+	assert.Equal(t, uint(2), remaining[1].Version)
+}
+
+func testFindPage(t *testing.T, repo TaskRepository) {
+	createdAt := time.Now().Truncate(time.Second)
+	for _, content := range []string{"1", "2", "3", "4", "5"} {
+		require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: content, CreatedAt: createdAt}, nil))
+	}
+
+	first, err := repo.FindPage(testOwnerID, nil, &models.PageRequest{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5", "4"}, contents(first.Tasks))
+	assert.Nil(t, first.PrevCursor)
+	require.NotNil(t, first.NextCursor)
+
+	second, err := repo.FindPage(testOwnerID, nil, &models.PageRequest{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "2"}, contents(second.Tasks))
+	require.NotNil(t, second.PrevCursor)
+	require.NotNil(t, second.NextCursor)
+
+	last, err := repo.FindPage(testOwnerID, nil, &models.PageRequest{Limit: 2, Cursor: second.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, contents(last.Tasks))
+	assert.Nil(t, last.NextCursor)
+
+	back, err := repo.FindPage(testOwnerID, nil, &models.PageRequest{Limit: 2, Cursor: second.PrevCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5", "4"}, contents(back.Tasks))
+	assert.Nil(t, back.PrevCursor)
+
+	sorted := &models.TaskFilter{Sort: []models.SortField{{Field: "content", Desc: true}}}
+	page, err := repo.FindPage(testOwnerID, sorted, &models.PageRequest{Limit: 3})
+	require.NoError(t, err)
+	require.NotNil(t, page.NextCursor)
+	page, err = repo.FindPage(testOwnerID, sorted, &models.PageRequest{Limit: 3, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "1"}, contents(page.Tasks))
+
+	_, err = repo.FindPage(testOwnerID, sorted, &models.PageRequest{Limit: 3, Cursor: &models.Cursor{ID: 1}})
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+}
+
+func testCount(t *testing.T, repo TaskRepository) {
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Buy milk", Completed: true}, nil))
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Buy bread"}, nil))
+
+	count, err := repo.Count(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	completed := true
+	count, err = repo.Count(testOwnerID, &models.TaskFilter{Completed: &completed})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func testSearch(t *testing.T, repo TaskRepository) {
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Buy milk"}, nil))
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Milk the cow, then buy more milk"}, nil))
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Walk the dog"}, nil))
+
+	results, err := repo.Search(testOwnerID, "milk")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Milk the cow, then buy more milk", results[0].Content)
+	assert.Contains(t, results[0].Snippet, "<mark>")
+	assert.GreaterOrEqual(t, results[0].Rank, results[1].Rank)
+
+	results, err = repo.Search(testOwnerID, "buy milk")
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	results, err = repo.Search(testOwnerID, "cat")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Snippets are HTML, so the content in them is escaped
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: `<script>alert("pwned")</script> feed the goldfish`}, nil))
+	results, err = repo.Search(testOwnerID, "goldfish")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.NotContains(t, results[0].Snippet, "<script>")
+	assert.Contains(t, results[0].Snippet, "&lt;script&gt;")
+	assert.Contains(t, results[0].Snippet, "<mark>goldfish</mark>")
+}
+
+func testOwnerIsolation(t *testing.T, repo TaskRepository) {
+	task := &models.Task{OwnerID: otherOwnerID, Content: "Private milk"}
+	require.NoError(t, repo.Create(task, nil))
+	require.NoError(t, repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Buy milk"}, nil))
+
+	_, err := repo.FindByID(testOwnerID, task.ID)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.Delete(&models.Task{ID: task.ID, OwnerID: testOwnerID, Version: task.Version}, true, nil))
+
+	hijacked := *task
+	hijacked.OwnerID = testOwnerID
+	hijacked.Content = "Hijacked"
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, repo.Update(&hijacked, nil))
+
+	tasks, err := repo.FindAll(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Buy milk"}, contents(tasks))
+
+	count, err := repo.Count(otherOwnerID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	results, err := repo.Search(testOwnerID, "milk")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Buy milk", results[0].Content)
+
+	found, err := repo.FindByID(otherOwnerID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Private milk", found.Content)
+}
+
+func testHistory(t *testing.T, repo TaskRepository) {
+	tokenID := uint(3)
+	actor := &models.Actor{UserID: testOwnerID, TokenID: &tokenID, RequestID: "req-1"}
+	task := &models.Task{OwnerID: testOwnerID, Content: "Draft", Priority: models.PriorityHigh}
+	require.NoError(t, repo.Create(task, actor))
+	task.Content = "Final"
+	require.NoError(t, repo.Update(task, nil))
+	task.Completed = true
+	require.NoError(t, repo.Complete(task, false, nil, nil))
+	require.NoError(t, repo.Delete(task, true, nil))
+	require.NoError(t, repo.Restore(testOwnerID, task.ID, nil))
+	require.NoError(t, repo.Create(&models.Task{OwnerID: otherOwnerID, Content: "Private"}, nil))
+
+	page, err := repo.FindEvents(testOwnerID, nil, &models.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 5)
+	assert.Nil(t, page.NextCursor)
+	types := make([]string, len(page.Events))
+	for i, event := range page.Events {
+		types[i] = event.Type
+		assert.Equal(t, task.ID, event.TaskID)
+		assert.Equal(t, uint(5-i), event.Version)
+	}
+	assert.Equal(t, []string{"restored", "deleted", "completed", "updated", "created"}, types)
+
+	created := page.Events[4]
+	assert.Equal(t, testOwnerID, created.ActorID)
+	assert.Equal(t, &tokenID, created.TokenID)
+	assert.Equal(t, "req-1", created.RequestID)
+	assert.JSONEq(t, "{}", created.Changes)
+	doc, err := created.Document()
+	require.NoError(t, err)
+	assert.Equal(t, "Draft", doc.Content)
+	assert.Equal(t, "high", doc.Priority)
+
+	updated := page.Events[3]
+	assert.Equal(t, testOwnerID, updated.ActorID)
+	assert.Nil(t, updated.TokenID)
+	assert.JSONEq(t, `{"content": {"from": "Draft", "to": "Final"}}`, updated.Changes)
+	assert.JSONEq(t, `{"completed": {"from": false, "to": true}}`, page.Events[2].Changes)
+
+	// Pages continue after the cursor
+	first, err := repo.FindEvents(testOwnerID, nil, &models.PageRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Events, 2)
+	require.NotNil(t, first.NextCursor)
+	second, err := repo.FindEvents(testOwnerID, nil, &models.PageRequest{Limit: 10, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Len(t, second.Events, 3)
+	assert.Equal(t, "completed", second.Events[0].Type)
+
+	filtered, err := repo.FindEvents(testOwnerID, &models.TaskEventFilter{Types: []string{"created", "deleted"}}, &models.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, filtered.Events, 2)
+	filtered, err = repo.FindEvents(testOwnerID, &models.TaskEventFilter{TokenID: &tokenID, RequestID: "req-1"}, &models.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, filtered.Events, 1)
+
+	event, err := repo.FindEvent(testOwnerID, task.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", event.Type)
+	_, err = repo.FindEvent(testOwnerID, task.ID, 9)
+	assert.IsType(t, &apperrors.VersionNotFoundError{}, err)
+	_, err = repo.FindEvent(otherOwnerID, task.ID, 2)
+	assert.IsType(t, &apperrors.VersionNotFoundError{}, err)
+}
+
+func testRecentEvents(t *testing.T, repo TaskRepository) {
+	first := &models.Task{OwnerID: testOwnerID, Content: "First"}
+	require.NoError(t, repo.Create(first, nil))
+	second := &models.Task{OwnerID: testOwnerID, Content: "Second"}
+	require.NoError(t, repo.Create(second, nil))
+	for i := 0; i < 3; i++ {
+		first.Content = fmt.Sprintf("First %d", i)
+		require.NoError(t, repo.Update(first, nil))
+	}
+	other := &models.Task{OwnerID: otherOwnerID, Content: "Other"}
+	require.NoError(t, repo.Create(other, nil))
+
+	events, err := repo.FindRecentEvents(testOwnerID, []uint{first.ID, second.ID, other.ID}, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, first.ID, events[0].TaskID)
+	assert.Equal(t, uint(4), events[0].Version)
+	assert.Equal(t, first.ID, events[1].TaskID)
+	assert.Equal(t, uint(3), events[1].Version)
+	assert.Equal(t, second.ID, events[2].TaskID)
+}
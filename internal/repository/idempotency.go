@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxClaimAttempts bounds how often Claim retries when the key it found
+// taken is deleted before it could be read
+const maxClaimAttempts = 3
+
+// IdempotencyRepository defines the interface for Idempotency-Key data access
+type IdempotencyRepository interface {
+	// Claim stores key, locking it for its request, unless the user already
+	// holds the key. A key that has expired, or whose request is still in
+	// flight past its lock, is taken over. Otherwise the key that is held
+	// is returned and nothing is stored.
+	Claim(key *models.IdempotencyKey, now time.Time) (*models.IdempotencyKey, error)
+	// Complete stores the response of the request that claimed the key
+	Complete(key *models.IdempotencyKey) error
+	// Release deletes a key whose request is still in flight, so that a
+	// retry runs the request again
+	Release(userID uint, key string) error
+	// Purge deletes the keys that expired before the given time and
+	// returns how many were deleted
+	Purge(before time.Time) (int64, error)
+}
+
+// idempotencyRepository implements IdempotencyRepository using GORM
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new IdempotencyRepository instance
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Claim stores key unless it is held. Inserting relies on the primary key,
+// so of two concurrent claims only one succeeds.
+func (r *idempotencyRepository) Claim(key *models.IdempotencyKey, now time.Time) (*models.IdempotencyKey, error) {
+	for attempt := 0; attempt < maxClaimAttempts; attempt++ {
+		result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil, nil
+		}
+
+		// Take over a stale key, checking it is still stale as part of the
+		// update so that only one claim can take it over
+		result = r.db.Model(&models.IdempotencyKey{}).
+			Where("user_id = ? AND idempotency_key = ?", key.UserID, key.Key).
+			Where("expires_at <= ? OR (status = 0 AND locked_until <= ?)", now, now).
+			Select("fingerprint", "status", "headers", "body", "locked_until", "expires_at", "created_at").
+			Updates(&models.IdempotencyKey{
+				Fingerprint: key.Fingerprint,
+				LockedUntil: key.LockedUntil,
+				ExpiresAt:   key.ExpiresAt,
+				CreatedAt:   now,
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil, nil
+		}
+
+		var held models.IdempotencyKey
+		err := r.db.Where("user_id = ? AND idempotency_key = ?", key.UserID, key.Key).First(&held).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Purged or released in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &held, nil
+	}
+	return nil, errors.New("idempotency key is contended")
+}
+
+// Complete stores the response of the request that claimed the key
+func (r *idempotencyRepository) Complete(key *models.IdempotencyKey) error {
+	return r.db.Model(&models.IdempotencyKey{}).
+		Where("user_id = ? AND idempotency_key = ? AND fingerprint = ? AND status = 0", key.UserID, key.Key, key.Fingerprint).
+		Updates(map[string]interface{}{"status": key.Status, "headers": key.Headers, "body": key.Body}).Error
+}
+
+// Release deletes a key whose request is still in flight
+func (r *idempotencyRepository) Release(userID uint, key string) error {
+	return r.db.Where("user_id = ? AND idempotency_key = ? AND status = 0", userID, key).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+// Purge deletes the keys that expired before the given time
+func (r *idempotencyRepository) Purge(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", before).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// idempotencyKeyID identifies an Idempotency-Key, which is scoped to its user
+type idempotencyKeyID struct {
+	userID uint
+	key    string
+}
+
+// memoryIdempotencyRepository implements IdempotencyRepository in process
+// memory. It is safe for concurrent use.
+type memoryIdempotencyRepository struct {
+	mu   sync.Mutex
+	keys map[idempotencyKeyID]models.IdempotencyKey
+}
+
+// NewMemoryIdempotencyRepository creates a new in-memory IdempotencyRepository instance
+func NewMemoryIdempotencyRepository() IdempotencyRepository {
+	return &memoryIdempotencyRepository{keys: make(map[idempotencyKeyID]models.IdempotencyKey)}
+}
+
+// Claim stores key unless it is held
+func (r *memoryIdempotencyRepository) Claim(key *models.IdempotencyKey, now time.Time) (*models.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKeyID{userID: key.UserID, key: key.Key}
+	if held, ok := r.keys[id]; ok {
+		stale := !now.Before(held.ExpiresAt) || (held.InFlight() && !now.Before(held.LockedUntil))
+		if !stale {
+			return &held, nil
+		}
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = now
+	}
+	key.Status = 0
+	r.keys[id] = *key
+	return nil, nil
+}
+
+// Complete stores the response of the request that claimed the key
+func (r *memoryIdempotencyRepository) Complete(key *models.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKeyID{userID: key.UserID, key: key.Key}
+	held, ok := r.keys[id]
+	if !ok || !held.InFlight() || held.Fingerprint != key.Fingerprint {
+		return nil
+	}
+	held.Status = key.Status
+	held.Headers = key.Headers
+	held.Body = key.Body
+	r.keys[id] = held
+	return nil
+}
+
+// Release deletes a key whose request is still in flight
+func (r *memoryIdempotencyRepository) Release(userID uint, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKeyID{userID: userID, key: key}
+	if held, ok := r.keys[id]; ok && held.InFlight() {
+		delete(r.keys, id)
+	}
+	return nil
+}
+
+// Purge deletes the keys that expired before the given time
+func (r *memoryIdempotencyRepository) Purge(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, key := range r.keys {
+		if !before.Before(key.ExpiresAt) {
+			delete(r.keys, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
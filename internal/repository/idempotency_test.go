@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	backends := map[string]func(t *testing.T) IdempotencyRepository{
+		"sqlite": func(t *testing.T) IdempotencyRepository {
+			return NewIdempotencyRepository(setupTestDB(t))
+		},
+		"memory": func(t *testing.T) IdempotencyRepository {
+			return NewMemoryIdempotencyRepository()
+		},
+	}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	newKey := func(userID uint, key, fingerprint string, now time.Time) *models.IdempotencyKey {
+		return &models.IdempotencyKey{
+			UserID: userID, Key: key, Fingerprint: fingerprint,
+			LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour),
+		}
+	}
+	for name, newRepo := range backends {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			held, err := repo.Claim(newKey(testOwnerID, "retry-1", "first", now), now)
+			require.NoError(t, err)
+			assert.Nil(t, held)
+
+			// Keys are scoped to their user
+			held, err = repo.Claim(newKey(otherOwnerID, "retry-1", "other", now), now)
+			require.NoError(t, err)
+			assert.Nil(t, held)
+
+			// A duplicate finds the key in flight
+			held, err = repo.Claim(newKey(testOwnerID, "retry-1", "second", now), now)
+			require.NoError(t, err)
+			require.NotNil(t, held)
+			assert.True(t, held.InFlight())
+			assert.Equal(t, "first", held.Fingerprint)
+
+			// Then its response once it is done
+			require.NoError(t, repo.Complete(&models.IdempotencyKey{
+				UserID: testOwnerID, Key: "retry-1", Fingerprint: "first",
+				Status: 201, Headers: `{"Content-Type":["application/json"]}`, Body: []byte(`{"id":1}`),
+			}))
+			held, err = repo.Claim(newKey(testOwnerID, "retry-1", "first", now), now.Add(2*time.Minute))
+			require.NoError(t, err)
+			require.NotNil(t, held)
+			assert.Equal(t, 201, held.Status)
+			assert.Equal(t, `{"Content-Type":["application/json"]}`, held.Headers)
+			assert.Equal(t, []byte(`{"id":1}`), held.Body)
+
+			// A completed key is not released, but an expired one is taken over
+			require.NoError(t, repo.Release(testOwnerID, "retry-1"))
+			later := now.Add(time.Hour)
+			held, err = repo.Claim(newKey(testOwnerID, "retry-1", "third", later), later)
+			require.NoError(t, err)
+			assert.Nil(t, held)
+
+			// A key in flight past its lock is taken over
+			lockExpired := later.Add(time.Minute)
+			held, err = repo.Claim(newKey(testOwnerID, "retry-1", "fourth", lockExpired), lockExpired)
+			require.NoError(t, err)
+			assert.Nil(t, held)
+
+			// A released key can be claimed again
+			require.NoError(t, repo.Release(testOwnerID, "retry-1"))
+			held, err = repo.Claim(newKey(testOwnerID, "retry-1", "fifth", lockExpired), lockExpired)
+			require.NoError(t, err)
+			assert.Nil(t, held)
+
+			purged, err := repo.Purge(now.Add(time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), purged)
+			held, err = repo.Claim(newKey(otherOwnerID, "retry-1", "sixth", now), now)
+			require.NoError(t, err)
+			assert.Nil(t, held)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ProjectRepository defines the interface for project data access
+type ProjectRepository interface {
+	Create(project *models.Project) error
+	FindAll(ownerID uint, includeArchived bool) ([]models.Project, error)
+	FindByID(ownerID, id uint) (*models.Project, error)
+	FindByIDs(ownerID uint, ids []uint) ([]models.Project, error)
+	MaxPosition(ownerID uint) (int, error)
+	Update(project *models.Project) error
+	Delete(ownerID, id uint, deleteTasks bool) error
+}
+
+// projectRepository implements ProjectRepository using GORM
+type projectRepository struct {
+	db *gorm.DB
+}
+
+// NewProjectRepository creates a new ProjectRepository instance
+func NewProjectRepository(db *gorm.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+// Create creates a new project in the database
+func (r *projectRepository) Create(project *models.Project) error {
+	return r.db.Create(project).Error
+}
+
+// FindAll retrieves the owner's projects ordered by position
+func (r *projectRepository) FindAll(ownerID uint, includeArchived bool) ([]models.Project, error) {
+	var projects []models.Project
+	tx := r.owned(ownerID)
+	if !includeArchived {
+		tx = tx.Where("archived = ?", false)
+	}
+	err := tx.Order("position, id").Find(&projects).Error
+	return projects, err
+}
+
+// FindByID retrieves one of the owner's projects by its ID
+func (r *projectRepository) FindByID(ownerID, id uint) (*models.Project, error) {
+	var project models.Project
+	err := r.owned(ownerID).First(&project, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &apperrors.ProjectNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+// FindByIDs retrieves those of the owner's projects with the given IDs,
+// ordered by ID; IDs of projects that do not exist are left out
+func (r *projectRepository) FindByIDs(ownerID uint, ids []uint) ([]models.Project, error) {
+	var projects []models.Project
+	err := r.owned(ownerID).Where("id IN ?", ids).Order("id").Find(&projects).Error
+	return projects, err
+}
+
+// MaxPosition returns the highest position among the owner's projects, or
+// -1 when the owner has none
+func (r *projectRepository) MaxPosition(ownerID uint) (int, error) {
+	var position *int
+	err := r.owned(ownerID).Model(&models.Project{}).Select("MAX(position)").Scan(&position).Error
+	if err != nil || position == nil {
+		return -1, err
+	}
+	return *position, nil
+}
+
+// Update updates an existing project of the project's owner in the database
+func (r *projectRepository) Update(project *models.Project) error {
+	result := r.owned(project.OwnerID).Model(project).Select("*").Updates(project)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.ProjectNotFoundError{ID: project.ID}
+	}
+	return nil
+}
+
+// Delete removes one of the owner's projects. Its tasks, including those in
+// the trash, are deleted for good along with it when deleteTasks is set,
+// and moved to the inbox otherwise.
+func (r *projectRepository) Delete(ownerID, id uint, deleteTasks bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		tasks := tx.Unscoped().Where("owner_id = ? AND project_id = ?", ownerID, id)
+		var err error
+		if deleteTasks {
+			err = tasks.Delete(&models.Task{}).Error
+		} else {
+			err = tasks.Model(&models.Task{}).Updates(map[string]interface{}{
+				"project_id": nil, "updated_at": time.Now(), "version": gorm.Expr("version + 1"),
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("owner_id = ?", ownerID).Delete(&models.Project{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &apperrors.ProjectNotFoundError{ID: id}
+		}
+		return nil
+	})
+}
+
+// owned scopes a query to the projects of one owner
+func (r *projectRepository) owned(ownerID uint) *gorm.DB {
+	return r.db.Where("owner_id = ?", ownerID)
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// projectTaskStore is implemented by task repositories that keep their tasks
+// in process memory, so that deleting a project can update its tasks
+type projectTaskStore interface {
+	releaseProject(ownerID, projectID uint, deleteTasks bool)
+}
+
+// memoryProjectRepository implements ProjectRepository in process memory.
+// It is safe for concurrent use.
+type memoryProjectRepository struct {
+	mu       sync.RWMutex
+	projects map[uint]models.Project
+	nextID   uint
+	tasks    projectTaskStore
+}
+
+// NewMemoryProjectRepository creates a new in-memory ProjectRepository
+// instance. Deleting a project updates its tasks in the given task
+// repository when that repository is also in memory.
+func NewMemoryProjectRepository(tasks TaskRepository) ProjectRepository {
+	store, _ := tasks.(projectTaskStore)
+	return &memoryProjectRepository{
+		projects: make(map[uint]models.Project),
+		nextID:   1,
+		tasks:    store,
+	}
+}
+
+// Create stores a new project, assigning its ID and timestamps
+func (r *memoryProjectRepository) Create(project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	project.ID = r.nextID
+	r.nextID++
+	project.CreatedAt = now
+	project.UpdatedAt = now
+	r.projects[project.ID] = *project
+	return nil
+}
+
+// FindAll retrieves the owner's projects ordered by position
+func (r *memoryProjectRepository) FindAll(ownerID uint, includeArchived bool) ([]models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := []models.Project{}
+	for _, project := range r.projects {
+		if project.OwnerID == ownerID && (includeArchived || !project.Archived) {
+			projects = append(projects, project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Position != projects[j].Position {
+			return projects[i].Position < projects[j].Position
+		}
+		return projects[i].ID < projects[j].ID
+	})
+	return projects, nil
+}
+
+// FindByID retrieves one of the owner's projects by its ID
+func (r *memoryProjectRepository) FindByID(ownerID, id uint) (*models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok || project.OwnerID != ownerID {
+		return nil, &apperrors.ProjectNotFoundError{ID: id}
+	}
+	return &project, nil
+}
+
+// FindByIDs retrieves those of the owner's projects with the given IDs,
+// ordered by ID; IDs of projects that do not exist are left out
+func (r *memoryProjectRepository) FindByIDs(ownerID uint, ids []uint) ([]models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := []models.Project{}
+	for _, id := range ids {
+		if project, ok := r.projects[id]; ok && project.OwnerID == ownerID {
+			projects = append(projects, project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects, nil
+}
+
+// MaxPosition returns the highest position among the owner's projects, or
+// -1 when the owner has none
+func (r *memoryProjectRepository) MaxPosition(ownerID uint) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	max := -1
+	for _, project := range r.projects {
+		if project.OwnerID == ownerID && project.Position > max {
+			max = project.Position
+		}
+	}
+	return max, nil
+}
+
+// Update replaces an existing project of the project's owner
+func (r *memoryProjectRepository) Update(project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.projects[project.ID]
+	if !ok || stored.OwnerID != project.OwnerID {
+		return &apperrors.ProjectNotFoundError{ID: project.ID}
+	}
+	project.CreatedAt = stored.CreatedAt
+	project.UpdatedAt = time.Now()
+	r.projects[project.ID] = *project
+	return nil
+}
+
+// Delete removes one of the owner's projects. Its tasks are deleted along
+// with it when deleteTasks is set, and moved to the inbox otherwise.
+func (r *memoryProjectRepository) Delete(ownerID, id uint, deleteTasks bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok || project.OwnerID != ownerID {
+		return &apperrors.ProjectNotFoundError{ID: id}
+	}
+	if r.tasks != nil {
+		r.tasks.releaseProject(ownerID, id, deleteTasks)
+	}
+	delete(r.projects, id)
+	return nil
+}
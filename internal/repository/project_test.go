@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// projectBackends returns matching project and task repositories per backend
+func projectBackends() map[string]func(t *testing.T) (ProjectRepository, TaskRepository) {
+	return map[string]func(t *testing.T) (ProjectRepository, TaskRepository){
+		"sqlite": func(t *testing.T) (ProjectRepository, TaskRepository) {
+			db := setupTestDB(t)
+			return NewProjectRepository(db), NewTaskRepository(db)
+		},
+		"memory": func(t *testing.T) (ProjectRepository, TaskRepository) {
+			tasks := NewMemoryTaskRepository()
+			return NewMemoryProjectRepository(tasks), tasks
+		},
+	}
+}
+
+func TestProjectRepository_CRUD(t *testing.T) {
+	for name, newRepos := range projectBackends() {
+		t.Run(name, func(t *testing.T) {
+			projects, _ := newRepos(t)
+
+			max, err := projects.MaxPosition(testOwnerID)
+			require.NoError(t, err)
+			assert.Equal(t, -1, max)
+
+			work := &models.Project{OwnerID: testOwnerID, Name: "Work", Color: "#ff0000", Position: 1}
+			home := &models.Project{OwnerID: testOwnerID, Name: "Home", Color: "#00ff00", Position: 0}
+			old := &models.Project{OwnerID: testOwnerID, Name: "Old", Color: "#0000ff", Position: 2, Archived: true}
+			other := &models.Project{OwnerID: otherOwnerID, Name: "Other", Color: "#000000", Position: 5}
+			for _, project := range []*models.Project{work, home, old, other} {
+				require.NoError(t, projects.Create(project))
+				assert.NotZero(t, project.ID)
+			}
+
+			list, err := projects.FindAll(testOwnerID, false)
+			require.NoError(t, err)
+			require.Len(t, list, 2)
+			assert.Equal(t, "Home", list[0].Name)
+			assert.Equal(t, "Work", list[1].Name)
+
+			list, err = projects.FindAll(testOwnerID, true)
+			require.NoError(t, err)
+			assert.Len(t, list, 3)
+
+			max, err = projects.MaxPosition(testOwnerID)
+			require.NoError(t, err)
+			assert.Equal(t, 2, max)
+
+			_, err = projects.FindByID(testOwnerID, other.ID)
+			assert.IsType(t, &apperrors.ProjectNotFoundError{}, err)
+			found, err := projects.FindByIDs(testOwnerID, []uint{old.ID, other.ID, work.ID, 999})
+			require.NoError(t, err)
+			require.Len(t, found, 2)
+			assert.Equal(t, "Work", found[0].Name)
+			assert.Equal(t, "Old", found[1].Name)
+
+			work.Name = "Office"
+			work.Archived = true
+			require.NoError(t, projects.Update(work))
+			project, err := projects.FindByID(testOwnerID, work.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Office", project.Name)
+			assert.True(t, project.Archived)
+
+			hijacked := *other
+			hijacked.OwnerID = testOwnerID
+			assert.IsType(t, &apperrors.ProjectNotFoundError{}, projects.Update(&hijacked))
+			assert.IsType(t, &apperrors.ProjectNotFoundError{}, projects.Delete(testOwnerID, other.ID, false))
+		})
+	}
+}
+
+func TestProjectRepository_DeleteMovesTasksToInbox(t *testing.T) {
+	for name, newRepos := range projectBackends() {
+		t.Run(name, func(t *testing.T) {
+			projects, tasks := newRepos(t)
+
+			project := &models.Project{OwnerID: testOwnerID, Name: "Work", Color: "#ff0000"}
+			require.NoError(t, projects.Create(project))
+			task := &models.Task{OwnerID: testOwnerID, Content: "Report", ProjectID: &project.ID}
+			require.NoError(t, tasks.Create(task, nil))
+			require.NoError(t, tasks.Create(&models.Task{OwnerID: testOwnerID, Content: "Inbox task"}, nil))
+
+			projectID := project.ID
+			inProject, err := tasks.FindAll(testOwnerID, &models.TaskFilter{ProjectID: &projectID})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Report"}, contents(inProject))
+
+			require.NoError(t, projects.Delete(testOwnerID, project.ID, false))
+
+			found, err := tasks.FindByID(testOwnerID, task.ID)
+			require.NoError(t, err)
+			assert.Nil(t, found.ProjectID)
+
+			inbox := uint(0)
+			inboxTasks, err := tasks.FindAll(testOwnerID, &models.TaskFilter{ProjectID: &inbox})
+			require.NoError(t, err)
+			assert.Len(t, inboxTasks, 2)
+		})
+	}
+}
+
+func TestProjectRepository_DeleteCascadesToTasks(t *testing.T) {
+	for name, newRepos := range projectBackends() {
+		t.Run(name, func(t *testing.T) {
+			projects, tasks := newRepos(t)
+
+			project := &models.Project{OwnerID: testOwnerID, Name: "Work", Color: "#ff0000"}
+			require.NoError(t, projects.Create(project))
+			task := &models.Task{OwnerID: testOwnerID, Content: "Report", ProjectID: &project.ID}
+			require.NoError(t, tasks.Create(task, nil))
+			require.NoError(t, tasks.Create(&models.Task{OwnerID: testOwnerID, Content: "Inbox task"}, nil))
+
+			require.NoError(t, projects.Delete(testOwnerID, project.ID, true))
+
+			_, err := tasks.FindByID(testOwnerID, task.ID)
+			assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+			_, err = projects.FindByID(testOwnerID, project.ID)
+			assert.IsType(t, &apperrors.ProjectNotFoundError{}, err)
+
+			remaining, err := tasks.FindAll(testOwnerID, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Inbox task"}, contents(remaining))
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagRepository defines the interface for tag data access
+type TagRepository interface {
+	FindOrCreate(ownerID uint, names []string) ([]models.Tag, error)
+	FindByNames(ownerID uint, names []string) ([]models.Tag, error)
+	FindAll(ownerID uint) ([]models.TagSummary, error)
+	FindByID(ownerID, id uint) (*models.Tag, error)
+	Update(tag *models.Tag) error
+	Merge(ownerID, sourceID, targetID uint) error
+	Delete(ownerID, id uint) error
+}
+
+// tagRepository implements TagRepository using GORM
+type tagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository creates a new TagRepository instance
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// FindOrCreate returns the owner's tags with the given names ordered by
+// name, creating the ones that do not exist yet
+func (r *tagRepository) FindOrCreate(ownerID uint, names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		tags, err = findOrCreateTags(tx, ownerID, names)
+		return err
+	})
+	return tags, err
+}
+
+// FindByNames returns the owner's tags with the given names that exist,
+// ordered by name
+func (r *tagRepository) FindByNames(ownerID uint, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if len(names) == 0 {
+		return tags, nil
+	}
+	err := r.owned(ownerID).Where("name IN ?", names).Order("name").Find(&tags).Error
+	return tags, err
+}
+
+// findOrCreateTags returns the owner's tags with the given names ordered by
+// name within a transaction, creating the ones that do not exist yet
+func findOrCreateTags(tx *gorm.DB, ownerID uint, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if len(names) == 0 {
+		return tags, nil
+	}
+	missing := make([]models.Tag, len(names))
+	for i, name := range names {
+		missing[i] = models.Tag{OwnerID: ownerID, Name: name}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+		return nil, err
+	}
+	err := tx.Where("owner_id = ? AND name IN ?", ownerID, names).Order("name").Find(&tags).Error
+	return tags, err
+}
+
+// FindAll retrieves the owner's tags ordered by name, with the number of
+// tasks outside the trash carrying each
+func (r *tagRepository) FindAll(ownerID uint) ([]models.TagSummary, error) {
+	tags := []models.TagSummary{}
+	err := r.db.Model(&models.Tag{}).
+		Select("tags.*, COUNT(tasks.id) AS task_count").
+		Joins("LEFT JOIN task_tags ON task_tags.tag_id = tags.id").
+		Joins("LEFT JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL").
+		Where("tags.owner_id = ?", ownerID).
+		Group("tags.id").
+		Order("tags.name").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// FindByID retrieves one of the owner's tags by its ID
+func (r *tagRepository) FindByID(ownerID, id uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.owned(ownerID).First(&tag, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &apperrors.TagNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Update updates an existing tag of the tag's owner in the database
+func (r *tagRepository) Update(tag *models.Tag) error {
+	result := r.owned(tag.OwnerID).Model(tag).Select("*").Updates(tag)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return &apperrors.ConflictError{Message: fmt.Sprintf("tag %q already exists", tag.Name)}
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.TagNotFoundError{ID: tag.ID}
+	}
+	return nil
+}
+
+// Merge re-points the tasks tagged with the source tag to the target tag
+// and deletes the source tag
+func (r *tagRepository) Merge(ownerID, sourceID, targetID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range []uint{sourceID, targetID} {
+			var count int64
+			if err := tx.Model(&models.Tag{}).Where("owner_id = ? AND id = ?", ownerID, id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return &apperrors.TagNotFoundError{ID: id}
+			}
+		}
+
+		err := tx.Exec(
+			"INSERT INTO task_tags (task_id, tag_id) SELECT task_id, ? FROM task_tags "+
+				"WHERE tag_id = ? AND task_id NOT IN (SELECT task_id FROM task_tags WHERE tag_id = ?)",
+			targetID, sourceID, targetID,
+		).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", sourceID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Tag{}, sourceID).Error
+	})
+}
+
+// Delete removes one of the owner's tags, detaching it from its tasks
+func (r *tagRepository) Delete(ownerID, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("owner_id = ?", ownerID).Delete(&models.Tag{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &apperrors.TagNotFoundError{ID: id}
+		}
+		return tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", id).Error
+	})
+}
+
+// owned scopes a query to the tags of one owner
+func (r *tagRepository) owned(ownerID uint) *gorm.DB {
+	return r.db.Where("owner_id = ?", ownerID)
+}
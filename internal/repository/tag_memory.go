@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// tagTaskStore is implemented by task repositories that keep their tasks in
+// process memory, so that renaming, merging and deleting tags can update
+// the tagged tasks
+type tagTaskStore interface {
+	renameTag(ownerID uint, tag models.Tag)
+	mergeTag(ownerID, sourceID uint, target models.Tag)
+	removeTag(ownerID, tagID uint)
+	tagCounts(ownerID uint) map[uint]int64
+	useTags(tags batchTagStore)
+}
+
+// batchTagStore is implemented by the in-memory tag repository, so that
+// batches of task writes can create their new tags with their tasks
+type batchTagStore interface {
+	createTags(ownerID uint, names []string, apply func(tags []models.Tag) error) error
+}
+
+// memoryTagRepository implements TagRepository in process memory.
+// It is safe for concurrent use.
+type memoryTagRepository struct {
+	mu     sync.RWMutex
+	tags   map[uint]models.Tag
+	nextID uint
+	tasks  tagTaskStore
+}
+
+// NewMemoryTagRepository creates a new in-memory TagRepository instance.
+// Changing a tag updates its tasks in the given task repository when that
+// repository is also in memory.
+func NewMemoryTagRepository(tasks TaskRepository) TagRepository {
+	store, _ := tasks.(tagTaskStore)
+	r := &memoryTagRepository{
+		tags:   make(map[uint]models.Tag),
+		nextID: 1,
+		tasks:  store,
+	}
+	if store != nil {
+		store.useTags(r)
+	}
+	return r
+}
+
+// FindOrCreate returns the owner's tags with the given names ordered by
+// name, creating the ones that do not exist yet
+func (r *memoryTagRepository) FindOrCreate(ownerID uint, names []string) ([]models.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.findOrCreate(ownerID, names), nil
+}
+
+// FindByNames returns the owner's tags with the given names that exist,
+// ordered by name
+func (r *memoryTagRepository) FindByNames(ownerID uint, names []string) ([]models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := []models.Tag{}
+	for _, name := range names {
+		tag, ok := r.findByName(ownerID, name)
+		if ok && !slices.ContainsFunc(tags, func(t models.Tag) bool { return t.ID == tag.ID }) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// createTags finds or creates the owner's tags with the given names and
+// passes them to apply, removing the tags it created when apply fails. The
+// tags stay locked while apply runs.
+func (r *memoryTagRepository) createTags(ownerID uint, names []string, apply func(tags []models.Tag) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nextID := r.nextID
+	if err := apply(r.findOrCreate(ownerID, names)); err != nil {
+		for id := nextID; id < r.nextID; id++ {
+			delete(r.tags, id)
+		}
+		r.nextID = nextID
+		return err
+	}
+	return nil
+}
+
+// findOrCreate returns the owner's tags with the given names ordered by
+// name, creating the ones that do not exist yet. The caller must hold the
+// write lock.
+func (r *memoryTagRepository) findOrCreate(ownerID uint, names []string) []models.Tag {
+	tags := []models.Tag{}
+	for _, name := range names {
+		tag, ok := r.findByName(ownerID, name)
+		if !ok {
+			now := time.Now()
+			tag = models.Tag{ID: r.nextID, OwnerID: ownerID, Name: name, CreatedAt: now, UpdatedAt: now}
+			r.nextID++
+			r.tags[tag.ID] = tag
+		}
+		if !slices.ContainsFunc(tags, func(t models.Tag) bool { return t.ID == tag.ID }) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
+
+// FindAll retrieves the owner's tags ordered by name, with the number of
+// tasks carrying each
+func (r *memoryTagRepository) FindAll(ownerID uint) ([]models.TagSummary, error) {
+	var counts map[uint]int64
+	if r.tasks != nil {
+		counts = r.tasks.tagCounts(ownerID)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := []models.TagSummary{}
+	for _, tag := range r.tags {
+		if tag.OwnerID == ownerID {
+			tags = append(tags, models.TagSummary{Tag: tag, TaskCount: counts[tag.ID]})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// FindByID retrieves one of the owner's tags by its ID
+func (r *memoryTagRepository) FindByID(ownerID, id uint) (*models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tag, ok := r.tags[id]
+	if !ok || tag.OwnerID != ownerID {
+		return nil, &apperrors.TagNotFoundError{ID: id}
+	}
+	return &tag, nil
+}
+
+// Update replaces an existing tag of the tag's owner and updates the tasks
+// carrying it
+func (r *memoryTagRepository) Update(tag *models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tags[tag.ID]
+	if !ok || stored.OwnerID != tag.OwnerID {
+		return &apperrors.TagNotFoundError{ID: tag.ID}
+	}
+	if other, ok := r.findByName(tag.OwnerID, tag.Name); ok && other.ID != tag.ID {
+		return &apperrors.ConflictError{Message: fmt.Sprintf("tag %q already exists", tag.Name)}
+	}
+	tag.CreatedAt = stored.CreatedAt
+	tag.UpdatedAt = time.Now()
+	r.tags[tag.ID] = *tag
+	if r.tasks != nil {
+		r.tasks.renameTag(tag.OwnerID, *tag)
+	}
+	return nil
+}
+
+// Merge re-points the tasks tagged with the source tag to the target tag
+// and deletes the source tag
+func (r *memoryTagRepository) Merge(ownerID, sourceID, targetID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if source, ok := r.tags[sourceID]; !ok || source.OwnerID != ownerID {
+		return &apperrors.TagNotFoundError{ID: sourceID}
+	}
+	target, ok := r.tags[targetID]
+	if !ok || target.OwnerID != ownerID {
+		return &apperrors.TagNotFoundError{ID: targetID}
+	}
+	if r.tasks != nil {
+		r.tasks.mergeTag(ownerID, sourceID, target)
+	}
+	delete(r.tags, sourceID)
+	return nil
+}
+
+// Delete removes one of the owner's tags, detaching it from its tasks
+func (r *memoryTagRepository) Delete(ownerID, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if tag, ok := r.tags[id]; !ok || tag.OwnerID != ownerID {
+		return &apperrors.TagNotFoundError{ID: id}
+	}
+	if r.tasks != nil {
+		r.tasks.removeTag(ownerID, id)
+	}
+	delete(r.tags, id)
+	return nil
+}
+
+// findByName returns the owner's tag with the given name.
+// The caller must hold the lock.
+func (r *memoryTagRepository) findByName(ownerID uint, name string) (models.Tag, bool) {
+	for _, tag := range r.tags {
+		if tag.OwnerID == ownerID && tag.Name == name {
+			return tag, true
+		}
+	}
+	return models.Tag{}, false
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// tagBackends returns matching tag and task repositories per backend
+func tagBackends() map[string]func(t *testing.T) (TagRepository, TaskRepository) {
+	return map[string]func(t *testing.T) (TagRepository, TaskRepository){
+		"sqlite": func(t *testing.T) (TagRepository, TaskRepository) {
+			db := setupTestDB(t)
+			return NewTagRepository(db), NewTaskRepository(db)
+		},
+		"memory": func(t *testing.T) (TagRepository, TaskRepository) {
+			tasks := NewMemoryTaskRepository()
+			return NewMemoryTagRepository(tasks), tasks
+		},
+	}
+}
+
+// createTagged creates a task of the test owner carrying the named tags
+func createTagged(t *testing.T, tags TagRepository, tasks TaskRepository, content string, names ...string) *models.Task {
+	found, err := tags.FindOrCreate(testOwnerID, names)
+	require.NoError(t, err)
+	task := &models.Task{OwnerID: testOwnerID, Content: content, Tags: found}
+	require.NoError(t, tasks.Create(task, nil))
+	return task
+}
+
+// summaryCounts maps tag names to task counts
+func summaryCounts(summaries []models.TagSummary) map[string]int64 {
+	counts := make(map[string]int64, len(summaries))
+	for _, summary := range summaries {
+		counts[summary.Name] = summary.TaskCount
+	}
+	return counts
+}
+
+func TestTagRepository_FindOrCreate(t *testing.T) {
+	for name, newRepos := range tagBackends() {
+		t.Run(name, func(t *testing.T) {
+			tags, _ := newRepos(t)
+
+			first, err := tags.FindOrCreate(testOwnerID, []string{"urgent", "backend"})
+			require.NoError(t, err)
+			require.Len(t, first, 2)
+			assert.Equal(t, "backend", first[0].Name)
+			assert.Equal(t, "urgent", first[1].Name)
+
+			second, err := tags.FindOrCreate(testOwnerID, []string{"urgent", "waiting"})
+			require.NoError(t, err)
+			require.Len(t, second, 2)
+			assert.Equal(t, first[1].ID, second[0].ID)
+			assert.NotZero(t, second[1].ID)
+
+			other, err := tags.FindOrCreate(otherOwnerID, []string{"urgent"})
+			require.NoError(t, err)
+			require.Len(t, other, 1)
+			assert.NotEqual(t, first[1].ID, other[0].ID)
+
+			_, err = tags.FindByID(testOwnerID, other[0].ID)
+			assert.IsType(t, &apperrors.TagNotFoundError{}, err)
+
+			none, err := tags.FindOrCreate(testOwnerID, nil)
+			require.NoError(t, err)
+			assert.Empty(t, none)
+		})
+	}
+}
+
+func TestTagRepository_TasksCarryTags(t *testing.T) {
+	for name, newRepos := range tagBackends() {
+		t.Run(name, func(t *testing.T) {
+			tags, tasks := newRepos(t)
+
+			task := createTagged(t, tags, tasks, "Fix login", "urgent", "backend")
+			found, err := tasks.FindByID(testOwnerID, task.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"backend", "urgent"}, found.TagNames())
+
+			waiting, err := tags.FindOrCreate(testOwnerID, []string{"waiting"})
+			require.NoError(t, err)
+			found.Tags = waiting
+			require.NoError(t, tasks.Update(found, nil))
+			found, err = tasks.FindByID(testOwnerID, task.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"waiting"}, found.TagNames())
+
+			summaries, err := tags.FindAll(testOwnerID)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]int64{"backend": 0, "urgent": 0, "waiting": 1}, summaryCounts(summaries))
+
+			require.NoError(t, tasks.Delete(found, true, nil))
+			summaries, err = tags.FindAll(testOwnerID)
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), summaryCounts(summaries)["waiting"])
+		})
+	}
+}
+
+func TestTagRepository_BatchCreatesCarryTags(t *testing.T) {
+	for name, newRepos := range tagBackends() {
+		t.Run(name, func(t *testing.T) {
+			tags, tasks := newRepos(t)
+			found, err := tags.FindOrCreate(testOwnerID, []string{"home", "work"})
+			require.NoError(t, err)
+
+			home := &models.Task{OwnerID: testOwnerID, Content: "Home", Tags: found[:1]}
+			both := &models.Task{OwnerID: testOwnerID, Content: "Both", Tags: found}
+			none := &models.Task{OwnerID: testOwnerID, Content: "None"}
+			require.NoError(t, tasks.ApplyBatch(&models.TaskBatch{
+				OwnerID: testOwnerID,
+				Creates: []*models.Task{home, both, none},
+			}))
+
+			loaded, err := tasks.FindByIDs(testOwnerID, []uint{home.ID, both.ID, none.ID})
+			require.NoError(t, err)
+			require.Len(t, loaded, 3)
+			assert.Equal(t, []string{"home"}, loaded[0].TagNames())
+			assert.Equal(t, []string{"home", "work"}, loaded[1].TagNames())
+			assert.Empty(t, loaded[2].TagNames())
+		})
+	}
+}
+
+func TestTagRepository_BatchCreatesNewTags(t *testing.T) {
+	for name, newRepos := range tagBackends() {
+		t.Run(name, func(t *testing.T) {
+			tags, tasks := newRepos(t)
+			found, err := tags.FindOrCreate(testOwnerID, []string{"home"})
+			require.NoError(t, err)
+
+			task := &models.Task{OwnerID: testOwnerID, Content: "Task", Tags: []models.Tag{
+				found[0], {OwnerID: testOwnerID, Name: "work"},
+			}}
+			batch := &models.TaskBatch{OwnerID: testOwnerID, Creates: []*models.Task{task}}
+			batch.AddNewTags(task)
+			assert.Equal(t, []string{"work"}, batch.NewTags)
+			require.NoError(t, tasks.ApplyBatch(batch))
+
+			created, err := tags.FindByNames(testOwnerID, []string{"work", "missing"})
+			require.NoError(t, err)
+			require.Len(t, created, 1)
+			assert.Equal(t, created[0].ID, task.Tags[1].ID)
+			loaded, err := tasks.FindByID(testOwnerID, task.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"home", "work"}, loaded.TagNames())
+		})
+	}
+}
+
+func TestTagRepository_FailedBatchCreatesNoTags(t *testing.T) {
+	for name, newRepos := range tagBackends() {
+		t.Run(name, func(t *testing.T) {
+			tags, tasks := newRepos(t)
+
+			task := &models.Task{OwnerID: testOwnerID, Content: "Task", Tags: []models.Tag{
+				{OwnerID: testOwnerID, Name: "work"},
+			}}
+			batch := &models.TaskBatch{OwnerID: testOwnerID, Creates: []*models.Task{task}, Deletes: []uint{999}}
+			batch.AddNewTags(task)
+			assert.Equal(t, &apperrors.TaskNotFoundError{ID: 999}, tasks.ApplyBatch(batch))
+
+			summaries, err := tags.FindAll(testOwnerID)
+			require.NoError(t, err)
+			assert.Empty(t, summaries)
+		})
+	}
+}
+
+func TestTagRepository_FilterByTags(t *testing.T) {
+	for name, newRepos := range tagBackends() {
+		t.Run(name, func(t *testing.T) {
+			tags, tasks := newRepos(t)
+
+			createTagged(t, tags, tasks, "Both", "urgent", "backend")
+			createTagged(t, tags, tasks, "Urgent only", "urgent")
+			createTagged(t, tags, tasks, "Waiting", "waiting")
+			createTagged(t, tags, tasks, "Untagged")
+
+			sorted := []models.SortField{{Field: "content"}}
+			all, err := tasks.FindAll(testOwnerID, &models.TaskFilter{
+				Tags: []string{"urgent", "backend"}, TagMode: models.TagModeAll, Sort: sorted,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Both"}, contents(all))
+
+			anyOf, err := tasks.FindAll(testOwnerID, &models.TaskFilter{
+				Tags: []string{"backend", "waiting"}, TagMode: models.TagModeAny, Sort: sorted,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Both", "Waiting"}, contents(anyOf))
+
+			count, err := tasks.Count(testOwnerID, &models.TaskFilter{Tags: []string{"urgent"}})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+		})
+	}
+}
+
+func TestTagRepository_Rename(t *testing.T) {
+	for name, newRepos := range tagBackends() {
+		t.Run(name, func(t *testing.T) {
+			tags, tasks := newRepos(t)
+
+			task := createTagged(t, tags, tasks, "Fix login", "urgnt", "backend")
+			tag := task.Tags[1]
+
+			tag.Name = "backend"
+			assert.IsType(t, &apperrors.ConflictError{}, tags.Update(&tag))
+
+			tag.Name = "urgent"
+			require.NoError(t, tags.Update(&tag))
+			found, err := tasks.FindByID(testOwnerID, task.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"backend", "urgent"}, found.TagNames())
+
+			hijacked := tag
+			hijacked.OwnerID = otherOwnerID
+			assert.IsType(t, &apperrors.TagNotFoundError{}, tags.Update(&hijacked))
+		})
+	}
+}
+
+func TestTagRepository_Merge(t *testing.T) {
+	for name, newRepos := range tagBackends() {
+		t.Run(name, func(t *testing.T) {
+			tags, tasks := newRepos(t)
+
+			both := createTagged(t, tags, tasks, "Both", "bug", "defect")
+			defectOnly := createTagged(t, tags, tasks, "Defect only", "defect")
+			bug, defect := both.Tags[0], both.Tags[1]
+
+			require.NoError(t, tags.Merge(testOwnerID, defect.ID, bug.ID))
+
+			for _, task := range []*models.Task{both, defectOnly} {
+				found, err := tasks.FindByID(testOwnerID, task.ID)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"bug"}, found.TagNames())
+			}
+			_, err := tags.FindByID(testOwnerID, defect.ID)
+			assert.IsType(t, &apperrors.TagNotFoundError{}, err)
+
+			summaries, err := tags.FindAll(testOwnerID)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]int64{"bug": 2}, summaryCounts(summaries))
+
+			assert.IsType(t, &apperrors.TagNotFoundError{}, tags.Merge(testOwnerID, 999, bug.ID))
+			assert.IsType(t, &apperrors.TagNotFoundError{}, tags.Merge(testOwnerID, bug.ID, 999))
+		})
+	}
+}
+
+func TestTagRepository_Delete(t *testing.T) {
+	for name, newRepos := range tagBackends() {
+		t.Run(name, func(t *testing.T) {
+			tags, tasks := newRepos(t)
+
+			task := createTagged(t, tags, tasks, "Fix login", "urgent", "backend")
+			require.NoError(t, tags.Delete(testOwnerID, task.Tags[1].ID))
+
+			found, err := tasks.FindByID(testOwnerID, task.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"backend"}, found.TagNames())
+
+			assert.IsType(t, &apperrors.TagNotFoundError{}, tags.Delete(testOwnerID, task.Tags[1].ID))
+			assert.IsType(t, &apperrors.TagNotFoundError{}, tags.Delete(otherOwnerID, task.Tags[0].ID))
+		})
+	}
+}
@@ -1,20 +1,85 @@
 package repository
 
 import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/todo-api-go-sda/internal/models"
 	apperrors "github.com/todo-api-go-sda/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// TaskRepository defines the interface for task data access
+// TaskRepository defines the interface for task data access. Every write
+// that changes a task's version appends an event to the task's history in
+// the same transaction, attributed to the given actor.
 type TaskRepository interface {
-	Create(task *models.Task) error
-	FindAll() ([]models.Task, error)
-	FindByID(id uint) (*models.Task, error)
-	Update(task *models.Task) error
-	Delete(id uint) error
+	Create(task *models.Task, actor *models.Actor) error
+	FindAll(ownerID uint, filter *models.TaskFilter) ([]models.Task, error)
+	FindPage(ownerID uint, filter *models.TaskFilter, page *models.PageRequest) (*models.TaskPage, error)
+	FindDue(ownerID uint, filter *models.TaskFilter) ([]models.Task, error)
+	Count(ownerID uint, filter *models.TaskFilter) (int64, error)
+	FindByID(ownerID, id uint) (*models.Task, error)
+	FindByIDs(ownerID uint, ids []uint) ([]models.Task, error)
+	FindDescendants(ownerID, id uint) ([]models.Task, error)
+	Update(task *models.Task, actor *models.Actor) error
+	Complete(task *models.Task, subtasks bool, next *models.Task, actor *models.Actor) error
+	Delete(task *models.Task, cascade bool, actor *models.Actor) error
+	ApplyBatch(batch *models.TaskBatch) error
+	FindTrash(ownerID uint) ([]models.Task, error)
+	FindTrashed(ownerID, id uint) (*models.Task, error)
+	Restore(ownerID, id uint, actor *models.Actor) error
+	DeletePermanently(ownerID, id uint, actor *models.Actor) error
+	Purge(before time.Time) ([]models.Task, error)
+	Search(ownerID uint, query string) ([]models.TaskSearchResult, error)
+	FindEvents(ownerID uint, filter *models.TaskEventFilter, page *models.PageRequest) (*models.TaskEventPage, error)
+	FindEvent(ownerID, taskID, version uint) (*models.TaskEvent, error)
+	FindRecentEvents(ownerID uint, taskIDs []uint, limit int) ([]models.TaskEvent, error)
 }
 
+// searchConfig is the PostgreSQL text search configuration used for task
+// content; it must match the expression of the idx_tasks_content_fts index
+const searchConfig = "english"
+
+// Markers ts_headline puts around the matches in a snippet. They are
+// private use characters, removed from the content it highlights, so that
+// the snippet can be HTML escaped before they are turned into <mark> tags.
+const (
+	matchStart = "\uE000"
+	matchStop  = "\uE001"
+)
+
+// headlineOptions are the options of ts_headline, which otherwise shows
+// between 15 and 35 words around the matches
+const headlineOptions = `StartSel="` + matchStart + `", StopSel="` + matchStop + `"`
+
+// snippetWords is how many words around the first match the snippets of
+// LIKE searches show on each side, about as many as ts_headline shows
+const snippetWords = 10
+
+// wordPattern matches the words of a snippet
+var wordPattern = regexp.MustCompile(`\S+`)
+
+// subtaskCountColumns selects the roll-up counts of each task's direct
+// subtasks that are not in the trash
+const subtaskCountColumns = "(SELECT COUNT(*) FROM tasks AS subtasks WHERE subtasks.parent_id = tasks.id AND subtasks.deleted_at IS NULL) AS subtask_count, " +
+	"(SELECT COUNT(*) FROM tasks AS subtasks WHERE subtasks.parent_id = tasks.id AND subtasks.deleted_at IS NULL AND subtasks.completed) AS completed_subtask_count"
+
+// trashRoot limits trashed tasks to those whose parent is not in the trash
+// as well, so that a trashed subtree is listed once
+const trashRoot = "NOT EXISTS (SELECT 1 FROM tasks AS parents WHERE parents.id = tasks.parent_id AND parents.deleted_at IS NOT NULL)"
+
+// descendantIDs selects the IDs of every task below the given tasks
+const descendantIDs = "WITH RECURSIVE subtree (id) AS (" +
+	"SELECT id FROM tasks WHERE parent_id IN ? " +
+	"UNION ALL SELECT tasks.id FROM tasks JOIN subtree ON tasks.parent_id = subtree.id" +
+	") SELECT id FROM subtree"
+
 // taskRepository implements TaskRepository using GORM
 type taskRepository struct {
 	db *gorm.DB
@@ -25,22 +90,198 @@ func NewTaskRepository(db *gorm.DB) TaskRepository {
 	return &taskRepository{db: db}
 }
 
-// Create creates a new task in the database
-func (r *taskRepository) Create(task *models.Task) error {
-	return r.db.Create(task).Error
+// Create creates a new task in the database and attaches its tags, which
+// must already exist. A recurring task without a series starts its own.
+func (r *taskRepository) Create(task *models.Task, actor *models.Actor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createTasks(tx, []*models.Task{task}, actor)
+	})
+}
+
+// FindAll retrieves the owner's tasks matching the filter from the database
+func (r *taskRepository) FindAll(ownerID uint, filter *models.TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	tx := applyTaskConditions(r.loaded(ownerID), filter)
+	err := applyTaskOrder(tx, taskOrderKeys(filter), false).Find(&tasks).Error
+	return tasks, err
+}
+
+// FindPage retrieves one page of the owner's tasks matching the filter using
+// keyset pagination, starting after the page cursor when one is given
+func (r *taskRepository) FindPage(ownerID uint, filter *models.TaskFilter, page *models.PageRequest) (*models.TaskPage, error) {
+	keys := taskOrderKeys(filter)
+	backward := page.Cursor != nil && page.Cursor.Backward
+
+	tx := applyTaskConditions(r.loaded(ownerID), filter)
+	if page.Cursor != nil {
+		condition, args, err := keysetCondition(keys, page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where(condition, args...)
+	}
+
+	var tasks []models.Task
+	err := applyTaskOrder(tx, keys, backward).Limit(page.Limit + 1).Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(tasks) > page.Limit
+	if hasMore {
+		tasks = tasks[:page.Limit]
+	}
+	if backward {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+
+	result := &models.TaskPage{Tasks: tasks}
+	if len(tasks) == 0 {
+		return result, nil
+	}
+	first, last := &tasks[0], &tasks[len(tasks)-1]
+	if hasMore || backward {
+		result.NextCursor = models.NewCursor(last, keys, false)
+	}
+	if (hasMore && backward) || (page.Cursor != nil && !backward) {
+		result.PrevCursor = models.NewCursor(first, keys, true)
+	}
+	return result, nil
+}
+
+// FindDue retrieves the owner's tasks with a due date matching the filter,
+// soonest first and most urgent first among tasks due at the same time
+func (r *taskRepository) FindDue(ownerID uint, filter *models.TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	tx := applyTaskConditions(r.loaded(ownerID), filter)
+	err := tx.Where("due_at IS NOT NULL").Order("due_at").Order("priority DESC").Order("id").Find(&tasks).Error
+	return tasks, err
+}
+
+// Count returns the number of the owner's tasks matching the filter
+func (r *taskRepository) Count(ownerID uint, filter *models.TaskFilter) (int64, error) {
+	var count int64
+	err := applyTaskConditions(r.owned(ownerID).Model(&models.Task{}), filter).Count(&count).Error
+	return count, err
+}
+
+// FindByID retrieves one of the owner's tasks by its ID
+func (r *taskRepository) FindByID(ownerID, id uint) (*models.Task, error) {
+	var task models.Task
+	err := r.loaded(ownerID).First(&task, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &apperrors.TaskNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindByIDs retrieves the owner's tasks with the given IDs in one query.
+// IDs that name no task of the owner are left out.
+func (r *taskRepository) FindByIDs(ownerID uint, ids []uint) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.loaded(ownerID).Where("id IN ?", ids).Order("id").Find(&tasks).Error
+	return tasks, err
+}
+
+// FindDescendants retrieves every task below one of the owner's tasks, in
+// creation order
+func (r *taskRepository) FindDescendants(ownerID, id uint) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.loaded(ownerID).Where("id IN (?)", gorm.Expr(descendantIDs, []uint{id})).
+		Order("created_at").Order("id").Find(&tasks).Error
+	return tasks, err
+}
+
+// Update updates an existing task of the task's owner in the database,
+// replacing its tags with the task's current tags. It fails with a
+// VersionConflictError when the task has been written since it was read.
+func (r *taskRepository) Update(task *models.Task, actor *models.Actor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return updateTask(tx, task, actor)
+	})
+}
+
+// Complete updates a completed task like Update, in one transaction with
+// completing its incomplete descendants when subtasks is set and creating
+// next, the following occurrence of a recurring task, when it is not nil
+func (r *taskRepository) Complete(task *models.Task, subtasks bool, next *models.Task, actor *models.Actor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return completeTask(tx, task, subtasks, next, actor)
+	})
+}
+
+// Delete moves an existing task of the task's owner to the trash along
+// with its subtasks, marking them all with the same deletion time. The task
+// must still be at the version it was read at, and must have no subtasks
+// unless cascade is set.
+func (r *taskRepository) Delete(task *models.Task, cascade bool, actor *models.Actor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if !cascade {
+			var count int64
+			err := tx.Model(&models.Task{}).Where("owner_id = ? AND parent_id = ?", task.OwnerID, task.ID).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return hasSubtasks(task.ID, count)
+			}
+		}
+		return trashTasks(tx, task.OwnerID, []uint{task.ID}, map[uint]uint{task.ID: task.Version}, time.Now(), actor)
+	})
+}
+
+// ApplyBatch applies the writes of a batch in one transaction, rolling all
+// of them back when one fails. Creates, completions and deletes take a
+// fixed number of statements however many tasks they write; updates take a
+// few per task.
+func (r *taskRepository) ApplyBatch(batch *models.TaskBatch) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(batch.NewTags) > 0 {
+			tags, err := findOrCreateTags(tx, batch.OwnerID, batch.NewTags)
+			if err != nil {
+				return err
+			}
+			batch.SetTagIDs(tags)
+		}
+		if err := createTasks(tx, batch.Creates, batch.Actor); err != nil {
+			return err
+		}
+		for _, update := range batch.Updates {
+			if err := completeTask(tx, update.Task, update.CompleteSubtasks, update.Next, batch.Actor); err != nil {
+				return err
+			}
+		}
+		if len(batch.Completions) > 0 {
+			if err := completeTasks(tx, batch.OwnerID, batch.Completions, &batch.CompletedAt, batch.Actor); err != nil {
+				return err
+			}
+		}
+		if len(batch.Deletes) > 0 {
+			return trashTasks(tx, batch.OwnerID, batch.Deletes, nil, time.Now(), batch.Actor)
+		}
+		return nil
+	})
 }
 
-// FindAll retrieves all tasks from the database
-func (r *taskRepository) FindAll() ([]models.Task, error) {
+// FindTrash retrieves the owner's trashed tasks that are not below another
+// trashed task, most recently deleted first
+func (r *taskRepository) FindTrash(ownerID uint) ([]models.Task, error) {
 	var tasks []models.Task
-	err := r.db.Order("created_at DESC").Find(&tasks).Error
+	err := r.trashed(ownerID).Where(trashRoot).
+		Order("deleted_at DESC, id DESC").
+		Find(&tasks).Error
 	return tasks, err
 }
 
-// FindByID retrieves a task by its ID
-func (r *taskRepository) FindByID(id uint) (*models.Task, error) {
+// FindTrashed retrieves one of the owner's trashed tasks by its ID
+func (r *taskRepository) FindTrashed(ownerID, id uint) (*models.Task, error) {
 	var task models.Task
-	err := r.db.First(&task, id).Error
+	err := r.trashed(ownerID).First(&task, id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, &apperrors.TaskNotFoundError{ID: id}
@@ -50,19 +291,707 @@ func (r *taskRepository) FindByID(id uint) (*models.Task, error) {
 	return &task, nil
 }
 
-// Update updates an existing task in the database
-func (r *taskRepository) Update(task *models.Task) error {
-	return r.db.Save(task).Error
+// Restore takes one of the owner's trashed tasks out of the trash along
+// with the subtasks that were trashed with it
+func (r *taskRepository) Restore(ownerID, id uint, actor *models.Actor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tasks []models.Task
+		err := tx.Unscoped().Preload("Tags", orderTags).
+			Where("owner_id = ? AND deleted_at IS NOT NULL", ownerID).
+			Where("(id = ? OR (id IN (?) AND deleted_at = (SELECT deleted_at FROM tasks AS restored WHERE restored.id = ?)))",
+				id, gorm.Expr(descendantIDs, []uint{id}), id).
+			Find(&tasks).Error
+		if err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			return &apperrors.TaskNotFoundError{ID: id}
+		}
+		err = tx.Unscoped().Model(&models.Task{}).Where("id IN ?", taskIDs(tasks)).
+			Updates(map[string]interface{}{"deleted_at": nil, "updated_at": time.Now(), "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+		return recordWrites(tx, models.TaskEventRestored, tasks, actor, func(task *models.Task) {
+			task.DeletedAt = gorm.DeletedAt{}
+		})
+	})
+}
+
+// DeletePermanently removes one of the owner's trashed tasks from the
+// database for good, along with its subtasks, and records that they were
+// purged
+func (r *taskRepository) DeletePermanently(ownerID, id uint, actor *models.Actor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tasks []models.Task
+		err := tx.Unscoped().Clauses(skipLocked).Preload("Tags", orderTags).
+			Where("owner_id = ? AND (id = ? OR id IN (?))", ownerID, id, gorm.Expr(descendantIDs, []uint{id})).
+			Order("id").Find(&tasks).Error
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(tasks, func(task models.Task) bool { return task.ID == id })
+		if i < 0 || !tasks[i].DeletedAt.Valid {
+			return &apperrors.TaskNotFoundError{ID: id}
+		}
+		return purgeTasks(tx, tasks, actor)
+	})
+}
+
+// Purge permanently removes the tasks of every owner that were trashed
+// before the given time, along with their subtasks, records that they were
+// purged and returns them. Tasks another purge is removing are skipped.
+func (r *taskRepository) Purge(before time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var expired []uint
+		err := tx.Unscoped().Model(&models.Task{}).Clauses(skipLocked).
+			Where("deleted_at < ?", before).Pluck("id", &expired).Error
+		if err != nil || len(expired) == 0 {
+			return err
+		}
+		err = tx.Unscoped().Clauses(skipLocked).Preload("Tags", orderTags).
+			Where("id IN ? OR id IN (?)", expired, gorm.Expr(descendantIDs, expired)).
+			Order("id").Find(&tasks).Error
+		if err != nil {
+			return err
+		}
+		return purgeTasks(tx, tasks, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// Search finds the owner's tasks whose content matches the query, ordered by
+// relevance. PostgreSQL uses full-text search; other dialects fall back to
+// LIKE matching.
+func (r *taskRepository) Search(ownerID uint, query string) ([]models.TaskSearchResult, error) {
+	if r.db.Dialector.Name() == "postgres" {
+		return r.searchFullText(ownerID, query)
+	}
+	return r.searchLike(ownerID, query)
+}
+
+// searchFullText searches tasks using PostgreSQL tsvector matching and ranking
+func (r *taskRepository) searchFullText(ownerID uint, query string) ([]models.TaskSearchResult, error) {
+	var results []models.TaskSearchResult
+	err := r.owned(ownerID).Model(&models.Task{}).
+		Select(
+			"tasks.*, "+subtaskCountColumns+", "+
+				"ts_rank(to_tsvector('"+searchConfig+"', content), plainto_tsquery('"+searchConfig+"', ?)) AS rank, "+
+				"ts_headline('"+searchConfig+"', translate(content, ?, ''), plainto_tsquery('"+searchConfig+"', ?), ?) AS snippet",
+			query, matchStart+matchStop, query, headlineOptions,
+		).
+		Where("to_tsvector('"+searchConfig+"', content) @@ plainto_tsquery('"+searchConfig+"', ?)", query).
+		Order("rank DESC, created_at DESC").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Snippet = markHeadline(results[i].Snippet)
+	}
+	return results, r.loadSearchTags(results)
+}
+
+// searchLike searches tasks with case-insensitive LIKE matching on every term
+// and ranks them by the number of term occurrences
+func (r *taskRepository) searchLike(ownerID uint, query string) ([]models.TaskSearchResult, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return []models.TaskSearchResult{}, nil
+	}
+
+	tx := r.loaded(ownerID).Order("created_at DESC")
+	for _, term := range terms {
+		tx = tx.Where("LOWER(content) LIKE ? ESCAPE '\\'", "%"+escapeLike(term)+"%")
+	}
+
+	var tasks []models.Task
+	if err := tx.Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return rankMatches(tasks, terms), nil
+}
+
+// loadSearchTags loads the tags of tasks found by a raw search query
+func (r *taskRepository) loadSearchTags(results []models.TaskSearchResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	var tasks []models.Task
+	if err := r.db.Select("id").Preload("Tags", orderTags).Find(&tasks, ids).Error; err != nil {
+		return err
+	}
+	tags := make(map[uint][]models.Tag, len(tasks))
+	for _, task := range tasks {
+		tags[task.ID] = task.Tags
+	}
+	for i := range results {
+		results[i].Tags = tags[results[i].ID]
+	}
+	return nil
+}
+
+// FindEvents retrieves one page of the events in the owner's task history
+// matching the filter, newest first, starting after the page cursor when
+// one is given
+func (r *taskRepository) FindEvents(ownerID uint, filter *models.TaskEventFilter, page *models.PageRequest) (*models.TaskEventPage, error) {
+	tx := applyEventConditions(r.db.Where("owner_id = ?", ownerID), filter)
+	if page.Cursor != nil {
+		tx = tx.Where("id < ?", page.Cursor.ID)
+	}
+	var events []models.TaskEvent
+	if err := tx.Order("id DESC").Limit(page.Limit + 1).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return eventPage(events, page.Limit), nil
+}
+
+// FindEvent retrieves the event that took one of the owner's tasks to the
+// given version
+func (r *taskRepository) FindEvent(ownerID, taskID, version uint) (*models.TaskEvent, error) {
+	var event models.TaskEvent
+	err := r.db.Where("owner_id = ? AND task_id = ? AND version = ?", ownerID, taskID, version).First(&event).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &apperrors.VersionNotFoundError{ID: taskID, Version: version}
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+// FindRecentEvents retrieves the latest events of each of the owner's
+// tasks, at most limit per task, newest first
+func (r *taskRepository) FindRecentEvents(ownerID uint, taskIDs []uint, limit int) ([]models.TaskEvent, error) {
+	ranked := r.db.Model(&models.TaskEvent{}).
+		Select("task_events.*, ROW_NUMBER() OVER (PARTITION BY task_id ORDER BY id DESC) AS position").
+		Where("owner_id = ? AND task_id IN ?", ownerID, taskIDs)
+	var events []models.TaskEvent
+	err := r.db.Table("(?) AS ranked", ranked).Where("position <= ?", limit).Order("id DESC").Find(&events).Error
+	return events, err
+}
+
+// owned scopes a query to the tasks of one owner
+func (r *taskRepository) owned(ownerID uint) *gorm.DB {
+	return r.db.Where("owner_id = ?", ownerID)
+}
+
+// trashed scopes a query to the trashed tasks of one owner and loads their
+// tags and subtask counts
+func (r *taskRepository) trashed(ownerID uint) *gorm.DB {
+	return r.db.Unscoped().Where("owner_id = ? AND deleted_at IS NOT NULL", ownerID).
+		Select("tasks.*, "+subtaskCountColumns).Preload("Tags", orderTags)
+}
+
+// loaded scopes a query to the tasks of one owner and loads their tags and
+// subtask counts
+func (r *taskRepository) loaded(ownerID uint) *gorm.DB {
+	return r.owned(ownerID).Select("tasks.*, "+subtaskCountColumns).Preload("Tags", orderTags)
+}
+
+// createTasks creates tasks with one statement, attaches their tags with
+// another and records their creation within a transaction. A recurring
+// task without a series starts its own.
+func createTasks(tx *gorm.DB, tasks []*models.Task, actor *models.Actor) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	for _, task := range tasks {
+		if task.Version == 0 {
+			task.Version = 1
+		}
+	}
+	if err := tx.Omit(clause.Associations).Create(tasks).Error; err != nil {
+		return err
+	}
+
+	var series []uint
+	var links []map[string]interface{}
+	events := make([]models.TaskEvent, len(tasks))
+	for i, task := range tasks {
+		if task.Recurrence != "" && task.SeriesID == nil {
+			task.SeriesID = &task.ID
+			series = append(series, task.ID)
+		}
+		for _, tag := range task.Tags {
+			links = append(links, map[string]interface{}{"task_id": task.ID, "tag_id": tag.ID})
+		}
+		events[i] = models.NewTaskEvent(models.TaskEventCreated, nil, task, actor)
+	}
+	if len(series) > 0 {
+		if err := tx.Model(&models.Task{}).Where("id IN ?", series).UpdateColumn("series_id", gorm.Expr("id")).Error; err != nil {
+			return err
+		}
+	}
+	if len(links) > 0 {
+		if err := tx.Table("task_tags").Create(links).Error; err != nil {
+			return err
+		}
+	}
+	return recordEvents(tx, events)
+}
+
+// completeTask updates a task like updateTask within a transaction,
+// completing its incomplete descendants when subtasks is set and creating
+// next when it is not nil
+func completeTask(tx *gorm.DB, task *models.Task, subtasks bool, next *models.Task, actor *models.Actor) error {
+	if err := updateTask(tx, task, actor); err != nil {
+		return err
+	}
+	if subtasks {
+		descendants := gorm.Expr(descendantIDs, []uint{task.ID})
+		if err := completeTasks(tx, task.OwnerID, descendants, task.CompletedAt, actor); err != nil {
+			return err
+		}
+	}
+	if next != nil {
+		return createTasks(tx, []*models.Task{next}, actor)
+	}
+	return nil
+}
+
+// completeTasks marks the owner's incomplete tasks among ids, a list of IDs
+// or a subquery selecting them, completed at completedAt regardless of their
+// version and records their completion within a transaction
+func completeTasks(tx *gorm.DB, ownerID uint, ids interface{}, completedAt *time.Time, actor *models.Actor) error {
+	var tasks []models.Task
+	err := tx.Preload("Tags", orderTags).
+		Where("owner_id = ? AND completed = ? AND id IN (?)", ownerID, false, ids).
+		Find(&tasks).Error
+	if err != nil || len(tasks) == 0 {
+		return err
+	}
+	err = tx.Model(&models.Task{}).Where("id IN ?", taskIDs(tasks)).
+		Updates(map[string]interface{}{
+			"completed": true, "completed_at": completedAt, "version": gorm.Expr("version + 1"),
+		}).Error
+	if err != nil {
+		return err
+	}
+	return recordWrites(tx, models.TaskEventCompleted, tasks, actor, func(task *models.Task) {
+		task.Completed = true
+		task.CompletedAt = completedAt
+	})
+}
+
+// trashTasks moves tasks of the owner to the trash along with their
+// subtasks within a transaction, marking them all with the given deletion
+// time. It fails with a TaskNotFoundError unless every task is found, and
+// with a VersionConflictError unless the tasks listed in versions are still
+// at the version given for them.
+func trashTasks(tx *gorm.DB, ownerID uint, ids []uint, versions map[uint]uint, deletedAt time.Time, actor *models.Actor) error {
+	var tasks []models.Task
+	err := tx.Preload("Tags", orderTags).
+		Where("owner_id = ? AND (id IN ? OR id IN (?))", ownerID, ids, gorm.Expr(descendantIDs, ids)).
+		Find(&tasks).Error
+	if err != nil {
+		return err
+	}
+	found := taskIDs(tasks)
+	for _, id := range ids {
+		if !slices.Contains(found, id) {
+			return &apperrors.TaskNotFoundError{ID: id}
+		}
+	}
+	trashed := map[string]interface{}{"deleted_at": deletedAt, "version": gorm.Expr("version + 1")}
+	for id, version := range versions {
+		result := tx.Model(&models.Task{}).Where("owner_id = ? AND id = ? AND version = ?", ownerID, id, version).Updates(trashed)
+		if result.Error == nil && result.RowsAffected == 0 {
+			result.Error = versionConflict(tx, ownerID, id)
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		found = slices.DeleteFunc(found, func(foundID uint) bool { return foundID == id })
+	}
+	if len(found) > 0 {
+		if err := tx.Model(&models.Task{}).Where("id IN ?", found).Updates(trashed).Error; err != nil {
+			return err
+		}
+	}
+	return recordWrites(tx, models.TaskEventDeleted, tasks, actor, func(task *models.Task) {
+		task.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+	})
+}
+
+// purgeTasks removes tasks from the database for good within a transaction
+// and records that they were purged. Their tags go with them through the
+// cascading foreign key.
+func purgeTasks(tx *gorm.DB, tasks []models.Task, actor *models.Actor) error {
+	err := recordWrites(tx, models.TaskEventPurged, tasks, actor, func(task *models.Task) {})
+	if err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&models.Task{}, taskIDs(tasks)).Error
+}
+
+// recordWrites records the events of a write that applied change to each
+// of the tasks, as read before it, and incremented their version
+func recordWrites(tx *gorm.DB, eventType string, tasks []models.Task, actor *models.Actor, change func(task *models.Task)) error {
+	events := make([]models.TaskEvent, len(tasks))
+	for i := range tasks {
+		written := tasks[i]
+		change(&written)
+		written.Version++
+		events[i] = models.NewTaskEvent(eventType, &tasks[i], &written, actor)
+	}
+	return recordEvents(tx, events)
 }
 
-// Delete removes a task from the database
-func (r *taskRepository) Delete(id uint) error {
-	result := r.db.Delete(&models.Task{}, id)
+// recordEvents adds events to the task history within a transaction, along
+// with the outbox messages announcing them to webhooks
+func recordEvents(tx *gorm.DB, events []models.TaskEvent) error {
+	if err := tx.Create(&events).Error; err != nil {
+		return err
+	}
+	messages := make([]models.OutboxMessage, len(events))
+	for i := range events {
+		messages[i] = models.NewOutboxMessage(&events[i])
+	}
+	return tx.Create(&messages).Error
+}
+
+// updateTask updates an existing task of the task's owner, replaces its
+// tags and records the update within a transaction. The task must still be
+// at the version it was read at, which is then incremented.
+func updateTask(tx *gorm.DB, task *models.Task, actor *models.Actor) error {
+	var before models.Task
+	err := tx.Preload("Tags", orderTags).Where("owner_id = ?", task.OwnerID).First(&before, task.ID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &apperrors.TaskNotFoundError{ID: task.ID}
+		}
+		return err
+	}
+
+	version := task.Version
+	task.Version++
+	result := tx.Where("owner_id = ? AND version = ?", task.OwnerID, version).Model(task).
+		Select("*").Omit(clause.Associations).Updates(task)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = versionConflict(tx, task.OwnerID, task.ID)
+	}
 	if result.Error != nil {
+		task.Version = version
 		return result.Error
 	}
-	if result.RowsAffected == 0 {
-		return &apperrors.TaskNotFoundError{ID: id}
+	if err := replaceTaskTags(tx, task); err != nil {
+		return err
+	}
+	event := models.NewTaskEvent(models.UpdateEventType(&before, task), &before, task, actor)
+	return recordEvents(tx, []models.TaskEvent{event})
+}
+
+// hasSubtasks refuses to delete a task with subtasks without cascading
+func hasSubtasks(id uint, count int64) error {
+	return &apperrors.ConflictError{
+		Message: fmt.Sprintf("task %d has %d subtasks; delete them first or cascade the deletion", id, count),
+	}
+}
+
+// versionConflict explains why a write to a version of a task matched no
+// row: either the task has been written since that version was read, or it
+// does not exist
+func versionConflict(tx *gorm.DB, ownerID, id uint) error {
+	var count int64
+	if err := tx.Model(&models.Task{}).Where("owner_id = ? AND id = ?", ownerID, id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return &apperrors.VersionConflictError{ID: id}
+	}
+	return &apperrors.TaskNotFoundError{ID: id}
+}
+
+// rankMatches ranks tasks by the number of term occurrences in their content
+// and highlights the occurrences around the first one; tasks with equal rank
+// keep their order
+func rankMatches(tasks []models.Task, terms []string) []models.TaskSearchResult {
+	pattern := termsPattern(terms)
+	results := make([]models.TaskSearchResult, len(tasks))
+	for i, task := range tasks {
+		matches := pattern.FindAllStringIndex(task.Content, -1)
+		results[i] = models.TaskSearchResult{
+			Task:    task,
+			Rank:    float64(len(matches)),
+			Snippet: highlight(task.Content, matches),
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+	return results
+}
+
+// highlight returns the HTML escaped snippet of content within snippetWords
+// words of its first match, with the matches in it wrapped in <mark> tags
+func highlight(content string, matches [][]int) string {
+	if len(matches) == 0 {
+		return ""
+	}
+	words := wordPattern.FindAllStringIndex(content, -1)
+	first := sort.Search(len(words), func(i int) bool { return words[i][1] > matches[0][0] })
+	last := sort.Search(len(words), func(i int) bool { return words[i][1] >= matches[0][1] })
+	start := words[max(first-snippetWords, 0)][0]
+	end := words[min(last+snippetWords, len(words)-1)][1]
+
+	var snippet strings.Builder
+	pos := start
+	for _, match := range matches {
+		if match[1] > end {
+			break
+		}
+		snippet.WriteString(html.EscapeString(content[pos:match[0]]))
+		snippet.WriteString("<mark>" + html.EscapeString(content[match[0]:match[1]]) + "</mark>")
+		pos = match[1]
+	}
+	snippet.WriteString(html.EscapeString(content[pos:end]))
+	return snippet.String()
+}
+
+// markHeadline HTML escapes a snippet made by ts_headline and turns the
+// markers around its matches into <mark> tags
+func markHeadline(headline string) string {
+	return strings.NewReplacer(matchStart, "<mark>", matchStop, "</mark>").Replace(html.EscapeString(headline))
+}
+
+// replaceTaskTags replaces the tags attached to a task with its current tags
+func replaceTaskTags(tx *gorm.DB, task *models.Task) error {
+	if err := tx.Exec("DELETE FROM task_tags WHERE task_id = ?", task.ID).Error; err != nil {
+		return err
+	}
+	for _, tag := range task.Tags {
+		err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?)", task.ID, tag.ID).Error
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+// taskIDs returns the IDs of the tasks
+func taskIDs(tasks []models.Task) []uint {
+	ids := make([]uint, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+	}
+	return ids
+}
+
+// eventPage cuts events read newest first, one more than the page limit
+// when more follow, down to one page
+func eventPage(events []models.TaskEvent, limit int) *models.TaskEventPage {
+	page := &models.TaskEventPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		last := &page.Events[limit-1]
+		page.NextCursor = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	return page
+}
+
+// applyEventConditions adds the filter conditions to a task event query
+func applyEventConditions(tx *gorm.DB, filter *models.TaskEventFilter) *gorm.DB {
+	if filter == nil {
+		return tx
+	}
+	if filter.TaskID != nil {
+		tx = tx.Where("task_id = ?", *filter.TaskID)
+	}
+	if len(filter.Types) > 0 {
+		tx = tx.Where("type IN ?", filter.Types)
+	}
+	if filter.TokenID != nil {
+		tx = tx.Where("token_id = ?", *filter.TokenID)
+	}
+	if filter.RequestID != "" {
+		tx = tx.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Since != nil {
+		tx = tx.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		tx = tx.Where("created_at < ?", *filter.Until)
+	}
+	return tx
+}
+
+// orderTags orders preloaded tags by name
+func orderTags(tx *gorm.DB) *gorm.DB {
+	return tx.Order("tags.name")
+}
+
+// applyTaskConditions adds the filter conditions to a task query
+func applyTaskConditions(tx *gorm.DB, filter *models.TaskFilter) *gorm.DB {
+	if filter == nil {
+		return tx
+	}
+	if filter.ProjectID != nil {
+		if *filter.ProjectID == 0 {
+			tx = tx.Where("project_id IS NULL")
+		} else {
+			tx = tx.Where("project_id = ?", *filter.ProjectID)
+		}
+	}
+	if filter.ParentID != nil {
+		if *filter.ParentID == 0 {
+			tx = tx.Where("parent_id IS NULL")
+		} else {
+			tx = tx.Where("parent_id = ?", *filter.ParentID)
+		}
+	}
+	if len(filter.ParentIDs) > 0 {
+		tx = tx.Where("parent_id IN ?", filter.ParentIDs)
+	}
+	if filter.SeriesID != nil {
+		tx = tx.Where("series_id = ?", *filter.SeriesID)
+	}
+	if len(filter.Tags) > 0 {
+		tagged := "SELECT task_tags.task_id FROM task_tags JOIN tags ON tags.id = task_tags.tag_id WHERE tags.name IN ?"
+		if filter.TagMode == models.TagModeAny {
+			tx = tx.Where("id IN ("+tagged+")", filter.Tags)
+		} else {
+			tx = tx.Where("id IN ("+tagged+" GROUP BY task_tags.task_id HAVING COUNT(*) = ?)", filter.Tags, len(filter.Tags))
+		}
+	}
+	if filter.Completed != nil {
+		tx = tx.Where("completed = ?", *filter.Completed)
+	}
+	if filter.DueFrom != nil {
+		tx = tx.Where("due_at >= ?", *filter.DueFrom)
+	}
+	if filter.DueBefore != nil {
+		tx = tx.Where("due_at < ?", *filter.DueBefore)
+	}
+	if filter.CreatedAfter != nil {
+		tx = tx.Where("created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		tx = tx.Where("updated_at < ?", *filter.UpdatedBefore)
+	}
+	if filter.ContentContains != "" {
+		tx = tx.Where("LOWER(content) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(filter.ContentContains))+"%")
+	}
+	return tx
+}
+
+// taskOrderKeys returns the complete ordering for a filter: the requested
+// sort (newest first by default) followed by created_at and id tie-breakers
+// so that every row has a unique position for keyset pagination
+func taskOrderKeys(filter *models.TaskFilter) []models.SortField {
+	var keys []models.SortField
+	if filter != nil {
+		for _, field := range filter.Sort {
+			if _, ok := models.TaskSortColumns[field.Field]; ok {
+				keys = append(keys, field)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		keys = append(keys, models.SortField{Field: "created_at", Desc: true})
+	}
+
+	idDesc := true
+	hasCreatedAt := false
+	for _, key := range keys {
+		if key.Field == "created_at" {
+			hasCreatedAt = true
+			idDesc = key.Desc
+		}
+	}
+	if !hasCreatedAt {
+		keys = append(keys, models.SortField{Field: "created_at", Desc: true})
+	}
+	return append(keys, models.SortField{Field: "id", Desc: idDesc})
+}
+
+// applyTaskOrder orders a task query by the keys, reversed when paging backward
+func applyTaskOrder(tx *gorm.DB, keys []models.SortField, backward bool) *gorm.DB {
+	for _, key := range keys {
+		column := key.Field
+		if key.Desc != backward {
+			column += " DESC"
+		}
+		tx = tx.Order(column)
+	}
+	return tx
+}
+
+// keysetCondition builds the WHERE clause selecting the rows that come after
+// the cursor in the ordering given by keys
+func keysetCondition(keys []models.SortField, cursor *models.Cursor) (string, []interface{}, error) {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		value, ok := cursorValue(cursor, key.Field)
+		if !ok {
+			return "", nil, &apperrors.ValidationError{Message: "invalid query parameter cursor: does not match the requested sort"}
+		}
+		values[i] = value
+	}
+
+	var clauses []string
+	var args []interface{}
+	for i, key := range keys {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].Field+" = ?")
+			args = append(args, values[j])
+		}
+		op := ">"
+		if key.Desc != cursor.Backward {
+			op = "<"
+		}
+		parts = append(parts, key.Field+" "+op+" ?")
+		args = append(args, values[i])
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return strings.Join(clauses, " OR "), args, nil
+}
+
+// cursorValue returns the cursor value for an ordering column
+func cursorValue(cursor *models.Cursor, field string) (interface{}, bool) {
+	switch field {
+	case "created_at":
+		return cursor.CreatedAt, true
+	case "id":
+		return cursor.ID, true
+	case "updated_at":
+		if cursor.UpdatedAt == nil {
+			return nil, false
+		}
+		return *cursor.UpdatedAt, true
+	case "content":
+		if cursor.Content == nil {
+			return nil, false
+		}
+		return *cursor.Content, true
+	case "completed":
+		if cursor.Completed == nil {
+			return nil, false
+		}
+		return *cursor.Completed, true
+	}
+	return nil, false
+}
+
+// escapeLike escapes LIKE wildcards so the term is matched literally
+func escapeLike(term string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(term)
+}
+
+// termsPattern builds a case-insensitive regexp matching any of the terms
+func termsPattern(terms []string) *regexp.Regexp {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+}
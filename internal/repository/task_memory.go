@@ -0,0 +1,966 @@
+package repository
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// memoryTaskRepository implements TaskRepository in process memory.
+// It is safe for concurrent use.
+type memoryTaskRepository struct {
+	mu            sync.RWMutex
+	tasks         map[uint]models.Task
+	nextID        uint
+	events        []models.TaskEvent
+	outbox        []models.OutboxMessage
+	nextMessageID uint
+	tags          batchTagStore
+}
+
+// NewMemoryTaskRepository creates a new in-memory TaskRepository instance
+func NewMemoryTaskRepository() TaskRepository {
+	return &memoryTaskRepository{
+		tasks:         make(map[uint]models.Task),
+		nextID:        1,
+		nextMessageID: 1,
+	}
+}
+
+// Create stores a new task, assigning its ID and timestamps. A recurring
+// task without a series starts its own.
+func (r *memoryTaskRepository) Create(task *models.Task, actor *models.Actor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.create(task, time.Now(), actor)
+	return nil
+}
+
+// create stores a new task like Create. The caller must hold the write lock.
+func (r *memoryTaskRepository) create(task *models.Task, now time.Time, actor *models.Actor) {
+	if task.ID == 0 {
+		task.ID = r.nextID
+	}
+	if task.ID >= r.nextID {
+		r.nextID = task.ID + 1
+	}
+	if task.Recurrence != "" && task.SeriesID == nil {
+		seriesID := task.ID
+		task.SeriesID = &seriesID
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = now
+	}
+	if task.UpdatedAt.IsZero() {
+		task.UpdatedAt = now
+	}
+	if task.Version == 0 {
+		task.Version = 1
+	}
+	r.tasks[task.ID] = storedTask(task)
+	r.record(models.NewTaskEvent(models.TaskEventCreated, nil, task, actor), now)
+}
+
+// record appends an event to the history of its task and the outbox
+// message announcing it to webhooks. The caller must hold the write lock.
+func (r *memoryTaskRepository) record(event models.TaskEvent, now time.Time) {
+	event.ID = uint(len(r.events) + 1)
+	event.CreatedAt = now
+	r.events = append(r.events, event)
+
+	message := models.NewOutboxMessage(&event)
+	message.ID = r.nextMessageID
+	r.nextMessageID++
+	r.outbox = append(r.outbox, message)
+}
+
+// takeOutbox removes and returns up to limit of the oldest outbox messages
+func (r *memoryTaskRepository) takeOutbox(limit int) []models.OutboxMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := min(limit, len(r.outbox))
+	messages := slices.Clone(r.outbox[:n])
+	r.outbox = slices.Delete(r.outbox, 0, n)
+	return messages
+}
+
+// FindAll retrieves the owner's tasks matching the filter
+func (r *memoryTaskRepository) FindAll(ownerID uint, filter *models.TaskFilter) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := r.match(ownerID, filter)
+	sortTasks(tasks, taskOrderKeys(filter), false)
+	r.countSubtasks(tasks)
+	return tasks, nil
+}
+
+// FindPage retrieves one page of the owner's tasks matching the filter,
+// starting after the page cursor when one is given
+func (r *memoryTaskRepository) FindPage(ownerID uint, filter *models.TaskFilter, page *models.PageRequest) (*models.TaskPage, error) {
+	keys := taskOrderKeys(filter)
+	backward := page.Cursor != nil && page.Cursor.Backward
+
+	r.mu.RLock()
+	tasks := r.match(ownerID, filter)
+	r.countSubtasks(tasks)
+	r.mu.RUnlock()
+
+	sortTasks(tasks, keys, backward)
+	if page.Cursor != nil {
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			value, ok := cursorValue(page.Cursor, key.Field)
+			if !ok {
+				return nil, &apperrors.ValidationError{Message: "invalid query parameter cursor: does not match the requested sort"}
+			}
+			values[i] = value
+		}
+		start := sort.Search(len(tasks), func(i int) bool {
+			return compareOrder(orderValues(&tasks[i], keys), values, keys, backward) > 0
+		})
+		tasks = tasks[start:]
+	}
+
+	hasMore := len(tasks) > page.Limit
+	if hasMore {
+		tasks = tasks[:page.Limit]
+	}
+	if backward {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+
+	result := &models.TaskPage{Tasks: tasks}
+	if len(tasks) == 0 {
+		return result, nil
+	}
+	first, last := &tasks[0], &tasks[len(tasks)-1]
+	if hasMore || backward {
+		result.NextCursor = models.NewCursor(last, keys, false)
+	}
+	if (hasMore && backward) || (page.Cursor != nil && !backward) {
+		result.PrevCursor = models.NewCursor(first, keys, true)
+	}
+	return result, nil
+}
+
+// FindDue retrieves the owner's tasks with a due date matching the filter,
+// soonest first and most urgent first among tasks due at the same time
+func (r *memoryTaskRepository) FindDue(ownerID uint, filter *models.TaskFilter) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, task := range r.match(ownerID, filter) {
+		if task.DueAt != nil {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := &tasks[i], &tasks[j]
+		if !a.DueAt.Equal(*b.DueAt) {
+			return a.DueAt.Before(*b.DueAt)
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.ID < b.ID
+	})
+	r.countSubtasks(tasks)
+	return tasks, nil
+}
+
+// Count returns the number of the owner's tasks matching the filter
+func (r *memoryTaskRepository) Count(ownerID uint, filter *models.TaskFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.match(ownerID, filter))), nil
+}
+
+// FindByID retrieves one of the owner's tasks by its ID
+func (r *memoryTaskRepository) FindByID(ownerID, id uint) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok || task.OwnerID != ownerID || task.DeletedAt.Valid {
+		return nil, &apperrors.TaskNotFoundError{ID: id}
+	}
+	tasks := []models.Task{task}
+	r.countSubtasks(tasks)
+	return &tasks[0], nil
+}
+
+// FindDescendants retrieves every task below one of the owner's tasks, in
+// creation order
+func (r *memoryTaskRepository) FindDescendants(ownerID, id uint) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, descendantID := range r.descendants(id) {
+		if task := r.tasks[descendantID]; task.OwnerID == ownerID && !task.DeletedAt.Valid {
+			tasks = append(tasks, task)
+		}
+	}
+	sortTasks(tasks, []models.SortField{{Field: "created_at"}, {Field: "id"}}, false)
+	r.countSubtasks(tasks)
+	return tasks, nil
+}
+
+// FindByIDs retrieves the owner's tasks with the given IDs, leaving out the
+// IDs that name no task of the owner
+func (r *memoryTaskRepository) FindByIDs(ownerID uint, ids []uint) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, id := range ids {
+		if task, ok := r.tasks[id]; ok && task.OwnerID == ownerID && !task.DeletedAt.Valid {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	r.countSubtasks(tasks)
+	return tasks, nil
+}
+
+// Update replaces an existing task of the task's owner, refreshes its
+// update timestamp and increments its version. It fails with a
+// VersionConflictError when the task has been written since it was read.
+func (r *memoryTaskRepository) Update(task *models.Task, actor *models.Actor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.complete(task, false, nil, time.Now(), actor)
+}
+
+// checkVersion checks that a task about to be written is still stored at
+// the version it was read at. The caller must hold the lock.
+func (r *memoryTaskRepository) checkVersion(task *models.Task) error {
+	existing, ok := r.tasks[task.ID]
+	if !ok || existing.OwnerID != task.OwnerID || existing.DeletedAt.Valid {
+		return &apperrors.TaskNotFoundError{ID: task.ID}
+	}
+	if existing.Version != task.Version {
+		return &apperrors.VersionConflictError{ID: task.ID}
+	}
+	return nil
+}
+
+// Complete updates a completed task like Update, together with completing
+// its incomplete descendants when subtasks is set and creating next, the
+// following occurrence of a recurring task, when it is not nil
+func (r *memoryTaskRepository) Complete(task *models.Task, subtasks bool, next *models.Task, actor *models.Actor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.complete(task, subtasks, next, time.Now(), actor)
+}
+
+// complete writes a task like Complete. The caller must hold the write lock.
+func (r *memoryTaskRepository) complete(task *models.Task, subtasks bool, next *models.Task, now time.Time, actor *models.Actor) error {
+	if err := r.checkVersion(task); err != nil {
+		return err
+	}
+	before := r.tasks[task.ID]
+	task.Version++
+	task.UpdatedAt = now
+	r.tasks[task.ID] = storedTask(task)
+	r.record(models.NewTaskEvent(models.UpdateEventType(&before, task), &before, task, actor), now)
+	if subtasks {
+		for _, id := range r.descendants(task.ID) {
+			r.completeTask(task.OwnerID, id, task.CompletedAt, now, actor)
+		}
+	}
+	if next != nil {
+		r.create(next, now, actor)
+	}
+	return nil
+}
+
+// completeTask marks one of the owner's tasks completed at completedAt
+// unless it is already completed or in the trash. The caller must hold the
+// write lock.
+func (r *memoryTaskRepository) completeTask(ownerID, id uint, completedAt *time.Time, now time.Time, actor *models.Actor) {
+	before, ok := r.tasks[id]
+	if !ok || before.OwnerID != ownerID || before.DeletedAt.Valid || before.Completed {
+		return
+	}
+	task := before
+	task.Completed = true
+	task.CompletedAt = completedAt
+	task.Version++
+	task.UpdatedAt = now
+	r.tasks[id] = task
+	r.record(models.NewTaskEvent(models.TaskEventCompleted, &before, &task, actor), now)
+}
+
+// Delete moves an existing task of the task's owner to the trash along
+// with its subtasks. The task must still be at the version it was read at,
+// and must have no subtasks unless cascade is set.
+func (r *memoryTaskRepository) Delete(task *models.Task, cascade bool, actor *models.Actor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkVersion(task); err != nil {
+		return err
+	}
+	if !cascade {
+		stored := []models.Task{r.tasks[task.ID]}
+		r.countSubtasks(stored)
+		if stored[0].SubtaskCount > 0 {
+			return hasSubtasks(task.ID, stored[0].SubtaskCount)
+		}
+	}
+	return r.trash(task.OwnerID, []uint{task.ID}, time.Now(), actor)
+}
+
+// trash moves tasks of the owner to the trash along with their subtasks,
+// failing without changes unless every task is found. The caller must hold
+// the write lock.
+func (r *memoryTaskRepository) trash(ownerID uint, ids []uint, now time.Time, actor *models.Actor) error {
+	for _, id := range ids {
+		if task, ok := r.tasks[id]; !ok || task.OwnerID != ownerID || task.DeletedAt.Valid {
+			return &apperrors.TaskNotFoundError{ID: id}
+		}
+	}
+	deletedAt := gorm.DeletedAt{Time: now, Valid: true}
+	for _, root := range ids {
+		for _, id := range append(r.descendants(root), root) {
+			before := r.tasks[id]
+			if before.DeletedAt.Valid {
+				continue
+			}
+			task := before
+			task.DeletedAt = deletedAt
+			task.Version++
+			task.UpdatedAt = now
+			r.tasks[id] = task
+			r.record(models.NewTaskEvent(models.TaskEventDeleted, &before, &task, actor), now)
+		}
+	}
+	return nil
+}
+
+// ApplyBatch applies the writes of a batch together, restoring the tasks
+// as they were when one fails. The new tags of the batch are created in
+// the tag repository using this one, and removed again when a write fails.
+func (r *memoryTaskRepository) ApplyBatch(batch *models.TaskBatch) error {
+	r.mu.RLock()
+	tags := r.tags
+	r.mu.RUnlock()
+
+	if len(batch.NewTags) == 0 || tags == nil {
+		return r.applyBatchLocked(batch)
+	}
+	// The tag lock is taken before the task lock, as when tags change
+	return tags.createTags(batch.OwnerID, batch.NewTags, func(created []models.Tag) error {
+		batch.SetTagIDs(created)
+		return r.applyBatchLocked(batch)
+	})
+}
+
+// applyBatchLocked applies the writes of a batch under the write lock,
+// restoring the tasks as they were when one fails
+func (r *memoryTaskRepository) applyBatchLocked(batch *models.TaskBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks, nextID, events, outbox := maps.Clone(r.tasks), r.nextID, len(r.events), len(r.outbox)
+	if err := r.applyBatch(batch, time.Now()); err != nil {
+		r.tasks, r.nextID, r.events, r.outbox = tasks, nextID, r.events[:events], r.outbox[:outbox]
+		return err
+	}
+	return nil
+}
+
+// applyBatch applies the writes of a batch in order. The caller must hold
+// the write lock.
+func (r *memoryTaskRepository) applyBatch(batch *models.TaskBatch, now time.Time) error {
+	for _, task := range batch.Creates {
+		r.create(task, now, batch.Actor)
+	}
+	for _, update := range batch.Updates {
+		if err := r.complete(update.Task, update.CompleteSubtasks, update.Next, now, batch.Actor); err != nil {
+			return err
+		}
+	}
+	for _, id := range batch.Completions {
+		completedAt := batch.CompletedAt
+		r.completeTask(batch.OwnerID, id, &completedAt, now, batch.Actor)
+	}
+	if len(batch.Deletes) > 0 {
+		return r.trash(batch.OwnerID, batch.Deletes, now, batch.Actor)
+	}
+	return nil
+}
+
+// FindTrash retrieves the owner's trashed tasks that are not below another
+// trashed task, most recently deleted first
+func (r *memoryTaskRepository) FindTrash(ownerID uint) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, task := range r.tasks {
+		if task.OwnerID != ownerID || !task.DeletedAt.Valid {
+			continue
+		}
+		if parent, ok := r.tasks[parentIDOf(&task)]; ok && parent.DeletedAt.Valid {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := &tasks[i], &tasks[j]
+		if !a.DeletedAt.Time.Equal(b.DeletedAt.Time) {
+			return a.DeletedAt.Time.After(b.DeletedAt.Time)
+		}
+		return a.ID > b.ID
+	})
+	r.countSubtasks(tasks)
+	return tasks, nil
+}
+
+// FindTrashed retrieves one of the owner's trashed tasks by its ID
+func (r *memoryTaskRepository) FindTrashed(ownerID, id uint) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok || task.OwnerID != ownerID || !task.DeletedAt.Valid {
+		return nil, &apperrors.TaskNotFoundError{ID: id}
+	}
+	tasks := []models.Task{task}
+	r.countSubtasks(tasks)
+	return &tasks[0], nil
+}
+
+// Restore takes one of the owner's trashed tasks out of the trash along
+// with the subtasks that were trashed with it
+func (r *memoryTaskRepository) Restore(ownerID, id uint, actor *models.Actor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok || task.OwnerID != ownerID || !task.DeletedAt.Valid {
+		return &apperrors.TaskNotFoundError{ID: id}
+	}
+	deletedAt := task.DeletedAt.Time
+	now := time.Now()
+	for _, id := range append(r.descendants(id), id) {
+		before := r.tasks[id]
+		if !before.DeletedAt.Valid || !before.DeletedAt.Time.Equal(deletedAt) {
+			continue
+		}
+		task := before
+		task.DeletedAt = gorm.DeletedAt{}
+		task.Version++
+		task.UpdatedAt = now
+		r.tasks[id] = task
+		r.record(models.NewTaskEvent(models.TaskEventRestored, &before, &task, actor), now)
+	}
+	return nil
+}
+
+// DeletePermanently removes one of the owner's trashed tasks for good,
+// along with its subtasks, and records that they were purged
+func (r *memoryTaskRepository) DeletePermanently(ownerID, id uint, actor *models.Actor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if task, ok := r.tasks[id]; !ok || task.OwnerID != ownerID || !task.DeletedAt.Valid {
+		return &apperrors.TaskNotFoundError{ID: id}
+	}
+	r.purge([]uint{id}, time.Now(), actor)
+	return nil
+}
+
+// Purge permanently removes the tasks of every owner that were trashed
+// before the given time, along with their subtasks, records that they were
+// purged and returns them
+func (r *memoryTaskRepository) Purge(before time.Time) ([]models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []uint
+	for id, task := range r.tasks {
+		if task.DeletedAt.Valid && task.DeletedAt.Time.Before(before) {
+			expired = append(expired, id)
+		}
+	}
+	slices.Sort(expired)
+	tasks := r.purge(expired, time.Now(), nil)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks, nil
+}
+
+// purge removes tasks and their descendants for good, like deleteTree, and
+// records that they were purged. It returns the tasks removed. The caller
+// must hold the write lock.
+func (r *memoryTaskRepository) purge(ids []uint, now time.Time, actor *models.Actor) []models.Task {
+	var purged []models.Task
+	for _, root := range ids {
+		if _, ok := r.tasks[root]; !ok {
+			// Removed along with an ancestor
+			continue
+		}
+		for _, id := range append(r.descendants(root), root) {
+			before := r.tasks[id]
+			task := before
+			task.Version++
+			r.record(models.NewTaskEvent(models.TaskEventPurged, &before, &task, actor), now)
+			purged = append(purged, before)
+			delete(r.tasks, id)
+		}
+	}
+	return purged
+}
+
+// Search finds the owner's tasks containing every query term, ordered by relevance
+func (r *memoryTaskRepository) Search(ownerID uint, query string) ([]models.TaskSearchResult, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return []models.TaskSearchResult{}, nil
+	}
+
+	r.mu.RLock()
+	var tasks []models.Task
+	for _, task := range r.tasks {
+		if task.OwnerID != ownerID || task.DeletedAt.Valid {
+			continue
+		}
+		content := strings.ToLower(task.Content)
+		matches := true
+		for _, term := range terms {
+			if !strings.Contains(content, term) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			tasks = append(tasks, task)
+		}
+	}
+	r.countSubtasks(tasks)
+	r.mu.RUnlock()
+
+	sortTasks(tasks, taskOrderKeys(nil), false)
+	return rankMatches(tasks, terms), nil
+}
+
+// FindEvents retrieves one page of the events in the owner's task history
+// matching the filter, newest first, starting after the page cursor when
+// one is given
+func (r *memoryTaskRepository) FindEvents(ownerID uint, filter *models.TaskEventFilter, page *models.PageRequest) (*models.TaskEventPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := []models.TaskEvent{}
+	for i := len(r.events) - 1; i >= 0 && len(events) <= page.Limit; i-- {
+		event := r.events[i]
+		if event.OwnerID != ownerID || (page.Cursor != nil && event.ID >= page.Cursor.ID) {
+			continue
+		}
+		if matchesEventFilter(&event, filter) {
+			events = append(events, event)
+		}
+	}
+	return eventPage(events, page.Limit), nil
+}
+
+// FindRecentEvents retrieves the latest events of each of the owner's
+// tasks, at most limit per task, newest first
+func (r *memoryTaskRepository) FindRecentEvents(ownerID uint, taskIDs []uint, limit int) ([]models.TaskEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := []models.TaskEvent{}
+	counts := make(map[uint]int, len(taskIDs))
+	for i := len(r.events) - 1; i >= 0; i-- {
+		event := r.events[i]
+		if event.OwnerID != ownerID || !slices.Contains(taskIDs, event.TaskID) || counts[event.TaskID] >= limit {
+			continue
+		}
+		counts[event.TaskID]++
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// FindEvent retrieves the event that took one of the owner's tasks to the
+// given version
+func (r *memoryTaskRepository) FindEvent(ownerID, taskID, version uint) (*models.TaskEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, event := range r.events {
+		if event.OwnerID == ownerID && event.TaskID == taskID && event.Version == version {
+			return &event, nil
+		}
+	}
+	return nil, &apperrors.VersionNotFoundError{ID: taskID, Version: version}
+}
+
+// releaseProject deletes the owner's tasks in a project, or moves them to the
+// inbox, when the project is deleted
+func (r *memoryTaskRepository) releaseProject(ownerID, projectID uint, deleteTasks bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, task := range r.tasks {
+		if task.OwnerID != ownerID || projectIDOf(&task) != projectID {
+			continue
+		}
+		if deleteTasks {
+			r.deleteTree(id)
+			continue
+		}
+		task.ProjectID = nil
+		task.Version++
+		task.UpdatedAt = now
+		r.tasks[id] = task
+	}
+}
+
+// renameTag updates a renamed tag on the owner's tasks
+func (r *memoryTaskRepository) renameTag(ownerID uint, tag models.Tag) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, task := range r.tasks {
+		if task.OwnerID != ownerID {
+			continue
+		}
+		if i := tagIndex(task.Tags, tag.ID); i >= 0 {
+			task.Tags = slices.Clone(task.Tags)
+			task.Tags[i] = tag
+			r.tasks[id] = storedTask(&task)
+		}
+	}
+}
+
+// mergeTag re-points the owner's tasks tagged with the source tag to the
+// target tag
+func (r *memoryTaskRepository) mergeTag(ownerID, sourceID uint, target models.Tag) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, task := range r.tasks {
+		if task.OwnerID != ownerID {
+			continue
+		}
+		if i := tagIndex(task.Tags, sourceID); i >= 0 {
+			task.Tags = slices.Delete(slices.Clone(task.Tags), i, i+1)
+			if tagIndex(task.Tags, target.ID) < 0 {
+				task.Tags = append(task.Tags, target)
+			}
+			r.tasks[id] = storedTask(&task)
+		}
+	}
+}
+
+// removeTag detaches a deleted tag from the owner's tasks
+func (r *memoryTaskRepository) removeTag(ownerID, tagID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, task := range r.tasks {
+		if task.OwnerID != ownerID {
+			continue
+		}
+		if i := tagIndex(task.Tags, tagID); i >= 0 {
+			task.Tags = slices.Delete(slices.Clone(task.Tags), i, i+1)
+			r.tasks[id] = task
+		}
+	}
+}
+
+// tagCounts returns the number of the owner's tasks carrying each tag
+func (r *memoryTaskRepository) tagCounts(ownerID uint) map[uint]int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[uint]int64)
+	for _, task := range r.tasks {
+		if task.OwnerID != ownerID || task.DeletedAt.Valid {
+			continue
+		}
+		for _, tag := range task.Tags {
+			counts[tag.ID]++
+		}
+	}
+	return counts
+}
+
+// useTags links the tag repository that creates the new tags of batches
+func (r *memoryTaskRepository) useTags(tags batchTagStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tags = tags
+}
+
+// descendants returns the IDs of every task below the given task.
+// The caller must hold the read lock.
+func (r *memoryTaskRepository) descendants(id uint) []uint {
+	children := make(map[uint][]uint)
+	for _, task := range r.tasks {
+		if task.ParentID != nil {
+			children[*task.ParentID] = append(children[*task.ParentID], task.ID)
+		}
+	}
+	var ids []uint
+	for queue := children[id]; len(queue) > 0; queue = queue[1:] {
+		ids = append(ids, queue[0])
+		queue = append(queue, children[queue[0]]...)
+	}
+	return ids
+}
+
+// deleteTree removes a task and its descendants, like the cascading foreign
+// key of the SQL schema. The caller must hold the write lock.
+func (r *memoryTaskRepository) deleteTree(id uint) {
+	for _, descendantID := range r.descendants(id) {
+		delete(r.tasks, descendantID)
+	}
+	delete(r.tasks, id)
+}
+
+// countSubtasks fills in the roll-up counts of the tasks' direct subtasks.
+// The caller must hold the read lock.
+func (r *memoryTaskRepository) countSubtasks(tasks []models.Task) {
+	index := make(map[uint]int, len(tasks))
+	for i := range tasks {
+		tasks[i].SubtaskCount, tasks[i].CompletedSubtaskCount = 0, 0
+		index[tasks[i].ID] = i
+	}
+	for _, task := range r.tasks {
+		if task.ParentID == nil || task.DeletedAt.Valid {
+			continue
+		}
+		if i, ok := index[*task.ParentID]; ok {
+			tasks[i].SubtaskCount++
+			if task.Completed {
+				tasks[i].CompletedSubtaskCount++
+			}
+		}
+	}
+}
+
+// match returns copies of the owner's stored tasks that satisfy the filter.
+// The caller must hold the read lock.
+func (r *memoryTaskRepository) match(ownerID uint, filter *models.TaskFilter) []models.Task {
+	tasks := make([]models.Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		if task.OwnerID == ownerID && !task.DeletedAt.Valid && matchesFilter(&task, filter) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// matchesFilter reports whether a task satisfies the filter conditions
+func matchesFilter(task *models.Task, filter *models.TaskFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.ProjectID != nil && projectIDOf(task) != *filter.ProjectID {
+		return false
+	}
+	if filter.ParentID != nil && parentIDOf(task) != *filter.ParentID {
+		return false
+	}
+	if len(filter.ParentIDs) > 0 && (task.ParentID == nil || !slices.Contains(filter.ParentIDs, *task.ParentID)) {
+		return false
+	}
+	if filter.SeriesID != nil && (task.SeriesID == nil || *task.SeriesID != *filter.SeriesID) {
+		return false
+	}
+	if len(filter.Tags) > 0 && !matchesTags(task, filter.Tags, filter.TagMode) {
+		return false
+	}
+	if filter.Completed != nil && task.Completed != *filter.Completed {
+		return false
+	}
+	if filter.DueFrom != nil && (task.DueAt == nil || task.DueAt.Before(*filter.DueFrom)) {
+		return false
+	}
+	if filter.DueBefore != nil && (task.DueAt == nil || !task.DueAt.Before(*filter.DueBefore)) {
+		return false
+	}
+	if filter.CreatedAfter != nil && !task.CreatedAt.After(*filter.CreatedAfter) {
+		return false
+	}
+	if filter.UpdatedBefore != nil && !task.UpdatedAt.Before(*filter.UpdatedBefore) {
+		return false
+	}
+	if filter.ContentContains != "" &&
+		!strings.Contains(strings.ToLower(task.Content), strings.ToLower(filter.ContentContains)) {
+		return false
+	}
+	return true
+}
+
+// matchesEventFilter reports whether a task event satisfies the filter
+// conditions
+func matchesEventFilter(event *models.TaskEvent, filter *models.TaskEventFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.TaskID != nil && event.TaskID != *filter.TaskID {
+		return false
+	}
+	if len(filter.Types) > 0 && !slices.Contains(filter.Types, event.Type) {
+		return false
+	}
+	if filter.TokenID != nil && (event.TokenID == nil || *event.TokenID != *filter.TokenID) {
+		return false
+	}
+	if filter.RequestID != "" && event.RequestID != filter.RequestID {
+		return false
+	}
+	if filter.Since != nil && event.CreatedAt.Before(*filter.Since) {
+		return false
+	}
+	if filter.Until != nil && !event.CreatedAt.Before(*filter.Until) {
+		return false
+	}
+	return true
+}
+
+// matchesTags reports whether a task carries all of the named tags, or any
+// of them in TagModeAny
+func matchesTags(task *models.Task, names []string, mode string) bool {
+	matched := 0
+	for _, name := range names {
+		if slices.ContainsFunc(task.Tags, func(tag models.Tag) bool { return tag.Name == name }) {
+			matched++
+		}
+	}
+	if mode == models.TagModeAny {
+		return matched > 0
+	}
+	return matched == len(names)
+}
+
+// tagIndex returns the index of the tag with the given ID, or -1
+func tagIndex(tags []models.Tag, id uint) int {
+	return slices.IndexFunc(tags, func(tag models.Tag) bool { return tag.ID == id })
+}
+
+// storedTask returns a copy of the task to store, with its own tag slice
+// ordered by name like the SQL repository returns them
+func storedTask(task *models.Task) models.Task {
+	stored := *task
+	stored.Tags = slices.Clone(task.Tags)
+	stored.Subtasks = nil
+	sort.Slice(stored.Tags, func(i, j int) bool { return stored.Tags[i].Name < stored.Tags[j].Name })
+	return stored
+}
+
+// projectIDOf returns the task's project ID, or 0 for inbox tasks
+func projectIDOf(task *models.Task) uint {
+	if task.ProjectID == nil {
+		return 0
+	}
+	return *task.ProjectID
+}
+
+// parentIDOf returns the task's parent ID, or 0 for top-level tasks
+func parentIDOf(task *models.Task) uint {
+	if task.ParentID == nil {
+		return 0
+	}
+	return *task.ParentID
+}
+
+// sortTasks orders tasks by the keys, reversed when paging backward
+func sortTasks(tasks []models.Task, keys []models.SortField, backward bool) {
+	values := make(map[uint][]interface{}, len(tasks))
+	for i := range tasks {
+		values[tasks[i].ID] = orderValues(&tasks[i], keys)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return compareOrder(values[tasks[i].ID], values[tasks[j].ID], keys, backward) < 0
+	})
+}
+
+// orderValues returns the values of a task for the ordering columns
+func orderValues(task *models.Task, keys []models.SortField) []interface{} {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		switch key.Field {
+		case "created_at":
+			values[i] = task.CreatedAt
+		case "updated_at":
+			values[i] = task.UpdatedAt
+		case "content":
+			values[i] = task.Content
+		case "completed":
+			values[i] = task.Completed
+		case "id":
+			values[i] = task.ID
+		}
+	}
+	return values
+}
+
+// compareOrder compares the ordering values of two rows, returning a negative
+// number when a sorts first, zero when equal, and a positive number otherwise
+func compareOrder(a, b []interface{}, keys []models.SortField, backward bool) int {
+	for i, key := range keys {
+		c := compareValues(a[i], b[i])
+		if c == 0 {
+			continue
+		}
+		if key.Desc != backward {
+			return -c
+		}
+		return c
+	}
+	return 0
+}
+
+// compareValues compares two ordering values of the same type
+func compareValues(a, b interface{}) int {
+	switch av := a.(type) {
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(av, b.(string))
+	case uint:
+		bv := b.(uint)
+		if av < bv {
+			return -1
+		}
+		if av > bv {
+			return 1
+		}
+		return 0
+	case bool:
+		bv := b.(bool)
+		if av == bv {
+			return 0
+		}
+		if !av {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
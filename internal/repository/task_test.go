@@ -1,24 +1,47 @@
 package repository
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/database"
+	"github.com/todo-api-go-sda/internal/database/migrations"
 	"github.com/todo-api-go-sda/internal/models"
 	apperrors "github.com/todo-api-go-sda/pkg/errors"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// Owners of the tasks created in these tests
+const (
+	testOwnerID  = uint(1)
+	otherOwnerID = uint(2)
+)
+
+func contents(tasks []models.Task) []string {
+	result := make([]string, len(tasks))
+	for i, task := range tasks {
+		result[i] = task.Content
+	}
+	return result
+}
+
 func setupTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(database.SQLiteDSN(":memory:")), &gorm.Config{TranslateError: true})
 	if err != nil {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
-	err = db.AutoMigrate(&models.Task{})
+	migrator, err := migrations.New(db)
 	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Up(); err != nil {
 		t.Fatalf("Failed to migrate: %v", err)
 	}
+	seedOwners(t, db)
 	return db
 }
 
@@ -26,8 +49,8 @@ func TestTaskRepository_Create(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewTaskRepository(db)
 
-	task := &models.Task{Content: "Test task"}
-	err := repo.Create(task)
+	task := &models.Task{OwnerID: testOwnerID, Content: "Test task"}
+	err := repo.Create(task, nil)
 
 	assert.NoError(t, err)
 	assert.NotZero(t, task.ID)
@@ -38,26 +61,62 @@ func TestTaskRepository_FindAll(t *testing.T) {
 	repo := NewTaskRepository(db)
 
 	// Create some tasks
-	err := repo.Create(&models.Task{Content: "Task 1"})
+	err := repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Task 1"}, nil)
 	assert.NoError(t, err)
-	err = repo.Create(&models.Task{Content: "Task 2"})
+	err = repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Task 2"}, nil)
 	assert.NoError(t, err)
 
-	tasks, err := repo.FindAll()
+	tasks, err := repo.FindAll(testOwnerID, nil)
 
 	assert.NoError(t, err)
 	assert.Len(t, tasks, 2)
 }
 
+func TestTaskRepository_FindPage_CustomSort(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTaskRepository(db)
+
+	for _, content := range []string{"b", "a", "c", "a"} {
+		err := repo.Create(&models.Task{OwnerID: testOwnerID, Content: content}, nil)
+		assert.NoError(t, err)
+	}
+
+	filter := &models.TaskFilter{Sort: []models.SortField{{Field: "content"}}}
+	var seen []string
+	page := &models.PageRequest{Limit: 3}
+	for {
+		result, err := repo.FindPage(testOwnerID, filter, page)
+		assert.NoError(t, err)
+		seen = append(seen, contents(result.Tasks)...)
+		if result.NextCursor == nil {
+			break
+		}
+		page = &models.PageRequest{Limit: 3, Cursor: result.NextCursor}
+	}
+
+	assert.Equal(t, []string{"a", "a", "b", "c"}, seen)
+}
+
+func TestTaskRepository_FindPage_CursorSortMismatch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTaskRepository(db)
+
+	filter := &models.TaskFilter{Sort: []models.SortField{{Field: "content"}}}
+	_, err := repo.FindPage(testOwnerID, filter, &models.PageRequest{Limit: 2, Cursor: &models.Cursor{ID: 1}})
+
+	assert.Error(t, err)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+}
+
 func TestTaskRepository_FindByID_Success(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewTaskRepository(db)
 
-	created := &models.Task{Content: "Test task"}
-	err := repo.Create(created)
+	created := &models.Task{OwnerID: testOwnerID, Content: "Test task"}
+	err := repo.Create(created, nil)
 	assert.NoError(t, err)
 
-	task, err := repo.FindByID(created.ID)
+	task, err := repo.FindByID(testOwnerID, created.ID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, created.ID, task.ID)
@@ -68,7 +127,7 @@ func TestTaskRepository_FindByID_NotFound(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewTaskRepository(db)
 
-	task, err := repo.FindByID(999)
+	task, err := repo.FindByID(testOwnerID, 999)
 
 	assert.Error(t, err)
 	assert.Nil(t, task)
@@ -79,16 +138,16 @@ func TestTaskRepository_Update(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewTaskRepository(db)
 
-	task := &models.Task{Content: "Old content"}
-	err := repo.Create(task)
+	task := &models.Task{OwnerID: testOwnerID, Content: "Old content"}
+	err := repo.Create(task, nil)
 	assert.NoError(t, err)
 
 	task.Content = "New content"
-	err = repo.Update(task)
+	err = repo.Update(task, nil)
 
 	assert.NoError(t, err)
 
-	updated, _ := repo.FindByID(task.ID)
+	updated, _ := repo.FindByID(testOwnerID, task.ID)
 	assert.Equal(t, "New content", updated.Content)
 }
 
@@ -96,15 +155,15 @@ func TestTaskRepository_Delete_Success(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewTaskRepository(db)
 
-	task := &models.Task{Content: "Task to delete"}
-	err := repo.Create(task)
+	task := &models.Task{OwnerID: testOwnerID, Content: "Task to delete"}
+	err := repo.Create(task, nil)
 	assert.NoError(t, err)
 
-	err = repo.Delete(task.ID)
+	err = repo.Delete(task, true, nil)
 
 	assert.NoError(t, err)
 
-	_, err = repo.FindByID(task.ID)
+	_, err = repo.FindByID(testOwnerID, task.ID)
 	assert.Error(t, err)
 }
 
@@ -112,8 +171,88 @@ func TestTaskRepository_Delete_NotFound(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewTaskRepository(db)
 
-	err := repo.Delete(999)
+	err := repo.Delete(&models.Task{ID: 999, OwnerID: testOwnerID, Version: 1}, true, nil)
 
 	assert.Error(t, err)
 	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
 }
+
+func TestTaskRepository_Search_RanksAndHighlights(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTaskRepository(db)
+
+	err := repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Buy milk"}, nil)
+	assert.NoError(t, err)
+	err = repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Milk the cow, then buy more milk"}, nil)
+	assert.NoError(t, err)
+	err = repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Walk the dog"}, nil)
+	assert.NoError(t, err)
+
+	results, err := repo.Search(testOwnerID, "MILK")
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "Milk the cow, then buy more milk", results[0].Content)
+	assert.Equal(t, "<mark>Milk</mark> the cow, then buy more <mark>milk</mark>", results[0].Snippet)
+	assert.Greater(t, results[0].Rank, results[1].Rank)
+}
+
+func TestTaskRepository_Search_SnippetAroundFirstMatch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTaskRepository(db)
+
+	before := strings.Repeat("before ", 30)
+	after := strings.Repeat(" after", 30)
+	err := repo.Create(&models.Task{OwnerID: testOwnerID, Content: before + "<b>milk</b> & Milk" + after + " milk"}, nil)
+	assert.NoError(t, err)
+
+	results, err := repo.Search(testOwnerID, "milk")
+
+	assert.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 3.0, results[0].Rank)
+	assert.Equal(t, strings.Repeat("before ", 10)+"&lt;b&gt;<mark>milk</mark>&lt;/b&gt; &amp; <mark>Milk</mark>"+strings.Repeat(" after", 8),
+		results[0].Snippet)
+}
+
+func TestTaskRepository_Search_MatchesAllTerms(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTaskRepository(db)
+
+	err := repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Buy milk"}, nil)
+	assert.NoError(t, err)
+	err = repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Buy bread"}, nil)
+	assert.NoError(t, err)
+
+	results, err := repo.Search(testOwnerID, "buy bread")
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Buy bread", results[0].Content)
+}
+
+func TestTaskRepository_Search_EscapesWildcards(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTaskRepository(db)
+
+	err := repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Reach 100% coverage"}, nil)
+	assert.NoError(t, err)
+	err = repo.Create(&models.Task{OwnerID: testOwnerID, Content: "Reach 100 users"}, nil)
+	assert.NoError(t, err)
+
+	results, err := repo.Search(testOwnerID, "100%")
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Reach 100% coverage", results[0].Content)
+}
+
+// seedOwners creates the users that own the test tasks
+func seedOwners(t *testing.T, db *gorm.DB) {
+	for _, id := range []uint{testOwnerID, otherOwnerID} {
+		user := &models.User{ID: id, Email: fmt.Sprintf("owner%d@example.com", id), PasswordHash: "x"}
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("Failed to create owner: %v", err)
+		}
+	}
+}
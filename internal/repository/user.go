@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ErrUserNotFound is returned when no user matches a lookup
+var ErrUserNotFound = errors.New("user not found")
+
+// UserRepository defines the interface for user data access
+type UserRepository interface {
+	Create(user *models.User) error
+	FindByID(id uint) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
+	Update(user *models.User) error
+}
+
+// userRepository implements UserRepository using GORM
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a new UserRepository instance
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+// Create creates a new user in the database
+func (r *userRepository) Create(user *models.User) error {
+	err := r.db.Create(user).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return &apperrors.ConflictError{Message: "email is already registered"}
+	}
+	return err
+}
+
+// FindByID retrieves a user by its ID
+func (r *userRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	return r.first(r.db.Where("id = ?", id), &user)
+}
+
+// FindByEmail retrieves a user by email address
+func (r *userRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	return r.first(r.db.Where("email = ?", email), &user)
+}
+
+// Update updates an existing user in the database
+func (r *userRepository) Update(user *models.User) error {
+	result := r.db.Model(user).Select("*").Omit("created_at").Updates(user)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// first loads the first user matching the query
+func (r *userRepository) first(tx *gorm.DB, user *models.User) (*models.User, error) {
+	if err := tx.First(user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
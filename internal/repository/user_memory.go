@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// memoryUserRepository implements UserRepository in process memory.
+// It is safe for concurrent use.
+type memoryUserRepository struct {
+	mu      sync.RWMutex
+	users   map[uint]models.User
+	byEmail map[string]uint
+	nextID  uint
+}
+
+// NewMemoryUserRepository creates a new in-memory UserRepository instance
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{
+		users:   make(map[uint]models.User),
+		byEmail: make(map[string]uint),
+		nextID:  1,
+	}
+}
+
+// Create stores a new user, assigning its ID and timestamps
+func (r *memoryUserRepository) Create(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byEmail[user.Email]; exists {
+		return &apperrors.ConflictError{Message: "email is already registered"}
+	}
+
+	now := time.Now()
+	user.ID = r.nextID
+	r.nextID++
+	if user.TimeZone == "" {
+		user.TimeZone = models.DefaultTimeZone
+	}
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.users[user.ID] = *user
+	r.byEmail[user.Email] = user.ID
+	return nil
+}
+
+// FindByID retrieves a user by its ID
+func (r *memoryUserRepository) FindByID(id uint) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// FindByEmail retrieves a user by email address
+func (r *memoryUserRepository) FindByEmail(email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byEmail[email]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	user := r.users[id]
+	return &user, nil
+}
+
+// Update replaces an existing user and refreshes its update timestamp. The
+// email address cannot change.
+func (r *memoryUserRepository) Update(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.Email = stored.Email
+	user.CreatedAt = stored.CreatedAt
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = *user
+	return nil
+}
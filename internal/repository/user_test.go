@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestUserRepository(t *testing.T) {
+	backends := map[string]func(t *testing.T) UserRepository{
+		"sqlite": func(t *testing.T) UserRepository {
+			return NewUserRepository(setupTestDB(t))
+		},
+		"memory": func(t *testing.T) UserRepository {
+			return NewMemoryUserRepository()
+		},
+	}
+	for name, newRepo := range backends {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			user := &models.User{Email: "ada@example.com", PasswordHash: "hash"}
+			require.NoError(t, repo.Create(user))
+			assert.NotZero(t, user.ID)
+
+			found, err := repo.FindByEmail("ada@example.com")
+			require.NoError(t, err)
+			assert.Equal(t, user.ID, found.ID)
+
+			found, err = repo.FindByID(user.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "ada@example.com", found.Email)
+			assert.Equal(t, "UTC", found.TimeZone)
+
+			found.TimeZone = "Europe/Paris"
+			require.NoError(t, repo.Update(found))
+			found, err = repo.FindByID(user.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Europe/Paris", found.TimeZone)
+			assert.ErrorIs(t, repo.Update(&models.User{ID: 999, Email: "x@example.com"}), ErrUserNotFound)
+
+			_, err = repo.FindByEmail("nobody@example.com")
+			assert.ErrorIs(t, err, ErrUserNotFound)
+			_, err = repo.FindByID(999)
+			assert.ErrorIs(t, err, ErrUserNotFound)
+
+			err = repo.Create(&models.User{Email: "ada@example.com", PasswordHash: "hash"})
+			assert.IsType(t, &apperrors.ConflictError{}, err)
+		})
+	}
+}
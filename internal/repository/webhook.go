@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository defines the interface for webhook and webhook delivery
+// data access
+type WebhookRepository interface {
+	Create(webhook *models.Webhook) error
+	FindAll(ownerID uint) ([]models.Webhook, error)
+	FindByID(ownerID, id uint) (*models.Webhook, error)
+	Update(webhook *models.Webhook) error
+	Delete(ownerID, id uint) error
+	DispatchOutbox(now time.Time, limit int) (int, error)
+	ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	FindDeliveries(ownerID uint, filter *models.WebhookDeliveryFilter, page *models.PageRequest) (*models.WebhookDeliveryPage, error)
+	FindDelivery(ownerID, id uint) (*models.WebhookDelivery, error)
+}
+
+// webhookRepository implements WebhookRepository using GORM
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository instance
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// Create stores a new webhook
+func (r *webhookRepository) Create(webhook *models.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+// FindAll retrieves the owner's webhooks, oldest first
+func (r *webhookRepository) FindAll(ownerID uint) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.Where("owner_id = ?", ownerID).Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// FindByID retrieves one of the owner's webhooks
+func (r *webhookRepository) FindByID(ownerID, id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := r.db.Where("owner_id = ?", ownerID).First(&webhook, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.WebhookNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// Update saves the changes to an existing webhook
+func (r *webhookRepository) Update(webhook *models.Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+// Delete removes one of the owner's webhooks along with its deliveries
+func (r *webhookRepository) Delete(ownerID, id uint) error {
+	result := r.db.Where("owner_id = ?", ownerID).Delete(&models.Webhook{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &apperrors.WebhookNotFoundError{ID: id}
+	}
+	return nil
+}
+
+// skipLocked locks the rows a query reads for the rest of the transaction
+// and leaves out the ones another transaction has locked, so that the
+// replicas of the API each take a different batch. SQLite, which only runs
+// one replica, ignores it.
+var skipLocked = clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}
+
+// DispatchOutbox turns up to limit of the oldest outbox messages into
+// pending deliveries, due at now, to the active webhooks of their owner
+// that subscribe to them, and removes the messages. It returns the number
+// of messages dispatched.
+func (r *webhookRepository) DispatchOutbox(now time.Time, limit int) (int, error) {
+	var dispatched int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var messages []models.OutboxMessage
+		if err := tx.Clauses(skipLocked).Order("id").Limit(limit).Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		owners := map[uint][]models.Webhook{}
+		var deliveries []models.WebhookDelivery
+		ids := make([]uint, len(messages))
+		for i, message := range messages {
+			ids[i] = message.ID
+			webhooks, ok := owners[message.OwnerID]
+			if !ok {
+				if err := tx.Where("owner_id = ? AND active = ?", message.OwnerID, true).Find(&webhooks).Error; err != nil {
+					return err
+				}
+				owners[message.OwnerID] = webhooks
+			}
+			deliveries = append(deliveries, newDeliveries(webhooks, &message, now)...)
+		}
+		if len(deliveries) > 0 {
+			if err := tx.Create(&deliveries).Error; err != nil {
+				return err
+			}
+		}
+		dispatched = len(messages)
+		return tx.Where("id IN ?", ids).Delete(&models.OutboxMessage{}).Error
+	})
+	return dispatched, err
+}
+
+// ClaimDueDeliveries claims up to limit pending deliveries to active
+// webhooks whose next attempt is due at now, oldest first, with their
+// webhook. Their next attempt is moved to the end of the lease so that no
+// other dispatcher claims them until the lease has expired.
+func (r *webhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(skipLocked).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+			Where("webhook_id IN (?)", tx.Model(&models.Webhook{}).Select("id").Where("active = ?", true)).
+			Order("next_attempt_at, id").Limit(limit).Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		expires := now.Add(lease)
+		ids := make([]uint, len(deliveries))
+		webhookIDs := make([]uint, 0, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			deliveries[i].NextAttemptAt = &expires
+			webhookIDs = append(webhookIDs, deliveries[i].WebhookID)
+		}
+		if err := tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", expires).Error; err != nil {
+			return err
+		}
+
+		var webhooks []models.Webhook
+		if err := tx.Where("id IN ?", webhookIDs).Find(&webhooks).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]*models.Webhook, len(webhooks))
+		for i := range webhooks {
+			byID[webhooks[i].ID] = &webhooks[i]
+		}
+		for i := range deliveries {
+			deliveries[i].Webhook = byID[deliveries[i].WebhookID]
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+// UpdateDelivery saves the outcome of an attempt at a delivery
+func (r *webhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Omit(clause.Associations).Save(delivery).Error
+}
+
+// FindDeliveries retrieves one page of the owner's deliveries matching the
+// filter, newest first, starting after the page cursor when one is given
+func (r *webhookRepository) FindDeliveries(ownerID uint, filter *models.WebhookDeliveryFilter, page *models.PageRequest) (*models.WebhookDeliveryPage, error) {
+	tx := r.db.Where("owner_id = ?", ownerID)
+	if filter.WebhookID != nil {
+		tx = tx.Where("webhook_id = ?", *filter.WebhookID)
+	}
+	if filter.Status != "" {
+		tx = tx.Where("status = ?", filter.Status)
+	}
+	if page.Cursor != nil {
+		tx = tx.Where("id < ?", page.Cursor.ID)
+	}
+	var deliveries []models.WebhookDelivery
+	if err := tx.Order("id DESC").Limit(page.Limit + 1).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveryPage(deliveries, page.Limit), nil
+}
+
+// FindDelivery retrieves one of the owner's deliveries
+func (r *webhookRepository) FindDelivery(ownerID, id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.Where("owner_id = ?", ownerID).First(&delivery, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &apperrors.DeliveryNotFoundError{ID: id}
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// newDeliveries builds the pending deliveries of an outbox message to the
+// webhooks that subscribe to it
+func newDeliveries(webhooks []models.Webhook, message *models.OutboxMessage, now time.Time) []models.WebhookDelivery {
+	var deliveries []models.WebhookDelivery
+	for i := range webhooks {
+		if !webhooks[i].Subscribes(message.Type) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhooks[i].ID,
+			OwnerID:       message.OwnerID,
+			EventID:       message.EventID,
+			Type:          message.Type,
+			Payload:       message.Payload,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+	return deliveries
+}
+
+// deliveryPage cuts deliveries read newest first, one more than the page
+// limit when more follow, down to one page
+func deliveryPage(deliveries []models.WebhookDelivery, limit int) *models.WebhookDeliveryPage {
+	page := &models.WebhookDeliveryPage{Deliveries: deliveries}
+	if len(deliveries) > limit {
+		page.Deliveries = deliveries[:limit]
+		last := &page.Deliveries[limit-1]
+		page.NextCursor = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	return page
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// webhookOutboxStore is implemented by task repositories that keep their
+// outbox in process memory, so that it can be dispatched to webhooks
+type webhookOutboxStore interface {
+	takeOutbox(limit int) []models.OutboxMessage
+}
+
+// memoryWebhookRepository implements WebhookRepository in process memory.
+// It is safe for concurrent use.
+type memoryWebhookRepository struct {
+	mu             sync.RWMutex
+	webhooks       map[uint]models.Webhook
+	nextID         uint
+	deliveries     []models.WebhookDelivery
+	nextDeliveryID uint
+	outbox         webhookOutboxStore
+}
+
+// NewMemoryWebhookRepository creates a new in-memory WebhookRepository
+// instance. The outbox of the given task repository is dispatched when that
+// repository is also in memory.
+func NewMemoryWebhookRepository(tasks TaskRepository) WebhookRepository {
+	store, _ := tasks.(webhookOutboxStore)
+	return &memoryWebhookRepository{
+		webhooks:       make(map[uint]models.Webhook),
+		nextID:         1,
+		nextDeliveryID: 1,
+		outbox:         store,
+	}
+}
+
+// Create stores a new webhook, assigning its ID and timestamps
+func (r *memoryWebhookRepository) Create(webhook *models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	webhook.ID = r.nextID
+	r.nextID++
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+	r.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+// FindAll retrieves the owner's webhooks, oldest first
+func (r *memoryWebhookRepository) FindAll(ownerID uint) ([]models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := []models.Webhook{}
+	for id := uint(1); id < r.nextID; id++ {
+		if webhook, ok := r.webhooks[id]; ok && webhook.OwnerID == ownerID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+// FindByID retrieves one of the owner's webhooks
+func (r *memoryWebhookRepository) FindByID(ownerID, id uint) (*models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok || webhook.OwnerID != ownerID {
+		return nil, &apperrors.WebhookNotFoundError{ID: id}
+	}
+	return &webhook, nil
+}
+
+// Update saves the changes to an existing webhook
+func (r *memoryWebhookRepository) Update(webhook *models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[webhook.ID]; !ok {
+		return &apperrors.WebhookNotFoundError{ID: webhook.ID}
+	}
+	webhook.UpdatedAt = time.Now()
+	r.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+// Delete removes one of the owner's webhooks along with its deliveries
+func (r *memoryWebhookRepository) Delete(ownerID, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok || webhook.OwnerID != ownerID {
+		return &apperrors.WebhookNotFoundError{ID: id}
+	}
+	delete(r.webhooks, id)
+	kept := r.deliveries[:0]
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID != id {
+			kept = append(kept, delivery)
+		}
+	}
+	r.deliveries = kept
+	return nil
+}
+
+// DispatchOutbox turns up to limit of the oldest outbox messages into
+// pending deliveries, due at now, to the active webhooks of their owner
+// that subscribe to them, and removes the messages. It returns the number
+// of messages dispatched.
+func (r *memoryWebhookRepository) DispatchOutbox(now time.Time, limit int) (int, error) {
+	if r.outbox == nil {
+		return 0, nil
+	}
+	messages := r.outbox.takeOutbox(limit)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range messages {
+		var webhooks []models.Webhook
+		for id := uint(1); id < r.nextID; id++ {
+			if webhook, ok := r.webhooks[id]; ok && webhook.OwnerID == messages[i].OwnerID {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+		for _, delivery := range newDeliveries(webhooks, &messages[i], now) {
+			delivery.ID = r.nextDeliveryID
+			r.nextDeliveryID++
+			delivery.CreatedAt = now
+			delivery.UpdatedAt = now
+			r.deliveries = append(r.deliveries, delivery)
+		}
+	}
+	return len(messages), nil
+}
+
+// ClaimDueDeliveries claims up to limit pending deliveries to active
+// webhooks whose next attempt is due at now, with their webhook, moving
+// their next attempt to the end of the lease
+func (r *memoryWebhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expires := now.Add(lease)
+	deliveries := []models.WebhookDelivery{}
+	for i := range r.deliveries {
+		if len(deliveries) == limit {
+			break
+		}
+		delivery := &r.deliveries[i]
+		if delivery.Status != models.DeliveryPending || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		webhook, ok := r.webhooks[delivery.WebhookID]
+		if !ok || !webhook.Active {
+			continue
+		}
+		delivery.NextAttemptAt = &expires
+		claimed := *delivery
+		claimed.Webhook = &webhook
+		deliveries = append(deliveries, claimed)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the outcome of an attempt at a delivery
+func (r *memoryWebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == delivery.ID {
+			delivery.UpdatedAt = time.Now()
+			stored := *delivery
+			stored.Webhook = nil
+			r.deliveries[i] = stored
+			return nil
+		}
+	}
+	return &apperrors.DeliveryNotFoundError{ID: delivery.ID}
+}
+
+// FindDeliveries retrieves one page of the owner's deliveries matching the
+// filter, newest first, starting after the page cursor when one is given
+func (r *memoryWebhookRepository) FindDeliveries(ownerID uint, filter *models.WebhookDeliveryFilter, page *models.PageRequest) (*models.WebhookDeliveryPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for i := len(r.deliveries) - 1; i >= 0 && len(deliveries) <= page.Limit; i-- {
+		delivery := r.deliveries[i]
+		if delivery.OwnerID != ownerID || (page.Cursor != nil && delivery.ID >= page.Cursor.ID) {
+			continue
+		}
+		if filter.WebhookID != nil && delivery.WebhookID != *filter.WebhookID {
+			continue
+		}
+		if filter.Status != "" && delivery.Status != filter.Status {
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveryPage(deliveries, page.Limit), nil
+}
+
+// FindDelivery retrieves one of the owner's deliveries
+func (r *memoryWebhookRepository) FindDelivery(ownerID, id uint) (*models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, delivery := range r.deliveries {
+		if delivery.ID == id && delivery.OwnerID == ownerID {
+			return &delivery, nil
+		}
+	}
+	return nil, &apperrors.DeliveryNotFoundError{ID: id}
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// webhookBackends returns matching webhook and task repositories per backend
+func webhookBackends() map[string]func(t *testing.T) (WebhookRepository, TaskRepository) {
+	return map[string]func(t *testing.T) (WebhookRepository, TaskRepository){
+		"sqlite": func(t *testing.T) (WebhookRepository, TaskRepository) {
+			db := setupTestDB(t)
+			return NewWebhookRepository(db), NewTaskRepository(db)
+		},
+		"memory": func(t *testing.T) (WebhookRepository, TaskRepository) {
+			tasks := NewMemoryTaskRepository()
+			return NewMemoryWebhookRepository(tasks), tasks
+		},
+	}
+}
+
+func TestWebhookRepository_CRUD(t *testing.T) {
+	for name, newRepos := range webhookBackends() {
+		t.Run(name, func(t *testing.T) {
+			webhooks, _ := newRepos(t)
+
+			hook := &models.Webhook{OwnerID: testOwnerID, URL: "https://example.com/hook", Secret: "s3cret", Events: "task.created", Active: true}
+			require.NoError(t, webhooks.Create(hook))
+			assert.NotZero(t, hook.ID)
+
+			found, err := webhooks.FindByID(testOwnerID, hook.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/hook", found.URL)
+			_, err = webhooks.FindByID(otherOwnerID, hook.ID)
+			assert.IsType(t, &apperrors.WebhookNotFoundError{}, err)
+
+			found.Events = "task.created task.deleted"
+			found.Active = false
+			require.NoError(t, webhooks.Update(found))
+			list, err := webhooks.FindAll(testOwnerID)
+			require.NoError(t, err)
+			require.Len(t, list, 1)
+			assert.Equal(t, []string{"task.created", "task.deleted"}, list[0].EventList())
+			assert.False(t, list[0].Active)
+
+			assert.IsType(t, &apperrors.WebhookNotFoundError{}, webhooks.Delete(otherOwnerID, hook.ID))
+			require.NoError(t, webhooks.Delete(testOwnerID, hook.ID))
+			list, err = webhooks.FindAll(testOwnerID)
+			require.NoError(t, err)
+			assert.Empty(t, list)
+		})
+	}
+}
+
+func TestWebhookRepository_DispatchOutbox(t *testing.T) {
+	for name, newRepos := range webhookBackends() {
+		t.Run(name, func(t *testing.T) {
+			webhooks, tasks := newRepos(t)
+			actor := &models.Actor{UserID: testOwnerID}
+
+			all := &models.Webhook{OwnerID: testOwnerID, URL: "https://example.com/all", Secret: "a", Events: "task.created task.updated", Active: true}
+			created := &models.Webhook{OwnerID: testOwnerID, URL: "https://example.com/created", Secret: "b", Events: "task.created", Active: true}
+			inactive := &models.Webhook{OwnerID: testOwnerID, URL: "https://example.com/off", Secret: "c", Events: "task.created", Active: true}
+			other := &models.Webhook{OwnerID: otherOwnerID, URL: "https://example.com/other", Secret: "d", Events: "task.created", Active: true}
+			for _, hook := range []*models.Webhook{all, created, inactive, other} {
+				require.NoError(t, webhooks.Create(hook))
+			}
+			inactive.Active = false
+			require.NoError(t, webhooks.Update(inactive))
+
+			task := &models.Task{OwnerID: testOwnerID, Content: "Write report"}
+			require.NoError(t, tasks.Create(task, actor))
+			task.Content = "Write the report"
+			require.NoError(t, tasks.Update(task, actor))
+
+			now := time.Now()
+			dispatched, err := webhooks.DispatchOutbox(now, 10)
+			require.NoError(t, err)
+			assert.Equal(t, 2, dispatched)
+			dispatched, err = webhooks.DispatchOutbox(now, 10)
+			require.NoError(t, err)
+			assert.Zero(t, dispatched)
+
+			page, err := webhooks.FindDeliveries(testOwnerID, &models.WebhookDeliveryFilter{}, &models.PageRequest{Limit: 2})
+			require.NoError(t, err)
+			require.Len(t, page.Deliveries, 2)
+			require.NotNil(t, page.NextCursor)
+			assert.Equal(t, models.WebhookEventTaskUpdated, page.Deliveries[0].Type)
+			assert.Equal(t, all.ID, page.Deliveries[0].WebhookID)
+			assert.Equal(t, models.DeliveryPending, page.Deliveries[0].Status)
+			assert.Contains(t, page.Deliveries[0].Payload, `"type":"task.updated"`)
+			next, err := webhooks.FindDeliveries(testOwnerID, &models.WebhookDeliveryFilter{}, &models.PageRequest{Limit: 2, Cursor: page.NextCursor})
+			require.NoError(t, err)
+			assert.Len(t, next.Deliveries, 1)
+			assert.Nil(t, next.NextCursor)
+
+			byHook, err := webhooks.FindDeliveries(testOwnerID, &models.WebhookDeliveryFilter{WebhookID: &created.ID}, &models.PageRequest{Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, byHook.Deliveries, 1)
+			assert.Equal(t, models.WebhookEventTaskCreated, byHook.Deliveries[0].Type)
+			others, err := webhooks.FindDeliveries(otherOwnerID, &models.WebhookDeliveryFilter{}, &models.PageRequest{Limit: 10})
+			require.NoError(t, err)
+			assert.Empty(t, others.Deliveries)
+
+			due, err := webhooks.ClaimDueDeliveries(now, time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, due, 3)
+			assert.Equal(t, now.Add(time.Minute), *due[0].NextAttemptAt)
+			claimed, err := webhooks.ClaimDueDeliveries(now, time.Minute, 10)
+			require.NoError(t, err)
+			assert.Empty(t, claimed, "claimed until the lease expires")
+			require.NotNil(t, due[0].Webhook)
+			assert.Equal(t, "a", due[0].Webhook.Secret)
+
+			delivery := due[0]
+			delivery.Status = models.DeliveryDead
+			delivery.Attempts = 5
+			delivery.LastError = "connection refused"
+			require.NoError(t, webhooks.UpdateDelivery(&delivery))
+			dead, err := webhooks.FindDeliveries(testOwnerID, &models.WebhookDeliveryFilter{Status: models.DeliveryDead}, &models.PageRequest{Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, dead.Deliveries, 1)
+			assert.Equal(t, 5, dead.Deliveries[0].Attempts)
+			due, err = webhooks.ClaimDueDeliveries(now.Add(time.Minute), time.Minute, 10)
+			require.NoError(t, err)
+			assert.Len(t, due, 2)
+
+			found, err := webhooks.FindDelivery(testOwnerID, delivery.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "connection refused", found.LastError)
+			_, err = webhooks.FindDelivery(otherOwnerID, delivery.ID)
+			assert.IsType(t, &apperrors.DeliveryNotFoundError{}, err)
+
+			require.NoError(t, webhooks.Delete(testOwnerID, all.ID))
+			_, err = webhooks.FindDelivery(testOwnerID, delivery.ID)
+			assert.IsType(t, &apperrors.DeliveryNotFoundError{}, err)
+		})
+	}
+}
+
+func TestWebhookRepository_BatchRollbackDropsOutbox(t *testing.T) {
+	for name, newRepos := range webhookBackends() {
+		t.Run(name, func(t *testing.T) {
+			webhooks, tasks := newRepos(t)
+			hook := &models.Webhook{OwnerID: testOwnerID, URL: "https://example.com/hook", Secret: "s", Events: "task.created", Active: true}
+			require.NoError(t, webhooks.Create(hook))
+
+			err := tasks.ApplyBatch(&models.TaskBatch{
+				OwnerID: testOwnerID,
+				Creates: []*models.Task{{OwnerID: testOwnerID, Content: "Never saved"}},
+				Deletes: []uint{999},
+				Actor:   &models.Actor{UserID: testOwnerID},
+			})
+			require.Error(t, err)
+
+			dispatched, err := webhooks.DispatchOutbox(time.Now(), 10)
+			require.NoError(t, err)
+			assert.Zero(t, dispatched)
+		})
+	}
+}
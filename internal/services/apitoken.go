@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// lastUsedGranularity limits how often a token's last-used timestamp is
+// written, so that busy scripts do not cause a write on every request
+const lastUsedGranularity = time.Minute
+
+// APITokenService defines the interface for personal access token business logic
+type APITokenService interface {
+	CreateToken(userID uint, req *models.CreateAPITokenRequest) (*models.APIToken, string, error)
+	ListTokens(userID uint) ([]models.APIToken, error)
+	RevokeToken(userID, id uint) error
+	Authenticate(token string) (*models.APIToken, error)
+}
+
+// apiTokenService implements APITokenService
+type apiTokenService struct {
+	repo repository.APITokenRepository
+}
+
+// NewAPITokenService creates a new APITokenService instance
+func NewAPITokenService(repo repository.APITokenRepository) APITokenService {
+	return &apiTokenService{repo: repo}
+}
+
+// CreateToken mints a new personal access token for the user and returns it
+// along with the plain token, which is not stored
+func (s *apiTokenService) CreateToken(userID uint, req *models.CreateAPITokenRequest) (*models.APIToken, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, "", &apperrors.ValidationError{Message: "name cannot be empty"}
+	}
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", &apperrors.ValidationError{Message: "expires_at must be in the future"}
+	}
+
+	plain, hash, prefix, err := auth.GenerateAPIToken()
+	if err != nil {
+		return nil, "", err
+	}
+	token := &models.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		TokenHash: hash,
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.repo.Create(token); err != nil {
+		return nil, "", err
+	}
+	return token, plain, nil
+}
+
+// ListTokens retrieves the user's personal access tokens
+func (s *apiTokenService) ListTokens(userID uint) ([]models.APIToken, error) {
+	return s.repo.FindByUser(userID)
+}
+
+// RevokeToken deletes one of the user's personal access tokens
+func (s *apiTokenService) RevokeToken(userID, id uint) error {
+	return s.repo.Delete(userID, id)
+}
+
+// Authenticate resolves a plain personal access token, rejecting unknown and
+// expired tokens, and records its use
+func (s *apiTokenService) Authenticate(plain string) (*models.APIToken, error) {
+	token, err := s.repo.FindByHash(auth.HashAPIToken(plain))
+	if errors.Is(err, repository.ErrAPITokenNotFound) {
+		return nil, &apperrors.UnauthorizedError{Message: "invalid or expired token"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.Expired(now) {
+		return nil, &apperrors.UnauthorizedError{Message: "invalid or expired token"}
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedGranularity {
+		if err := s.repo.TouchLastUsed(token.ID, now); err != nil {
+			return nil, err
+		}
+		token.LastUsedAt = &now
+	}
+	return token, nil
+}
+
+// normalizeScopes validates the requested scopes and returns them without
+// duplicates in canonical order
+func normalizeScopes(requested []string) ([]string, error) {
+	granted := make(map[string]bool, len(requested))
+	for _, scope := range requested {
+		if !auth.IsScope(scope) {
+			return nil, &apperrors.ValidationError{
+				Message: fmt.Sprintf("unknown scope %q; allowed: %s", scope, strings.Join(auth.Scopes, ", ")),
+			}
+		}
+		granted[scope] = true
+	}
+	if len(granted) == 0 {
+		return nil, &apperrors.ValidationError{Message: "at least one scope is required"}
+	}
+
+	scopes := make([]string, 0, len(granted))
+	for _, scope := range auth.Scopes {
+		if granted[scope] {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// MockAPITokenRepository is a mock implementation of APITokenRepository
+type MockAPITokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPITokenRepository) Create(token *models.APIToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockAPITokenRepository) FindByUser(userID uint) ([]models.APIToken, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.APIToken), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) FindByHash(hash string) (*models.APIToken, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIToken), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockAPITokenRepository) Delete(userID, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func TestCreateToken_Success(t *testing.T) {
+	mockRepo := new(MockAPITokenRepository)
+	service := NewAPITokenService(mockRepo)
+
+	mockRepo.On("Create", mock.AnythingOfType("*models.APIToken")).Return(nil)
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	token, plain, err := service.CreateToken(3, &models.CreateAPITokenRequest{
+		Name:      " backup script ",
+		Scopes:    []string{auth.ScopeTasksWrite, auth.ScopeTasksRead, auth.ScopeTasksRead},
+		ExpiresAt: &expiresAt,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), token.UserID)
+	assert.Equal(t, "backup script", token.Name)
+	assert.Equal(t, []string{auth.ScopeTasksRead, auth.ScopeTasksWrite}, token.ScopeList())
+	assert.Equal(t, auth.HashAPIToken(plain), token.TokenHash)
+	assert.True(t, auth.IsAPIToken(plain))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateToken_ValidationErrors(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := map[string]*models.CreateAPITokenRequest{
+		"blank name":    {Name: "  ", Scopes: []string{auth.ScopeTasksRead}},
+		"unknown scope": {Name: "script", Scopes: []string{"tasks:admin"}},
+		"no scopes":     {Name: "script", Scopes: []string{}},
+		"past expiry":   {Name: "script", Scopes: []string{auth.ScopeTasksRead}, ExpiresAt: &past},
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockAPITokenRepository)
+			service := NewAPITokenService(mockRepo)
+
+			token, _, err := service.CreateToken(3, req)
+
+			assert.Nil(t, token)
+			assert.IsType(t, &apperrors.ValidationError{}, err)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestAuthenticate_Success(t *testing.T) {
+	mockRepo := new(MockAPITokenRepository)
+	service := NewAPITokenService(mockRepo)
+
+	stored := &models.APIToken{ID: 4, UserID: 3, Scopes: auth.ScopeTasksRead}
+	mockRepo.On("FindByHash", auth.HashAPIToken("todo_pat_secret")).Return(stored, nil)
+	mockRepo.On("TouchLastUsed", uint(4), mock.AnythingOfType("time.Time")).Return(nil)
+
+	token, err := service.Authenticate("todo_pat_secret")
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), token.UserID)
+	assert.NotNil(t, token.LastUsedAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthenticate_RecentlyUsedIsNotTouched(t *testing.T) {
+	mockRepo := new(MockAPITokenRepository)
+	service := NewAPITokenService(mockRepo)
+
+	lastUsed := time.Now().Add(-10 * time.Second)
+	stored := &models.APIToken{ID: 4, UserID: 3, LastUsedAt: &lastUsed}
+	mockRepo.On("FindByHash", mock.Anything).Return(stored, nil)
+
+	_, err := service.Authenticate("todo_pat_secret")
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticate_Rejected(t *testing.T) {
+	mockRepo := new(MockAPITokenRepository)
+	service := NewAPITokenService(mockRepo)
+
+	expired := time.Now().Add(-time.Minute)
+	mockRepo.On("FindByHash", auth.HashAPIToken("todo_pat_expired")).Return(&models.APIToken{ID: 4, ExpiresAt: &expired}, nil)
+	mockRepo.On("FindByHash", auth.HashAPIToken("todo_pat_unknown")).Return(nil, repository.ErrAPITokenNotFound)
+
+	_, err := service.Authenticate("todo_pat_expired")
+	assert.IsType(t, &apperrors.UnauthorizedError{}, err)
+
+	_, err = service.Authenticate("todo_pat_unknown")
+	assert.IsType(t, &apperrors.UnauthorizedError{}, err)
+	mockRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// AuthService defines the interface for account and token business logic
+type AuthService interface {
+	Register(req *models.RegisterRequest) (*models.AuthResponse, error)
+	Login(req *models.LoginRequest) (*models.AuthResponse, error)
+	Refresh(req *models.RefreshRequest) (*models.AuthResponse, error)
+	GetProfile(userID uint) (*models.User, error)
+	UpdateProfile(userID uint, req *models.UpdateProfileRequest) (*models.User, error)
+	UserTimeZone(userID uint) (string, error)
+}
+
+// authService implements AuthService
+type authService struct {
+	users  repository.UserRepository
+	tokens *auth.TokenManager
+}
+
+// NewAuthService creates a new AuthService instance
+func NewAuthService(users repository.UserRepository, tokens *auth.TokenManager) AuthService {
+	return &authService{users: users, tokens: tokens}
+}
+
+// Register creates a new user account and signs it in
+func (s *authService) Register(req *models.RegisterRequest) (*models.AuthResponse, error) {
+	email := normalizeEmail(req.Email)
+	if _, err := s.users.FindByEmail(email); err == nil {
+		return nil, &apperrors.ConflictError{Message: "email is already registered"}
+	} else if !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, err
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	timeZone := req.TimeZone
+	if timeZone == "" {
+		timeZone = models.DefaultTimeZone
+	} else if err := validateTimeZone(timeZone); err != nil {
+		return nil, err
+	}
+	user := &models.User{Email: email, PasswordHash: hash, TimeZone: timeZone}
+	if err := s.users.Create(user); err != nil {
+		return nil, err
+	}
+	return s.issue(user)
+}
+
+// Login verifies the user's credentials and issues new tokens
+func (s *authService) Login(req *models.LoginRequest) (*models.AuthResponse, error) {
+	user, err := s.users.FindByEmail(normalizeEmail(req.Email))
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, err
+	}
+
+	hash := ""
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	if !auth.CheckPassword(hash, req.Password) {
+		return nil, &apperrors.UnauthorizedError{Message: "invalid email or password"}
+	}
+	return s.issue(user)
+}
+
+// Refresh exchanges a valid refresh token for new tokens
+func (s *authService) Refresh(req *models.RefreshRequest) (*models.AuthResponse, error) {
+	userID, err := s.tokens.Verify(req.RefreshToken, auth.TokenTypeRefresh)
+	if err != nil {
+		return nil, &apperrors.UnauthorizedError{Message: "invalid or expired refresh token"}
+	}
+
+	user, err := s.users.FindByID(userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, &apperrors.UnauthorizedError{Message: "invalid or expired refresh token"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(user)
+}
+
+// GetProfile retrieves the signed-in user's account
+func (s *authService) GetProfile(userID uint) (*models.User, error) {
+	user, err := s.users.FindByID(userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, &apperrors.UnauthorizedError{Message: "account no longer exists"}
+	}
+	return user, err
+}
+
+// UpdateProfile changes the signed-in user's settings
+func (s *authService) UpdateProfile(userID uint, req *models.UpdateProfileRequest) (*models.User, error) {
+	if err := validateTimeZone(req.TimeZone); err != nil {
+		return nil, err
+	}
+	user, err := s.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+	user.TimeZone = req.TimeZone
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UserTimeZone returns the saved time zone of the user
+func (s *authService) UserTimeZone(userID uint) (string, error) {
+	user, err := s.GetProfile(userID)
+	if err != nil {
+		return "", err
+	}
+	return user.TimeZone, nil
+}
+
+// issue creates the token response for a signed-in user
+func (s *authService) issue(user *models.User) (*models.AuthResponse, error) {
+	pair, err := s.tokens.Issue(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthResponse{
+		AccessToken:  pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(pair.ExpiresIn.Seconds()),
+		User:         user.ToResponse(),
+	}, nil
+}
+
+// normalizeEmail canonicalizes an email address for storage and lookup
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// validateTimeZone checks that a time zone names a known IANA zone
+func validateTimeZone(name string) error {
+	if _, err := time.LoadLocation(name); err != nil || name == "" || name == "Local" {
+		return &apperrors.ValidationError{Message: "time_zone must be an IANA time zone such as Europe/Paris"}
+	}
+	return nil
+}
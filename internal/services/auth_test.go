@@ -0,0 +1,163 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// MockUserRepository is a mock implementation of UserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(user *models.User) error {
+	args := m.Called(user)
+	if args.Error(0) == nil {
+		user.ID = 1
+	}
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByID(id uint) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func newTestTokenManager(t *testing.T) *auth.TokenManager {
+	tokens, err := auth.NewTokenManager(&config.AuthConfig{
+		JWTAlgorithm:    "HS256",
+		JWTSecret:       "test-secret",
+		JWTIssuer:       "todo-api",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+	require.NoError(t, err)
+	return tokens
+}
+
+func TestRegister_Success(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	tokens := newTestTokenManager(t)
+	service := NewAuthService(mockRepo, tokens)
+
+	mockRepo.On("FindByEmail", "ada@example.com").Return(nil, repository.ErrUserNotFound)
+	mockRepo.On("Create", mock.MatchedBy(func(user *models.User) bool {
+		return user.Email == "ada@example.com" && auth.CheckPassword(user.PasswordHash, "password123")
+	})).Return(nil)
+
+	response, err := service.Register(&models.RegisterRequest{Email: " Ada@Example.com ", Password: "password123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", response.TokenType)
+	assert.Equal(t, 60, response.ExpiresIn)
+	assert.Equal(t, "ada@example.com", response.User.Email)
+	userID, err := tokens.Verify(response.AccessToken, auth.TokenTypeAccess)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), userID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegister_DuplicateEmail(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewAuthService(mockRepo, newTestTokenManager(t))
+
+	mockRepo.On("FindByEmail", "ada@example.com").Return(&models.User{ID: 1, Email: "ada@example.com"}, nil)
+
+	response, err := service.Register(&models.RegisterRequest{Email: "ada@example.com", Password: "password123"})
+
+	assert.Nil(t, response)
+	assert.IsType(t, &apperrors.ConflictError{}, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestLogin_Success(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewAuthService(mockRepo, newTestTokenManager(t))
+
+	hash, err := auth.HashPassword("password123")
+	require.NoError(t, err)
+	mockRepo.On("FindByEmail", "ada@example.com").Return(&models.User{ID: 3, Email: "ada@example.com", PasswordHash: hash}, nil)
+
+	response, err := service.Login(&models.LoginRequest{Email: "ADA@example.com", Password: "password123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), response.User.ID)
+	assert.NotEmpty(t, response.RefreshToken)
+}
+
+func TestLogin_InvalidCredentials(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewAuthService(mockRepo, newTestTokenManager(t))
+
+	hash, err := auth.HashPassword("password123")
+	require.NoError(t, err)
+	mockRepo.On("FindByEmail", "ada@example.com").Return(&models.User{ID: 3, PasswordHash: hash}, nil)
+	mockRepo.On("FindByEmail", "nobody@example.com").Return(nil, repository.ErrUserNotFound)
+
+	_, err = service.Login(&models.LoginRequest{Email: "ada@example.com", Password: "wrong-password"})
+	assert.IsType(t, &apperrors.UnauthorizedError{}, err)
+
+	_, err = service.Login(&models.LoginRequest{Email: "nobody@example.com", Password: "password123"})
+	assert.IsType(t, &apperrors.UnauthorizedError{}, err)
+}
+
+func TestRefresh(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	tokens := newTestTokenManager(t)
+	service := NewAuthService(mockRepo, tokens)
+
+	pair, err := tokens.Issue(3)
+	require.NoError(t, err)
+	mockRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Email: "ada@example.com"}, nil)
+
+	response, err := service.Refresh(&models.RefreshRequest{RefreshToken: pair.RefreshToken})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), response.User.ID)
+
+	_, err = service.Refresh(&models.RefreshRequest{RefreshToken: pair.AccessToken})
+	assert.IsType(t, &apperrors.UnauthorizedError{}, err)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewAuthService(mockRepo, newTestTokenManager(t))
+
+	mockRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, TimeZone: models.DefaultTimeZone}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
+
+	user, err := service.UpdateProfile(3, &models.UpdateProfileRequest{TimeZone: "Asia/Tokyo"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", user.TimeZone)
+
+	timeZone, err := service.UserTimeZone(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", timeZone)
+
+	_, err = service.UpdateProfile(3, &models.UpdateProfileRequest{TimeZone: "Local"})
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// BulkTasks applies a list of operations to the user's tasks and returns
+// the outcome of each, in order. The tasks the operations target are read
+// with one query, and each task may only be targeted once. When atomic is
+// set, the operations are written in one transaction and none of them are
+// when one fails; the others then fail with a NotAppliedError. Otherwise
+// each operation is written on its own and fails alone.
+func (s *taskService) BulkTasks(actor *models.Actor, ops []models.BulkTaskOperation, atomic bool, loc *time.Location) ([]models.BulkTaskOutcome, error) {
+	ownerID := actor.UserID
+	outcomes := make([]models.BulkTaskOutcome, len(ops))
+	targets, err := s.bulkTargets(ownerID, ops, outcomes)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := s.bulkRefs(ownerID, ops, targets, outcomes)
+	if err != nil {
+		return nil, err
+	}
+
+	if !atomic {
+		for i := range ops {
+			if outcomes[i].Err != nil {
+				continue
+			}
+			batch := &models.TaskBatch{OwnerID: ownerID, Actor: actor}
+			task, err := s.prepareOperation(ownerID, &ops[i], targets, refs, batch, loc)
+			if err == nil && !batch.Empty() {
+				err = s.withCurrent(ownerID, s.repo.ApplyBatch(batch))
+			}
+			if err != nil {
+				task = nil
+			} else {
+				s.publishBatch(batch, targets)
+			}
+			outcomes[i] = models.BulkTaskOutcome{Task: task, Err: err}
+		}
+		return outcomes, nil
+	}
+
+	batch := &models.TaskBatch{OwnerID: ownerID, Actor: actor}
+	failed := false
+	for i := range ops {
+		if outcomes[i].Err == nil {
+			outcomes[i].Task, outcomes[i].Err = s.prepareOperation(ownerID, &ops[i], targets, refs, batch, loc)
+		}
+		failed = failed || outcomes[i].Err != nil
+	}
+	if !failed && !batch.Empty() {
+		if err := s.repo.ApplyBatch(batch); err != nil {
+			id, ok := failedTaskID(err)
+			i := slices.IndexFunc(ops, func(op models.BulkTaskOperation) bool {
+				return op.Op != models.BulkOpCreate && op.ID == id
+			})
+			if !ok || i < 0 {
+				return nil, err
+			}
+			outcomes[i].Err = s.withCurrent(ownerID, err)
+			failed = true
+		}
+	}
+	if failed {
+		for i := range outcomes {
+			outcomes[i].Task = nil
+			if outcomes[i].Err == nil {
+				outcomes[i].Err = &apperrors.NotAppliedError{}
+			}
+		}
+		return outcomes, nil
+	}
+	s.publishBatch(batch, targets)
+	return outcomes, nil
+}
+
+// bulkTargets reads the tasks targeted by bulk operations with one query,
+// keyed by ID. Operations without a target, or whose target an earlier
+// operation already has, fail with their outcome.
+func (s *taskService) bulkTargets(ownerID uint, ops []models.BulkTaskOperation, outcomes []models.BulkTaskOutcome) (map[uint]*models.Task, error) {
+	targeted := make(map[uint]int)
+	var ids []uint
+	for i, op := range ops {
+		if op.Op == models.BulkOpCreate {
+			continue
+		}
+		if op.ID == 0 {
+			outcomes[i].Err = &apperrors.ValidationError{Message: fmt.Sprintf("id is required to %s a task", op.Op)}
+			continue
+		}
+		if first, ok := targeted[op.ID]; ok {
+			outcomes[i].Err = &apperrors.ValidationError{
+				Message: fmt.Sprintf("task %d is already the target of operation %d", op.ID, first),
+			}
+			continue
+		}
+		targeted[op.ID] = i
+		ids = append(ids, op.ID)
+	}
+
+	targets := make(map[uint]*models.Task, len(ids))
+	if len(ids) == 0 {
+		return targets, nil
+	}
+	tasks, err := s.repo.FindByIDs(ownerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		targets[tasks[i].ID] = &tasks[i]
+	}
+	return targets, nil
+}
+
+// bulkRefs resolves the projects and the existing tags named by the bulk
+// operations that have not failed yet, with one query for each. The missing
+// tags are only created when the operations naming them are written.
+func (s *taskService) bulkRefs(ownerID uint, ops []models.BulkTaskOperation, targets map[uint]*models.Task, outcomes []models.BulkTaskOutcome) (*taskRefs, error) {
+	var projectIDs []uint
+	var names []string
+	for i, op := range ops {
+		if outcomes[i].Err != nil || (op.Op != models.BulkOpCreate && targets[op.ID] == nil) {
+			continue
+		}
+		var projectID *uint
+		var tags []string
+		switch {
+		case op.Op == models.BulkOpCreate && op.Task != nil:
+			projectID, tags = op.Task.ProjectID, op.Task.Tags
+		case op.Op == models.BulkOpUpdate && op.Changes != nil:
+			projectID, tags = op.Changes.ProjectID, op.Changes.Tags
+		}
+		if projectID != nil && *projectID != 0 {
+			projectIDs = append(projectIDs, *projectID)
+		}
+		// Invalid names fail the operation once it is prepared
+		if normalized, err := normalizeTagNames(tags); err == nil {
+			names = append(names, normalized...)
+		}
+	}
+
+	refs := &taskRefs{projects: map[uint]*models.Project{}, tags: map[string]models.Tag{}}
+	if len(projectIDs) > 0 {
+		slices.Sort(projectIDs)
+		projectIDs = slices.Compact(projectIDs)
+		projects, err := s.projects.FindByIDs(ownerID, projectIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range projectIDs {
+			refs.projects[id] = nil
+		}
+		for i := range projects {
+			refs.projects[projects[i].ID] = &projects[i]
+		}
+	}
+	if len(names) > 0 {
+		names, _ = normalizeTagNames(names)
+		tags, err := s.tags.FindByNames(ownerID, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			refs.tags[name] = models.Tag{OwnerID: ownerID, Name: name}
+		}
+		for _, tag := range tags {
+			refs.tags[tag.Name] = tag
+		}
+	}
+	return refs, nil
+}
+
+// prepareOperation validates one bulk operation like the request it stands
+// for and adds its write, if it changes anything, to the batch. It returns
+// the task the operation writes, which is nil for deletions.
+func (s *taskService) prepareOperation(ownerID uint, op *models.BulkTaskOperation, targets map[uint]*models.Task, refs *taskRefs, batch *models.TaskBatch, loc *time.Location) (*models.Task, error) {
+	if op.Op == models.BulkOpCreate {
+		if op.Task == nil {
+			return nil, &apperrors.ValidationError{Message: "task is required to create a task"}
+		}
+		task, err := s.newTask(ownerID, op.Task, loc, refs)
+		if err != nil {
+			return nil, err
+		}
+		batch.Creates = append(batch.Creates, task)
+		batch.AddNewTags(task)
+		return task, nil
+	}
+
+	task, ok := targets[op.ID]
+	if !ok {
+		return nil, &apperrors.TaskNotFoundError{ID: op.ID}
+	}
+	if op.Version != nil && task.Version != *op.Version {
+		return nil, &apperrors.VersionConflictError{ID: op.ID, Current: task}
+	}
+
+	var req *models.UpdateTaskRequest
+	switch op.Op {
+	case models.BulkOpDelete:
+		if task.SubtaskCount > 0 && !op.Cascade {
+			return nil, hasSubtasks(task)
+		}
+		batch.Deletes = append(batch.Deletes, task.ID)
+		return nil, nil
+	case models.BulkOpComplete:
+		// Completing a completed task, and its completed subtasks, is a no-op
+		if task.Completed && (!op.Cascade || task.CompletedSubtaskCount == task.SubtaskCount) {
+			return task, nil
+		}
+		completed := true
+		req = &models.UpdateTaskRequest{Completed: &completed, CompleteSubtasks: op.Cascade}
+	default:
+		if op.Changes == nil {
+			return nil, &apperrors.ValidationError{Message: "changes are required to update a task"}
+		}
+		req = op.Changes
+	}
+
+	update, err := s.changeTask(ownerID, task, req, loc, refs)
+	if err != nil {
+		return nil, err
+	}
+	if update.CompleteSubtasks {
+		task.CompletedSubtaskCount = task.SubtaskCount
+	}
+	batch.Updates = append(batch.Updates, *update)
+	batch.AddNewTags(update.Task)
+	if update.Next != nil {
+		batch.AddNewTags(update.Next)
+	}
+	return task, nil
+}
+
+// CompleteMatchingTasks completes every incomplete task of the user that
+// matches the filter in one transaction and returns how many it completed.
+// Recurring tasks get their next occurrence like in UpdateTask, advanced in
+// loc; the others are completed together. Subtasks are only completed when
+// they match the filter themselves.
+func (s *taskService) CompleteMatchingTasks(actor *models.Actor, filter *models.TaskFilter, loc *time.Location) (int, error) {
+	ownerID := actor.UserID
+	if err := s.checkBulkFilter(ownerID, filter); err != nil {
+		return 0, err
+	}
+	incomplete := *filter
+	completed := false
+	incomplete.Completed = &completed
+	tasks, err := s.repo.FindAll(ownerID, &incomplete)
+	if err != nil || len(tasks) == 0 {
+		return 0, err
+	}
+
+	now := s.now().UTC()
+	batch := &models.TaskBatch{OwnerID: ownerID, Actor: actor, CompletedAt: now}
+	for i := range tasks {
+		task := &tasks[i]
+		if task.Recurrence == "" {
+			batch.Completions = append(batch.Completions, task.ID)
+			continue
+		}
+		task.Completed = true
+		task.CompletedAt = &now
+		batch.Updates = append(batch.Updates, models.TaskUpdate{Task: task, Next: nextOccurrence(task, loc)})
+	}
+	if err := s.repo.ApplyBatch(batch); err != nil {
+		return 0, s.withCurrent(ownerID, err)
+	}
+	s.publishBatch(batch, nil)
+	for i := range tasks {
+		if task := &tasks[i]; !task.Completed {
+			task.Completed = true
+			task.CompletedAt = &now
+			task.Version++
+			s.publishTask(events.TaskUpdated, task)
+		}
+	}
+	return len(tasks), nil
+}
+
+// DeleteMatchingTasks moves every task of the user that matches the filter
+// to the trash in one transaction and returns how many matched. Tasks with
+// subtasks are only deleted when cascade is set, and then take all of
+// their subtasks with them.
+func (s *taskService) DeleteMatchingTasks(actor *models.Actor, filter *models.TaskFilter, cascade bool) (int, error) {
+	ownerID := actor.UserID
+	if err := s.checkBulkFilter(ownerID, filter); err != nil {
+		return 0, err
+	}
+	tasks, err := s.repo.FindAll(ownerID, filter)
+	if err != nil || len(tasks) == 0 {
+		return 0, err
+	}
+
+	ids := make([]uint, len(tasks))
+	deleted := make(map[uint]*models.Task, len(tasks))
+	for i := range tasks {
+		if tasks[i].SubtaskCount > 0 && !cascade {
+			return 0, hasSubtasks(&tasks[i])
+		}
+		ids[i] = tasks[i].ID
+		deleted[ids[i]] = &tasks[i]
+	}
+	batch := &models.TaskBatch{OwnerID: ownerID, Actor: actor, Deletes: ids}
+	if err := s.repo.ApplyBatch(batch); err != nil {
+		return 0, err
+	}
+	s.publishBatch(batch, deleted)
+	return len(ids), nil
+}
+
+// checkBulkFilter validates the filter of a bulk action, which must select
+// some of the user's tasks rather than all of them
+func (s *taskService) checkBulkFilter(ownerID uint, filter *models.TaskFilter) error {
+	if filter == nil || filter.Empty() {
+		return &apperrors.ValidationError{Message: "at least one filter is required for a bulk action"}
+	}
+	return s.checkFilter(ownerID, filter)
+}
+
+// publishBatch publishes the events of an applied batch, except for the
+// tasks it completes by ID, which the caller has the state of. The tasks it
+// deletes are looked up in targets by ID.
+func (s *taskService) publishBatch(batch *models.TaskBatch, targets map[uint]*models.Task) {
+	for _, task := range batch.Creates {
+		s.publishTask(events.TaskCreated, task)
+	}
+	for i := range batch.Updates {
+		s.publishUpdate(&batch.Updates[i])
+	}
+	for _, id := range batch.Deletes {
+		s.publishDeleted(targets[id])
+	}
+}
+
+// failedTaskID returns the ID of the task a failed write was refused for
+func failedTaskID(err error) (uint, bool) {
+	switch e := err.(type) {
+	case *apperrors.TaskNotFoundError:
+		return e.ID, true
+	case *apperrors.VersionConflictError:
+		return e.ID, true
+	}
+	return 0, false
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// bulkTargetTasks returns the tasks the bulk tests target: 1 is a plain
+// task at version 3 and 2 has a subtask
+func bulkTargetTasks() []models.Task {
+	return []models.Task{
+		{ID: 1, OwnerID: testOwnerID, Content: "Plain", Version: 3},
+		{ID: 2, OwnerID: testOwnerID, Content: "Parent", Version: 1, SubtaskCount: 1},
+	}
+}
+
+func TestBulkTasks_BestEffort(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	targets := append(bulkTargetTasks(), models.Task{ID: 3, OwnerID: testOwnerID, Content: "Other", Version: 1})
+	mockRepo.On("FindByIDs", testOwnerID, []uint{1, 2, 99, 3}).Return(targets, nil)
+	mockRepo.On("ApplyBatch", mock.AnythingOfType("*models.TaskBatch")).Return(nil)
+
+	content := "Renamed"
+	stale := uint(2)
+	outcomes, err := service.BulkTasks(testActor, []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "New"}},
+		{Op: models.BulkOpUpdate, ID: 1, Changes: &models.UpdateTaskRequest{Content: &content}},
+		{Op: models.BulkOpDelete, ID: 2},
+		{Op: models.BulkOpComplete, ID: 99},
+		{Op: models.BulkOpComplete, ID: 1},
+		{Op: models.BulkOpComplete, ID: 3, Version: &stale},
+		{Op: models.BulkOpUpdate},
+	}, false, time.UTC)
+
+	require.NoError(t, err)
+	require.Len(t, outcomes, 7)
+	assert.NoError(t, outcomes[0].Err)
+	assert.Equal(t, "New", outcomes[0].Task.Content)
+	assert.NoError(t, outcomes[1].Err)
+	assert.Equal(t, "Renamed", outcomes[1].Task.Content)
+	assert.IsType(t, &apperrors.ConflictError{}, outcomes[2].Err)
+	assert.Equal(t, &apperrors.TaskNotFoundError{ID: 99}, outcomes[3].Err)
+	assert.IsType(t, &apperrors.ValidationError{}, outcomes[4].Err)
+	assert.IsType(t, &apperrors.VersionConflictError{}, outcomes[5].Err)
+	assert.IsType(t, &apperrors.ValidationError{}, outcomes[6].Err)
+
+	// Only the operations that passed validation were written, one at a time
+	mockRepo.AssertNumberOfCalls(t, "ApplyBatch", 2)
+}
+
+func TestBulkTasks_Atomic(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByIDs", testOwnerID, []uint{2, 1}).Return(bulkTargetTasks(), nil)
+	mockRepo.On("ApplyBatch", mock.MatchedBy(func(batch *models.TaskBatch) bool {
+		return len(batch.Creates) == 1 && len(batch.Updates) == 1 && batch.Updates[0].CompleteSubtasks &&
+			len(batch.Deletes) == 1 && batch.Deletes[0] == 1
+	})).Return(nil)
+
+	outcomes, err := service.BulkTasks(testActor, []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "New"}},
+		{Op: models.BulkOpComplete, ID: 2, Cascade: true},
+		{Op: models.BulkOpDelete, ID: 1},
+	}, true, time.UTC)
+
+	require.NoError(t, err)
+	for _, outcome := range outcomes {
+		assert.NoError(t, outcome.Err)
+	}
+	assert.True(t, outcomes[1].Task.Completed)
+	assert.Equal(t, int64(1), outcomes[1].Task.CompletedSubtaskCount)
+	assert.Nil(t, outcomes[2].Task)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBulkTasks_AtomicFailure(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByIDs", testOwnerID, []uint{2, 1}).Return(bulkTargetTasks(), nil)
+
+	outcomes, err := service.BulkTasks(testActor, []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "New"}},
+		{Op: models.BulkOpDelete, ID: 2},
+		{Op: models.BulkOpComplete, ID: 1},
+	}, true, time.UTC)
+
+	require.NoError(t, err)
+	assert.IsType(t, &apperrors.NotAppliedError{}, outcomes[0].Err)
+	assert.Nil(t, outcomes[0].Task)
+	assert.IsType(t, &apperrors.ConflictError{}, outcomes[1].Err)
+	assert.IsType(t, &apperrors.NotAppliedError{}, outcomes[2].Err)
+	mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+}
+
+func TestBulkTasks_AtomicWriteConflict(t *testing.T) {
+	current := &models.Task{ID: 1, OwnerID: testOwnerID, Content: "Changed", Version: 4}
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByIDs", testOwnerID, []uint{2, 1}).Return(bulkTargetTasks(), nil)
+	mockRepo.On("ApplyBatch", mock.AnythingOfType("*models.TaskBatch")).Return(&apperrors.VersionConflictError{ID: 1})
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(current, nil)
+
+	outcomes, err := service.BulkTasks(testActor, []models.BulkTaskOperation{
+		{Op: models.BulkOpDelete, ID: 2, Cascade: true},
+		{Op: models.BulkOpComplete, ID: 1},
+	}, true, time.UTC)
+
+	require.NoError(t, err)
+	assert.IsType(t, &apperrors.NotAppliedError{}, outcomes[0].Err)
+	assert.Equal(t, &apperrors.VersionConflictError{ID: 1, Current: current}, outcomes[1].Err)
+}
+
+func TestBulkTasks_ResolvesProjectsAndTagsOnce(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjects := new(MockProjectRepository)
+	mockTags := new(MockTagRepository)
+	service := NewTaskService(mockRepo, mockProjects, mockTags, testTaskConfig, nil)
+	work, home, missing := uint(4), uint(5), uint(6)
+	mockRepo.On("FindByIDs", testOwnerID, []uint{1, 2}).Return(bulkTargetTasks(), nil)
+	mockProjects.On("FindByIDs", testOwnerID, []uint{4, 5, 6}).Return([]models.Project{
+		{ID: 4, OwnerID: testOwnerID, Name: "Work"},
+		{ID: 5, OwnerID: testOwnerID, Name: "Home"},
+	}, nil).Once()
+	mockTags.On("FindByNames", testOwnerID, []string{"urgent", "home", "later"}).Return([]models.Tag{
+		{ID: 1, OwnerID: testOwnerID, Name: "urgent"},
+		{ID: 2, OwnerID: testOwnerID, Name: "home"},
+	}, nil).Once()
+	// The missing tag is left to the batch of the operation naming it
+	mockRepo.On("ApplyBatch", mock.MatchedBy(func(batch *models.TaskBatch) bool {
+		return len(batch.NewTags) == 0
+	})).Return(nil).Twice()
+	mockRepo.On("ApplyBatch", mock.MatchedBy(func(batch *models.TaskBatch) bool {
+		return assert.ObjectsAreEqual([]string{"later"}, batch.NewTags)
+	})).Return(nil).Once()
+
+	outcomes, err := service.BulkTasks(testActor, []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "One", ProjectID: &work, Tags: []string{"Urgent", "home"}}},
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "Two", ProjectID: &work, Tags: []string{"home"}}},
+		{Op: models.BulkOpUpdate, ID: 1, Changes: &models.UpdateTaskRequest{ProjectID: &home, Tags: []string{"later"}}},
+		{Op: models.BulkOpUpdate, ID: 2, Changes: &models.UpdateTaskRequest{ProjectID: &missing}},
+	}, false, time.UTC)
+
+	require.NoError(t, err)
+	require.NoError(t, outcomes[0].Err)
+	assert.Equal(t, &work, outcomes[0].Task.ProjectID)
+	assert.Equal(t, []string{"home", "urgent"}, outcomes[0].Task.TagNames())
+	require.NoError(t, outcomes[1].Err)
+	require.NoError(t, outcomes[2].Err)
+	assert.Equal(t, &home, outcomes[2].Task.ProjectID)
+	assert.Equal(t, []string{"later"}, outcomes[2].Task.TagNames())
+	assert.Equal(t, &apperrors.ProjectNotFoundError{ID: missing}, outcomes[3].Err)
+	mockRepo.AssertExpectations(t)
+	mockProjects.AssertExpectations(t)
+	mockTags.AssertExpectations(t)
+	mockProjects.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	mockTags.AssertNotCalled(t, "FindOrCreate", mock.Anything, mock.Anything)
+}
+
+func TestBulkTasks_AtomicFailureCreatesNoTags(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockTags := new(MockTagRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), mockTags, testTaskConfig, nil)
+	mockRepo.On("FindByIDs", testOwnerID, []uint{99}).Return([]models.Task{}, nil)
+	mockTags.On("FindByNames", testOwnerID, []string{"new"}).Return([]models.Tag{}, nil)
+
+	outcomes, err := service.BulkTasks(testActor, []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "New", Tags: []string{"new"}}},
+		{Op: models.BulkOpUpdate, ID: 99, Changes: &models.UpdateTaskRequest{Tags: []string{"unknown"}}},
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "Bad", Tags: []string{"new"}, Priority: "extreme"}},
+	}, true, time.UTC)
+
+	require.NoError(t, err)
+	assert.IsType(t, &apperrors.NotAppliedError{}, outcomes[0].Err)
+	assert.Equal(t, &apperrors.TaskNotFoundError{ID: 99}, outcomes[1].Err)
+	assert.IsType(t, &apperrors.ValidationError{}, outcomes[2].Err)
+	mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+	mockTags.AssertNotCalled(t, "FindOrCreate", mock.Anything, mock.Anything)
+}
+
+func TestBulkTasks_CompletingCompletedTaskWritesNothing(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, publisher)
+	mockRepo.On("FindByIDs", testOwnerID, []uint{1, 2}).Return([]models.Task{
+		{ID: 1, OwnerID: testOwnerID, Content: "Done", Completed: true, Version: 3},
+		{ID: 2, OwnerID: testOwnerID, Content: "Parent", Completed: true, Version: 2, SubtaskCount: 1, CompletedSubtaskCount: 1},
+	}, nil)
+
+	for _, atomic := range []bool{false, true} {
+		outcomes, err := service.BulkTasks(testActor, []models.BulkTaskOperation{
+			{Op: models.BulkOpComplete, ID: 1},
+			{Op: models.BulkOpComplete, ID: 2, Cascade: true},
+		}, atomic, time.UTC)
+
+		require.NoError(t, err)
+		for _, outcome := range outcomes {
+			require.NoError(t, outcome.Err)
+			assert.True(t, outcome.Task.Completed)
+		}
+		assert.Equal(t, uint(3), outcomes[0].Task.Version)
+		assert.Equal(t, uint(2), outcomes[1].Task.Version)
+	}
+	mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+	assert.Empty(t, publisher.events)
+}
+
+func TestCompleteMatchingTasks(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	seriesID := uint(2)
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, publisher)
+	service.(*taskService).now = func() time.Time { return now }
+
+	incomplete := false
+	mockRepo.On("FindAll", testOwnerID, &models.TaskFilter{Tags: []string{"home"}, Completed: &incomplete}).Return([]models.Task{
+		{ID: 1, OwnerID: testOwnerID, Content: "Plain"},
+		{ID: 2, OwnerID: testOwnerID, Content: "Daily", DueAt: &dueAt, Recurrence: "FREQ=DAILY", SeriesID: &seriesID, Occurrence: 1},
+	}, nil)
+	mockRepo.On("ApplyBatch", mock.MatchedBy(func(batch *models.TaskBatch) bool {
+		if len(batch.Updates) != 1 {
+			return false
+		}
+		update := batch.Updates[0]
+		return assert.ObjectsAreEqual([]uint{1}, batch.Completions) && batch.CompletedAt.Equal(now) &&
+			update.Task.ID == 2 && update.Task.Completed && update.Task.Recurrence == "" &&
+			update.Next != nil && update.Next.Occurrence == 2 && update.Next.DueAt.Equal(dueAt.AddDate(0, 0, 1))
+	})).Return(nil)
+
+	count, err := service.CompleteMatchingTasks(testActor, &models.TaskFilter{Tags: []string{"home"}}, time.UTC)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, []string{events.TaskUpdated, events.TaskCreated, events.TaskUpdated}, publisher.types())
+	assert.Contains(t, string(publisher.events[2].Data), `"completed":true`)
+}
+
+func TestDeleteMatchingTasks(t *testing.T) {
+	projectID := uint(4)
+	filter := &models.TaskFilter{ProjectID: &projectID}
+	mockRepo := new(MockTaskRepository)
+	mockProjects := new(MockProjectRepository)
+	service := NewTaskService(mockRepo, mockProjects, new(MockTagRepository), testTaskConfig, nil)
+	mockProjects.On("FindByID", testOwnerID, projectID).Return(&models.Project{ID: projectID, OwnerID: testOwnerID}, nil)
+	mockRepo.On("FindAll", testOwnerID, filter).Return(bulkTargetTasks(), nil)
+	mockRepo.On("ApplyBatch", &models.TaskBatch{OwnerID: testOwnerID, Actor: testActor, Deletes: []uint{1, 2}}).Return(nil)
+
+	_, err := service.DeleteMatchingTasks(testActor, filter, false)
+	assert.IsType(t, &apperrors.ConflictError{}, err)
+
+	count, err := service.DeleteMatchingTasks(testActor, filter, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBulkActions_RequireFilter(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+
+	_, err := service.CompleteMatchingTasks(testActor, &models.TaskFilter{}, time.UTC)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	_, err = service.DeleteMatchingTasks(testActor, &models.TaskFilter{Sort: []models.SortField{{Field: "content"}}}, true)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+}
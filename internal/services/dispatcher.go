@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// maxErrorBody limits how much of an unsuccessful response is kept as the
+// error of a delivery attempt
+const maxErrorBody = 512
+
+// WebhookDispatcher turns the outbox into webhook deliveries and attempts
+// the deliveries that are due, retrying failures with exponential backoff
+// until they run out of attempts. Deliveries are made at least once, so
+// receivers should use the event ID to drop duplicates. Several replicas
+// may run a dispatcher: each claims its own deliveries for a lease and
+// attempts them in a bounded pool of workers.
+type WebhookDispatcher struct {
+	repo        repository.WebhookRepository
+	client      *http.Client
+	timeout     time.Duration
+	lease       time.Duration
+	interval    time.Duration
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	batchSize   int
+	concurrency int
+	perWebhook  int
+	now         func() time.Time
+}
+
+// NewWebhookDispatcher creates a WebhookDispatcher using the settings of cfg
+func NewWebhookDispatcher(repo repository.WebhookRepository, cfg *config.WebhookConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:        repo,
+		client:      &http.Client{},
+		timeout:     cfg.Timeout,
+		lease:       cfg.Lease,
+		interval:    cfg.DeliveryInterval,
+		maxAttempts: cfg.MaxAttempts,
+		baseDelay:   cfg.RetryBaseDelay,
+		maxDelay:    cfg.RetryMaxDelay,
+		batchSize:   cfg.BatchSize,
+		concurrency: max(cfg.Concurrency, 1),
+		perWebhook:  max(cfg.ConcurrencyPerWebhook, 1),
+		now:         time.Now,
+	}
+}
+
+// Dispatch turns a batch of outbox messages into deliveries and returns how
+// many messages were dispatched
+func (d *WebhookDispatcher) Dispatch() (int, error) {
+	return d.repo.DispatchOutbox(d.now(), d.batchSize)
+}
+
+// Deliver claims a batch of the deliveries that are due, attempts them and
+// returns how many were attempted. The deliveries to each webhook are
+// worked through by up to perWebhook workers, which share concurrency
+// slots with the workers of the other webhooks. Deliveries whose attempt
+// could not finish before the lease expires are left for the next claim.
+func (d *WebhookDispatcher) Deliver(ctx context.Context) (int, error) {
+	claimedAt := d.now()
+	deliveries, err := d.repo.ClaimDueDeliveries(claimedAt, d.lease, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	queues := map[uint]chan *models.WebhookDelivery{}
+	for i := range deliveries {
+		queue, ok := queues[deliveries[i].WebhookID]
+		if !ok {
+			queue = make(chan *models.WebhookDelivery, len(deliveries))
+			queues[deliveries[i].WebhookID] = queue
+		}
+		queue <- &deliveries[i]
+	}
+
+	deadline := claimedAt.Add(d.lease - d.timeout)
+	slots := make(chan struct{}, d.concurrency)
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		attempted int
+		firstErr  error
+	)
+	for _, queue := range queues {
+		close(queue)
+		for range min(d.perWebhook, len(queue)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for delivery := range queue {
+					slots <- struct{}{}
+					if ctx.Err() != nil || d.now().After(deadline) {
+						<-slots
+						continue
+					}
+					err := d.attempt(ctx, delivery)
+					<-slots
+
+					mu.Lock()
+					attempted++
+					if err != nil && firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	return attempted, firstErr
+}
+
+// Run dispatches the outbox and attempts due deliveries right away and then
+// at every interval until ctx is done. Failures are logged and retried at
+// the next interval.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.Dispatch(); err != nil {
+			log.Printf("Failed to dispatch webhook outbox: %v", err)
+		}
+		if _, err := d.Deliver(ctx); err != nil {
+			log.Printf("Failed to deliver webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// attempt POSTs a delivery to its webhook and records the outcome. A 2xx
+// response succeeds it; otherwise it is retried after a backoff, or moved
+// to the dead-letter list when it has run out of attempts.
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	status, err := d.post(ctx, delivery)
+	now := d.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliverySucceeded
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+	case delivery.Attempts >= d.maxAttempts:
+		delivery.Status = models.DeliveryDead
+		delivery.NextAttemptAt = nil
+		delivery.LastError = err.Error()
+	default:
+		next := now.Add(d.retryDelay(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = err.Error()
+	}
+	return d.repo.UpdateDelivery(delivery)
+}
+
+// post sends the payload of a delivery, giving the webhook the timeout to
+// respond, and returns the response status, if a response was received,
+// and an error unless it was a 2xx
+func (d *WebhookDispatcher) post(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-api-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.Type)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if len(detail) == 0 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, fmt.Errorf("webhook responded with status %d: %s", resp.StatusCode, detail)
+}
+
+// retryDelay returns the backoff before the next attempt at a delivery that
+// has failed attempts times: the base delay, doubled for each attempt after
+// the first, up to the maximum delay
+func (d *WebhookDispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.baseDelay
+	for i := 1; i < attempts && delay < d.maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.maxDelay)
+}
+
+// SignWebhookPayload returns the X-Webhook-Signature of a payload: its
+// HMAC-SHA256 under the webhook secret, hex encoded and prefixed with
+// "sha256="
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+)
+
+// testWebhookConfig retries after a minute, then two, up to three attempts
+var testWebhookConfig = &config.WebhookConfig{
+	Timeout:               time.Second,
+	Lease:                 time.Minute,
+	MaxAttempts:           3,
+	RetryBaseDelay:        time.Minute,
+	RetryMaxDelay:         time.Hour,
+	BatchSize:             10,
+	Concurrency:           4,
+	ConcurrencyPerWebhook: 1,
+}
+
+// testReceiver is a local webhook receiver that verifies signatures and
+// responds with the queued statuses, then 200
+type testReceiver struct {
+	*httptest.Server
+	secret string
+
+	mu       sync.Mutex
+	statuses []int
+	received []models.WebhookPayload
+	headers  []http.Header
+}
+
+func newTestReceiver(t *testing.T, secret string, statuses ...int) *testReceiver {
+	r := &testReceiver{secret: secret, statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, SignWebhookPayload(r.secret, body), req.Header.Get(WebhookSignatureHeader))
+
+		var payload models.WebhookPayload
+		require.NoError(t, json.Unmarshal(body, &payload))
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.received = append(r.received, payload)
+		r.headers = append(r.headers, req.Header.Clone())
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+		if status >= 300 {
+			_, _ = w.Write([]byte("try again later"))
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// webhookFixture wires in-memory task and webhook repositories to a
+// dispatcher whose clock is set by the test
+type webhookFixture struct {
+	tasks      repository.TaskRepository
+	webhooks   repository.WebhookRepository
+	dispatcher *WebhookDispatcher
+	now        time.Time
+}
+
+func newWebhookFixture(t *testing.T, receiver *testReceiver) *webhookFixture {
+	tasks := repository.NewMemoryTaskRepository()
+	f := &webhookFixture{
+		tasks:    tasks,
+		webhooks: repository.NewMemoryWebhookRepository(tasks),
+		now:      time.Now(),
+	}
+	f.dispatcher = NewWebhookDispatcher(f.webhooks, testWebhookConfig)
+	f.dispatcher.now = func() time.Time { return f.now }
+	require.NoError(t, f.webhooks.Create(&models.Webhook{
+		OwnerID: testOwnerID, URL: receiver.URL, Secret: receiver.secret,
+		Events: "task.created task.completed", Active: true,
+	}))
+	return f
+}
+
+// deliver dispatches the outbox and attempts the due deliveries, returning
+// how many were attempted
+func (f *webhookFixture) deliver(t *testing.T) int {
+	_, err := f.dispatcher.Dispatch()
+	require.NoError(t, err)
+	attempted, err := f.dispatcher.Deliver(context.Background())
+	require.NoError(t, err)
+	return attempted
+}
+
+func (f *webhookFixture) delivery(t *testing.T) models.WebhookDelivery {
+	page, err := f.webhooks.FindDeliveries(testOwnerID, &models.WebhookDeliveryFilter{}, &models.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Deliveries, 1)
+	return page.Deliveries[0]
+}
+
+func TestWebhookDispatcher_DeliversSignedPayload(t *testing.T) {
+	receiver := newTestReceiver(t, "s3cret")
+	f := newWebhookFixture(t, receiver)
+	task := &models.Task{OwnerID: testOwnerID, Content: "Write report"}
+	require.NoError(t, f.tasks.Create(task, testActor))
+	task.Content = "Write the report"
+	require.NoError(t, f.tasks.Update(task, testActor))
+
+	assert.Equal(t, 1, f.deliver(t))
+
+	require.Len(t, receiver.received, 1)
+	payload := receiver.received[0]
+	assert.Equal(t, models.WebhookEventTaskCreated, payload.Type)
+	assert.Equal(t, task.ID, payload.Data.TaskID)
+	assert.Contains(t, string(payload.Data.State), `"content":"Write report"`)
+	assert.Equal(t, models.WebhookEventTaskCreated, receiver.headers[0].Get(WebhookEventHeader))
+	assert.Equal(t, "application/json", receiver.headers[0].Get("Content-Type"))
+
+	delivery := f.delivery(t)
+	assert.Equal(t, models.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, ptr(http.StatusOK), delivery.ResponseStatus)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Zero(t, f.deliver(t))
+}
+
+func TestWebhookDispatcher_RetriesWithBackoff(t *testing.T) {
+	receiver := newTestReceiver(t, "s3cret", http.StatusInternalServerError, http.StatusBadGateway)
+	f := newWebhookFixture(t, receiver)
+	require.NoError(t, f.tasks.Create(&models.Task{OwnerID: testOwnerID, Content: "Task"}, testActor))
+
+	assert.Equal(t, 1, f.deliver(t))
+	delivery := f.delivery(t)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Equal(t, ptr(http.StatusInternalServerError), delivery.ResponseStatus)
+	assert.Equal(t, "webhook responded with status 500: try again later", delivery.LastError)
+	assert.Equal(t, f.now.Add(time.Minute), *delivery.NextAttemptAt)
+	assert.Zero(t, f.deliver(t), "not due before the backoff")
+
+	f.now = f.now.Add(time.Minute)
+	assert.Equal(t, 1, f.deliver(t))
+	delivery = f.delivery(t)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, f.now.Add(2*time.Minute), *delivery.NextAttemptAt)
+
+	f.now = f.now.Add(2 * time.Minute)
+	assert.Equal(t, 1, f.deliver(t))
+	delivery = f.delivery(t)
+	assert.Equal(t, models.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Empty(t, delivery.LastError)
+	assert.Len(t, receiver.received, 3)
+}
+
+func TestWebhookDispatcher_DeadLetterAndRedeliver(t *testing.T) {
+	receiver := newTestReceiver(t, "s3cret", http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	f := newWebhookFixture(t, receiver)
+	require.NoError(t, f.tasks.Create(&models.Task{OwnerID: testOwnerID, Content: "Task"}, testActor))
+
+	for i := 0; i < testWebhookConfig.MaxAttempts; i++ {
+		assert.Equal(t, 1, f.deliver(t))
+		f.now = f.now.Add(time.Hour)
+	}
+	delivery := f.delivery(t)
+	assert.Equal(t, models.DeliveryDead, delivery.Status)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Zero(t, f.deliver(t))
+
+	dead, err := f.webhooks.FindDeliveries(testOwnerID, &models.WebhookDeliveryFilter{Status: models.DeliveryDead}, &models.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, dead.Deliveries, 1)
+
+	service := NewWebhookService(f.webhooks).(*webhookService)
+	service.now = func() time.Time { return f.now }
+	_, err = service.Redeliver(testOwnerID, delivery.WebhookID, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.deliver(t))
+	delivery = f.delivery(t)
+	assert.Equal(t, models.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+}
+
+func TestWebhookDispatcher_Unreachable(t *testing.T) {
+	receiver := newTestReceiver(t, "s3cret")
+	f := newWebhookFixture(t, receiver)
+	receiver.Close()
+	require.NoError(t, f.tasks.Create(&models.Task{OwnerID: testOwnerID, Content: "Task"}, testActor))
+
+	assert.Equal(t, 1, f.deliver(t))
+
+	delivery := f.delivery(t)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Nil(t, delivery.ResponseStatus)
+	assert.NotEmpty(t, delivery.LastError)
+}
+
+func TestWebhookDispatcher_SlowWebhookDoesNotHoldUpOthers(t *testing.T) {
+	fast := newTestReceiver(t, "s3cret")
+	f := newWebhookFixture(t, fast)
+
+	// The slow webhook answers once released, counting the requests it has
+	// in flight at the same time
+	release := make(chan struct{})
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	t.Cleanup(slow.Close)
+	require.NoError(t, f.webhooks.Create(&models.Webhook{
+		OwnerID: testOwnerID, URL: slow.URL, Secret: "s3cret", Events: "task.created", Active: true,
+	}))
+
+	for _, content := range []string{"One", "Two", "Three"} {
+		require.NoError(t, f.tasks.Create(&models.Task{OwnerID: testOwnerID, Content: content}, testActor))
+	}
+	_, err := f.dispatcher.Dispatch()
+	require.NoError(t, err)
+
+	done := make(chan int)
+	go func() {
+		attempted, err := f.dispatcher.Deliver(context.Background())
+		assert.NoError(t, err)
+		done <- attempted
+	}()
+	assert.Eventually(t, func() bool {
+		fast.mu.Lock()
+		defer fast.mu.Unlock()
+		return len(fast.received) == 3
+	}, 5*time.Second, 10*time.Millisecond, "the fast webhook is not held up by the slow one")
+
+	close(release)
+	assert.Equal(t, 6, <-done)
+	assert.Equal(t, 1, maxInFlight, "one delivery at a time to the same webhook")
+}
+
+func TestWebhookDispatcher_TimesOutEachAttempt(t *testing.T) {
+	receiver := newTestReceiver(t, "s3cret")
+	f := newWebhookFixture(t, receiver)
+	stop := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-stop
+	}))
+	t.Cleanup(hanging.Close)
+	t.Cleanup(func() { close(stop) })
+	require.NoError(t, f.webhooks.Update(&models.Webhook{
+		ID: 1, OwnerID: testOwnerID, URL: hanging.URL, Secret: "s3cret", Events: "task.created", Active: true,
+	}))
+	require.NoError(t, f.tasks.Create(&models.Task{OwnerID: testOwnerID, Content: "Task"}, testActor))
+
+	start := time.Now()
+	assert.Equal(t, 1, f.deliver(t))
+	assert.Less(t, time.Since(start), 3*testWebhookConfig.Timeout)
+	delivery := f.delivery(t)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Contains(t, delivery.LastError, "context deadline exceeded")
+}
+
+func TestWebhookDispatcher_RetryDelay(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(nil, &config.WebhookConfig{RetryBaseDelay: 30 * time.Second, RetryMaxDelay: 5 * time.Minute})
+
+	assert.Equal(t, 30*time.Second, dispatcher.retryDelay(1))
+	assert.Equal(t, time.Minute, dispatcher.retryDelay(2))
+	assert.Equal(t, 4*time.Minute, dispatcher.retryDelay(4))
+	assert.Equal(t, 5*time.Minute, dispatcher.retryDelay(5))
+	assert.Equal(t, 5*time.Minute, dispatcher.retryDelay(40))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// HMAC-SHA256 test vector from RFC 4231, test case 2
+	assert.Equal(t,
+		"sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		SignWebhookPayload("Jefe", []byte("what do ya want for nothing?")))
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// ListTaskHistory retrieves one page of the events of one of the user's
+// tasks, newest first. The history of a task in the trash can be listed.
+func (s *taskService) ListTaskHistory(ownerID, id uint, page *models.PageRequest) (*models.TaskEventPage, error) {
+	if _, err := s.GetTaskByID(ownerID, id, true); err != nil {
+		return nil, err
+	}
+	return s.repo.FindEvents(ownerID, &models.TaskEventFilter{TaskID: &id}, page)
+}
+
+// ListTaskEvents retrieves one page of the events of the user's tasks
+// matching the filter, newest first
+func (s *taskService) ListTaskEvents(ownerID uint, filter *models.TaskEventFilter, page *models.PageRequest) (*models.TaskEventPage, error) {
+	if filter.Since != nil && filter.Until != nil && !filter.Until.After(*filter.Since) {
+		return nil, &apperrors.ValidationError{Message: "until must be after since"}
+	}
+	return s.repo.FindEvents(ownerID, filter, page)
+}
+
+// ListRecentTaskEvents retrieves the latest events in the history of each of
+// the user's tasks with the given IDs, at most limit per task, newest first
+func (s *taskService) ListRecentTaskEvents(ownerID uint, ids []uint, limit int) ([]models.TaskEvent, error) {
+	if len(ids) == 0 {
+		return []models.TaskEvent{}, nil
+	}
+	if limit < 1 {
+		return nil, &apperrors.ValidationError{Message: "limit must be at least 1"}
+	}
+	return s.repo.FindRecentEvents(ownerID, ids, limit)
+}
+
+// RevertTask sets the writable fields of one of the user's tasks back to
+// their values at a version in its history, as a new update. The task's
+// recurrence is left as it is, since reverting it would change the whole
+// series. Reverting to the current version changes nothing.
+func (s *taskService) RevertTask(actor *models.Actor, id, version uint, loc *time.Location, ifMatch models.VersionCondition) (*models.Task, error) {
+	task, err := s.repo.FindByID(actor.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ifMatch.Allows(task.Version) {
+		return nil, &apperrors.VersionConflictError{ID: id, Current: task}
+	}
+	event, err := s.repo.FindEvent(actor.UserID, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if version == task.Version {
+		return task, nil
+	}
+	doc, err := event.Document()
+	if err != nil {
+		return nil, err
+	}
+
+	req := doc.UpdateRequest()
+	req.Recurrence = nil
+	update, err := s.changeTask(actor.UserID, task, req, loc, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveUpdate(actor, update)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// recordedTask returns the event that took a task to a version with the
+// given content and tags
+func recordedTask(version uint, content string, tags ...string) *models.TaskEvent {
+	task := &models.Task{ID: 1, OwnerID: testOwnerID, Content: content, Version: version}
+	for _, name := range tags {
+		task.Tags = append(task.Tags, models.Tag{Name: name})
+	}
+	event := models.NewTaskEvent(models.TaskEventUpdated, nil, task, nil)
+	return &event
+}
+
+func TestRevertTask_Success(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockTags := new(MockTagRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), mockTags, testTaskConfig, nil)
+	current := &models.Task{ID: 1, OwnerID: testOwnerID, Content: "Final", Version: 3, Priority: models.PriorityHigh}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(current, nil)
+	mockRepo.On("FindEvent", testOwnerID, uint(1), uint(1)).Return(recordedTask(1, "Draft", "work"), nil)
+	tags := []models.Tag{{ID: 4, Name: "work"}}
+	mockTags.On("FindOrCreate", testOwnerID, []string{"work"}).Return(tags, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	task, err := service.RevertTask(testActor, 1, 1, time.UTC, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Draft", task.Content)
+	assert.Equal(t, models.PriorityNone, task.Priority)
+	assert.Equal(t, tags, task.Tags)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRevertTask_KeepsRecurrence(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockTags := new(MockTagRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), mockTags, testTaskConfig, nil)
+	dueAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	seriesID := uint(1)
+	current := &models.Task{
+		ID: 1, OwnerID: testOwnerID, Content: "Standup", Version: 2,
+		DueAt: &dueAt, Recurrence: "FREQ=DAILY", SeriesID: &seriesID, Occurrence: 1,
+	}
+	event := models.NewTaskEvent(models.TaskEventCreated, nil, &models.Task{
+		ID: 1, OwnerID: testOwnerID, Content: "Daily standup", Version: 1, DueAt: &dueAt,
+	}, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(current, nil)
+	mockRepo.On("FindEvent", testOwnerID, uint(1), uint(1)).Return(&event, nil)
+	mockTags.On("FindOrCreate", testOwnerID, []string{}).Return([]models.Tag{}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	task, err := service.RevertTask(testActor, 1, 1, time.UTC, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Daily standup", task.Content)
+	assert.Equal(t, "FREQ=DAILY", task.Recurrence)
+}
+
+func TestRevertTask_CurrentVersion(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	current := &models.Task{ID: 1, OwnerID: testOwnerID, Content: "Final", Version: 3}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(current, nil)
+	mockRepo.On("FindEvent", testOwnerID, uint(1), uint(3)).Return(recordedTask(3, "Final"), nil)
+
+	task, err := service.RevertTask(testActor, 1, 3, time.UTC, nil)
+
+	require.NoError(t, err)
+	assert.Same(t, current, task)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRevertTask_Errors(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1, OwnerID: testOwnerID, Version: 3}, nil)
+	mockRepo.On("FindEvent", testOwnerID, uint(1), uint(9)).Return(nil, &apperrors.VersionNotFoundError{ID: 1, Version: 9})
+
+	_, err := service.RevertTask(testActor, 1, 9, time.UTC, nil)
+	assert.IsType(t, &apperrors.VersionNotFoundError{}, err)
+
+	_, err = service.RevertTask(testActor, 1, 1, time.UTC, models.VersionCondition{2})
+	assert.IsType(t, &apperrors.VersionConflictError{}, err)
+	mockRepo.AssertNotCalled(t, "FindEvent", testOwnerID, uint(1), uint(1))
+}
+
+func TestListTaskHistory_Trashed(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	page := &models.PageRequest{Limit: 20}
+	history := &models.TaskEventPage{Events: []models.TaskEvent{*recordedTask(1, "Draft")}}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(nil, &apperrors.TaskNotFoundError{ID: 1})
+	mockRepo.On("FindTrashed", testOwnerID, uint(1)).Return(trashedTask(1, nil), nil)
+	mockRepo.On("FindEvents", testOwnerID, &models.TaskEventFilter{TaskID: ptr(uint(1))}, page).Return(history, nil)
+
+	result, err := service.ListTaskHistory(testOwnerID, 1, page)
+
+	require.NoError(t, err)
+	assert.Equal(t, history, result)
+}
+
+func TestListTaskEvents_InvalidWindow(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	since := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	_, err := service.ListTaskEvents(testOwnerID, &models.TaskEventFilter{Since: &since, Until: &since}, &models.PageRequest{Limit: 20})
+
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "FindEvents", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListRecentTaskEvents(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	recent := []models.TaskEvent{*recordedTask(2, "Final"), *recordedTask(1, "Draft")}
+	mockRepo.On("FindRecentEvents", testOwnerID, []uint{1}, 5).Return(recent, nil)
+
+	result, err := service.ListRecentTaskEvents(testOwnerID, []uint{1}, 5)
+	require.NoError(t, err)
+	assert.Equal(t, recent, result)
+
+	_, err = service.ListRecentTaskEvents(testOwnerID, []uint{1}, 0)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNumberOfCalls(t, "FindRecentEvents", 1)
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// IdempotencyService defines the interface for Idempotency-Key handling
+type IdempotencyService interface {
+	Begin(userID uint, key, fingerprint string) (*models.StoredResponse, error)
+	Finish(userID uint, key, fingerprint string, response *models.StoredResponse) error
+	Abandon(userID uint, key string) error
+	Purge() (int64, error)
+}
+
+// idempotencyService implements IdempotencyService
+type idempotencyService struct {
+	repo        repository.IdempotencyRepository
+	ttl         time.Duration
+	lockTimeout time.Duration
+	now         func() time.Time
+}
+
+// NewIdempotencyService creates a new IdempotencyService instance
+func NewIdempotencyService(repo repository.IdempotencyRepository, cfg *config.IdempotencyConfig) IdempotencyService {
+	return &idempotencyService{
+		repo:        repo,
+		ttl:         cfg.KeyTTL,
+		lockTimeout: cfg.LockTimeout,
+		now:         time.Now,
+	}
+}
+
+// Begin claims the user's key for a request with the given fingerprint. It
+// returns nil when the request should run, or the response to replay when
+// the key was used for the same request before. A key used for a different
+// request, or whose request is still in flight, is a conflict.
+func (s *idempotencyService) Begin(userID uint, key, fingerprint string) (*models.StoredResponse, error) {
+	now := s.now().UTC()
+	held, err := s.repo.Claim(&models.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		LockedUntil: now.Add(s.lockTimeout),
+		ExpiresAt:   now.Add(s.ttl),
+	}, now)
+	if err != nil || held == nil {
+		return nil, err
+	}
+
+	if held.Fingerprint != fingerprint {
+		return nil, &apperrors.ConflictError{
+			Message: fmt.Sprintf("Idempotency-Key %q has already been used for a different request", key),
+		}
+	}
+	if held.InFlight() {
+		return nil, &apperrors.ConflictError{
+			Message: fmt.Sprintf("a request with Idempotency-Key %q is still in progress", key),
+		}
+	}
+
+	response := &models.StoredResponse{Status: held.Status, Body: held.Body}
+	if err := json.Unmarshal([]byte(held.Headers), &response.Header); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Finish stores the response to the request that claimed the key
+func (s *idempotencyService) Finish(userID uint, key, fingerprint string, response *models.StoredResponse) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+	return s.repo.Complete(&models.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      response.Status,
+		Headers:     string(headers),
+		Body:        response.Body,
+	})
+}
+
+// Abandon releases the key of a request that failed without a response
+// worth replaying, so that a retry runs it again
+func (s *idempotencyService) Abandon(userID uint, key string) error {
+	return s.repo.Release(userID, key)
+}
+
+// Purge deletes the expired keys and returns how many were deleted
+func (s *idempotencyService) Purge() (int64, error) {
+	return s.repo.Purge(s.now().UTC())
+}
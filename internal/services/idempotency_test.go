@@ -0,0 +1,111 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// MockIdempotencyRepository is a mock implementation of IdempotencyRepository
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Claim(key *models.IdempotencyKey, now time.Time) (*models.IdempotencyKey, error) {
+	args := m.Called(key, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Complete(key *models.IdempotencyKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Release(userID uint, key string) error {
+	args := m.Called(userID, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Purge(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+var testIdempotencyConfig = &config.IdempotencyConfig{KeyTTL: 24 * time.Hour, LockTimeout: time.Minute}
+
+func newTestIdempotencyService(repo *MockIdempotencyRepository, now time.Time) IdempotencyService {
+	service := NewIdempotencyService(repo, testIdempotencyConfig).(*idempotencyService)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestIdempotencyBegin_Claimed(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	mockRepo := new(MockIdempotencyRepository)
+	service := newTestIdempotencyService(mockRepo, now)
+	mockRepo.On("Claim", &models.IdempotencyKey{
+		UserID: 7, Key: "retry-1", Fingerprint: "abc",
+		LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(24 * time.Hour),
+	}, now).Return(nil, nil)
+
+	stored, err := service.Begin(7, "retry-1", "abc")
+
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestIdempotencyBegin_Held(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	done := &models.IdempotencyKey{
+		UserID: 7, Key: "retry-1", Fingerprint: "abc",
+		Status: http.StatusCreated, Headers: `{"Location":["/api/v1/tasks/3"]}`, Body: []byte(`{"id":3}`),
+	}
+	inFlight := &models.IdempotencyKey{UserID: 7, Key: "retry-2", Fingerprint: "abc"}
+
+	mockRepo := new(MockIdempotencyRepository)
+	service := newTestIdempotencyService(mockRepo, now)
+	mockRepo.On("Claim", mock.MatchedBy(func(key *models.IdempotencyKey) bool { return key.Key == "retry-1" }), now).Return(done, nil)
+	mockRepo.On("Claim", mock.MatchedBy(func(key *models.IdempotencyKey) bool { return key.Key == "retry-2" }), now).Return(inFlight, nil)
+
+	stored, err := service.Begin(7, "retry-1", "abc")
+	require.NoError(t, err)
+	assert.Equal(t, &models.StoredResponse{
+		Status: http.StatusCreated,
+		Header: http.Header{"Location": {"/api/v1/tasks/3"}},
+		Body:   []byte(`{"id":3}`),
+	}, stored)
+
+	_, err = service.Begin(7, "retry-1", "def")
+	assert.IsType(t, &apperrors.ConflictError{}, err)
+
+	_, err = service.Begin(7, "retry-2", "abc")
+	assert.IsType(t, &apperrors.ConflictError{}, err)
+}
+
+func TestIdempotencyFinish(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	service := newTestIdempotencyService(mockRepo, time.Now())
+	mockRepo.On("Complete", &models.IdempotencyKey{
+		UserID: 7, Key: "retry-1", Fingerprint: "abc",
+		Status: http.StatusCreated, Headers: `{"Content-Type":["application/json"]}`, Body: []byte(`{"id":3}`),
+	}).Return(nil)
+
+	err := service.Finish(7, "retry-1", "abc", &models.StoredResponse{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   []byte(`{"id":3}`),
+	})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// defaultProjectColor is used for projects created without a color
+const defaultProjectColor = "#808080"
+
+// ProjectService defines the interface for project business logic
+type ProjectService interface {
+	CreateProject(ownerID uint, req *models.CreateProjectRequest) (*models.Project, error)
+	ListProjects(ownerID uint, includeArchived bool) ([]models.Project, error)
+	GetProjectByID(ownerID, id uint) (*models.Project, error)
+	UpdateProject(ownerID, id uint, req *models.UpdateProjectRequest) (*models.Project, error)
+	DeleteProject(ownerID, id uint, deleteTasks bool) error
+}
+
+// projectService implements ProjectService
+type projectService struct {
+	repo repository.ProjectRepository
+}
+
+// NewProjectService creates a new ProjectService instance
+func NewProjectService(repo repository.ProjectRepository) ProjectService {
+	return &projectService{repo: repo}
+}
+
+// CreateProject creates a new project owned by the user, placed after the
+// user's other projects unless a position is given
+func (s *projectService) CreateProject(ownerID uint, req *models.CreateProjectRequest) (*models.Project, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &apperrors.ValidationError{Message: "name cannot be empty"}
+	}
+
+	project := &models.Project{
+		OwnerID: ownerID,
+		Name:    name,
+		Color:   defaultProjectColor,
+	}
+	if req.Color != "" {
+		project.Color = strings.ToLower(req.Color)
+	}
+	if req.Position != nil {
+		project.Position = *req.Position
+	} else {
+		max, err := s.repo.MaxPosition(ownerID)
+		if err != nil {
+			return nil, err
+		}
+		project.Position = max + 1
+	}
+
+	if err := s.repo.Create(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// ListProjects retrieves the user's projects, skipping archived ones unless
+// requested
+func (s *projectService) ListProjects(ownerID uint, includeArchived bool) ([]models.Project, error) {
+	return s.repo.FindAll(ownerID, includeArchived)
+}
+
+// GetProjectByID retrieves one of the user's projects by its ID
+func (s *projectService) GetProjectByID(ownerID, id uint) (*models.Project, error) {
+	return s.repo.FindByID(ownerID, id)
+}
+
+// UpdateProject updates an existing project of the user
+func (s *projectService) UpdateProject(ownerID, id uint, req *models.UpdateProjectRequest) (*models.Project, error) {
+	project, err := s.repo.FindByID(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields if provided
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, &apperrors.ValidationError{Message: "name cannot be empty"}
+		}
+		project.Name = name
+	}
+	if req.Color != nil {
+		project.Color = strings.ToLower(*req.Color)
+	}
+	if req.Archived != nil {
+		project.Archived = *req.Archived
+	}
+	if req.Position != nil {
+		project.Position = *req.Position
+	}
+
+	if err := s.repo.Update(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// DeleteProject deletes one of the user's projects, deleting its tasks too
+// when deleteTasks is set and moving them to the inbox otherwise
+func (s *projectService) DeleteProject(ownerID, id uint, deleteTasks bool) error {
+	return s.repo.Delete(ownerID, id, deleteTasks)
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// MockProjectRepository is a mock implementation of ProjectRepository
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectRepository) Create(project *models.Project) error {
+	args := m.Called(project)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) FindAll(ownerID uint, includeArchived bool) ([]models.Project, error) {
+	args := m.Called(ownerID, includeArchived)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) FindByID(ownerID, id uint) (*models.Project, error) {
+	args := m.Called(ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) FindByIDs(ownerID uint, ids []uint) ([]models.Project, error) {
+	args := m.Called(ownerID, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) MaxPosition(ownerID uint) (int, error) {
+	args := m.Called(ownerID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProjectRepository) Update(project *models.Project) error {
+	args := m.Called(project)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) Delete(ownerID, id uint, deleteTasks bool) error {
+	args := m.Called(ownerID, id, deleteTasks)
+	return args.Error(0)
+}
+
+func TestCreateProject_AppendsAfterExisting(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewProjectService(mockRepo)
+
+	mockRepo.On("MaxPosition", testOwnerID).Return(2, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Project")).Return(nil)
+
+	project, err := service.CreateProject(testOwnerID, &models.CreateProjectRequest{Name: " Work "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Work", project.Name)
+	assert.Equal(t, testOwnerID, project.OwnerID)
+	assert.Equal(t, 3, project.Position)
+	assert.Equal(t, defaultProjectColor, project.Color)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateProject_ExplicitPositionAndColor(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewProjectService(mockRepo)
+
+	mockRepo.On("Create", mock.AnythingOfType("*models.Project")).Return(nil)
+
+	position := 0
+	project, err := service.CreateProject(testOwnerID, &models.CreateProjectRequest{
+		Name: "Home", Color: "#FF8800", Position: &position,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, project.Position)
+	assert.Equal(t, "#ff8800", project.Color)
+	mockRepo.AssertNotCalled(t, "MaxPosition", mock.Anything)
+}
+
+func TestUpdateProject_Archive(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewProjectService(mockRepo)
+
+	existing := &models.Project{ID: 1, OwnerID: testOwnerID, Name: "Work"}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(existing, nil)
+	mockRepo.On("Update", existing).Return(nil)
+
+	archived := true
+	project, err := service.UpdateProject(testOwnerID, 1, &models.UpdateProjectRequest{Archived: &archived})
+
+	assert.NoError(t, err)
+	assert.True(t, project.Archived)
+	assert.Equal(t, "Work", project.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteProject(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewProjectService(mockRepo)
+
+	mockRepo.On("Delete", testOwnerID, uint(1), true).Return(nil)
+	mockRepo.On("Delete", testOwnerID, uint(2), false).Return(&apperrors.ProjectNotFoundError{ID: 2})
+
+	assert.NoError(t, service.DeleteProject(testOwnerID, 1, true))
+	assert.IsType(t, &apperrors.ProjectNotFoundError{}, service.DeleteProject(testOwnerID, 2, false))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTask_InProject(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjects := new(MockProjectRepository)
+	service := NewTaskService(mockRepo, mockProjects, new(MockTagRepository), testTaskConfig, nil)
+
+	mockProjects.On("FindByID", testOwnerID, uint(4)).Return(&models.Project{ID: 4, OwnerID: testOwnerID}, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	projectID := uint(4)
+	task, err := service.CreateTask(testActor, &models.CreateTaskRequest{Content: "Report", ProjectID: &projectID}, time.UTC)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, task.ProjectID) {
+		assert.Equal(t, uint(4), *task.ProjectID)
+	}
+}
+
+func TestCreateTask_InvalidProject(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjects := new(MockProjectRepository)
+	service := NewTaskService(mockRepo, mockProjects, new(MockTagRepository), testTaskConfig, nil)
+
+	mockProjects.On("FindByID", testOwnerID, uint(4)).Return(&models.Project{ID: 4, Archived: true}, nil)
+	mockProjects.On("FindByID", testOwnerID, uint(5)).Return(nil, &apperrors.ProjectNotFoundError{ID: 5})
+
+	archived, missing := uint(4), uint(5)
+	_, err := service.CreateTask(testActor, &models.CreateTaskRequest{Content: "Report", ProjectID: &archived}, time.UTC)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+
+	_, err = service.CreateTask(testActor, &models.CreateTaskRequest{Content: "Report", ProjectID: &missing}, time.UTC)
+	assert.IsType(t, &apperrors.ProjectNotFoundError{}, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_MoveToInbox(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjects := new(MockProjectRepository)
+	service := NewTaskService(mockRepo, mockProjects, new(MockTagRepository), testTaskConfig, nil)
+
+	projectID := uint(4)
+	existing := &models.Task{ID: 1, OwnerID: testOwnerID, Content: "Report", ProjectID: &projectID}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(existing, nil)
+	mockRepo.On("Update", existing, testActor).Return(nil)
+
+	inbox := uint(0)
+	task, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{ProjectID: &inbox}, time.UTC, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, task.ProjectID)
+	mockProjects.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+}
+
+func TestListTasks_UnknownProject(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockProjects := new(MockProjectRepository)
+	service := NewTaskService(mockRepo, mockProjects, new(MockTagRepository), testTaskConfig, nil)
+
+	mockProjects.On("FindByID", testOwnerID, uint(9)).Return(nil, &apperrors.ProjectNotFoundError{ID: 9})
+
+	projectID := uint(9)
+	_, err := service.ListTasks(testOwnerID, &models.TaskFilter{ProjectID: &projectID}, &models.PageRequest{Limit: 10})
+
+	assert.IsType(t, &apperrors.ProjectNotFoundError{}, err)
+	mockRepo.AssertNotCalled(t, "FindPage", mock.Anything, mock.Anything, mock.Anything)
+}
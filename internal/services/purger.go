@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/repository"
+)
+
+// TrashPurger permanently deletes the tasks that have been in the trash for
+// longer than the retention period
+type TrashPurger struct {
+	repo      repository.TaskRepository
+	events    events.Publisher
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewTrashPurger creates a TrashPurger using the trash settings of cfg that
+// publishes the tasks it purges to publisher, which may be nil
+func NewTrashPurger(repo repository.TaskRepository, cfg *config.TaskConfig, publisher events.Publisher) *TrashPurger {
+	if publisher == nil {
+		publisher = events.Discard
+	}
+	return &TrashPurger{
+		repo:      repo,
+		events:    publisher,
+		retention: cfg.TrashRetention,
+		interval:  cfg.TrashPurgeInterval,
+		now:       time.Now,
+	}
+}
+
+// Purge permanently deletes the tasks trashed before the retention period
+// and returns how many were deleted. Like deletions, the subtasks purged
+// along with their parent are not published separately.
+func (p *TrashPurger) Purge() (int64, error) {
+	tasks, err := p.repo.Purge(p.now().Add(-p.retention))
+	if err != nil {
+		return 0, err
+	}
+	purged := make(map[uint]bool, len(tasks))
+	for _, task := range tasks {
+		purged[task.ID] = true
+	}
+	for i := range tasks {
+		if tasks[i].ParentID == nil || !purged[*tasks[i].ParentID] {
+			publishRemoved(p.events, events.TaskPurged, &tasks[i])
+		}
+	}
+	return int64(len(tasks)), nil
+}
+
+// Run purges the trash right away and then at every interval until ctx is
+// done
+func (p *TrashPurger) Run(ctx context.Context) {
+	RunPurge(ctx, p.interval, "tasks from the trash", p.Purge)
+}
+
+// RunPurge calls purge right away and then at every interval until ctx is
+// done, logging how many of what it purged. Failures are logged and retried
+// at the next interval.
+func RunPurge(ctx context.Context, interval time.Duration, what string, purge func() (int64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if purged, err := purge(); err != nil {
+			log.Printf("Failed to purge %s: %v", what, err)
+		} else if purged > 0 {
+			log.Printf("Purged %d %s", purged, what)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// recurringTask returns the occurrence-th task of series 1, due at dueAt
+func recurringTask(id uint, rule string, occurrence int, dueAt time.Time) *models.Task {
+	seriesID := uint(1)
+	return &models.Task{
+		ID: id, OwnerID: testOwnerID, Content: "Water plants", Priority: models.PriorityHigh,
+		DueAt: &dueAt, Recurrence: rule, SeriesID: &seriesID, Occurrence: occurrence,
+		Tags: []models.Tag{{ID: 4, Name: "home"}},
+	}
+}
+
+func TestCreateTask_Recurring(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	task, err := service.CreateTask(testActor, &models.CreateTaskRequest{
+		Content: "Standup", DueAt: ptr("2026-10-19T09:00"), Recurrence: "rrule:freq=weekly;byday=fr,mo",
+	}, time.UTC)
+
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,FR", task.Recurrence)
+	assert.Equal(t, 1, task.Occurrence)
+	// The repository starts the series when it creates the task
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestCreateTask_InvalidRecurrence(t *testing.T) {
+	tests := map[string]*models.CreateTaskRequest{
+		"invalid rule":    {Content: "Standup", DueAt: ptr("2026-10-19"), Recurrence: "FREQ=HOURLY"},
+		"missing due_at":  {Content: "Standup", Recurrence: "FREQ=DAILY"},
+		"count and until": {Content: "Standup", DueAt: ptr("2026-10-19"), Recurrence: "FREQ=DAILY;COUNT=2;UNTIL=20261231"},
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+
+			_, err := service.CreateTask(testActor, req, time.UTC)
+
+			assert.IsType(t, &apperrors.ValidationError{}, err)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateTask_CompleteRecurring(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	// 9:00 in Paris, the week before daylight saving time ends
+	mockRepo.On("FindByID", testOwnerID, uint(3)).
+		Return(recurringTask(3, "FREQ=WEEKLY;COUNT=5", 2, time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC)), nil)
+	var next *models.Task
+	mockRepo.On("Complete", mock.AnythingOfType("*models.Task"), false, mock.AnythingOfType("*models.Task"), testActor).
+		Run(func(args mock.Arguments) { next = args.Get(2).(*models.Task) }).Return(nil)
+
+	task, err := service.UpdateTask(testActor, 3, &models.UpdateTaskRequest{Completed: ptr(true)}, paris, nil)
+
+	require.NoError(t, err)
+	assert.True(t, task.Completed)
+	assert.Empty(t, task.Recurrence)
+	require.NotNil(t, next)
+	assert.Equal(t, "Water plants", next.Content)
+	assert.Equal(t, models.PriorityHigh, next.Priority)
+	assert.Equal(t, []string{"home"}, next.TagNames())
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=5", next.Recurrence)
+	assert.Equal(t, ptr(uint(1)), next.SeriesID)
+	assert.Equal(t, 3, next.Occurrence)
+	assert.False(t, next.Completed)
+	assert.Equal(t, time.Date(2026, 10, 27, 8, 0, 0, 0, time.UTC), *next.DueAt)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_CompleteLastOccurrence(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(3)).
+		Return(recurringTask(3, "FREQ=DAILY;COUNT=2", 2, time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)), nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	task, err := service.UpdateTask(testActor, 3, &models.UpdateTaskRequest{Completed: ptr(true)}, time.UTC, nil)
+
+	require.NoError(t, err)
+	assert.True(t, task.Completed)
+	assert.Equal(t, "FREQ=DAILY;COUNT=2", task.Recurrence)
+	mockRepo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_SetRecurrence(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1, OwnerID: testOwnerID}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	_, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Recurrence: ptr("FREQ=DAILY")}, time.UTC, nil)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+
+	task, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{
+		Recurrence: ptr("FREQ=MONTHLY;BYDAY=-1FR"), DueAt: ptr("2026-10-30"),
+	}, time.UTC, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;BYDAY=-1FR", task.Recurrence)
+	assert.Equal(t, ptr(uint(1)), task.SeriesID)
+	assert.Equal(t, 1, task.Occurrence)
+}
+
+func TestPreviewOccurrences(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(3)).
+		Return(recurringTask(3, "FREQ=DAILY;INTERVAL=2;COUNT=4", 2, time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)), nil)
+	mockRepo.On("FindByID", testOwnerID, uint(4)).Return(&models.Task{ID: 4, OwnerID: testOwnerID}, nil)
+
+	occurrences, err := service.PreviewOccurrences(testOwnerID, 3, 5, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 10, 22, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 24, 9, 0, 0, 0, time.UTC),
+	}, occurrences)
+
+	_, err = service.PreviewOccurrences(testOwnerID, 4, 5, time.UTC)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+
+	_, err = service.PreviewOccurrences(testOwnerID, 3, 0, time.UTC)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	_, err = service.PreviewOccurrences(testOwnerID, 3, maxPreviewOccurrences+1, time.UTC)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+}
+
+func TestEndSeries(t *testing.T) {
+	dueAt := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	done := recurringTask(1, "", 1, dueAt)
+	done.Completed = true
+	current := recurringTask(2, "FREQ=DAILY", 2, dueAt.AddDate(0, 0, 1))
+
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(done, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(5)).Return(&models.Task{ID: 5, OwnerID: testOwnerID}, nil)
+	mockRepo.On("FindAll", testOwnerID, &models.TaskFilter{SeriesID: done.SeriesID}).
+		Return([]models.Task{*done, *current}, nil).Once()
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	task, err := service.EndSeries(testActor, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), task.ID)
+	assert.Empty(t, task.Recurrence)
+
+	ended := *current
+	ended.Recurrence = ""
+	mockRepo.On("FindAll", testOwnerID, &models.TaskFilter{SeriesID: done.SeriesID}).
+		Return([]models.Task{*done, ended}, nil)
+	_, err = service.EndSeries(testActor, 1)
+	assert.IsType(t, &apperrors.ConflictError{}, err)
+
+	_, err = service.EndSeries(testActor, 5)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// mockTaskChain makes tasks 1 to n findable, each task the parent of the next
+func mockTaskChain(repo *MockTaskRepository, n uint) {
+	for id := uint(1); id <= n; id++ {
+		task := &models.Task{ID: id, OwnerID: testOwnerID}
+		if id > 1 {
+			parentID := id - 1
+			task.ParentID = &parentID
+		}
+		repo.On("FindByID", testOwnerID, id).Return(task, nil)
+	}
+}
+
+func TestCreateTask_Subtask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockTaskChain(mockRepo, 3)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	parentID := uint(1)
+	task, err := service.CreateTask(testActor, &models.CreateTaskRequest{Content: "Step", ParentID: &parentID}, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, &parentID, task.ParentID)
+
+	// Task 3 is already at the maximum depth of 2 levels below a top-level task
+	parentID = 3
+	_, err = service.CreateTask(testActor, &models.CreateTaskRequest{Content: "Too deep", ParentID: &parentID}, time.UTC)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestCreateTask_SubtaskOfMissingTask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(99)).Return(nil, &apperrors.TaskNotFoundError{ID: 99})
+
+	parentID := uint(99)
+	_, err := service.CreateTask(testActor, &models.CreateTaskRequest{Content: "Step", ParentID: &parentID}, time.UTC)
+
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+}
+
+func TestUpdateTask_MoveSubtree(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockTaskChain(mockRepo, 2)
+	mockRepo.On("FindByID", testOwnerID, uint(5)).Return(&models.Task{ID: 5, OwnerID: testOwnerID}, nil)
+	mockRepo.On("FindDescendants", testOwnerID, uint(1)).Return([]models.Task{{ID: 2, ParentID: ptr(uint(1))}}, nil)
+	mockRepo.On("FindDescendants", testOwnerID, uint(5)).Return([]models.Task{{ID: 6, ParentID: ptr(uint(5))}}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	// Moving a task below its own subtask would create a cycle
+	_, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{ParentID: ptr(uint(2))}, time.UTC, nil)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+
+	// Task 5 has a subtask of its own, which would end up 3 levels deep
+	_, err = service.UpdateTask(testActor, 5, &models.UpdateTaskRequest{ParentID: ptr(uint(2))}, time.UTC, nil)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+
+	task, err := service.UpdateTask(testActor, 5, &models.UpdateTaskRequest{ParentID: ptr(uint(1))}, time.UTC, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), *task.ParentID)
+
+	task, err = service.UpdateTask(testActor, 2, &models.UpdateTaskRequest{ParentID: ptr(uint(0))}, time.UTC, nil)
+	require.NoError(t, err)
+	assert.Nil(t, task.ParentID)
+}
+
+func TestUpdateTask_CompleteSubtasks(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1, SubtaskCount: 3, CompletedSubtaskCount: 1}, nil)
+	mockRepo.On("Complete", mock.AnythingOfType("*models.Task"), true, (*models.Task)(nil), testActor).Return(nil)
+
+	completed := true
+	task, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Completed: &completed, CompleteSubtasks: true}, time.UTC, nil)
+
+	require.NoError(t, err)
+	assert.True(t, task.Completed)
+	assert.NotNil(t, task.CompletedAt)
+	assert.Equal(t, int64(3), task.CompletedSubtaskCount)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_CompleteSubtasksOfIncompleteTask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1}, nil)
+
+	_, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{CompleteSubtasks: true}, time.UTC, nil)
+
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteTask_WithSubtasks(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	task := &models.Task{ID: 1, SubtaskCount: 2}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(task, nil)
+	mockRepo.On("Delete", task, true, testActor).Return(nil)
+
+	err := service.DeleteTask(testActor, 1, false, nil)
+	assert.IsType(t, &apperrors.ConflictError{}, err)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+
+	err = service.DeleteTask(testActor, 1, true, nil)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTaskTree(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1, Content: "Parent"}, nil)
+	mockRepo.On("FindDescendants", testOwnerID, uint(1)).Return([]models.Task{
+		{ID: 2, Content: "First", ParentID: ptr(uint(1))},
+		{ID: 3, Content: "Nested", ParentID: ptr(uint(4))},
+		{ID: 4, Content: "Second", ParentID: ptr(uint(1))},
+	}, nil)
+
+	task, err := service.GetTaskTree(testOwnerID, 1)
+
+	require.NoError(t, err)
+	require.Len(t, task.Subtasks, 2)
+	assert.Equal(t, "First", task.Subtasks[0].Content)
+	assert.Empty(t, task.Subtasks[0].Subtasks)
+	assert.Equal(t, "Second", task.Subtasks[1].Content)
+	require.Len(t, task.Subtasks[1].Subtasks, 1)
+	assert.Equal(t, "Nested", task.Subtasks[1].Subtasks[0].Content)
+}
+
+func TestListSubtasksOf(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	subtasks := []models.Task{{ID: 3, OwnerID: testOwnerID}, {ID: 4, OwnerID: testOwnerID}}
+	mockRepo.On("FindAll", testOwnerID, &models.TaskFilter{
+		ParentIDs: []uint{1, 2},
+		Sort:      []models.SortField{{Field: "created_at"}},
+	}).Return(subtasks, nil)
+
+	result, err := service.ListSubtasksOf(testOwnerID, []uint{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, subtasks, result)
+
+	// No parents need no query
+	result, err = service.ListSubtasksOf(testOwnerID, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+	mockRepo.AssertNumberOfCalls(t, "FindAll", 1)
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// TagService defines the interface for tag business logic
+type TagService interface {
+	ListTags(ownerID uint) ([]models.TagSummary, error)
+	RenameTag(ownerID, id uint, name string) (*models.Tag, error)
+	MergeTag(ownerID, sourceID, targetID uint) (*models.Tag, error)
+	DeleteTag(ownerID, id uint) error
+}
+
+// tagService implements TagService
+type tagService struct {
+	repo repository.TagRepository
+}
+
+// NewTagService creates a new TagService instance
+func NewTagService(repo repository.TagRepository) TagService {
+	return &tagService{repo: repo}
+}
+
+// ListTags retrieves the user's tags with the number of tasks carrying each
+func (s *tagService) ListTags(ownerID uint) ([]models.TagSummary, error) {
+	return s.repo.FindAll(ownerID)
+}
+
+// RenameTag renames one of the user's tags; its tasks keep the tag
+func (s *tagService) RenameTag(ownerID, id uint, name string) (*models.Tag, error) {
+	name = models.NormalizeTagName(name)
+	if name == "" {
+		return nil, &apperrors.ValidationError{Message: "name cannot be empty"}
+	}
+
+	tag, err := s.repo.FindByID(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.Name == name {
+		return tag, nil
+	}
+	tag.Name = name
+	if err := s.repo.Update(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// MergeTag moves the tasks of the source tag to the target tag, deletes the
+// source tag and returns the target tag
+func (s *tagService) MergeTag(ownerID, sourceID, targetID uint) (*models.Tag, error) {
+	if sourceID == targetID {
+		return nil, &apperrors.ValidationError{Message: "cannot merge a tag into itself"}
+	}
+	if err := s.repo.Merge(ownerID, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ownerID, targetID)
+}
+
+// DeleteTag deletes one of the user's tags, removing it from its tasks
+func (s *tagService) DeleteTag(ownerID, id uint) error {
+	return s.repo.Delete(ownerID, id)
+}
+
+// normalizeTagNames normalizes the tag names given for a task, dropping
+// duplicates; every name must be non-empty once normalized
+func normalizeTagNames(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = models.NormalizeTagName(name)
+		if name == "" {
+			return nil, &apperrors.ValidationError{Message: "tags cannot be empty"}
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
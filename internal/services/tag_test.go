@@ -0,0 +1,174 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// MockTagRepository is a mock implementation of TagRepository
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) FindOrCreate(ownerID uint, names []string) ([]models.Tag, error) {
+	args := m.Called(ownerID, names)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) FindByNames(ownerID uint, names []string) ([]models.Tag, error) {
+	args := m.Called(ownerID, names)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) FindAll(ownerID uint) ([]models.TagSummary, error) {
+	args := m.Called(ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TagSummary), args.Error(1)
+}
+
+func (m *MockTagRepository) FindByID(ownerID, id uint) (*models.Tag, error) {
+	args := m.Called(ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) Update(tag *models.Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) Merge(ownerID, sourceID, targetID uint) error {
+	args := m.Called(ownerID, sourceID, targetID)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) Delete(ownerID, id uint) error {
+	args := m.Called(ownerID, id)
+	return args.Error(0)
+}
+
+func TestRenameTag_Normalizes(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewTagService(mockRepo)
+
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Tag{ID: 1, OwnerID: testOwnerID, Name: "urgnt"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Tag")).Return(nil)
+
+	tag, err := service.RenameTag(testOwnerID, 1, "  Very   Urgent ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "very urgent", tag.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRenameTag_Empty(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewTagService(mockRepo)
+
+	_, err := service.RenameTag(testOwnerID, 1, "   ")
+
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestMergeTag(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewTagService(mockRepo)
+
+	mockRepo.On("Merge", testOwnerID, uint(2), uint(1)).Return(nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Tag{ID: 1, Name: "bug"}, nil)
+
+	tag, err := service.MergeTag(testOwnerID, 2, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "bug", tag.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMergeTag_IntoItself(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewTagService(mockRepo)
+
+	_, err := service.MergeTag(testOwnerID, 1, 1)
+
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateTask_WithTags(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockTags := new(MockTagRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), mockTags, testTaskConfig, nil)
+
+	tags := []models.Tag{{ID: 1, Name: "backend"}, {ID: 2, Name: "urgent"}}
+	mockTags.On("FindOrCreate", testOwnerID, []string{"urgent", "backend"}).Return(tags, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	task, err := service.CreateTask(testActor, &models.CreateTaskRequest{
+		Content: "Fix login",
+		Tags:    []string{"Urgent", " backend", "urgent"},
+	}, time.UTC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, tags, task.Tags)
+	mockTags.AssertExpectations(t)
+}
+
+func TestCreateTask_EmptyTag(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockTags := new(MockTagRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), mockTags, testTaskConfig, nil)
+
+	_, err := service.CreateTask(testActor, &models.CreateTaskRequest{Content: "Fix login", Tags: []string{" "}}, time.UTC)
+
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_ClearTags(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockTags := new(MockTagRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), mockTags, testTaskConfig, nil)
+
+	existing := &models.Task{ID: 1, OwnerID: testOwnerID, Content: "Fix login", Tags: []models.Tag{{ID: 1, Name: "urgent"}}}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(existing, nil)
+	mockRepo.On("Update", existing, testActor).Return(nil)
+	mockTags.On("FindOrCreate", testOwnerID, []string{}).Return([]models.Tag{}, nil)
+
+	task, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Tags: []string{}}, time.UTC, nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, task.Tags)
+}
+
+func TestUpdateTask_KeepsTagsWhenOmitted(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockTags := new(MockTagRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), mockTags, testTaskConfig, nil)
+
+	existing := &models.Task{ID: 1, OwnerID: testOwnerID, Content: "Fix login", Tags: []models.Tag{{ID: 1, Name: "urgent"}}}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(existing, nil)
+	mockRepo.On("Update", existing, testActor).Return(nil)
+
+	completed := true
+	task, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Completed: &completed}, time.UTC, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"urgent"}, task.TagNames())
+	mockTags.AssertNotCalled(t, "FindOrCreate", mock.Anything, mock.Anything)
+}
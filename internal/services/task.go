@@ -1,75 +1,868 @@
 package services
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
 	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/recurrence"
 	"github.com/todo-api-go-sda/internal/repository"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
 )
 
-// TaskService defines the interface for task business logic
+// maxSearchQueryLength is the longest search query accepted
+const maxSearchQueryLength = 200
+
+// maxUpcomingDays is the longest window accepted for the upcoming view
+const maxUpcomingDays = 365
+
+// maxPreviewOccurrences is the largest number of occurrences previewed at once
+const maxPreviewOccurrences = 100
+
+// Layouts accepted for due dates without a UTC offset, which are read in
+// the caller's time zone
+const (
+	localDateTimeLayout      = "2006-01-02T15:04:05"
+	localShortDateTimeLayout = "2006-01-02T15:04"
+	localDateLayout          = "2006-01-02"
+)
+
+// TaskService defines the interface for task business logic. Writes take
+// the actor making them, whose tasks they apply to, and are recorded in the
+// history of the tasks they write.
 type TaskService interface {
-	CreateTask(req *models.CreateTaskRequest) (*models.Task, error)
-	GetAllTasks() ([]models.Task, error)
-	GetTaskByID(id uint) (*models.Task, error)
-	UpdateTask(id uint, req *models.UpdateTaskRequest) (*models.Task, error)
-	DeleteTask(id uint) error
+	CreateTask(actor *models.Actor, req *models.CreateTaskRequest, loc *time.Location) (*models.Task, error)
+	ListTasks(ownerID uint, filter *models.TaskFilter, page *models.PageRequest) (*models.TaskPage, error)
+	ListOverdueTasks(ownerID uint, filter *models.TaskFilter) ([]models.Task, error)
+	ListTodayTasks(ownerID uint, filter *models.TaskFilter, loc *time.Location) ([]models.Task, error)
+	ListUpcomingTasks(ownerID uint, filter *models.TaskFilter, days int, loc *time.Location) ([]models.Task, error)
+	GetTaskByID(ownerID, id uint, includeTrashed bool) (*models.Task, error)
+	GetTasksByIDs(ownerID uint, ids []uint) ([]models.Task, error)
+	GetTaskTree(ownerID, id uint) (*models.Task, error)
+	ListSubtasks(ownerID, id uint) ([]models.Task, error)
+	ListSubtasksOf(ownerID uint, ids []uint) ([]models.Task, error)
+	PreviewOccurrences(ownerID, id uint, count int, loc *time.Location) ([]time.Time, error)
+	EndSeries(actor *models.Actor, id uint) (*models.Task, error)
+	UpdateTask(actor *models.Actor, id uint, req *models.UpdateTaskRequest, loc *time.Location, ifMatch models.VersionCondition) (*models.Task, error)
+	DeleteTask(actor *models.Actor, id uint, cascade bool, ifMatch models.VersionCondition) error
+	BulkTasks(actor *models.Actor, ops []models.BulkTaskOperation, atomic bool, loc *time.Location) ([]models.BulkTaskOutcome, error)
+	CompleteMatchingTasks(actor *models.Actor, filter *models.TaskFilter, loc *time.Location) (int, error)
+	DeleteMatchingTasks(actor *models.Actor, filter *models.TaskFilter, cascade bool) (int, error)
+	ListTrash(ownerID uint) ([]models.Task, error)
+	RestoreTask(actor *models.Actor, id uint) (*models.Task, error)
+	DeleteTaskPermanently(actor *models.Actor, id uint) error
+	SearchTasks(ownerID uint, query string) ([]models.TaskSearchResult, error)
+	ListTaskHistory(ownerID, id uint, page *models.PageRequest) (*models.TaskEventPage, error)
+	ListTaskEvents(ownerID uint, filter *models.TaskEventFilter, page *models.PageRequest) (*models.TaskEventPage, error)
+	ListRecentTaskEvents(ownerID uint, ids []uint, limit int) ([]models.TaskEvent, error)
+	RevertTask(actor *models.Actor, id, version uint, loc *time.Location, ifMatch models.VersionCondition) (*models.Task, error)
 }
 
 // taskService implements TaskService
 type taskService struct {
-	repo repository.TaskRepository
+	repo     repository.TaskRepository
+	projects repository.ProjectRepository
+	tags     repository.TagRepository
+	events   events.Publisher
+	maxDepth int
+	now      func() time.Time
 }
 
-// NewTaskService creates a new TaskService instance
-func NewTaskService(repo repository.TaskRepository) TaskService {
-	return &taskService{repo: repo}
+// NewTaskService creates a new TaskService instance that publishes the
+// changes it makes to tasks to publisher, which may be nil
+func NewTaskService(repo repository.TaskRepository, projects repository.ProjectRepository, tags repository.TagRepository, cfg *config.TaskConfig, publisher events.Publisher) TaskService {
+	if publisher == nil {
+		publisher = events.Discard
+	}
+	return &taskService{repo: repo, projects: projects, tags: tags, events: publisher, maxDepth: cfg.MaxSubtaskDepth, now: time.Now}
 }
 
-// CreateTask creates a new task
-func (s *taskService) CreateTask(req *models.CreateTaskRequest) (*models.Task, error) {
+// CreateTask creates a new task owned by the user. Due dates without a UTC
+// offset are read in loc. A recurring task starts a new series, which the
+// repository identifies by the task.
+func (s *taskService) CreateTask(actor *models.Actor, req *models.CreateTaskRequest, loc *time.Location) (*models.Task, error) {
+	task, err := s.newTask(actor.UserID, req, loc, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(task, actor); err != nil {
+		return nil, err
+	}
+	s.publishTask(events.TaskCreated, task)
+	return task, nil
+}
+
+// newTask builds a task of the user from a create request, resolving its
+// project, parent and tags
+func (s *taskService) newTask(ownerID uint, req *models.CreateTaskRequest, loc *time.Location, refs *taskRefs) (*models.Task, error) {
 	task := &models.Task{
+		OwnerID:   ownerID,
 		Content:   req.Content,
 		Completed: false,
 	}
-	err := s.repo.Create(task)
+	if req.Priority != "" {
+		priority, err := parsePriority(req.Priority)
+		if err != nil {
+			return nil, err
+		}
+		task.Priority = priority
+	}
+	if req.DueAt != nil {
+		dueAt, err := parseDueAt(*req.DueAt, loc)
+		if err != nil {
+			return nil, err
+		}
+		task.DueAt = dueAt
+	}
+	if req.Recurrence != "" {
+		rule, err := parseRecurrence(req.Recurrence)
+		if err != nil {
+			return nil, err
+		}
+		task.Recurrence = rule.String()
+		task.Occurrence = 1
+	}
+	if err := validateRecurrence(task); err != nil {
+		return nil, err
+	}
+	if req.ProjectID != nil {
+		projectID, err := s.taskProject(ownerID, *req.ProjectID, refs)
+		if err != nil {
+			return nil, err
+		}
+		task.ProjectID = projectID
+	}
+	if req.ParentID != nil {
+		parentID, err := s.taskParent(ownerID, 0, *req.ParentID, 0)
+		if err != nil {
+			return nil, err
+		}
+		task.ParentID = parentID
+	}
+	if len(req.Tags) > 0 {
+		tags, err := s.taskTags(ownerID, req.Tags, refs)
+		if err != nil {
+			return nil, err
+		}
+		task.Tags = tags
+	}
+	return task, nil
+}
+
+// ListTasks retrieves one page of the user's tasks matching the filter
+func (s *taskService) ListTasks(ownerID uint, filter *models.TaskFilter, page *models.PageRequest) (*models.TaskPage, error) {
+	if err := s.checkFilter(ownerID, filter); err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.FindPage(ownerID, filter, page)
 	if err != nil {
 		return nil, err
 	}
+	if page.IncludeTotal {
+		total, err := s.repo.Count(ownerID, filter)
+		if err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+	return result, nil
+}
+
+// checkFilter validates a task filter of the user, whose project must exist
+func (s *taskService) checkFilter(ownerID uint, filter *models.TaskFilter) error {
+	if filter == nil {
+		return nil
+	}
+	if filter.CreatedAfter != nil && filter.UpdatedBefore != nil &&
+		!filter.UpdatedBefore.After(*filter.CreatedAfter) {
+		return &apperrors.ValidationError{Message: "updated_before must be after created_after"}
+	}
+	if filter.ProjectID != nil && *filter.ProjectID != 0 {
+		if _, err := s.projects.FindByID(ownerID, *filter.ProjectID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListOverdueTasks retrieves the user's tasks that are past their due date,
+// soonest due first
+func (s *taskService) ListOverdueTasks(ownerID uint, filter *models.TaskFilter) ([]models.Task, error) {
+	now := s.now()
+	return s.listDue(ownerID, filter, nil, &now)
+}
+
+// ListTodayTasks retrieves the user's tasks due during the current day in
+// loc, soonest due first
+func (s *taskService) ListTodayTasks(ownerID uint, filter *models.TaskFilter, loc *time.Location) ([]models.Task, error) {
+	today := startOfDay(s.now(), loc)
+	tomorrow := today.AddDate(0, 0, 1)
+	return s.listDue(ownerID, filter, &today, &tomorrow)
+}
+
+// ListUpcomingTasks retrieves the user's tasks due during the given number
+// of days after the current day in loc, soonest due first
+func (s *taskService) ListUpcomingTasks(ownerID uint, filter *models.TaskFilter, days int, loc *time.Location) ([]models.Task, error) {
+	if days < 1 || days > maxUpcomingDays {
+		return nil, &apperrors.ValidationError{
+			Message: fmt.Sprintf("days must be between 1 and %d", maxUpcomingDays),
+		}
+	}
+	today := startOfDay(s.now(), loc)
+	from, until := today.AddDate(0, 0, 1), today.AddDate(0, 0, days+1)
+	return s.listDue(ownerID, filter, &from, &until)
+}
+
+// listDue retrieves the user's tasks due in [from, until), only counting
+// incomplete tasks unless the filter asks otherwise
+func (s *taskService) listDue(ownerID uint, filter *models.TaskFilter, from, until *time.Time) ([]models.Task, error) {
+	due := models.TaskFilter{}
+	if filter != nil {
+		due = *filter
+	}
+	if due.Completed == nil {
+		completed := false
+		due.Completed = &completed
+	}
+	if from != nil {
+		utc := from.UTC()
+		due.DueFrom = &utc
+	}
+	if until != nil {
+		utc := until.UTC()
+		due.DueBefore = &utc
+	}
+	if due.ProjectID != nil && *due.ProjectID != 0 {
+		if _, err := s.projects.FindByID(ownerID, *due.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.FindDue(ownerID, &due)
+}
+
+// GetTaskByID retrieves one of the user's tasks by its ID. Tasks in the
+// trash are only found when includeTrashed is set.
+func (s *taskService) GetTaskByID(ownerID, id uint, includeTrashed bool) (*models.Task, error) {
+	task, err := s.repo.FindByID(ownerID, id)
+	if _, ok := err.(*apperrors.TaskNotFoundError); ok && includeTrashed {
+		return s.repo.FindTrashed(ownerID, id)
+	}
+	return task, err
+}
+
+// GetTaskTree retrieves one of the user's tasks with all of its subtasks
+// nested below it
+func (s *taskService) GetTaskTree(ownerID, id uint) (*models.Task, error) {
+	task, err := s.repo.FindByID(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := s.repo.FindDescendants(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	attachSubtasks(task, descendants)
 	return task, nil
 }
 
-// GetAllTasks retrieves all tasks
-func (s *taskService) GetAllTasks() ([]models.Task, error) {
-	return s.repo.FindAll()
+// ListSubtasks retrieves the direct subtasks of one of the user's tasks,
+// oldest first
+func (s *taskService) ListSubtasks(ownerID, id uint) ([]models.Task, error) {
+	if _, err := s.repo.FindByID(ownerID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindAll(ownerID, &models.TaskFilter{
+		ParentID: &id,
+		Sort:     []models.SortField{{Field: "created_at"}},
+	})
+}
+
+// GetTasksByIDs retrieves the user's tasks with the given IDs in ID order,
+// skipping IDs that do not name one of them
+func (s *taskService) GetTasksByIDs(ownerID uint, ids []uint) ([]models.Task, error) {
+	if len(ids) == 0 {
+		return []models.Task{}, nil
+	}
+	return s.repo.FindByIDs(ownerID, ids)
 }
 
-// GetTaskByID retrieves a task by its ID
-func (s *taskService) GetTaskByID(id uint) (*models.Task, error) {
-	return s.repo.FindByID(id)
+// ListSubtasksOf retrieves the direct subtasks of any of the user's tasks
+// with the given IDs, in creation order
+func (s *taskService) ListSubtasksOf(ownerID uint, ids []uint) ([]models.Task, error) {
+	if len(ids) == 0 {
+		return []models.Task{}, nil
+	}
+	return s.repo.FindAll(ownerID, &models.TaskFilter{
+		ParentIDs: ids,
+		Sort:      []models.SortField{{Field: "created_at"}},
+	})
 }
 
-// UpdateTask updates an existing task
-func (s *taskService) UpdateTask(id uint, req *models.UpdateTaskRequest) (*models.Task, error) {
-	task, err := s.repo.FindByID(id)
+// UpdateTask updates an existing task of the user, recording when it is
+// completed. Due dates without a UTC offset are read in loc. With
+// CompleteSubtasks, the task's subtasks are completed in the same
+// transaction. Completing a recurring task creates its next occurrence in
+// the same transaction, with the due date advanced in loc, and hands the
+// recurrence rule over to it. Keeping the task's project or parent is not
+// a move, so it is allowed in an archived project. The task must be at one
+// of the versions ifMatch allows, and still be at it when saved.
+func (s *taskService) UpdateTask(actor *models.Actor, id uint, req *models.UpdateTaskRequest, loc *time.Location, ifMatch models.VersionCondition) (*models.Task, error) {
+	task, err := s.repo.FindByID(actor.UserID, id)
 	if err != nil {
 		return nil, err
 	}
+	if !ifMatch.Allows(task.Version) {
+		return nil, &apperrors.VersionConflictError{ID: id, Current: task}
+	}
+
+	update, err := s.changeTask(actor.UserID, task, req, loc, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveUpdate(actor, update)
+}
+
+// saveUpdate saves the write changeTask returned for one of the user's
+// tasks and returns the task
+func (s *taskService) saveUpdate(actor *models.Actor, update *models.TaskUpdate) (*models.Task, error) {
+	task := update.Task
+	if update.CompleteSubtasks || update.Next != nil {
+		if err := s.repo.Complete(task, update.CompleteSubtasks, update.Next, actor); err != nil {
+			return nil, s.withCurrent(actor.UserID, err)
+		}
+		if update.CompleteSubtasks {
+			task.CompletedSubtaskCount = task.SubtaskCount
+		}
+		s.publishUpdate(update)
+		return task, nil
+	}
+
+	if err := s.repo.Update(task, actor); err != nil {
+		return nil, s.withCurrent(actor.UserID, err)
+	}
+	s.publishUpdate(update)
+	return task, nil
+}
+
+// changeTask applies an update request to one of the user's tasks read from
+// the repository and returns the write that saves it, with the next occurrence when a
+// recurring task is being completed
+func (s *taskService) changeTask(ownerID uint, task *models.Task, req *models.UpdateTaskRequest, loc *time.Location, refs *taskRefs) (*models.TaskUpdate, error) {
+	id := task.ID
 
 	// Update fields if provided
 	if req.Content != nil {
 		task.Content = *req.Content
 	}
-	if req.Completed != nil {
+	completing := false
+	if req.Completed != nil && *req.Completed != task.Completed {
 		task.Completed = *req.Completed
+		completing = task.Completed
+		if task.Completed {
+			completedAt := s.now().UTC()
+			task.CompletedAt = &completedAt
+		} else {
+			task.CompletedAt = nil
+		}
 	}
+	if req.Priority != nil {
+		priority, err := parsePriority(*req.Priority)
+		if err != nil {
+			return nil, err
+		}
+		task.Priority = priority
+	}
+	if req.DueAt != nil {
+		task.DueAt = nil
+		if *req.DueAt != "" {
+			dueAt, err := parseDueAt(*req.DueAt, loc)
+			if err != nil {
+				return nil, err
+			}
+			task.DueAt = dueAt
+		}
+	}
+	if req.Recurrence != nil {
+		task.Recurrence = ""
+		if *req.Recurrence != "" {
+			rule, err := parseRecurrence(*req.Recurrence)
+			if err != nil {
+				return nil, err
+			}
+			task.Recurrence = rule.String()
+			if task.SeriesID == nil {
+				task.SeriesID = &task.ID
+				task.Occurrence = 1
+			}
+		}
+	}
+	if err := validateRecurrence(task); err != nil {
+		return nil, err
+	}
+	if req.ProjectID != nil && !sameID(task.ProjectID, *req.ProjectID) {
+		projectID, err := s.taskProject(ownerID, *req.ProjectID, refs)
+		if err != nil {
+			return nil, err
+		}
+		task.ProjectID = projectID
+	}
+	if req.ParentID != nil && !sameID(task.ParentID, *req.ParentID) {
+		height := 0
+		if *req.ParentID != 0 {
+			descendants, err := s.repo.FindDescendants(ownerID, id)
+			if err != nil {
+				return nil, err
+			}
+			height = attachSubtasks(&models.Task{ID: id}, descendants)
+		}
+		parentID, err := s.taskParent(ownerID, id, *req.ParentID, height)
+		if err != nil {
+			return nil, err
+		}
+		task.ParentID = parentID
+	}
+	if req.Tags != nil {
+		tags, err := s.taskTags(ownerID, req.Tags, refs)
+		if err != nil {
+			return nil, err
+		}
+		task.Tags = tags
+	}
+
+	if req.CompleteSubtasks && !task.Completed {
+		return nil, &apperrors.ValidationError{Message: "complete_subtasks requires the task to be completed"}
+	}
+	update := &models.TaskUpdate{Task: task, CompleteSubtasks: req.CompleteSubtasks}
+	if completing && task.Recurrence != "" {
+		update.Next = nextOccurrence(task, loc)
+	}
+	return update, nil
+}
+
+// PreviewOccurrences lists up to count occurrences of a recurring task of
+// the user that follow it, computed in loc and returned in UTC
+func (s *taskService) PreviewOccurrences(ownerID, id uint, count int, loc *time.Location) ([]time.Time, error) {
+	if count < 1 || count > maxPreviewOccurrences {
+		return nil, &apperrors.ValidationError{
+			Message: fmt.Sprintf("count must be between 1 and %d", maxPreviewOccurrences),
+		}
+	}
+	task, err := s.repo.FindByID(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Recurrence == "" {
+		return nil, &apperrors.ValidationError{Message: fmt.Sprintf("task %d does not recur", id)}
+	}
+	rule, err := parseRecurrence(task.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	occurrences := rule.Following(task.DueAt.In(loc), task.Occurrence, count)
+	for i := range occurrences {
+		occurrences[i] = occurrences[i].UTC()
+	}
+	return occurrences, nil
+}
 
-	err = s.repo.Update(task)
+// EndSeries ends the recurring series one of the user's tasks belongs to by
+// removing the recurrence rule from its current occurrence, which is
+// returned. No further occurrences are created; existing ones are kept.
+func (s *taskService) EndSeries(actor *models.Actor, id uint) (*models.Task, error) {
+	ownerID := actor.UserID
+	task, err := s.repo.FindByID(ownerID, id)
 	if err != nil {
 		return nil, err
 	}
-	return task, nil
+	if task.SeriesID == nil {
+		return nil, &apperrors.ValidationError{Message: fmt.Sprintf("task %d is not part of a recurring series", id)}
+	}
+	occurrences, err := s.repo.FindAll(ownerID, &models.TaskFilter{SeriesID: task.SeriesID})
+	if err != nil {
+		return nil, err
+	}
+	for i := range occurrences {
+		current := &occurrences[i]
+		if current.Recurrence == "" {
+			continue
+		}
+		current.Recurrence = ""
+		if err := s.repo.Update(current, actor); err != nil {
+			return nil, s.withCurrent(ownerID, err)
+		}
+		s.publishTask(events.TaskUpdated, current)
+		return current, nil
+	}
+	return nil, &apperrors.ConflictError{Message: fmt.Sprintf("the series of task %d has already ended", id)}
+}
+
+// DeleteTask moves one of the user's tasks to the trash. A task with
+// subtasks is only deleted when cascade is set, and then takes all of its
+// subtasks with it. The task must be at one of the versions ifMatch allows.
+func (s *taskService) DeleteTask(actor *models.Actor, id uint, cascade bool, ifMatch models.VersionCondition) error {
+	task, err := s.repo.FindByID(actor.UserID, id)
+	if err != nil {
+		return err
+	}
+	if !ifMatch.Allows(task.Version) {
+		return &apperrors.VersionConflictError{ID: id, Current: task}
+	}
+	if task.SubtaskCount > 0 && !cascade {
+		return hasSubtasks(task)
+	}
+	if err := s.repo.Delete(task, cascade, actor); err != nil {
+		return s.withCurrent(actor.UserID, err)
+	}
+	s.publishDeleted(task)
+	return nil
+}
+
+// ListTrash retrieves the user's tasks in the trash, most recently deleted
+// first. Subtasks trashed along with their parent are not listed
+// separately.
+func (s *taskService) ListTrash(ownerID uint) ([]models.Task, error) {
+	return s.repo.FindTrash(ownerID)
+}
+
+// RestoreTask takes one of the user's tasks out of the trash, along with
+// the subtasks that were deleted with it. A subtask cannot be restored while
+// its parent is in the trash.
+func (s *taskService) RestoreTask(actor *models.Actor, id uint) (*models.Task, error) {
+	ownerID := actor.UserID
+	task, err := s.repo.FindTrashed(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.ParentID != nil {
+		_, err := s.repo.FindByID(ownerID, *task.ParentID)
+		if _, ok := err.(*apperrors.TaskNotFoundError); ok {
+			return nil, &apperrors.ConflictError{
+				Message: fmt.Sprintf("task %d is a subtask of task %d, which is in the trash; restore that task first", id, *task.ParentID),
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Restore(ownerID, id, actor); err != nil {
+		return nil, err
+	}
+	restored, err := s.repo.FindByID(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.publishTask(events.TaskRestored, restored)
+	return restored, nil
+}
+
+// DeleteTaskPermanently deletes one of the user's tasks in the trash for
+// good, along with its subtasks
+func (s *taskService) DeleteTaskPermanently(actor *models.Actor, id uint) error {
+	task, err := s.repo.FindTrashed(actor.UserID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeletePermanently(actor.UserID, id, actor); err != nil {
+		return err
+	}
+	publishRemoved(s.events, events.TaskPurged, task)
+	return nil
+}
+
+// SearchTasks searches the user's tasks by content, most relevant first
+func (s *taskService) SearchTasks(ownerID uint, query string) ([]models.TaskSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, &apperrors.ValidationError{Message: "q is required"}
+	}
+	if len(query) > maxSearchQueryLength {
+		return nil, &apperrors.ValidationError{
+			Message: fmt.Sprintf("q must be at most %d characters", maxSearchQueryLength),
+		}
+	}
+	return s.repo.Search(ownerID, query)
+}
+
+// withCurrent attaches the task's current state to a version conflict
+// reported by the repository, which means another write landed between
+// reading the task and saving it
+func (s *taskService) withCurrent(ownerID uint, err error) error {
+	conflict, ok := err.(*apperrors.VersionConflictError)
+	if !ok || conflict.Current != nil {
+		return err
+	}
+	if current, findErr := s.repo.FindByID(ownerID, conflict.ID); findErr == nil {
+		conflict.Current = current
+	}
+	return err
+}
+
+// publishTask publishes an event carrying a task as returned by the API.
+// Changes the event implies, such as the subtasks completed, trashed or
+// restored along with their parent, are not published separately.
+func (s *taskService) publishTask(eventType string, task *models.Task) {
+	s.publish(task.OwnerID, eventType, task.ToResponse())
+}
+
+// publishUpdate publishes the events of a saved update: the task was
+// updated, and its next occurrence created
+func (s *taskService) publishUpdate(update *models.TaskUpdate) {
+	s.publishTask(events.TaskUpdated, update.Task)
+	if update.Next != nil {
+		s.publishTask(events.TaskCreated, update.Next)
+	}
+}
+
+// publishDeleted publishes the deletion of a task
+func (s *taskService) publishDeleted(task *models.Task) {
+	publishRemoved(s.events, events.TaskDeleted, task)
+}
+
+// publish sends an event about one of the user's tasks to the publisher
+func (s *taskService) publish(ownerID uint, eventType string, data interface{}) {
+	publish(s.events, ownerID, eventType, data)
+}
+
+// publishRemoved publishes the removal of a task, to the trash or for good,
+// identified by its ID and project
+func publishRemoved(publisher events.Publisher, eventType string, task *models.Task) {
+	publish(publisher, task.OwnerID, eventType, struct {
+		ID        uint  `json:"id"`
+		ProjectID *uint `json:"project_id"`
+	}{ID: task.ID, ProjectID: task.ProjectID})
+}
+
+// publish sends an event about one of the user's tasks to publisher
+func publish(publisher events.Publisher, ownerID uint, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+	publisher.Publish(events.Event{OwnerID: ownerID, Type: eventType, Data: payload})
+}
+
+// hasSubtasks refuses to delete a task with subtasks without cascading
+func hasSubtasks(task *models.Task) error {
+	return &apperrors.ConflictError{
+		Message: fmt.Sprintf("task %d has %d subtasks; delete them first or cascade the deletion", task.ID, task.SubtaskCount),
+	}
+}
+
+// sameID reports whether an optional reference, such as a task's project,
+// already points at id, 0 meaning none
+func sameID(ref *uint, id uint) bool {
+	if ref == nil {
+		return id == 0
+	}
+	return *ref == id
+}
+
+// taskRefs holds the projects and tags resolved ahead of a batch of task
+// writes, so that each write does not look them up again. Projects that do
+// not exist are kept as nil. A nil taskRefs looks everything up.
+type taskRefs struct {
+	projects map[uint]*models.Project
+	tags     map[string]models.Tag
+}
+
+// taskProject resolves the project a task is assigned to: 0 means the inbox,
+// any other ID must name one of the user's projects that is not archived
+func (s *taskService) taskProject(ownerID, projectID uint, refs *taskRefs) (*uint, error) {
+	if projectID == 0 {
+		return nil, nil
+	}
+	var project *models.Project
+	ok := false
+	if refs != nil {
+		project, ok = refs.projects[projectID]
+	}
+	if !ok {
+		found, err := s.projects.FindByID(ownerID, projectID)
+		if err != nil {
+			return nil, err
+		}
+		project = found
+	}
+	if project == nil {
+		return nil, &apperrors.ProjectNotFoundError{ID: projectID}
+	}
+	if project.Archived {
+		return nil, &apperrors.ValidationError{Message: "cannot add tasks to an archived project"}
+	}
+	return &project.ID, nil
+}
+
+// taskTags resolves the tags named for a task, creating the missing ones.
+// With refs, the missing ones are left without an ID for the batch writing
+// the task to create.
+func (s *taskService) taskTags(ownerID uint, names []string, refs *taskRefs) ([]models.Tag, error) {
+	names, err := normalizeTagNames(names)
+	if err != nil {
+		return nil, err
+	}
+	if refs != nil {
+		tags := make([]models.Tag, 0, len(names))
+		for _, name := range names {
+			if tag, ok := refs.tags[name]; ok {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == len(names) {
+			slices.SortFunc(tags, func(a, b models.Tag) int { return strings.Compare(a.Name, b.Name) })
+			return tags, nil
+		}
+	}
+	return s.tags.FindOrCreate(ownerID, names)
+}
+
+// taskParent resolves the parent a task is created or moved under, 0
+// meaning none. It refuses to move a task below itself or one of its
+// subtasks, and to nest the task's subtree, height levels deep below the
+// task, deeper than the maximum subtask depth.
+func (s *taskService) taskParent(ownerID, taskID, parentID uint, height int) (*uint, error) {
+	if parentID == 0 {
+		return nil, nil
+	}
+	parent, err := s.repo.FindByID(ownerID, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	depth := 1
+	for ancestor := parent; ; depth++ {
+		if ancestor.ID == taskID {
+			return nil, &apperrors.ValidationError{Message: "a task cannot be moved below itself or one of its subtasks"}
+		}
+		if depth+height > s.maxDepth {
+			return nil, &apperrors.ValidationError{
+				Message: fmt.Sprintf("subtasks can be nested at most %d levels deep", s.maxDepth),
+			}
+		}
+		if ancestor.ParentID == nil {
+			break
+		}
+		if ancestor, err = s.repo.FindByID(ownerID, *ancestor.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	return &parent.ID, nil
+}
+
+// attachSubtasks nests the descendants of a task below it, keeping their
+// order among siblings, and returns the number of levels below the task
+func attachSubtasks(task *models.Task, descendants []models.Task) int {
+	children := make(map[uint][]models.Task)
+	for _, descendant := range descendants {
+		if descendant.ParentID != nil {
+			children[*descendant.ParentID] = append(children[*descendant.ParentID], descendant)
+		}
+	}
+
+	var attach func(task *models.Task) int
+	attach = func(task *models.Task) int {
+		task.Subtasks = children[task.ID]
+		if task.Subtasks == nil {
+			task.Subtasks = []models.Task{}
+		}
+		height := 0
+		for i := range task.Subtasks {
+			height = max(height, 1+attach(&task.Subtasks[i]))
+		}
+		return height
+	}
+	return attach(task)
+}
+
+// parseRecurrence parses and validates a recurrence rule
+func parseRecurrence(value string) (*recurrence.Rule, error) {
+	rule, err := recurrence.Parse(value)
+	if err != nil {
+		return nil, &apperrors.ValidationError{Message: "invalid recurrence: " + err.Error()}
+	}
+	return rule, nil
+}
+
+// validateRecurrence checks that a recurring task has the due date its
+// occurrences are computed from
+func validateRecurrence(task *models.Task) error {
+	if task.Recurrence != "" && task.DueAt == nil {
+		return &apperrors.ValidationError{Message: "recurring tasks require a due date"}
+	}
+	return nil
+}
+
+// nextOccurrence builds the occurrence following a recurring task that is
+// being completed and hands the recurrence rule over to it. It returns nil,
+// leaving the task as is, when the series ends with the task.
+func nextOccurrence(task *models.Task, loc *time.Location) *models.Task {
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return nil
+	}
+	following := rule.Following(task.DueAt.In(loc), task.Occurrence, 1)
+	if len(following) == 0 {
+		return nil
+	}
+	dueAt := following[0].UTC()
+	next := &models.Task{
+		OwnerID:    task.OwnerID,
+		ProjectID:  task.ProjectID,
+		ParentID:   task.ParentID,
+		Content:    task.Content,
+		Priority:   task.Priority,
+		DueAt:      &dueAt,
+		Tags:       task.Tags,
+		Recurrence: task.Recurrence,
+		SeriesID:   task.SeriesID,
+		Occurrence: task.Occurrence + 1,
+	}
+	task.Recurrence = ""
+	return next
+}
+
+// parsePriority parses a priority name
+func parsePriority(name string) (models.Priority, error) {
+	priority, ok := models.ParsePriority(name)
+	if !ok {
+		return models.PriorityNone, &apperrors.ValidationError{
+			Message: "priority must be one of none, low, medium, high or urgent",
+		}
+	}
+	return priority, nil
+}
+
+// parseDueAt parses a due date given as an RFC 3339 timestamp, or as a
+// date-time or date without offset in loc. A bare date is due at the end of
+// that day. The result is in UTC.
+func parseDueAt(value string, loc *time.Location) (*time.Time, error) {
+	var dueAt time.Time
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		dueAt = t
+	} else if t, err := time.ParseInLocation(localDateTimeLayout, value, loc); err == nil {
+		dueAt = t
+	} else if t, err := time.ParseInLocation(localShortDateTimeLayout, value, loc); err == nil {
+		dueAt = t
+	} else if t, err := time.ParseInLocation(localDateLayout, value, loc); err == nil {
+		dueAt = t.AddDate(0, 0, 1).Add(-time.Second)
+	} else {
+		return nil, &apperrors.ValidationError{
+			Message: "due_at must be an RFC 3339 timestamp, or a date-time or date in the caller's time zone",
+		}
+	}
+	dueAt = dueAt.UTC()
+	return &dueAt, nil
 }
 
-// DeleteTask deletes a task by its ID
-func (s *taskService) DeleteTask(id uint) error {
-	return s.repo.Delete(id)
+// startOfDay returns midnight at the start of the day containing t in loc
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
 }
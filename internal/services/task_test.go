@@ -1,88 +1,287 @@
 package services
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
 	"github.com/todo-api-go-sda/internal/models"
 	apperrors "github.com/todo-api-go-sda/pkg/errors"
 )
 
+// testOwnerID is the user that owns the tasks in these tests
+const testOwnerID = uint(7)
+
+// testActor makes the writes in these tests
+var testActor = &models.Actor{UserID: testOwnerID}
+
+// testTaskConfig allows two levels of subtasks
+var testTaskConfig = &config.TaskConfig{MaxSubtaskDepth: 2}
+
 // MockTaskRepository is a mock implementation of TaskRepository
 type MockTaskRepository struct {
 	mock.Mock
 }
 
-func (m *MockTaskRepository) Create(task *models.Task) error {
-	args := m.Called(task)
+func (m *MockTaskRepository) Create(task *models.Task, actor *models.Actor) error {
+	args := m.Called(task, actor)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) FindAll(ownerID uint, filter *models.TaskFilter) ([]models.Task, error) {
+	args := m.Called(ownerID, filter)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindPage(ownerID uint, filter *models.TaskFilter, page *models.PageRequest) (*models.TaskPage, error) {
+	args := m.Called(ownerID, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskPage), args.Error(1)
+}
+
+func (m *MockTaskRepository) Count(ownerID uint, filter *models.TaskFilter) (int64, error) {
+	args := m.Called(ownerID, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindByID(ownerID, id uint) (*models.Task, error) {
+	args := m.Called(ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindByIDs(ownerID uint, ids []uint) ([]models.Task, error) {
+	args := m.Called(ownerID, ids)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindDescendants(ownerID, id uint) ([]models.Task, error) {
+	args := m.Called(ownerID, id)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) Update(task *models.Task, actor *models.Actor) error {
+	args := m.Called(task, actor)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) Complete(task *models.Task, subtasks bool, next *models.Task, actor *models.Actor) error {
+	args := m.Called(task, subtasks, next, actor)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) Delete(task *models.Task, cascade bool, actor *models.Actor) error {
+	args := m.Called(task, cascade, actor)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) ApplyBatch(batch *models.TaskBatch) error {
+	args := m.Called(batch)
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) FindAll() ([]models.Task, error) {
-	args := m.Called()
+func (m *MockTaskRepository) FindTrash(ownerID uint) ([]models.Task, error) {
+	args := m.Called(ownerID)
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) FindByID(id uint) (*models.Task, error) {
-	args := m.Called(id)
+func (m *MockTaskRepository) FindTrashed(ownerID, id uint) (*models.Task, error) {
+	args := m.Called(ownerID, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) Update(task *models.Task) error {
-	args := m.Called(task)
+func (m *MockTaskRepository) Restore(ownerID, id uint, actor *models.Actor) error {
+	args := m.Called(ownerID, id, actor)
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) Delete(id uint) error {
-	args := m.Called(id)
+func (m *MockTaskRepository) DeletePermanently(ownerID, id uint, actor *models.Actor) error {
+	args := m.Called(ownerID, id, actor)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) Purge(before time.Time) ([]models.Task, error) {
+	args := m.Called(before)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindDue(ownerID uint, filter *models.TaskFilter) ([]models.Task, error) {
+	args := m.Called(ownerID, filter)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) Search(ownerID uint, query string) ([]models.TaskSearchResult, error) {
+	args := m.Called(ownerID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TaskSearchResult), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindEvents(ownerID uint, filter *models.TaskEventFilter, page *models.PageRequest) (*models.TaskEventPage, error) {
+	args := m.Called(ownerID, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskEventPage), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindRecentEvents(ownerID uint, taskIDs []uint, limit int) ([]models.TaskEvent, error) {
+	args := m.Called(ownerID, taskIDs, limit)
+	return args.Get(0).([]models.TaskEvent), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindEvent(ownerID, taskID, version uint) (*models.TaskEvent, error) {
+	args := m.Called(ownerID, taskID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskEvent), args.Error(1)
+}
+
+// recordingPublisher records the events published to it
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(event events.Event) {
+	p.events = append(p.events, event)
+}
+
+// types returns the types of the recorded events
+func (p *recordingPublisher) types() []string {
+	types := make([]string, len(p.events))
+	for i, event := range p.events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+func TestTaskEvents_Published(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, publisher)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task"), testActor).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Task).ID = 3
+	})
+	mockRepo.On("FindByID", testOwnerID, uint(3)).Return(&models.Task{ID: 3, OwnerID: testOwnerID, Content: "Test task"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(errors.New("database is down")).Once()
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+	mockRepo.On("Delete", mock.AnythingOfType("*models.Task"), false, testActor).Return(nil)
+
+	_, err := service.CreateTask(testActor, &models.CreateTaskRequest{Content: "Test task"}, time.UTC)
+	require.NoError(t, err)
+	content := "Renamed"
+	_, err = service.UpdateTask(testActor, 3, &models.UpdateTaskRequest{Content: &content}, time.UTC, nil)
+	require.Error(t, err)
+	_, err = service.UpdateTask(testActor, 3, &models.UpdateTaskRequest{Content: &content}, time.UTC, nil)
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTask(testActor, 3, false, nil))
+
+	assert.Equal(t, []string{events.TaskCreated, events.TaskUpdated, events.TaskDeleted}, publisher.types())
+	for _, event := range publisher.events {
+		assert.Equal(t, testOwnerID, event.OwnerID)
+	}
+	assert.Contains(t, string(publisher.events[0].Data), `"content":"Test task"`)
+	assert.Contains(t, string(publisher.events[1].Data), `"content":"Renamed"`)
+	assert.JSONEq(t, `{"id":3,"project_id":null}`, string(publisher.events[2].Data))
+}
+
 func TestCreateTask_Success(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
 
 	req := &models.CreateTaskRequest{Content: "Test task"}
-	mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
 
-	task, err := service.CreateTask(req)
+	task, err := service.CreateTask(testActor, req, time.UTC)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, task)
+	assert.Equal(t, testOwnerID, task.OwnerID)
 	assert.Equal(t, "Test task", task.Content)
 	assert.False(t, task.Completed)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetAllTasks_Success(t *testing.T) {
+func TestListTasks_Success(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
 
-	expectedTasks := []models.Task{
+	page := &models.PageRequest{Limit: 10}
+	expectedPage := &models.TaskPage{Tasks: []models.Task{
 		{ID: 1, Content: "Task 1"},
 		{ID: 2, Content: "Task 2"},
+	}}
+	mockRepo.On("FindPage", testOwnerID, (*models.TaskFilter)(nil), page).Return(expectedPage, nil)
+
+	result, err := service.ListTasks(testOwnerID, nil, page)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Tasks, 2)
+	assert.Nil(t, result.Total)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Count", mock.Anything, mock.Anything)
+}
+
+func TestListTasks_WithFilterAndTotal(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+
+	completed := true
+	filter := &models.TaskFilter{
+		Completed: &completed,
+		Sort:      []models.SortField{{Field: "updated_at", Desc: true}},
 	}
-	mockRepo.On("FindAll").Return(expectedTasks, nil)
+	page := &models.PageRequest{Limit: 1, IncludeTotal: true}
+	expectedPage := &models.TaskPage{Tasks: []models.Task{{ID: 1, Content: "Task 1", Completed: true}}}
+	mockRepo.On("FindPage", testOwnerID, filter, page).Return(expectedPage, nil)
+	mockRepo.On("Count", testOwnerID, filter).Return(int64(3), nil)
 
-	tasks, err := service.GetAllTasks()
+	result, err := service.ListTasks(testOwnerID, filter, page)
 
 	assert.NoError(t, err)
-	assert.Len(t, tasks, 2)
+	assert.Len(t, result.Tasks, 1)
+	assert.Equal(t, int64(3), *result.Total)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestListTasks_InvalidTimeRange(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+
+	after := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := &models.TaskFilter{CreatedAfter: &after, UpdatedBefore: &before}
+
+	result, err := service.ListTasks(testOwnerID, filter, &models.PageRequest{Limit: 10})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "FindPage", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestGetTaskByID_Success(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
 
 	expectedTask := &models.Task{ID: 1, Content: "Task 1"}
-	mockRepo.On("FindByID", uint(1)).Return(expectedTask, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(expectedTask, nil)
 
-	task, err := service.GetTaskByID(1)
+	task, err := service.GetTaskByID(testOwnerID, 1, false)
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint(1), task.ID)
@@ -91,11 +290,11 @@ func TestGetTaskByID_Success(t *testing.T) {
 
 func TestGetTaskByID_NotFound(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
 
-	mockRepo.On("FindByID", uint(999)).Return(nil, &apperrors.TaskNotFoundError{ID: 999})
+	mockRepo.On("FindByID", testOwnerID, uint(999)).Return(nil, &apperrors.TaskNotFoundError{ID: 999})
 
-	task, err := service.GetTaskByID(999)
+	task, err := service.GetTaskByID(testOwnerID, 999, false)
 
 	assert.Error(t, err)
 	assert.Nil(t, task)
@@ -105,16 +304,16 @@ func TestGetTaskByID_NotFound(t *testing.T) {
 
 func TestUpdateTask_Success(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
 
 	existingTask := &models.Task{ID: 1, Content: "Old content", Completed: false}
 	newContent := "New content"
 	req := &models.UpdateTaskRequest{Content: &newContent}
 
-	mockRepo.On("FindByID", uint(1)).Return(existingTask, nil)
-	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(existingTask, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
 
-	task, err := service.UpdateTask(1, req)
+	task, err := service.UpdateTask(testActor, 1, req, time.UTC, nil)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "New content", task.Content)
@@ -123,12 +322,187 @@ func TestUpdateTask_Success(t *testing.T) {
 
 func TestDeleteTask_Success(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
 
-	mockRepo.On("Delete", uint(1)).Return(nil)
+	task := &models.Task{ID: 1, OwnerID: testOwnerID, Version: 2}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(task, nil)
+	mockRepo.On("Delete", task, false, testActor).Return(nil)
 
-	err := service.DeleteTask(1)
+	err := service.DeleteTask(testActor, 1, false, nil)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestSearchTasks_Success(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+
+	expected := []models.TaskSearchResult{
+		{Task: models.Task{ID: 1, Content: "Buy milk"}, Rank: 1, Snippet: "Buy <mark>milk</mark>"},
+	}
+	mockRepo.On("Search", testOwnerID, "milk").Return(expected, nil)
+
+	results, err := service.SearchTasks(testOwnerID, "  milk  ")
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchTasks_EmptyQuery(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+
+	results, err := service.SearchTasks(testOwnerID, "   ")
+
+	assert.Error(t, err)
+	assert.Nil(t, results)
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+}
+
+func TestCreateTask_DueAtAndPriority(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		dueAt    string
+		expected time.Time
+	}{
+		{"2026-03-10T09:30:00Z", time.Date(2026, 3, 10, 9, 30, 0, 0, time.UTC)},
+		{"2026-03-10T09:30", time.Date(2026, 3, 10, 8, 30, 0, 0, time.UTC)},
+		{"2026-03-10", time.Date(2026, 3, 10, 22, 59, 59, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dueAt, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+			mockRepo.On("Create", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+			dueAt := tt.dueAt
+			task, err := service.CreateTask(testActor, &models.CreateTaskRequest{
+				Content: "File taxes", Priority: "high", DueAt: &dueAt,
+			}, paris)
+
+			assert.NoError(t, err)
+			assert.Equal(t, models.PriorityHigh, task.Priority)
+			assert.Equal(t, tt.expected, *task.DueAt)
+			assert.Nil(t, task.CompletedAt)
+		})
+	}
+}
+
+func TestCreateTask_InvalidDueAt(t *testing.T) {
+	service := NewTaskService(new(MockTaskRepository), new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+
+	dueAt := "next tuesday"
+	_, err := service.CreateTask(testActor, &models.CreateTaskRequest{Content: "File taxes", DueAt: &dueAt}, time.UTC)
+
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+}
+
+func TestUpdateTask_CompletedAt(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	service.(*taskService).now = func() time.Time { return now }
+
+	existingTask := &models.Task{ID: 1, Content: "File taxes"}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(existingTask, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	completed := true
+	task, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Completed: &completed}, time.UTC, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, now, *task.CompletedAt)
+
+	now = now.Add(time.Hour)
+	task, err = service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Completed: &completed}, time.UTC, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Hour), *task.CompletedAt)
+
+	completed = false
+	task, err = service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Completed: &completed}, time.UTC, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, task.CompletedAt)
+}
+
+func TestUpdateTask_ClearDueAt(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+
+	dueAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1, DueAt: &dueAt}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	cleared := ""
+	task, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{DueAt: &cleared}, time.UTC, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, task.DueAt)
+}
+
+func TestListDueTasks_Windows(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	// 02:00 UTC on March 11 is still March 10 in New York
+	now := time.Date(2026, 3, 11, 2, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, newYork).UTC() }
+	incomplete := false
+
+	tests := []struct {
+		name     string
+		list     func(TaskService) ([]models.Task, error)
+		expected models.TaskFilter
+	}{
+		{
+			name: "overdue",
+			list: func(s TaskService) ([]models.Task, error) {
+				return s.ListOverdueTasks(testOwnerID, &models.TaskFilter{})
+			},
+			expected: models.TaskFilter{Completed: &incomplete, DueBefore: &now},
+		},
+		{
+			name: "today",
+			list: func(s TaskService) ([]models.Task, error) {
+				return s.ListTodayTasks(testOwnerID, &models.TaskFilter{}, newYork)
+			},
+			expected: models.TaskFilter{Completed: &incomplete, DueFrom: ptr(day(10)), DueBefore: ptr(day(11))},
+		},
+		{
+			name: "upcoming",
+			list: func(s TaskService) ([]models.Task, error) {
+				return s.ListUpcomingTasks(testOwnerID, &models.TaskFilter{}, 3, newYork)
+			},
+			expected: models.TaskFilter{Completed: &incomplete, DueFrom: ptr(day(11)), DueBefore: ptr(day(14))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+			service.(*taskService).now = func() time.Time { return now }
+			mockRepo.On("FindDue", testOwnerID, &tt.expected).Return([]models.Task{{ID: 1}}, nil)
+
+			tasks, err := tt.list(service)
+
+			assert.NoError(t, err)
+			assert.Len(t, tasks, 1)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListUpcomingTasks_InvalidDays(t *testing.T) {
+	service := NewTaskService(new(MockTaskRepository), new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+
+	for _, days := range []int{0, 366} {
+		_, err := service.ListUpcomingTasks(testOwnerID, &models.TaskFilter{}, days, time.UTC)
+		assert.IsType(t, &apperrors.ValidationError{}, err)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func trashedTask(id uint, parentID *uint) *models.Task {
+	return &models.Task{
+		ID: id, OwnerID: testOwnerID, ParentID: parentID,
+		DeletedAt: gorm.DeletedAt{Time: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC), Valid: true},
+	}
+}
+
+func TestGetTaskByID_IncludeTrashed(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(nil, &apperrors.TaskNotFoundError{ID: 1})
+	mockRepo.On("FindTrashed", testOwnerID, uint(1)).Return(trashedTask(1, nil), nil)
+
+	_, err := service.GetTaskByID(testOwnerID, 1, false)
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+	mockRepo.AssertNotCalled(t, "FindTrashed", testOwnerID, uint(1))
+
+	task, err := service.GetTaskByID(testOwnerID, 1, true)
+	require.NoError(t, err)
+	assert.True(t, task.DeletedAt.Valid)
+}
+
+func TestRestoreTask_Success(t *testing.T) {
+	parentID := uint(1)
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, publisher)
+	mockRepo.On("FindTrashed", testOwnerID, uint(2)).Return(trashedTask(2, &parentID), nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1, OwnerID: testOwnerID}, nil)
+	mockRepo.On("Restore", testOwnerID, uint(2), testActor).Return(nil)
+	mockRepo.On("FindByID", testOwnerID, uint(2)).Return(&models.Task{ID: 2, OwnerID: testOwnerID, ParentID: &parentID}, nil)
+
+	task, err := service.RestoreTask(testActor, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), task.ID)
+	assert.False(t, task.DeletedAt.Valid)
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, []string{events.TaskRestored}, publisher.types())
+}
+
+func TestRestoreTask_ParentInTrash(t *testing.T) {
+	parentID := uint(1)
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindTrashed", testOwnerID, uint(2)).Return(trashedTask(2, &parentID), nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(nil, &apperrors.TaskNotFoundError{ID: 1})
+
+	_, err := service.RestoreTask(testActor, 2)
+
+	assert.IsType(t, &apperrors.ConflictError{}, err)
+	mockRepo.AssertNotCalled(t, "Restore", testOwnerID, uint(2), testActor)
+}
+
+func TestRestoreTask_NotInTrash(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindTrashed", testOwnerID, uint(3)).Return(nil, &apperrors.TaskNotFoundError{ID: 3})
+
+	_, err := service.RestoreTask(testActor, 3)
+
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, err)
+}
+
+func TestDeleteTaskPermanently(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, publisher)
+	mockRepo.On("FindTrashed", testOwnerID, uint(1)).Return(trashedTask(1, nil), nil)
+	mockRepo.On("DeletePermanently", testOwnerID, uint(1), testActor).Return(nil)
+	mockRepo.On("FindTrashed", testOwnerID, uint(2)).Return(nil, &apperrors.TaskNotFoundError{ID: 2})
+
+	require.NoError(t, service.DeleteTaskPermanently(testActor, 1))
+	assert.IsType(t, &apperrors.TaskNotFoundError{}, service.DeleteTaskPermanently(testActor, 2))
+
+	mockRepo.AssertNotCalled(t, "DeletePermanently", testOwnerID, uint(2), testActor)
+	require.Equal(t, []string{events.TaskPurged}, publisher.types())
+	assert.JSONEq(t, `{"id":1,"project_id":null}`, string(publisher.events[0].Data))
+}
+
+func TestTrashPurger_Purge(t *testing.T) {
+	parentID := uint(1)
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	purger := NewTrashPurger(mockRepo, &config.TaskConfig{TrashRetention: 30 * 24 * time.Hour, TrashPurgeInterval: time.Hour}, publisher)
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	purger.now = func() time.Time { return now }
+	mockRepo.On("Purge", time.Date(2026, 9, 17, 9, 0, 0, 0, time.UTC)).Return([]models.Task{
+		*trashedTask(1, nil), *trashedTask(2, &parentID), *trashedTask(3, nil),
+	}, nil)
+
+	purged, err := purger.Purge()
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	mockRepo.AssertExpectations(t)
+	// The subtask is purged along with its parent
+	assert.Equal(t, []string{events.TaskPurged, events.TaskPurged}, publisher.types())
+	assert.JSONEq(t, `{"id":3,"project_id":null}`, string(publisher.events[1].Data))
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestUpdateTask_IfMatch(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1, OwnerID: testOwnerID, Content: "Current", Version: 3}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(nil)
+
+	_, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Content: ptr("Mine")}, time.UTC, models.VersionCondition{2})
+	require.IsType(t, &apperrors.VersionConflictError{}, err)
+	current := err.(*apperrors.VersionConflictError).Current.(*models.Task)
+	assert.Equal(t, "Current", current.Content)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	// An empty condition, from If-Match with only weak tags, allows nothing
+	_, err = service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Content: ptr("Mine")}, time.UTC, models.VersionCondition{})
+	assert.IsType(t, &apperrors.VersionConflictError{}, err)
+
+	task, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Content: ptr("Mine")}, time.UTC, models.VersionCondition{2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, "Mine", task.Content)
+}
+
+func TestUpdateTask_ConcurrentWrite(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1, OwnerID: testOwnerID, Version: 3}, nil).Once()
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task"), testActor).Return(&apperrors.VersionConflictError{ID: 1})
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(&models.Task{ID: 1, OwnerID: testOwnerID, Content: "Theirs", Version: 4}, nil)
+
+	_, err := service.UpdateTask(testActor, 1, &models.UpdateTaskRequest{Content: ptr("Mine")}, time.UTC, nil)
+
+	require.IsType(t, &apperrors.VersionConflictError{}, err)
+	current := err.(*apperrors.VersionConflictError).Current.(*models.Task)
+	assert.Equal(t, uint(4), current.Version)
+	assert.Equal(t, "Theirs", current.Content)
+}
+
+func TestDeleteTask_IfMatch(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	task := &models.Task{ID: 1, OwnerID: testOwnerID, Version: 3}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(task, nil)
+	mockRepo.On("Delete", task, false, testActor).Return(nil)
+
+	err := service.DeleteTask(testActor, 1, false, models.VersionCondition{2})
+	assert.IsType(t, &apperrors.VersionConflictError{}, err)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+
+	require.NoError(t, service.DeleteTask(testActor, 1, false, models.VersionCondition{3}))
+	mockRepo.AssertCalled(t, "Delete", task, false, testActor)
+}
+
+func TestDeleteTask_WrittenSinceRead(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, new(MockProjectRepository), new(MockTagRepository), testTaskConfig, nil)
+	read := &models.Task{ID: 1, OwnerID: testOwnerID, Content: "Mine", Version: 3}
+	current := &models.Task{ID: 1, OwnerID: testOwnerID, Content: "Theirs", Version: 4}
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(read, nil).Once()
+	mockRepo.On("FindByID", testOwnerID, uint(1)).Return(current, nil)
+	mockRepo.On("Delete", read, false, testActor).Return(&apperrors.VersionConflictError{ID: 1})
+
+	err := service.DeleteTask(testActor, 1, false, models.VersionCondition{3})
+
+	assert.Equal(t, &apperrors.VersionConflictError{ID: 1, Current: current}, err)
+}
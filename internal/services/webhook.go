@@ -0,0 +1,203 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// webhookSecretPrefix marks generated webhook secrets
+const webhookSecretPrefix = "whsec_"
+
+// WebhookService defines the interface for webhook business logic
+type WebhookService interface {
+	CreateWebhook(ownerID uint, req *models.CreateWebhookRequest) (*models.Webhook, error)
+	ListWebhooks(ownerID uint) ([]models.Webhook, error)
+	GetWebhook(ownerID, id uint) (*models.Webhook, error)
+	UpdateWebhook(ownerID, id uint, req *models.UpdateWebhookRequest) (*models.Webhook, error)
+	DeleteWebhook(ownerID, id uint) error
+	ListDeliveries(ownerID uint, filter *models.WebhookDeliveryFilter, page *models.PageRequest) (*models.WebhookDeliveryPage, error)
+	Redeliver(ownerID, webhookID, deliveryID uint) (*models.WebhookDelivery, error)
+}
+
+// webhookService implements WebhookService
+type webhookService struct {
+	repo repository.WebhookRepository
+	now  func() time.Time
+}
+
+// NewWebhookService creates a new WebhookService instance
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo, now: time.Now}
+}
+
+// CreateWebhook subscribes a webhook to events of the user's tasks. A
+// secret is generated when none is given, and omitting the event types
+// subscribes to all of them.
+func (s *webhookService) CreateWebhook(ownerID uint, req *models.CreateWebhookRequest) (*models.Webhook, error) {
+	target, err := normalizeWebhookURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	events := models.WebhookEventTypes
+	if req.Events != nil {
+		if events, err = normalizeWebhookEvents(req.Events); err != nil {
+			return nil, err
+		}
+	}
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	webhook := &models.Webhook{
+		OwnerID: ownerID,
+		URL:     target,
+		Secret:  secret,
+		Events:  strings.Join(events, " "),
+		Active:  true,
+	}
+	if err := s.repo.Create(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// ListWebhooks retrieves the user's webhooks
+func (s *webhookService) ListWebhooks(ownerID uint) ([]models.Webhook, error) {
+	return s.repo.FindAll(ownerID)
+}
+
+// GetWebhook retrieves one of the user's webhooks
+func (s *webhookService) GetWebhook(ownerID, id uint) (*models.Webhook, error) {
+	return s.repo.FindByID(ownerID, id)
+}
+
+// UpdateWebhook changes the given fields of one of the user's webhooks
+func (s *webhookService) UpdateWebhook(ownerID, id uint, req *models.UpdateWebhookRequest) (*models.Webhook, error) {
+	webhook, err := s.repo.FindByID(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL != nil {
+		if webhook.URL, err = normalizeWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+	}
+	if req.Secret != nil {
+		if *req.Secret == "" {
+			return nil, &apperrors.ValidationError{Message: "secret cannot be empty"}
+		}
+		webhook.Secret = *req.Secret
+	}
+	if req.Events != nil {
+		events, err := normalizeWebhookEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Events = strings.Join(events, " ")
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	if err := s.repo.Update(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook removes one of the user's webhooks along with its
+// deliveries
+func (s *webhookService) DeleteWebhook(ownerID, id uint) error {
+	return s.repo.Delete(ownerID, id)
+}
+
+// ListDeliveries retrieves one page of the user's webhook deliveries
+// matching the filter, newest first. Filtering by a webhook requires it to
+// exist.
+func (s *webhookService) ListDeliveries(ownerID uint, filter *models.WebhookDeliveryFilter, page *models.PageRequest) (*models.WebhookDeliveryPage, error) {
+	if filter.WebhookID != nil {
+		if _, err := s.repo.FindByID(ownerID, *filter.WebhookID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.FindDeliveries(ownerID, filter, page)
+}
+
+// Redeliver makes a delivery of one of the user's webhooks pending and due
+// right away, with its full number of attempts, whatever its status. It is
+// attempted once the webhook is active.
+func (s *webhookService) Redeliver(ownerID, webhookID, deliveryID uint) (*models.WebhookDelivery, error) {
+	if _, err := s.repo.FindByID(ownerID, webhookID); err != nil {
+		return nil, err
+	}
+	delivery, err := s.repo.FindDelivery(ownerID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.WebhookID != webhookID {
+		return nil, &apperrors.DeliveryNotFoundError{ID: deliveryID}
+	}
+
+	now := s.now()
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// normalizeWebhookURL validates that a webhook URL is an absolute http or
+// https URL
+func normalizeWebhookURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return "", &apperrors.ValidationError{Message: "url must be an absolute http or https URL"}
+	}
+	return raw, nil
+}
+
+// normalizeWebhookEvents validates the requested event types and returns
+// them without duplicates in canonical order
+func normalizeWebhookEvents(requested []string) ([]string, error) {
+	for _, eventType := range requested {
+		if !slices.Contains(models.WebhookEventTypes, eventType) {
+			return nil, &apperrors.ValidationError{
+				Message: fmt.Sprintf("unknown event type %q; allowed: %s", eventType, strings.Join(models.WebhookEventTypes, ", ")),
+			}
+		}
+	}
+	if len(requested) == 0 {
+		return nil, &apperrors.ValidationError{Message: "at least one event type is required"}
+	}
+
+	events := make([]string, 0, len(requested))
+	for _, eventType := range models.WebhookEventTypes {
+		if slices.Contains(requested, eventType) {
+			events = append(events, eventType)
+		}
+	}
+	return events, nil
+}
+
+// generateWebhookSecret returns a random secret for signing payloads
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
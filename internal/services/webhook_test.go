@@ -0,0 +1,183 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// MockWebhookRepository is a mock implementation of WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(webhook *models.Webhook) error {
+	args := m.Called(webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) FindAll(ownerID uint) ([]models.Webhook, error) {
+	args := m.Called(ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) FindByID(ownerID, id uint) (*models.Webhook, error) {
+	args := m.Called(ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(webhook *models.Webhook) error {
+	args := m.Called(webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ownerID, id uint) error {
+	args := m.Called(ownerID, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) DispatchOutbox(now time.Time, limit int) (int, error) {
+	args := m.Called(now, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(now, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) FindDeliveries(ownerID uint, filter *models.WebhookDeliveryFilter, page *models.PageRequest) (*models.WebhookDeliveryPage, error) {
+	args := m.Called(ownerID, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDeliveryPage), args.Error(1)
+}
+
+func (m *MockWebhookRepository) FindDelivery(ownerID, id uint) (*models.WebhookDelivery, error) {
+	args := m.Called(ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func TestCreateWebhook_Defaults(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Webhook")).Return(nil)
+
+	webhook, err := service.CreateWebhook(testOwnerID, &models.CreateWebhookRequest{URL: " https://example.com/hook "})
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook", webhook.URL)
+	assert.Equal(t, models.WebhookEventTypes, webhook.EventList())
+	assert.True(t, strings.HasPrefix(webhook.Secret, webhookSecretPrefix))
+	assert.True(t, webhook.Active)
+}
+
+func TestCreateWebhook_NormalizesEvents(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Webhook")).Return(nil)
+
+	webhook, err := service.CreateWebhook(testOwnerID, &models.CreateWebhookRequest{
+		URL:    "http://localhost:9000/events",
+		Secret: "s3cret",
+		Events: []string{"task.deleted", "task.created", "task.deleted"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "task.created task.deleted", webhook.Events)
+	assert.Equal(t, "s3cret", webhook.Secret)
+}
+
+func TestCreateWebhook_Invalid(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo)
+
+	for _, req := range []*models.CreateWebhookRequest{
+		{URL: "example.com/hook"},
+		{URL: "ftp://example.com/hook"},
+		{URL: "https://example.com/hook", Events: []string{"task.archived"}},
+		{URL: "https://example.com/hook", Events: []string{}},
+	} {
+		_, err := service.CreateWebhook(testOwnerID, req)
+		assert.IsType(t, &apperrors.ValidationError{}, err, req.URL)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo)
+	webhook := &models.Webhook{ID: 3, OwnerID: testOwnerID, URL: "https://example.com/hook", Secret: "old", Events: "task.created", Active: true}
+	mockRepo.On("FindByID", testOwnerID, uint(3)).Return(webhook, nil)
+	mockRepo.On("Update", webhook).Return(nil)
+
+	updated, err := service.UpdateWebhook(testOwnerID, 3, &models.UpdateWebhookRequest{
+		Events: []string{"task.completed"},
+		Active: ptr(false),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "task.completed", updated.Events)
+	assert.False(t, updated.Active)
+	assert.Equal(t, "old", updated.Secret)
+
+	_, err = service.UpdateWebhook(testOwnerID, 3, &models.UpdateWebhookRequest{Secret: ptr("")})
+	assert.IsType(t, &apperrors.ValidationError{}, err)
+}
+
+func TestListDeliveries_UnknownWebhook(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo)
+	mockRepo.On("FindByID", testOwnerID, uint(9)).Return(nil, &apperrors.WebhookNotFoundError{ID: 9})
+
+	_, err := service.ListDeliveries(testOwnerID, &models.WebhookDeliveryFilter{WebhookID: ptr(uint(9))}, &models.PageRequest{Limit: 20})
+
+	assert.IsType(t, &apperrors.WebhookNotFoundError{}, err)
+	mockRepo.AssertNotCalled(t, "FindDeliveries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRedeliver(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo).(*webhookService)
+	service.now = func() time.Time { return now }
+	mockRepo.On("FindByID", testOwnerID, uint(3)).Return(&models.Webhook{ID: 3, OwnerID: testOwnerID}, nil)
+	dead := &models.WebhookDelivery{ID: 5, WebhookID: 3, OwnerID: testOwnerID, Status: models.DeliveryDead, Attempts: 8}
+	mockRepo.On("FindDelivery", testOwnerID, uint(5)).Return(dead, nil)
+	mockRepo.On("FindDelivery", testOwnerID, uint(6)).Return(&models.WebhookDelivery{ID: 6, WebhookID: 4, OwnerID: testOwnerID}, nil)
+	mockRepo.On("UpdateDelivery", dead).Return(nil)
+
+	delivery, err := service.Redeliver(testOwnerID, 3, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.Attempts)
+	assert.Equal(t, &now, delivery.NextAttemptAt)
+
+	_, err = service.Redeliver(testOwnerID, 3, 6)
+	assert.IsType(t, &apperrors.DeliveryNotFoundError{}, err)
+}
@@ -0,0 +1,180 @@
+// Package validation checks requests against their binding tags and turns
+// the failures into validation errors that name the invalid fields as they
+// are spelled in the request body.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// Validate checks req, a pointer to a request, against its binding tags
+func Validate(req interface{}) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return Error(req, err)
+	}
+	return nil
+}
+
+// Error turns the error from binding or validating req into a
+// ValidationError with a violation for each invalid field. Errors that are
+// not about a field, such as malformed JSON, keep their own message.
+func Error(req interface{}, err error) error {
+	var fieldErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var timeErr *time.ParseError
+	switch {
+	case errors.As(err, &fieldErrs):
+		violations := make([]apperrors.FieldViolation, len(fieldErrs))
+		for i, fieldErr := range fieldErrs {
+			violations[i] = apperrors.FieldViolation{
+				Field:       fieldPath(reflect.TypeOf(req), fieldErr.StructNamespace()),
+				Description: describe(fieldErr),
+			}
+		}
+		return newError(violations)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return newError([]apperrors.FieldViolation{{
+			Field:       decodedPath(typeErr.Field),
+			Description: fmt.Sprintf("must be %s, not %s", jsonType(typeErr.Type), typeErr.Value),
+		}})
+	case errors.As(err, &timeErr):
+		// Times decode themselves, so the error does not name their field
+		return &apperrors.ValidationError{
+			Message: fmt.Sprintf("%q is not a time in RFC 3339 format, such as 2026-01-02T15:04:05Z", timeErr.Value),
+		}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return &apperrors.ValidationError{Message: "the body is not valid JSON: " + err.Error()}
+	case errors.Is(err, io.EOF):
+		return &apperrors.ValidationError{Message: "the body must be a JSON object"}
+	}
+	return &apperrors.ValidationError{Message: err.Error()}
+}
+
+// newError returns a ValidationError listing the violations
+func newError(violations []apperrors.FieldViolation) *apperrors.ValidationError {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.Field + " " + violation.Description
+	}
+	return &apperrors.ValidationError{Message: strings.Join(messages, "; "), Violations: violations}
+}
+
+// describe says what a value failing a validation tag should have been
+func describe(fieldErr validator.FieldError) string {
+	kind := fieldErr.Kind()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		switch {
+		case kind == reflect.Slice || kind == reflect.Map:
+			return fmt.Sprintf("must have at least %s items", fieldErr.Param())
+		case kind != reflect.String:
+			return "must be at least " + fieldErr.Param()
+		}
+		return fmt.Sprintf("must be at least %s characters long", fieldErr.Param())
+	case "max":
+		if kind == reflect.Slice || kind == reflect.Map {
+			return fmt.Sprintf("must have at most %s items", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at most %s characters long", fieldErr.Param())
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fieldErr.Param()), ", ")
+	case "hexcolor":
+		return "must be a hex color such as #ff0000"
+	case "email":
+		return "must be an email address"
+	case "timezone":
+		return "must be an IANA time zone such as Europe/Paris"
+	}
+	if fieldErr.Param() != "" {
+		return fmt.Sprintf("must satisfy %s=%s", fieldErr.Tag(), fieldErr.Param())
+	}
+	return "must satisfy " + fieldErr.Tag()
+}
+
+// fieldPath turns the namespace of a field in the Go struct typ, such as
+// CreateTaskRequest.Tags[2], into its path in the JSON body, tags[2]
+func fieldPath(typ reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+	path := make([]string, len(segments))
+	for i, segment := range segments {
+		name, index, indexed := strings.Cut(segment, "[")
+		path[i] = name
+
+		var field reflect.StructField
+		found := false
+		if typ = indirect(typ); typ != nil && typ.Kind() == reflect.Struct {
+			field, found = typ.FieldByName(name)
+		}
+		typ = nil
+		if found {
+			if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+				path[i] = tag
+			}
+			typ = indirect(field.Type)
+			if indexed && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map) {
+				typ = typ.Elem()
+			}
+		}
+		if indexed {
+			path[i] += "[" + index
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// decodedPath turns the path of a field that failed to decode, such as
+// operations.0.task.due_at, into the form of fieldPath, operations[0].task.due_at
+func decodedPath(field string) string {
+	segments := strings.Split(field, ".")
+	path := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if _, err := strconv.Atoi(segment); err == nil && len(path) > 0 {
+			path[len(path)-1] += "[" + segment + "]"
+			continue
+		}
+		path = append(path, segment)
+	}
+	return strings.Join(path, ".")
+}
+
+// indirect returns the type pointed to by typ, through any pointers
+func indirect(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// jsonType names the JSON type a Go type is decoded from
+func jsonType(typ reflect.Type) string {
+	switch typ.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Pointer:
+		return jsonType(typ.Elem())
+	}
+	return "an object"
+}
@@ -0,0 +1,75 @@
+package validation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(&models.CreateTaskRequest{Content: "Task"}))
+
+	err := Validate(&models.CreateTaskRequest{Content: strings.Repeat("x", 1001), Tags: []string{"ok", strings.Repeat("x", 51)}})
+	var validationErr *apperrors.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []apperrors.FieldViolation{
+		{Field: "content", Description: "must be at most 1000 characters long"},
+		{Field: "tags[1]", Description: "must be at most 50 characters long"},
+	}, validationErr.Violations)
+	assert.Equal(t, "content must be at most 1000 characters long; tags[1] must be at most 50 characters long", err.Error())
+}
+
+func TestValidate_Projects(t *testing.T) {
+	position := -1
+	err := Validate(&models.CreateProjectRequest{Name: "Work", Color: "red", Position: &position})
+	assert.EqualError(t, err, "color must be a hex color such as #ff0000; position must be at least 0")
+}
+
+func TestValidate_Accounts(t *testing.T) {
+	err := Validate(&models.RegisterRequest{Email: "ada", Password: "short", TimeZone: "Mars/Olympus"})
+	assert.EqualError(t, err, "email must be an email address; password must be at least 8 characters long; "+
+		"time_zone must be an IANA time zone such as Europe/Paris")
+
+	assert.EqualError(t, Validate(&models.LoginRequest{}), "email is required; password is required")
+	assert.EqualError(t, Validate(&models.RefreshRequest{}), "refresh_token is required")
+	assert.EqualError(t, Validate(&models.UpdateProfileRequest{TimeZone: "Mars/Olympus"}),
+		"time_zone must be an IANA time zone such as Europe/Paris")
+}
+
+func TestValidate_APITokens(t *testing.T) {
+	err := Validate(&models.CreateAPITokenRequest{Name: strings.Repeat("x", 101), Scopes: []string{}})
+	assert.EqualError(t, err, "name must be at most 100 characters long; scopes must have at least 1 items")
+
+	var req models.CreateAPITokenRequest
+	err = Error(&req, json.Unmarshal([]byte(`{"name":"CI","scopes":["tasks:read"],"expires_at":"tomorrow"}`), &req))
+	assert.EqualError(t, err, `"tomorrow" is not a time in RFC 3339 format, such as 2026-01-02T15:04:05Z`)
+}
+
+func TestError_NestedFields(t *testing.T) {
+	req := &models.BulkTaskRequest{Operations: []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "Task"}},
+		{Op: "rename", Task: &models.CreateTaskRequest{Priority: "asap"}},
+	}}
+	err := Error(req, binding.Validator.ValidateStruct(req))
+	assert.EqualError(t, err, "operations[1].op must be one of create, update, complete, delete; "+
+		"operations[1].task.content is required; operations[1].task.priority must be one of none, low, medium, high, urgent")
+}
+
+func TestError_DecodingErrors(t *testing.T) {
+	var req models.CreateTaskRequest
+	err := Error(&req, json.Unmarshal([]byte(`{"content":"Task","tags":"home"}`), &req))
+	assert.EqualError(t, err, "tags must be an array, not string")
+
+	var bulk models.BulkTaskRequest
+	err = Error(&bulk, json.Unmarshal([]byte(`{"operations":[{"op":"create"},{"op":"update","changes":{"due_at":42}}]}`), &bulk))
+	assert.EqualError(t, err, "operations[1].changes.due_at must be a string, not number")
+
+	err = Error(&req, json.Unmarshal([]byte(`{"content":}`), &req))
+	assert.EqualError(t, err, "the body is not valid JSON: invalid character '}' looking for beginning of value")
+}
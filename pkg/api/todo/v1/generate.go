@@ -0,0 +1,6 @@
+// Package todov1 holds the Go code generated from the todo.v1 protobuf
+// package in proto/todo/v1: its messages and the TaskService gRPC client
+// and server.
+package todov1
+
+//go:generate protoc -I ../../../../proto --go_out=../../../.. --go_opt=module=github.com/todo-api-go-sda --go-grpc_out=../../../.. --go-grpc_opt=module=github.com/todo-api-go-sda todo/v1/task.proto
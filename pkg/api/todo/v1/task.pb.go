@@ -0,0 +1,999 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: todo/v1/task.proto
+
+package todov1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Priority is the urgency of a task
+type Priority int32
+
+const (
+	// PRIORITY_UNSPECIFIED leaves the priority of a new task at none
+	Priority_PRIORITY_UNSPECIFIED Priority = 0
+	Priority_PRIORITY_NONE        Priority = 1
+	Priority_PRIORITY_LOW         Priority = 2
+	Priority_PRIORITY_MEDIUM      Priority = 3
+	Priority_PRIORITY_HIGH        Priority = 4
+	Priority_PRIORITY_URGENT      Priority = 5
+)
+
+// Enum value maps for Priority.
+var (
+	Priority_name = map[int32]string{
+		0: "PRIORITY_UNSPECIFIED",
+		1: "PRIORITY_NONE",
+		2: "PRIORITY_LOW",
+		3: "PRIORITY_MEDIUM",
+		4: "PRIORITY_HIGH",
+		5: "PRIORITY_URGENT",
+	}
+	Priority_value = map[string]int32{
+		"PRIORITY_UNSPECIFIED": 0,
+		"PRIORITY_NONE":        1,
+		"PRIORITY_LOW":         2,
+		"PRIORITY_MEDIUM":      3,
+		"PRIORITY_HIGH":        4,
+		"PRIORITY_URGENT":      5,
+	}
+)
+
+func (x Priority) Enum() *Priority {
+	p := new(Priority)
+	*p = x
+	return p
+}
+
+func (x Priority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Priority) Descriptor() protoreflect.EnumDescriptor {
+	return file_todo_v1_task_proto_enumTypes[0].Descriptor()
+}
+
+func (Priority) Type() protoreflect.EnumType {
+	return &file_todo_v1_task_proto_enumTypes[0]
+}
+
+func (x Priority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Priority.Descriptor instead.
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{0}
+}
+
+type TaskEvent_Type int32
+
+const (
+	TaskEvent_TYPE_UNSPECIFIED TaskEvent_Type = 0
+	TaskEvent_TYPE_CREATED     TaskEvent_Type = 1
+	TaskEvent_TYPE_UPDATED     TaskEvent_Type = 2
+	TaskEvent_TYPE_DELETED     TaskEvent_Type = 3
+	// TYPE_RESET tells that events may have been missed since the resume
+	// token, so the client should reload the tasks it shows
+	TaskEvent_TYPE_RESET TaskEvent_Type = 4
+	// TYPE_RESTORED tells that the task was taken out of the trash
+	TaskEvent_TYPE_RESTORED TaskEvent_Type = 5
+	// TYPE_PURGED tells that the task was deleted from the trash for good
+	TaskEvent_TYPE_PURGED TaskEvent_Type = 6
+)
+
+// Enum value maps for TaskEvent_Type.
+var (
+	TaskEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CREATED",
+		2: "TYPE_UPDATED",
+		3: "TYPE_DELETED",
+		4: "TYPE_RESET",
+		5: "TYPE_RESTORED",
+		6: "TYPE_PURGED",
+	}
+	TaskEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_CREATED":     1,
+		"TYPE_UPDATED":     2,
+		"TYPE_DELETED":     3,
+		"TYPE_RESET":       4,
+		"TYPE_RESTORED":    5,
+		"TYPE_PURGED":      6,
+	}
+)
+
+func (x TaskEvent_Type) Enum() *TaskEvent_Type {
+	p := new(TaskEvent_Type)
+	*p = x
+	return p
+}
+
+func (x TaskEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TaskEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_todo_v1_task_proto_enumTypes[1].Descriptor()
+}
+
+func (TaskEvent_Type) Type() protoreflect.EnumType {
+	return &file_todo_v1_task_proto_enumTypes[1]
+}
+
+func (x TaskEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TaskEvent_Type.Descriptor instead.
+func (TaskEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{8, 0}
+}
+
+// Task is a task of the authenticated user
+type Task struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// project_id is unset for tasks in the inbox
+	ProjectId *uint64 `protobuf:"varint,2,opt,name=project_id,json=projectId,proto3,oneof" json:"project_id,omitempty"`
+	// parent_id is unset for top-level tasks
+	ParentId    *uint64                `protobuf:"varint,3,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	Content     string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	Completed   bool                   `protobuf:"varint,5,opt,name=completed,proto3" json:"completed,omitempty"`
+	Priority    Priority               `protobuf:"varint,6,opt,name=priority,proto3,enum=todo.v1.Priority" json:"priority,omitempty"`
+	DueAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// recurrence is an RFC 5545 rule such as "FREQ=WEEKLY;BYDAY=MO"
+	Recurrence string `protobuf:"bytes,9,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	// series_id is set for the occurrences of a recurring task
+	SeriesId   *uint64 `protobuf:"varint,10,opt,name=series_id,json=seriesId,proto3,oneof" json:"series_id,omitempty"`
+	Occurrence int32   `protobuf:"varint,11,opt,name=occurrence,proto3" json:"occurrence,omitempty"`
+	// version increases with every change and guards updates and deletes
+	Version               uint64                 `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	Tags                  []string               `protobuf:"bytes,13,rep,name=tags,proto3" json:"tags,omitempty"`
+	SubtaskCount          int64                  `protobuf:"varint,14,opt,name=subtask_count,json=subtaskCount,proto3" json:"subtask_count,omitempty"`
+	CompletedSubtaskCount int64                  `protobuf:"varint,15,opt,name=completed_subtask_count,json=completedSubtaskCount,proto3" json:"completed_subtask_count,omitempty"`
+	CreatedAt             *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt             *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_todo_v1_task_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_task_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Task) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Task) GetProjectId() uint64 {
+	if x != nil && x.ProjectId != nil {
+		return *x.ProjectId
+	}
+	return 0
+}
+
+func (x *Task) GetParentId() uint64 {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return 0
+}
+
+func (x *Task) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Task) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+func (x *Task) GetPriority() Priority {
+	if x != nil {
+		return x.Priority
+	}
+	return Priority_PRIORITY_UNSPECIFIED
+}
+
+func (x *Task) GetDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueAt
+	}
+	return nil
+}
+
+func (x *Task) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Task) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *Task) GetSeriesId() uint64 {
+	if x != nil && x.SeriesId != nil {
+		return *x.SeriesId
+	}
+	return 0
+}
+
+func (x *Task) GetOccurrence() int32 {
+	if x != nil {
+		return x.Occurrence
+	}
+	return 0
+}
+
+func (x *Task) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Task) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Task) GetSubtaskCount() int64 {
+	if x != nil {
+		return x.SubtaskCount
+	}
+	return 0
+}
+
+func (x *Task) GetCompletedSubtaskCount() int64 {
+	if x != nil {
+		return x.CompletedSubtaskCount
+	}
+	return 0
+}
+
+func (x *Task) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Task) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type CreateTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// task holds the fields of the new task; id, completed and the output
+	// only fields are ignored
+	Task          *Task `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaskRequest) Reset() {
+	*x = CreateTaskRequest{}
+	mi := &file_todo_v1_task_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaskRequest) ProtoMessage() {}
+
+func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_task_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaskRequest.ProtoReflect.Descriptor instead.
+func (*CreateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateTaskRequest) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+type GetTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskRequest) Reset() {
+	*x = GetTaskRequest{}
+	mi := &file_todo_v1_task_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskRequest) ProtoMessage() {}
+
+func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_task_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetTaskRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page_size is the maximum number of tasks to return. It defaults to the
+	// server's page size and is capped at its maximum page size.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of a previous response
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// project_id only lists the tasks of a project, or of the inbox when 0
+	ProjectId *uint64 `protobuf:"varint,3,opt,name=project_id,json=projectId,proto3,oneof" json:"project_id,omitempty"`
+	Completed *bool   `protobuf:"varint,4,opt,name=completed,proto3,oneof" json:"completed,omitempty"`
+	// tags only lists the tasks having every one of the tags
+	Tags          []string `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_todo_v1_task_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_task_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListTasksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetProjectId() uint64 {
+	if x != nil && x.ProjectId != nil {
+		return *x.ProjectId
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetCompleted() bool {
+	if x != nil && x.Completed != nil {
+		return *x.Completed
+	}
+	return false
+}
+
+func (x *ListTasksRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type ListTasksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Tasks []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	// next_page_token is empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_todo_v1_task_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_task_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+func (x *ListTasksResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type UpdateTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// task holds the task's id and its new field values
+	Task *Task `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	// update_mask names the fields to change: content, completed, priority,
+	// due_at, recurrence, project_id, parent_id and tags. A field named by
+	// the mask but unset in task is cleared.
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	// version, when set, only applies the update to that version of the task
+	Version *uint64 `protobuf:"varint,3,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	// complete_subtasks completes every subtask of a completed task with it
+	CompleteSubtasks bool `protobuf:"varint,4,opt,name=complete_subtasks,json=completeSubtasks,proto3" json:"complete_subtasks,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *UpdateTaskRequest) Reset() {
+	*x = UpdateTaskRequest{}
+	mi := &file_todo_v1_task_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTaskRequest) ProtoMessage() {}
+
+func (x *UpdateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_task_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTaskRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateTaskRequest) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *UpdateTaskRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateTaskRequest) GetVersion() uint64 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+func (x *UpdateTaskRequest) GetCompleteSubtasks() bool {
+	if x != nil {
+		return x.CompleteSubtasks
+	}
+	return false
+}
+
+type DeleteTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// cascade deletes the task's subtasks along with it
+	Cascade bool `protobuf:"varint,2,opt,name=cascade,proto3" json:"cascade,omitempty"`
+	// version, when set, only deletes that version of the task
+	Version       *uint64 `protobuf:"varint,3,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTaskRequest) Reset() {
+	*x = DeleteTaskRequest{}
+	mi := &file_todo_v1_task_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTaskRequest) ProtoMessage() {}
+
+func (x *DeleteTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_task_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTaskRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTaskRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteTaskRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteTaskRequest) GetCascade() bool {
+	if x != nil {
+		return x.Cascade
+	}
+	return false
+}
+
+func (x *DeleteTaskRequest) GetVersion() uint64 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+type WatchTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// resume_token is the id of the last event received on a previous call.
+	// The events since then are sent first when they are still buffered.
+	ResumeToken   string `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTasksRequest) Reset() {
+	*x = WatchTasksRequest{}
+	mi := &file_todo_v1_task_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTasksRequest) ProtoMessage() {}
+
+func (x *WatchTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_task_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTasksRequest.ProtoReflect.Descriptor instead.
+func (*WatchTasksRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WatchTasksRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+// TaskEvent is a change to one of the user's tasks
+type TaskEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id resumes the stream after this event
+	Id   string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type TaskEvent_Type `protobuf:"varint,2,opt,name=type,proto3,enum=todo.v1.TaskEvent_Type" json:"type,omitempty"`
+	// task is the task as created, updated or restored
+	Task *Task `protobuf:"bytes,3,opt,name=task,proto3" json:"task,omitempty"`
+	// task_id is the id of the changed task
+	TaskId        uint64 `protobuf:"varint,4,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskEvent) Reset() {
+	*x = TaskEvent{}
+	mi := &file_todo_v1_task_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskEvent) ProtoMessage() {}
+
+func (x *TaskEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_task_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskEvent.ProtoReflect.Descriptor instead.
+func (*TaskEvent) Descriptor() ([]byte, []int) {
+	return file_todo_v1_task_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TaskEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetType() TaskEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return TaskEvent_TYPE_UNSPECIFIED
+}
+
+func (x *TaskEvent) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *TaskEvent) GetTaskId() uint64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+var File_todo_v1_task_proto protoreflect.FileDescriptor
+
+const file_todo_v1_task_proto_rawDesc = "" +
+	"\n" +
+	"\x12todo/v1/task.proto\x12\atodo.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc3\x05\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\"\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\x04H\x00R\tprojectId\x88\x01\x01\x12 \n" +
+	"\tparent_id\x18\x03 \x01(\x04H\x01R\bparentId\x88\x01\x01\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12\x1c\n" +
+	"\tcompleted\x18\x05 \x01(\bR\tcompleted\x12-\n" +
+	"\bpriority\x18\x06 \x01(\x0e2\x11.todo.v1.PriorityR\bpriority\x121\n" +
+	"\x06due_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x05dueAt\x12=\n" +
+	"\fcompleted_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\t \x01(\tR\n" +
+	"recurrence\x12 \n" +
+	"\tseries_id\x18\n" +
+	" \x01(\x04H\x02R\bseriesId\x88\x01\x01\x12\x1e\n" +
+	"\n" +
+	"occurrence\x18\v \x01(\x05R\n" +
+	"occurrence\x12\x18\n" +
+	"\aversion\x18\f \x01(\x04R\aversion\x12\x12\n" +
+	"\x04tags\x18\r \x03(\tR\x04tags\x12#\n" +
+	"\rsubtask_count\x18\x0e \x01(\x03R\fsubtaskCount\x126\n" +
+	"\x17completed_subtask_count\x18\x0f \x01(\x03R\x15completedSubtaskCount\x129\n" +
+	"\n" +
+	"created_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAtB\r\n" +
+	"\v_project_idB\f\n" +
+	"\n" +
+	"_parent_idB\f\n" +
+	"\n" +
+	"_series_id\"6\n" +
+	"\x11CreateTaskRequest\x12!\n" +
+	"\x04task\x18\x01 \x01(\v2\r.todo.v1.TaskR\x04task\" \n" +
+	"\x0eGetTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\xc6\x01\n" +
+	"\x10ListTasksRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\"\n" +
+	"\n" +
+	"project_id\x18\x03 \x01(\x04H\x00R\tprojectId\x88\x01\x01\x12!\n" +
+	"\tcompleted\x18\x04 \x01(\bH\x01R\tcompleted\x88\x01\x01\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tagsB\r\n" +
+	"\v_project_idB\f\n" +
+	"\n" +
+	"_completed\"`\n" +
+	"\x11ListTasksResponse\x12#\n" +
+	"\x05tasks\x18\x01 \x03(\v2\r.todo.v1.TaskR\x05tasks\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xcb\x01\n" +
+	"\x11UpdateTaskRequest\x12!\n" +
+	"\x04task\x18\x01 \x01(\v2\r.todo.v1.TaskR\x04task\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12\x1d\n" +
+	"\aversion\x18\x03 \x01(\x04H\x00R\aversion\x88\x01\x01\x12+\n" +
+	"\x11complete_subtasks\x18\x04 \x01(\bR\x10completeSubtasksB\n" +
+	"\n" +
+	"\b_version\"h\n" +
+	"\x11DeleteTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x18\n" +
+	"\acascade\x18\x02 \x01(\bR\acascade\x12\x1d\n" +
+	"\aversion\x18\x03 \x01(\x04H\x00R\aversion\x88\x01\x01B\n" +
+	"\n" +
+	"\b_version\"6\n" +
+	"\x11WatchTasksRequest\x12!\n" +
+	"\fresume_token\x18\x01 \x01(\tR\vresumeToken\"\x8d\x02\n" +
+	"\tTaskEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12+\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x17.todo.v1.TaskEvent.TypeR\x04type\x12!\n" +
+	"\x04task\x18\x03 \x01(\v2\r.todo.v1.TaskR\x04task\x12\x17\n" +
+	"\atask_id\x18\x04 \x01(\x04R\x06taskId\"\x86\x01\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_CREATED\x10\x01\x12\x10\n" +
+	"\fTYPE_UPDATED\x10\x02\x12\x10\n" +
+	"\fTYPE_DELETED\x10\x03\x12\x0e\n" +
+	"\n" +
+	"TYPE_RESET\x10\x04\x12\x11\n" +
+	"\rTYPE_RESTORED\x10\x05\x12\x0f\n" +
+	"\vTYPE_PURGED\x10\x06*\x86\x01\n" +
+	"\bPriority\x12\x18\n" +
+	"\x14PRIORITY_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rPRIORITY_NONE\x10\x01\x12\x10\n" +
+	"\fPRIORITY_LOW\x10\x02\x12\x13\n" +
+	"\x0fPRIORITY_MEDIUM\x10\x03\x12\x11\n" +
+	"\rPRIORITY_HIGH\x10\x04\x12\x13\n" +
+	"\x0fPRIORITY_URGENT\x10\x052\xf8\x02\n" +
+	"\vTaskService\x127\n" +
+	"\n" +
+	"CreateTask\x12\x1a.todo.v1.CreateTaskRequest\x1a\r.todo.v1.Task\x121\n" +
+	"\aGetTask\x12\x17.todo.v1.GetTaskRequest\x1a\r.todo.v1.Task\x12B\n" +
+	"\tListTasks\x12\x19.todo.v1.ListTasksRequest\x1a\x1a.todo.v1.ListTasksResponse\x127\n" +
+	"\n" +
+	"UpdateTask\x12\x1a.todo.v1.UpdateTaskRequest\x1a\r.todo.v1.Task\x12@\n" +
+	"\n" +
+	"DeleteTask\x12\x1a.todo.v1.DeleteTaskRequest\x1a\x16.google.protobuf.Empty\x12>\n" +
+	"\n" +
+	"WatchTasks\x12\x1a.todo.v1.WatchTasksRequest\x1a\x12.todo.v1.TaskEvent0\x01B3Z1github.com/todo-api-go-sda/pkg/api/todo/v1;todov1b\x06proto3"
+
+var (
+	file_todo_v1_task_proto_rawDescOnce sync.Once
+	file_todo_v1_task_proto_rawDescData []byte
+)
+
+func file_todo_v1_task_proto_rawDescGZIP() []byte {
+	file_todo_v1_task_proto_rawDescOnce.Do(func() {
+		file_todo_v1_task_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_todo_v1_task_proto_rawDesc), len(file_todo_v1_task_proto_rawDesc)))
+	})
+	return file_todo_v1_task_proto_rawDescData
+}
+
+var file_todo_v1_task_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_todo_v1_task_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_todo_v1_task_proto_goTypes = []any{
+	(Priority)(0),                 // 0: todo.v1.Priority
+	(TaskEvent_Type)(0),           // 1: todo.v1.TaskEvent.Type
+	(*Task)(nil),                  // 2: todo.v1.Task
+	(*CreateTaskRequest)(nil),     // 3: todo.v1.CreateTaskRequest
+	(*GetTaskRequest)(nil),        // 4: todo.v1.GetTaskRequest
+	(*ListTasksRequest)(nil),      // 5: todo.v1.ListTasksRequest
+	(*ListTasksResponse)(nil),     // 6: todo.v1.ListTasksResponse
+	(*UpdateTaskRequest)(nil),     // 7: todo.v1.UpdateTaskRequest
+	(*DeleteTaskRequest)(nil),     // 8: todo.v1.DeleteTaskRequest
+	(*WatchTasksRequest)(nil),     // 9: todo.v1.WatchTasksRequest
+	(*TaskEvent)(nil),             // 10: todo.v1.TaskEvent
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil), // 12: google.protobuf.FieldMask
+	(*emptypb.Empty)(nil),         // 13: google.protobuf.Empty
+}
+var file_todo_v1_task_proto_depIdxs = []int32{
+	0,  // 0: todo.v1.Task.priority:type_name -> todo.v1.Priority
+	11, // 1: todo.v1.Task.due_at:type_name -> google.protobuf.Timestamp
+	11, // 2: todo.v1.Task.completed_at:type_name -> google.protobuf.Timestamp
+	11, // 3: todo.v1.Task.created_at:type_name -> google.protobuf.Timestamp
+	11, // 4: todo.v1.Task.updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 5: todo.v1.CreateTaskRequest.task:type_name -> todo.v1.Task
+	2,  // 6: todo.v1.ListTasksResponse.tasks:type_name -> todo.v1.Task
+	2,  // 7: todo.v1.UpdateTaskRequest.task:type_name -> todo.v1.Task
+	12, // 8: todo.v1.UpdateTaskRequest.update_mask:type_name -> google.protobuf.FieldMask
+	1,  // 9: todo.v1.TaskEvent.type:type_name -> todo.v1.TaskEvent.Type
+	2,  // 10: todo.v1.TaskEvent.task:type_name -> todo.v1.Task
+	3,  // 11: todo.v1.TaskService.CreateTask:input_type -> todo.v1.CreateTaskRequest
+	4,  // 12: todo.v1.TaskService.GetTask:input_type -> todo.v1.GetTaskRequest
+	5,  // 13: todo.v1.TaskService.ListTasks:input_type -> todo.v1.ListTasksRequest
+	7,  // 14: todo.v1.TaskService.UpdateTask:input_type -> todo.v1.UpdateTaskRequest
+	8,  // 15: todo.v1.TaskService.DeleteTask:input_type -> todo.v1.DeleteTaskRequest
+	9,  // 16: todo.v1.TaskService.WatchTasks:input_type -> todo.v1.WatchTasksRequest
+	2,  // 17: todo.v1.TaskService.CreateTask:output_type -> todo.v1.Task
+	2,  // 18: todo.v1.TaskService.GetTask:output_type -> todo.v1.Task
+	6,  // 19: todo.v1.TaskService.ListTasks:output_type -> todo.v1.ListTasksResponse
+	2,  // 20: todo.v1.TaskService.UpdateTask:output_type -> todo.v1.Task
+	13, // 21: todo.v1.TaskService.DeleteTask:output_type -> google.protobuf.Empty
+	10, // 22: todo.v1.TaskService.WatchTasks:output_type -> todo.v1.TaskEvent
+	17, // [17:23] is the sub-list for method output_type
+	11, // [11:17] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_todo_v1_task_proto_init() }
+func file_todo_v1_task_proto_init() {
+	if File_todo_v1_task_proto != nil {
+		return
+	}
+	file_todo_v1_task_proto_msgTypes[0].OneofWrappers = []any{}
+	file_todo_v1_task_proto_msgTypes[3].OneofWrappers = []any{}
+	file_todo_v1_task_proto_msgTypes[5].OneofWrappers = []any{}
+	file_todo_v1_task_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_todo_v1_task_proto_rawDesc), len(file_todo_v1_task_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_todo_v1_task_proto_goTypes,
+		DependencyIndexes: file_todo_v1_task_proto_depIdxs,
+		EnumInfos:         file_todo_v1_task_proto_enumTypes,
+		MessageInfos:      file_todo_v1_task_proto_msgTypes,
+	}.Build()
+	File_todo_v1_task_proto = out.File
+	file_todo_v1_task_proto_goTypes = nil
+	file_todo_v1_task_proto_depIdxs = nil
+}
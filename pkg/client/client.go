@@ -0,0 +1,254 @@
+// Package client is a Go client of the tasks of the todo API. Every call
+// takes a context, failed requests are retried with jittered exponential
+// backoff, and error responses are returned as the errors of pkg/errors.
+//
+//	c, err := client.New("https://todo.example.com", client.WithToken(token))
+//	task, err := c.CreateTask(ctx, &client.CreateTaskRequest{Content: "Buy milk"})
+package client
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiPrefix is the path of the API below the base URL
+const apiPrefix = "/api/v1"
+
+// userAgent identifies the client to the server
+const userAgent = "todo-api-go-client"
+
+// Headers the client sends, as read by the server's middleware
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	timeZoneHeader       = "Time-Zone"
+)
+
+// Default retry settings
+const (
+	defaultMaxRetries = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 2 * time.Second
+)
+
+// Client calls the todo API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	token      string
+	timeZone   string
+	httpClient *http.Client
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken authenticates requests with a bearer token: an access token or
+// a personal access token
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient sends requests with the given http.Client instead of
+// http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a failed request is retried and the
+// bounds of the backoff between attempts. Zero retries disables retrying.
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithTimeZone sends the IANA name of a time zone in the Time-Zone header,
+// overriding the user's time zone for due dates without an offset
+func WithTimeZone(name string) Option {
+	return func(c *Client) {
+		c.timeZone = name
+	}
+}
+
+// New creates a Client of the API served at baseURL, such as
+// "https://todo.example.com"
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("client: base URL %q must be an absolute http or https URL", baseURL)
+	}
+	c := &Client{
+		baseURL:    strings.TrimSuffix(u.String(), "/"),
+		httpClient: http.DefaultClient,
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request is an API request that can be sent more than once
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   []byte
+	header http.Header
+}
+
+// newRequest builds a request with the JSON encoding of body, if any
+func newRequest(method, path string, body interface{}) (*request, error) {
+	req := &request{method: method, path: path, header: http.Header{}}
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("client: encoding request: %w", err)
+		}
+		req.body = encoded
+	}
+	return req, nil
+}
+
+// do sends a request, retrying it while it fails with a retryable error,
+// and decodes a successful response into out, if any. POST requests are
+// sent with an Idempotency-Key, so that the server runs them once however
+// often they are retried.
+func (c *Client) do(ctx context.Context, req *request, out interface{}) (*http.Response, error) {
+	if req.method == http.MethodPost && req.header.Get(idempotencyKeyHeader) == "" {
+		req.header.Set(idempotencyKeyHeader, newIdempotencyKey())
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req)
+		if err == nil && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out != nil {
+				if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+					return resp, fmt.Errorf("client: decoding response: %w", err)
+				}
+			}
+			return resp, nil
+		}
+
+		var retryAfter time.Duration
+		if err == nil {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			err = decodeError(resp)
+			resp.Body.Close()
+		}
+		if attempt >= c.maxRetries || !retryable(ctx, resp) {
+			return resp, err
+		}
+
+		delay := max(c.backoff(attempt), retryAfter)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes one attempt at a request
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	target := c.baseURL + apiPrefix + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("client: %w", err)
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", userAgent)
+	if req.body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.timeZone != "" {
+		httpReq.Header.Set(timeZoneHeader, c.timeZone)
+	}
+	return c.httpClient.Do(httpReq)
+}
+
+// retryable reports whether a failed attempt is worth retrying: the request
+// did not reach the server or the server was unavailable or overloaded.
+// Nothing is retried once ctx is done.
+func retryable(ctx context.Context, resp *http.Response) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if resp == nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the retry that follows the given failed
+// attempt: a random duration up to the minimum backoff doubled for each
+// attempt, capped at the maximum backoff ("full jitter")
+func (c *Client) backoff(attempt int) time.Duration {
+	ceiling := c.minBackoff
+	for i := 0; i < attempt && ceiling < c.maxBackoff; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, c.maxBackoff)
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds; dates and
+// malformed values are ignored
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// newIdempotencyKey returns a random Idempotency-Key of 32 hex digits
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	cryptorand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,295 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/handlers"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	"github.com/todo-api-go-sda/internal/services"
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// testServer serves the tasks routes of the API over HTTP in memory to a
+// registered user. Fail makes the server answer the next requests with
+// the given statuses instead of the router's responses, after the router
+// has handled them.
+type testServer struct {
+	url       string
+	token     string
+	userID    uint
+	apiTokens services.APITokenService
+	requests  atomic.Int32
+	fail      chan int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	tokens, err := auth.NewTokenManager(&config.AuthConfig{
+		JWTAlgorithm:    "HS256",
+		JWTSecret:       "test-secret",
+		JWTIssuer:       "todo-api",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+	require.NoError(t, err)
+	authService := services.NewAuthService(repository.NewMemoryUserRepository(), tokens)
+	registered, err := authService.Register(&models.RegisterRequest{Email: "client@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	taskRepo := repository.NewMemoryTaskRepository()
+	taskService := services.NewTaskService(taskRepo, repository.NewMemoryProjectRepository(taskRepo), repository.NewMemoryTagRepository(taskRepo),
+		&config.TaskConfig{MaxSubtaskDepth: 2}, events.NewBus(10))
+	taskHandler := handlers.NewTaskHandler(taskService, &config.ServerConfig{DefaultPageSize: 50, MaxPageSize: 100, CursorSecret: "test-secret"})
+	apiTokens := services.NewAPITokenService(repository.NewMemoryAPITokenRepository())
+	idempotent := middleware.Idempotency(services.NewIdempotencyService(repository.NewMemoryIdempotencyRepository(),
+		&config.IdempotencyConfig{KeyTTL: time.Hour, LockTimeout: time.Minute}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	read := middleware.RequireScope(auth.ScopeTasksRead)
+	write := middleware.RequireScope(auth.ScopeTasksWrite)
+	del := middleware.RequireScope(auth.ScopeTasksDelete)
+	tasks := router.Group("/api/v1/tasks", middleware.RequireAuth(tokens, apiTokens), middleware.ResolveTimeZone(authService), idempotent)
+	{
+		tasks.POST("", write, taskHandler.CreateTask)
+		tasks.GET("", read, taskHandler.ListTasks)
+		tasks.GET("/:id", read, taskHandler.GetTask)
+		tasks.PUT("/:id", write, taskHandler.UpdateTask)
+		tasks.DELETE("/:id", del, taskHandler.DeleteTask)
+	}
+
+	s := &testServer{
+		token:     registered.AccessToken,
+		userID:    registered.User.ID,
+		apiTokens: apiTokens,
+		fail:      make(chan int, 10),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		select {
+		case status := <-s.fail:
+			router.ServeHTTP(httptest.NewRecorder(), r)
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, http.StatusText(status), status)
+		default:
+			router.ServeHTTP(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	s.url = server.URL
+	return s
+}
+
+// client returns a client of the server, authenticated with the token of
+// the user, that retries without delay
+func (s *testServer) client(t *testing.T, opts ...Option) *Client {
+	opts = append([]Option{WithToken(s.token), WithRetries(2, time.Millisecond, time.Millisecond)}, opts...)
+	c, err := New(s.url, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+// testContext returns a context that ends with the test
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "localhost:8080", "ftp://example.com", "http://"} {
+		_, err := New(baseURL)
+		assert.Error(t, err, baseURL)
+	}
+}
+
+func TestClient_CreateGetUpdateDelete(t *testing.T) {
+	server := newTestServer(t)
+	c := server.client(t, WithTimeZone("Europe/Paris"))
+	ctx := testContext(t)
+
+	dueAt := "2030-05-01T09:00"
+	created, err := c.CreateTask(ctx, &CreateTaskRequest{Content: "Ship the client", Tags: []string{"go"}, Priority: "high", DueAt: &dueAt})
+	require.NoError(t, err)
+	assert.Equal(t, "Ship the client", created.Content)
+	assert.Equal(t, []string{"go"}, created.Tags)
+	assert.Equal(t, "high", created.Priority)
+	assert.Equal(t, time.Date(2030, 5, 1, 7, 0, 0, 0, time.UTC), created.DueAt.UTC(), "due in the client's time zone")
+	assert.Equal(t, uint(1), created.Version)
+
+	got, err := c.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, got.ID)
+
+	doc := NewTaskDocument(got)
+	doc.Content = "Ship the Go client"
+	doc.Tags = append(doc.Tags, "sdk")
+	updated, err := c.UpdateTask(ctx, got.ID, &ReplaceTaskRequest{TaskDocument: doc}, IfVersion(got.Version))
+	require.NoError(t, err)
+	assert.Equal(t, "Ship the Go client", updated.Content)
+	assert.Equal(t, []string{"go", "sdk"}, updated.Tags)
+	assert.Equal(t, created.DueAt.UTC(), updated.DueAt.UTC(), "the document keeps the due date")
+	assert.Equal(t, uint(2), updated.Version)
+
+	// A write at a stale version gets the current task back
+	_, err = c.UpdateTask(ctx, got.ID, &ReplaceTaskRequest{TaskDocument: doc}, IfVersion(got.Version))
+	var conflict *apperrors.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, created.ID, conflict.ID)
+	require.IsType(t, &Task{}, conflict.Current)
+	assert.Equal(t, uint(2), conflict.Current.(*Task).Version)
+
+	require.NoError(t, c.DeleteTask(ctx, created.ID, IfVersion(updated.Version)))
+	_, err = c.GetTask(ctx, created.ID)
+	var notFound *apperrors.TaskNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, created.ID, notFound.ID)
+}
+
+func TestClient_ListTasks(t *testing.T) {
+	server := newTestServer(t)
+	c := server.client(t)
+	ctx := testContext(t)
+
+	for _, content := range []string{"One", "Two", "Three", "Four", "Five"} {
+		_, err := c.CreateTask(ctx, &CreateTaskRequest{Content: content})
+		require.NoError(t, err)
+	}
+	task, err := c.CreateTask(ctx, &CreateTaskRequest{Content: "Done"})
+	require.NoError(t, err)
+	doc := NewTaskDocument(task)
+	doc.Completed = true
+	_, err = c.UpdateTask(ctx, task.ID, &ReplaceTaskRequest{TaskDocument: doc})
+	require.NoError(t, err)
+
+	open := false
+	pages := c.ListTasks(ctx, &ListTasksOptions{Completed: &open, Sort: "content", PageSize: 2, IncludeTotal: true})
+	var sizes []int
+	var contents []string
+	for pages.Next() {
+		page := pages.Page()
+		sizes = append(sizes, page.Count)
+		require.NotNil(t, page.Total)
+		assert.Equal(t, int64(5), *page.Total)
+		for _, task := range page.Tasks {
+			contents = append(contents, task.Content)
+		}
+	}
+	require.NoError(t, pages.Err())
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	assert.Equal(t, []string{"Five", "Four", "One", "Three", "Two"}, contents)
+	assert.False(t, pages.Next(), "the iterator stays done")
+
+	all, err := c.ListTasks(ctx, nil).All()
+	require.NoError(t, err)
+	assert.Len(t, all, 6)
+
+	_, err = c.ListTasks(ctx, &ListTasksOptions{TagMode: "some"}).All()
+	var invalid *apperrors.ValidationError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "invalid query parameter tag_mode: must be all or any", invalid.Message)
+}
+
+func TestClient_Errors(t *testing.T) {
+	server := newTestServer(t)
+	c := server.client(t)
+	ctx := testContext(t)
+
+	_, err := c.CreateTask(ctx, &CreateTaskRequest{})
+	var invalid *apperrors.ValidationError
+	assert.ErrorAs(t, err, &invalid)
+
+	parent, err := c.CreateTask(ctx, &CreateTaskRequest{Content: "Parent"})
+	require.NoError(t, err)
+	_, err = c.CreateTask(ctx, &CreateTaskRequest{Content: "Child", ParentID: &parent.ID})
+	require.NoError(t, err)
+	err = c.DeleteTask(ctx, parent.ID)
+	var conflict *apperrors.ConflictError
+	assert.ErrorAs(t, err, &conflict)
+	assert.NoError(t, c.DeleteTask(ctx, parent.ID, Cascade()))
+
+	anonymous := server.client(t, WithToken("not-a-token"))
+	_, err = anonymous.GetTask(ctx, parent.ID)
+	var unauthorized *apperrors.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorized)
+
+	_, readOnly, err := server.apiTokens.CreateToken(server.userID, &models.CreateAPITokenRequest{Name: "ci", Scopes: []string{auth.ScopeTasksRead}})
+	require.NoError(t, err)
+	_, err = server.client(t, WithToken(readOnly)).CreateTask(ctx, &CreateTaskRequest{Content: "Task"})
+	var forbidden *apperrors.ForbiddenError
+	require.ErrorAs(t, err, &forbidden)
+	assert.Equal(t, "token is missing the "+auth.ScopeTasksWrite+" scope", forbidden.Message)
+}
+
+func TestClient_RetriesWithIdempotencyKey(t *testing.T) {
+	server := newTestServer(t)
+	c := server.client(t)
+	ctx := testContext(t)
+
+	// The task is created by the first attempt and replayed to the retries
+	server.fail <- http.StatusServiceUnavailable
+	server.fail <- http.StatusBadGateway
+	task, err := c.CreateTask(ctx, &CreateTaskRequest{Content: "Once"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), server.requests.Load())
+	all, err := c.ListTasks(ctx, nil).All()
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, task.ID, all[0].ID)
+
+	// Retries stop after the last one
+	for range 3 {
+		server.fail <- http.StatusServiceUnavailable
+	}
+	_, err = c.GetTask(ctx, task.ID)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Empty(t, apiErr.Code)
+	assert.Equal(t, int32(7), server.requests.Load())
+
+	// Errors of the API are not retried
+	_, err = c.GetTask(ctx, task.ID+1)
+	assert.Error(t, err)
+	assert.Equal(t, int32(8), server.requests.Load())
+}
+
+func TestClient_ContextEndsRetries(t *testing.T) {
+	server := newTestServer(t)
+	c := server.client(t, WithRetries(5, time.Hour, time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	server.fail <- http.StatusServiceUnavailable
+	started := time.Now()
+	_, err := c.GetTask(ctx, 1)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	assert.Less(t, time.Since(started), time.Second)
+	assert.Equal(t, int32(1), server.requests.Load())
+}
+
+func TestBackoff(t *testing.T) {
+	c, err := New("http://localhost", WithRetries(5, 100*time.Millisecond, time.Second))
+	require.NoError(t, err)
+
+	for attempt, ceiling := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		for range 20 {
+			delay := c.backoff(attempt)
+			assert.Greater(t, delay, time.Duration(0))
+			assert.LessOrEqual(t, delay, ceiling)
+		}
+	}
+}
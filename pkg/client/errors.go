@@ -0,0 +1,89 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	apperrors "github.com/todo-api-go-sda/pkg/errors"
+)
+
+// maxErrorBody limits how much of an error response is read
+const maxErrorBody = 64 << 10
+
+// Error is an error response that has no more specific error in
+// pkg/errors, such as an internal error or a response from a proxy in front
+// of the API. Code is empty when the body was not an API error.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("todo API responded with status %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("todo API responded with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// errorResponse is the body of an API error response. Current holds the
+// task's current representation on version conflicts.
+type errorResponse struct {
+	Error   apperrors.ErrorDetail `json:"error"`
+	Current *Task                 `json:"current"`
+}
+
+// decodeError turns an error response into the error of pkg/errors its
+// code stands for, or an *Error. The IDs of not found errors are recovered
+// from their messages.
+func decodeError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var decoded errorResponse
+	if err := json.Unmarshal(body, &decoded); err != nil || decoded.Error.Code == "" {
+		message := string(body)
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: message}
+	}
+
+	detail := decoded.Error
+	switch detail.Code {
+	case apperrors.CodeTaskNotFound:
+		err := &apperrors.TaskNotFoundError{}
+		fmt.Sscanf(detail.Message, "Task with id %d not found", &err.ID)
+		return err
+	case apperrors.CodeVersionNotFound:
+		err := &apperrors.VersionNotFoundError{}
+		fmt.Sscanf(detail.Message, "Version %d of task with id %d not found", &err.Version, &err.ID)
+		return err
+	case apperrors.CodeProjectNotFound:
+		err := &apperrors.ProjectNotFoundError{}
+		fmt.Sscanf(detail.Message, "Project with id %d not found", &err.ID)
+		return err
+	case apperrors.CodeTagNotFound:
+		err := &apperrors.TagNotFoundError{}
+		fmt.Sscanf(detail.Message, "Tag with id %d not found", &err.ID)
+		return err
+	case apperrors.CodeValidationError:
+		return &apperrors.ValidationError{Message: detail.Message}
+	case apperrors.CodeUnauthorized:
+		return &apperrors.UnauthorizedError{Message: detail.Message}
+	case apperrors.CodeForbidden:
+		return &apperrors.ForbiddenError{Message: detail.Message}
+	case apperrors.CodeConflict:
+		return &apperrors.ConflictError{Message: detail.Message}
+	case apperrors.CodeVersionConflict:
+		err := &apperrors.VersionConflictError{}
+		if decoded.Current != nil {
+			err.ID = decoded.Current.ID
+			err.Current = decoded.Current
+		}
+		return err
+	case apperrors.CodeNotApplied:
+		return &apperrors.NotAppliedError{}
+	}
+	return &Error{StatusCode: resp.StatusCode, Code: detail.Code, Message: detail.Message}
+}
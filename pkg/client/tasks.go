@@ -0,0 +1,297 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// The request and response bodies of the tasks API
+type (
+	// Task is a task as the API represents it
+	Task = models.TaskResponse
+	// TaskPage is one page of a list of tasks
+	TaskPage = models.TaskListResponse
+	// CreateTaskRequest is the body of CreateTask
+	CreateTaskRequest = models.CreateTaskRequest
+	// TaskDocument holds the writable fields of a task
+	TaskDocument = models.TaskDocument
+	// ReplaceTaskRequest is the body of UpdateTask, which replaces every
+	// writable field of the task
+	ReplaceTaskRequest = models.ReplaceTaskRequest
+)
+
+// NewTaskDocument returns the writable fields of a task, to be changed and
+// sent back with UpdateTask
+func NewTaskDocument(task *Task) TaskDocument {
+	doc := TaskDocument{
+		Content:    task.Content,
+		Completed:  task.Completed,
+		ProjectID:  task.ProjectID,
+		ParentID:   task.ParentID,
+		Tags:       append([]string(nil), task.Tags...),
+		Priority:   task.Priority,
+		Recurrence: task.Recurrence,
+	}
+	if task.DueAt != nil {
+		dueAt := task.DueAt.UTC().Format(time.RFC3339Nano)
+		doc.DueAt = &dueAt
+	}
+	return doc
+}
+
+// ListTasksOptions filters, sorts and pages a list of tasks. Zero values
+// leave their query parameter out.
+type ListTasksOptions struct {
+	// ProjectID selects the tasks of a project, or of the inbox with 0
+	ProjectID *uint
+	// ParentID selects the subtasks of a task, or top-level tasks with 0
+	ParentID *uint
+	// SeriesID selects the occurrences of a recurring series
+	SeriesID *uint
+	// Tags selects tagged tasks, matching all tags or, with a TagMode of
+	// "any", any of them
+	Tags    []string
+	TagMode string
+	// Completed selects completed or open tasks
+	Completed       *bool
+	CreatedAfter    *time.Time
+	UpdatedBefore   *time.Time
+	ContentContains string
+	// Sort is a comma-separated list of sort fields, each prefixed with "-"
+	// for descending order, such as "-priority,due_at"
+	Sort string
+	// PageSize is the number of tasks per page, up to the server's maximum
+	PageSize int
+	// IncludeTotal counts the tasks matching the filter on every page
+	IncludeTotal bool
+	// Cursor starts the list at a page other than the first
+	Cursor string
+}
+
+// query encodes the options as query parameters
+func (o *ListTasksOptions) query() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	if o.ProjectID != nil {
+		query.Set("project_id", formatID(*o.ProjectID))
+	}
+	if o.ParentID != nil {
+		query.Set("parent_id", formatID(*o.ParentID))
+	}
+	if o.SeriesID != nil {
+		query.Set("series_id", formatID(*o.SeriesID))
+	}
+	for _, tag := range o.Tags {
+		query.Add("tag", tag)
+	}
+	if o.TagMode != "" {
+		query.Set("tag_mode", o.TagMode)
+	}
+	if o.Completed != nil {
+		query.Set("completed", strconv.FormatBool(*o.Completed))
+	}
+	if o.CreatedAfter != nil {
+		query.Set("created_after", o.CreatedAfter.Format(time.RFC3339))
+	}
+	if o.UpdatedBefore != nil {
+		query.Set("updated_before", o.UpdatedBefore.Format(time.RFC3339))
+	}
+	if o.ContentContains != "" {
+		query.Set("content_contains", o.ContentContains)
+	}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	if o.PageSize > 0 {
+		query.Set("limit", strconv.Itoa(o.PageSize))
+	}
+	if o.IncludeTotal {
+		query.Set("include_total", "true")
+	}
+	if o.Cursor != "" {
+		query.Set("cursor", o.Cursor)
+	}
+	return query
+}
+
+// WriteOption sets a condition or a mode of a write to a task
+type WriteOption func(*writeOptions)
+
+// writeOptions collects the WriteOptions of a write
+type writeOptions struct {
+	version uint
+	cascade bool
+}
+
+// IfVersion applies a write only while the task is at the given version.
+// Otherwise the write fails with a *errors.VersionConflictError whose
+// Current is the task's current *Task.
+func IfVersion(version uint) WriteOption {
+	return func(o *writeOptions) {
+		o.version = version
+	}
+}
+
+// Cascade deletes the subtasks of a task along with it; without it,
+// DeleteTask refuses to delete a task that has subtasks. It has no effect
+// on other writes.
+func Cascade() WriteOption {
+	return func(o *writeOptions) {
+		o.cascade = true
+	}
+}
+
+// apply sets the If-Match header and query parameters of a write
+func (o *writeOptions) apply(req *request) {
+	if o.version != 0 {
+		req.header.Set("If-Match", `"`+strconv.FormatUint(uint64(o.version), 10)+`"`)
+	}
+	if o.cascade {
+		req.query = url.Values{"subtasks": {"cascade"}}
+	}
+}
+
+// CreateTask creates a task
+func (c *Client) CreateTask(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+	r, err := newRequest(http.MethodPost, "/tasks", req)
+	if err != nil {
+		return nil, err
+	}
+	var task Task
+	if _, err := c.do(ctx, r, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetTask returns the task with the given ID
+func (c *Client) GetTask(ctx context.Context, id uint) (*Task, error) {
+	r, err := newRequest(http.MethodGet, taskPath(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var task Task
+	if _, err := c.do(ctx, r, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTask replaces every writable field of a task and returns the
+// updated task
+func (c *Client) UpdateTask(ctx context.Context, id uint, req *ReplaceTaskRequest, opts ...WriteOption) (*Task, error) {
+	r, err := newRequest(http.MethodPut, taskPath(id), req)
+	if err != nil {
+		return nil, err
+	}
+	applyWriteOptions(r, opts)
+	var task Task
+	if _, err := c.do(ctx, r, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask moves a task to the trash
+func (c *Client) DeleteTask(ctx context.Context, id uint, opts ...WriteOption) error {
+	r, err := newRequest(http.MethodDelete, taskPath(id), nil)
+	if err != nil {
+		return err
+	}
+	applyWriteOptions(r, opts)
+	_, err = c.do(ctx, r, nil)
+	return err
+}
+
+// ListTasks returns an iterator over the pages of the tasks matching opts.
+// Pages are fetched with ctx as the iterator advances.
+//
+//	pages := c.ListTasks(ctx, &client.ListTasksOptions{Completed: &open})
+//	for pages.Next() {
+//		for _, task := range pages.Page().Tasks {
+//			...
+//		}
+//	}
+//	if err := pages.Err(); err != nil {
+//		...
+//	}
+func (c *Client) ListTasks(ctx context.Context, opts *ListTasksOptions) *TaskPages {
+	return &TaskPages{client: c, ctx: ctx, query: opts.query()}
+}
+
+// TaskPages iterates over the pages of a list of tasks
+type TaskPages struct {
+	client *Client
+	ctx    context.Context
+	query  url.Values
+	page   *TaskPage
+	done   bool
+	err    error
+}
+
+// Next fetches the next page, reporting whether there was one. It returns
+// false after the last page and when a page fails, which Err then reports.
+func (p *TaskPages) Next() bool {
+	if p.done || p.err != nil {
+		return false
+	}
+	var page TaskPage
+	r := &request{method: http.MethodGet, path: "/tasks", query: p.query, header: http.Header{}}
+	if _, err := p.client.do(p.ctx, r, &page); err != nil {
+		p.err = err
+		p.page = nil
+		return false
+	}
+	p.page = &page
+	if page.NextCursor == "" {
+		p.done = true
+	} else {
+		p.query.Set("cursor", page.NextCursor)
+	}
+	return true
+}
+
+// Page returns the page fetched by the last call to Next
+func (p *TaskPages) Page() *TaskPage {
+	return p.page
+}
+
+// Err returns the error that stopped the iteration, if any
+func (p *TaskPages) Err() error {
+	return p.err
+}
+
+// All fetches the remaining pages and returns their tasks
+func (p *TaskPages) All() ([]Task, error) {
+	var tasks []Task
+	for p.Next() {
+		tasks = append(tasks, p.page.Tasks...)
+	}
+	return tasks, p.err
+}
+
+// applyWriteOptions applies the options of a write to its request
+func applyWriteOptions(req *request, opts []WriteOption) {
+	var o writeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.apply(req)
+}
+
+// taskPath returns the path of a task
+func taskPath(id uint) string {
+	return "/tasks/" + formatID(id)
+}
+
+// formatID formats an ID as a path segment or query parameter
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -9,9 +9,21 @@ import (
 
 // Error codes
 const (
-	CodeTaskNotFound    = "TASK_NOT_FOUND"
-	CodeValidationError = "VALIDATION_ERROR"
-	CodeInternalError   = "INTERNAL_ERROR"
+	CodeTaskNotFound         = "TASK_NOT_FOUND"
+	CodeVersionNotFound      = "VERSION_NOT_FOUND"
+	CodeTokenNotFound        = "TOKEN_NOT_FOUND"
+	CodeWebhookNotFound      = "WEBHOOK_NOT_FOUND"
+	CodeDeliveryNotFound     = "DELIVERY_NOT_FOUND"
+	CodeProjectNotFound      = "PROJECT_NOT_FOUND"
+	CodeTagNotFound          = "TAG_NOT_FOUND"
+	CodeValidationError      = "VALIDATION_ERROR"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeConflict             = "CONFLICT"
+	CodeVersionConflict      = "VERSION_CONFLICT"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeNotApplied           = "NOT_APPLIED"
+	CodeInternalError        = "INTERNAL_ERROR"
 )
 
 // TaskNotFoundError represents a task not found error
@@ -23,18 +35,130 @@ func (e *TaskNotFoundError) Error() string {
 	return fmt.Sprintf("Task with id %d not found", e.ID)
 }
 
-// ValidationError represents a validation error
+// VersionNotFoundError represents a version of a task that is not in the
+// task's history
+type VersionNotFoundError struct {
+	ID      uint
+	Version uint
+}
+
+func (e *VersionNotFoundError) Error() string {
+	return fmt.Sprintf("Version %d of task with id %d not found in its history", e.Version, e.ID)
+}
+
+// TokenNotFoundError represents a personal access token not found error
+type TokenNotFoundError struct {
+	ID uint
+}
+
+func (e *TokenNotFoundError) Error() string {
+	return fmt.Sprintf("Token with id %d not found", e.ID)
+}
+
+// WebhookNotFoundError represents a webhook not found error
+type WebhookNotFoundError struct {
+	ID uint
+}
+
+func (e *WebhookNotFoundError) Error() string {
+	return fmt.Sprintf("Webhook with id %d not found", e.ID)
+}
+
+// DeliveryNotFoundError represents a webhook delivery not found error
+type DeliveryNotFoundError struct {
+	ID uint
+}
+
+func (e *DeliveryNotFoundError) Error() string {
+	return fmt.Sprintf("Delivery with id %d not found", e.ID)
+}
+
+type ProjectNotFoundError struct {
+	ID uint
+}
+
+func (e *ProjectNotFoundError) Error() string {
+	return fmt.Sprintf("Project with id %d not found", e.ID)
+}
+
+// TagNotFoundError represents a tag not found error
+type TagNotFoundError struct {
+	ID uint
+}
+
+func (e *TagNotFoundError) Error() string {
+	return fmt.Sprintf("Tag with id %d not found", e.ID)
+}
+
+// ValidationError represents a validation error. Violations lists the
+// invalid fields of a request, when the error is about its fields.
 type ValidationError struct {
-	Message string
+	Message    string
+	Violations []FieldViolation
+}
+
+// FieldViolation describes why a field of a request is invalid
+type FieldViolation struct {
+	Field       string
+	Description string
 }
 
 func (e *ValidationError) Error() string {
 	return e.Message
 }
 
-// ErrorResponse represents the error response structure
+// UnauthorizedError represents missing or invalid credentials
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError represents valid credentials that lack the required permission
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+// ConflictError represents a request that conflicts with existing state
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// VersionConflictError represents a conditional write to a task that has
+// changed since the client read it. Current holds the task's current
+// representation, which is returned along with the error.
+type VersionConflictError struct {
+	ID      uint
+	Current interface{}
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("Task with id %d has been modified since it was read", e.ID)
+}
+
+// NotAppliedError represents an operation of an atomic batch that was valid
+// but not applied because another operation of the batch failed
+type NotAppliedError struct{}
+
+func (e *NotAppliedError) Error() string {
+	return "Not applied because another operation of the atomic batch failed"
+}
+
+// ErrorResponse represents the error response structure. Current is only
+// set on version conflicts.
 type ErrorResponse struct {
-	Error ErrorDetail `json:"error"`
+	Error   ErrorDetail `json:"error"`
+	Current interface{} `json:"current,omitempty"`
 }
 
 // ErrorDetail contains error details
@@ -58,14 +182,46 @@ func RespondWithError(c *gin.Context, statusCode int, code, message string) {
 	c.JSON(statusCode, NewErrorResponse(code, message))
 }
 
-// HandleError handles different error types and responds appropriately
-func HandleError(c *gin.Context, err error) {
+// Describe returns the HTTP status and error details an error is reported with
+func Describe(err error) (int, ErrorDetail) {
 	switch e := err.(type) {
 	case *TaskNotFoundError:
-		RespondWithError(c, http.StatusNotFound, CodeTaskNotFound, e.Error())
+		return http.StatusNotFound, ErrorDetail{Code: CodeTaskNotFound, Message: e.Error()}
+	case *VersionNotFoundError:
+		return http.StatusNotFound, ErrorDetail{Code: CodeVersionNotFound, Message: e.Error()}
+	case *TokenNotFoundError:
+		return http.StatusNotFound, ErrorDetail{Code: CodeTokenNotFound, Message: e.Error()}
+	case *WebhookNotFoundError:
+		return http.StatusNotFound, ErrorDetail{Code: CodeWebhookNotFound, Message: e.Error()}
+	case *DeliveryNotFoundError:
+		return http.StatusNotFound, ErrorDetail{Code: CodeDeliveryNotFound, Message: e.Error()}
+	case *ProjectNotFoundError:
+		return http.StatusNotFound, ErrorDetail{Code: CodeProjectNotFound, Message: e.Error()}
+	case *TagNotFoundError:
+		return http.StatusNotFound, ErrorDetail{Code: CodeTagNotFound, Message: e.Error()}
 	case *ValidationError:
-		RespondWithError(c, http.StatusBadRequest, CodeValidationError, e.Error())
+		return http.StatusBadRequest, ErrorDetail{Code: CodeValidationError, Message: e.Error()}
+	case *UnauthorizedError:
+		return http.StatusUnauthorized, ErrorDetail{Code: CodeUnauthorized, Message: e.Error()}
+	case *ForbiddenError:
+		return http.StatusForbidden, ErrorDetail{Code: CodeForbidden, Message: e.Error()}
+	case *ConflictError:
+		return http.StatusConflict, ErrorDetail{Code: CodeConflict, Message: e.Error()}
+	case *VersionConflictError:
+		return http.StatusPreconditionFailed, ErrorDetail{Code: CodeVersionConflict, Message: e.Error()}
+	case *NotAppliedError:
+		return http.StatusFailedDependency, ErrorDetail{Code: CodeNotApplied, Message: e.Error()}
 	default:
-		RespondWithError(c, http.StatusInternalServerError, CodeInternalError, "An internal error occurred")
+		return http.StatusInternalServerError, ErrorDetail{Code: CodeInternalError, Message: "An internal error occurred"}
+	}
+}
+
+// HandleError handles different error types and responds appropriately
+func HandleError(c *gin.Context, err error) {
+	status, detail := Describe(err)
+	response := ErrorResponse{Error: detail}
+	if conflict, ok := err.(*VersionConflictError); ok {
+		response.Current = conflict.Current
 	}
+	c.JSON(status, response)
 }
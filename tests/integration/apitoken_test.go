@@ -0,0 +1,88 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// createAPIToken mints a personal access token for the test user
+func createAPIToken(t *testing.T, req models.CreateAPITokenRequest) models.CreateAPITokenResponse {
+	t.Helper()
+	w := makeRequest(http.MethodPost, "/api/v1/tokens", req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create token: %s", w.Body.String())
+	}
+	var response models.CreateAPITokenResponse
+	parseResponse(t, w, &response)
+	return response
+}
+
+func TestAPITokens_CreateListRevoke(t *testing.T) {
+	created := createAPIToken(t, models.CreateAPITokenRequest{Name: "backup", Scopes: []string{"tasks:read"}})
+	assert.NotEmpty(t, created.Token)
+	assert.Equal(t, []string{"tasks:read"}, created.Scopes)
+
+	w := makeRequestAs(created.Token, http.MethodGet, "/api/v1/tasks", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeRequest(http.MethodGet, "/api/v1/tokens", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Token)
+
+	var list models.APITokenListResponse
+	parseResponse(t, w, &list)
+	var listed *models.APITokenResponse
+	for i := range list.Tokens {
+		if list.Tokens[i].ID == created.ID {
+			listed = &list.Tokens[i]
+		}
+	}
+	if assert.NotNil(t, listed) {
+		assert.NotNil(t, listed.LastUsedAt)
+	}
+
+	w = makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/tokens/%d", created.ID), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = makeRequestAs(created.Token, http.MethodGet, "/api/v1/tasks", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAPITokens_ScopeEnforced(t *testing.T) {
+	created := createAPIToken(t, models.CreateAPITokenRequest{Name: "reader", Scopes: []string{"tasks:read"}})
+
+	w := makeRequestAs(created.Token, http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Nope"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response models.ErrorResponse
+	parseResponse(t, w, &response)
+	assert.Equal(t, "FORBIDDEN", response.Error.Code)
+
+	w = makeRequestAs(created.Token, http.MethodGet, "/api/v1/tokens", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAPITokens_Expired(t *testing.T) {
+	expiresAt := time.Now().Add(time.Second)
+	created := createAPIToken(t, models.CreateAPITokenRequest{
+		Name: "short-lived", Scopes: []string{"tasks:read"}, ExpiresAt: &expiresAt,
+	})
+	time.Sleep(time.Until(expiresAt) + 10*time.Millisecond)
+
+	w := makeRequestAs(created.Token, http.MethodGet, "/api/v1/tasks", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAPITokens_InvalidScope(t *testing.T) {
+	w := makeRequest(http.MethodPost, "/api/v1/tokens",
+		models.CreateAPITokenRequest{Name: "bad", Scopes: []string{"tasks:admin"}})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,108 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// cleanupUser removes a user created by a test along with its tasks
+func cleanupUser(t *testing.T, email string) {
+	t.Helper()
+	if err := testDB.Exec("DELETE FROM users WHERE email = ?", email).Error; err != nil {
+		t.Fatalf("Failed to cleanup user: %v", err)
+	}
+}
+
+func TestAuth_RegisterLoginRefresh(t *testing.T) {
+	cleanupUser(t, "ada@example.com")
+
+	w := makeRequestAs("", http.MethodPost, "/api/v1/auth/register",
+		models.RegisterRequest{Email: "Ada@Example.com", Password: "password123"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var registered models.AuthResponse
+	parseResponse(t, w, &registered)
+	assert.Equal(t, "ada@example.com", registered.User.Email)
+	assert.Equal(t, "Bearer", registered.TokenType)
+
+	w = makeRequestAs("", http.MethodPost, "/api/v1/auth/login",
+		models.LoginRequest{Email: "ada@example.com", Password: "password123"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var loggedIn models.AuthResponse
+	parseResponse(t, w, &loggedIn)
+	assert.Equal(t, registered.User.ID, loggedIn.User.ID)
+
+	w = makeRequestAs("", http.MethodPost, "/api/v1/auth/refresh",
+		models.RefreshRequest{RefreshToken: loggedIn.RefreshToken})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var refreshed models.AuthResponse
+	parseResponse(t, w, &refreshed)
+	w = makeRequestAs(refreshed.AccessToken, http.MethodGet, "/api/v1/tasks", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuth_RegisterDuplicateEmail(t *testing.T) {
+	w := makeRequestAs("", http.MethodPost, "/api/v1/auth/register",
+		models.RegisterRequest{Email: "owner@example.com", Password: "password123"})
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response models.ErrorResponse
+	parseResponse(t, w, &response)
+	assert.Equal(t, "CONFLICT", response.Error.Code)
+}
+
+func TestAuth_LoginWrongPassword(t *testing.T) {
+	w := makeRequestAs("", http.MethodPost, "/api/v1/auth/login",
+		models.LoginRequest{Email: "owner@example.com", Password: "wrong-password"})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuth_TasksRequireToken(t *testing.T) {
+	w := makeRequestAs("", http.MethodGet, "/api/v1/tasks", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = makeRequestAs("not-a-token", http.MethodGet, "/api/v1/tasks", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	var response models.ErrorResponse
+	parseResponse(t, w, &response)
+	assert.Equal(t, "UNAUTHORIZED", response.Error.Code)
+}
+
+func TestAuth_OtherUsersTaskNotFound(t *testing.T) {
+	cleanupTasks(t)
+	cleanupUser(t, "intruder@example.com")
+	intruder := registerUser("intruder@example.com")
+
+	createW := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Private task"})
+	var created models.TaskResponse
+	parseResponse(t, createW, &created)
+	path := fmt.Sprintf("/api/v1/tasks/%d", created.ID)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		w := makeRequestAs(intruder, method, path, models.UpdateTaskRequest{})
+		assert.Equal(t, http.StatusNotFound, w.Code, method)
+
+		var response models.ErrorResponse
+		parseResponse(t, w, &response)
+		assert.Equal(t, "TASK_NOT_FOUND", response.Error.Code)
+	}
+
+	w := makeRequestAs(intruder, http.MethodGet, "/api/v1/tasks", nil)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	assert.Equal(t, 0, list.Count)
+
+	w = makeRequest(http.MethodGet, path, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
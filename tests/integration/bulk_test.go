@@ -0,0 +1,159 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// createTasks creates one top-level task per content and returns them
+func createTasks(t *testing.T, contents ...string) []models.TaskResponse {
+	tasks := make([]models.TaskResponse, len(contents))
+	for i, content := range contents {
+		w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: content})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		parseResponse(t, w, &tasks[i])
+	}
+	return tasks
+}
+
+func TestBulkTasks_BestEffort(t *testing.T) {
+	cleanupTasks(t)
+	tasks := createTasks(t, "Milk", "Bread")
+
+	content := "Oat milk"
+	w := makeRequest(http.MethodPost, "/api/v1/tasks/bulk", models.BulkTaskRequest{Operations: []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "Eggs", Tags: []string{"groceries"}}},
+		{Op: models.BulkOpUpdate, ID: tasks[0].ID, Changes: &models.UpdateTaskRequest{Content: &content}},
+		{Op: models.BulkOpComplete, ID: 999999},
+		{Op: models.BulkOpDelete, ID: tasks[1].ID},
+	}})
+	require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+
+	var response models.BulkTaskResponse
+	parseResponse(t, w, &response)
+	assert.Equal(t, 3, response.Succeeded)
+	assert.Equal(t, 1, response.Failed)
+	require.Len(t, response.Results, 4)
+	assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+	assert.Equal(t, []string{"groceries"}, response.Results[0].Task.Tags)
+	assert.Equal(t, "Oat milk", response.Results[1].Task.Content)
+	assert.Equal(t, http.StatusNotFound, response.Results[2].Status)
+	assert.Equal(t, "TASK_NOT_FOUND", response.Results[2].Error.Code)
+	assert.Equal(t, http.StatusNoContent, response.Results[3].Status)
+
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?sort=content", nil)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	require.Equal(t, 2, list.Count)
+	assert.Equal(t, "Eggs", list.Tasks[0].Content)
+	assert.Equal(t, "Oat milk", list.Tasks[1].Content)
+}
+
+func TestBulkTasks_Atomic(t *testing.T) {
+	cleanupTasks(t)
+	tasks := createTasks(t, "Milk", "Bread")
+
+	stale := tasks[1].Version + 1
+	w := makeRequest(http.MethodPost, "/api/v1/tasks/bulk?atomic=true", models.BulkTaskRequest{Operations: []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "Eggs"}},
+		{Op: models.BulkOpComplete, ID: tasks[0].ID},
+		{Op: models.BulkOpDelete, ID: tasks[1].ID, Version: &stale},
+	}})
+	require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+
+	var response models.BulkTaskResponse
+	parseResponse(t, w, &response)
+	assert.True(t, response.Atomic)
+	assert.Equal(t, 0, response.Succeeded)
+	assert.Equal(t, "NOT_APPLIED", response.Results[0].Error.Code)
+	assert.Equal(t, http.StatusFailedDependency, response.Results[1].Status)
+	assert.Equal(t, http.StatusPreconditionFailed, response.Results[2].Status)
+
+	// Nothing was written
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?completed=false", nil)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	assert.Equal(t, 2, list.Count)
+
+	version := tasks[1].Version
+	w = makeRequest(http.MethodPost, "/api/v1/tasks/bulk?atomic=true", models.BulkTaskRequest{Operations: []models.BulkTaskOperation{
+		{Op: models.BulkOpCreate, Task: &models.CreateTaskRequest{Content: "Eggs"}},
+		{Op: models.BulkOpComplete, ID: tasks[0].ID},
+		{Op: models.BulkOpDelete, ID: tasks[1].ID, Version: &version},
+	}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	parseResponse(t, w, &response)
+	assert.Equal(t, 3, response.Succeeded)
+	assert.True(t, response.Results[1].Task.Completed)
+
+	w = makeRequest(http.MethodGet, "/api/v1/trash", nil)
+	parseResponse(t, w, &list)
+	require.Equal(t, 1, list.Count)
+	assert.Equal(t, tasks[1].ID, list.Tasks[0].ID)
+}
+
+func TestBulkTasks_DeleteRequiresScope(t *testing.T) {
+	cleanupTasks(t)
+	tasks := createTasks(t, "Milk")
+	created := createAPIToken(t, models.CreateAPITokenRequest{Name: "writer", Scopes: []string{"tasks:write"}})
+
+	w := makeRequestAs(created.Token, http.MethodPost, "/api/v1/tasks/bulk", models.BulkTaskRequest{Operations: []models.BulkTaskOperation{
+		{Op: models.BulkOpDelete, ID: tasks[0].ID},
+	}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = makeRequestAs(created.Token, http.MethodPost, "/api/v1/tasks/bulk", models.BulkTaskRequest{Operations: []models.BulkTaskOperation{
+		{Op: models.BulkOpComplete, ID: tasks[0].ID},
+	}})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestBulkActions_ByFilter(t *testing.T) {
+	cleanupTasks(t)
+	due := "2026-10-20"
+	for _, req := range []models.CreateTaskRequest{
+		{Content: "Milk", Tags: []string{"groceries"}},
+		{Content: "Bread", Tags: []string{"groceries"}},
+		{Content: "Water plants", Tags: []string{"home"}, DueAt: &due, Recurrence: "FREQ=WEEKLY"},
+		{Content: "Report"},
+	} {
+		w := makeRequest(http.MethodPost, "/api/v1/tasks", req)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	w := makeRequest(http.MethodPost, "/api/v1/tasks/bulk/complete", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = makeRequest(http.MethodPost, "/api/v1/tasks/bulk/complete?tag=groceries&tag=home&tag_mode=any", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result models.BulkActionResponse
+	parseResponse(t, w, &result)
+	assert.Equal(t, 3, result.Count)
+
+	// The recurring task moved on to its next occurrence
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?completed=false&sort=content", nil)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	require.Equal(t, 2, list.Count)
+	assert.Equal(t, "Report", list.Tasks[0].Content)
+	assert.Equal(t, "Water plants", list.Tasks[1].Content)
+	assert.Equal(t, 2, list.Tasks[1].Occurrence)
+
+	w = makeRequest(http.MethodPost, "/api/v1/tasks/bulk/delete?completed=true", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	parseResponse(t, w, &result)
+	assert.Equal(t, 3, result.Count)
+
+	w = makeRequest(http.MethodGet, "/api/v1/trash", nil)
+	parseResponse(t, w, &list)
+	assert.Equal(t, 3, list.Count)
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?completed=true", nil)
+	parseResponse(t, w, &list)
+	assert.Equal(t, 0, list.Count)
+}
@@ -0,0 +1,53 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+func TestETag_ConditionalUpdate(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Shared task"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.TaskResponse
+	parseResponse(t, w, &created)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+	path := fmt.Sprintf("/api/v1/tasks/%d", created.ID)
+
+	w = makeConditionalRequest(http.MethodGet, path, nil, map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// The first client updates the version both clients read
+	first := "First client"
+	w = makeConditionalRequest(http.MethodPut, path, models.TaskDocument{Content: first}, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	// The second client's update is based on a stale version
+	second := "Second client"
+	w = makeConditionalRequest(http.MethodPut, path, models.TaskDocument{Content: second}, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var conflict models.ErrorResponse
+	parseResponse(t, w, &conflict)
+	assert.Equal(t, "VERSION_CONFLICT", conflict.Error.Code)
+	require.NotNil(t, conflict.Current)
+	assert.Equal(t, first, conflict.Current.Content)
+
+	w = makeConditionalRequest(http.MethodGet, path, nil, map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeConditionalRequest(http.MethodDelete, path, nil, map[string]string{"If-Match": etag})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = makeConditionalRequest(http.MethodDelete, path, nil, map[string]string{"If-Match": `"2"`})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,109 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// nextEvent waits for the next event of a subscription
+func nextEvent(t *testing.T, sub *events.Subscription, timeout time.Duration) (events.Event, bool) {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events():
+		return event, ok
+	case <-time.After(timeout):
+		return events.Event{}, false
+	}
+}
+
+// testUserID returns the ID of the test user
+func testUserID(t *testing.T) uint {
+	t.Helper()
+	w := makeRequest(http.MethodGet, "/api/v1/me", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var user models.UserResponse
+	parseResponse(t, w, &user)
+	return user.ID
+}
+
+func TestEvents_PublishTaskChanges(t *testing.T) {
+	cleanupTasks(t)
+	sub, _, _ := testBus.Subscribe(testUserID(t), "")
+	defer sub.Close()
+
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Stream me"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+	w = makePatchRequest(path, map[string]interface{}{"completed": true})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = makeRequest(http.MethodDelete, path, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = makeRequest(http.MethodPost, path+"/restore", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = makeRequest(http.MethodDelete, path, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/trash/%d", task.ID), nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	// Other users' changes are not streamed
+	other := registerUser("events-other@example.com")
+	w = makeRequestAs(other, http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Not yours"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var types []string
+	for {
+		event, ok := nextEvent(t, sub, 100*time.Millisecond)
+		if !ok {
+			break
+		}
+		types = append(types, event.Type)
+		var data models.TaskResponse
+		require.NoError(t, json.Unmarshal(event.Data, &data))
+		assert.Equal(t, task.ID, data.ID)
+	}
+	assert.Equal(t, []string{
+		events.TaskCreated, events.TaskUpdated, events.TaskDeleted, events.TaskRestored, events.TaskDeleted, events.TaskPurged,
+	}, types)
+}
+
+func TestEvents_PostgresBridge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	replicaA, replicaB := events.NewBus(10), events.NewBus(10)
+	for _, bus := range []*events.Bus{replicaA, replicaB} {
+		go events.NewPostgresBridge(testDB, getTestDSN(), "task_events_test", bus).Run(ctx)
+	}
+	onA, _, _ := replicaA.Subscribe(1, "")
+	defer onA.Close()
+	onB, _, _ := replicaB.Subscribe(1, "")
+	defer onB.Close()
+
+	// Notifications sent before the bridges listen are lost, so publish
+	// until one comes through
+	var received events.Event
+	deadline := time.Now().Add(5 * time.Second)
+	for ok := false; !ok; {
+		require.True(t, time.Now().Before(deadline), "no event was bridged")
+		replicaA.Publish(events.Event{OwnerID: 1, Type: events.TaskCreated, Data: json.RawMessage(`{"id":1}`)})
+		received, ok = nextEvent(t, onB, 200*time.Millisecond)
+	}
+	assert.Equal(t, events.TaskCreated, received.Type)
+	assert.JSONEq(t, `{"id":1}`, string(received.Data))
+
+	event, ok := nextEvent(t, onA, time.Second)
+	require.True(t, ok, "the publishing replica receives its own event")
+	assert.Equal(t, events.TaskCreated, event.Type)
+}
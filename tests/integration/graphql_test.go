@@ -0,0 +1,119 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/graph"
+)
+
+// graphQL runs a GraphQL request as the test user, decodes its data into
+// data and returns the codes of its errors
+func graphQL(t *testing.T, query string, variables map[string]interface{}, data interface{}) []string {
+	t.Helper()
+	w := makeRequest(http.MethodPost, "/api/v1/graphql", graph.Request{Query: query, Variables: variables})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	parseResponse(t, w, &response)
+	if data != nil {
+		require.NoError(t, json.Unmarshal(response.Data, data))
+	}
+	var codes []string
+	for _, err := range response.Errors {
+		codes = append(codes, err.Extensions.Code)
+	}
+	return codes
+}
+
+func TestGraphQL_NestedTasks(t *testing.T) {
+	cleanupTasks(t)
+	parents := createTasks(t, "Move house", "Plan trip")
+
+	// Subtasks are created through mutations
+	for _, parent := range parents {
+		for _, content := range []string{"Book", "Pack"} {
+			var created struct {
+				CreateTask struct {
+					ID string `json:"id"`
+				} `json:"createTask"`
+			}
+			codes := graphQL(t, `mutation ($input: CreateTaskInput!) { createTask(input: $input) { id } }`,
+				map[string]interface{}{"input": map[string]interface{}{
+					"content": parent.Content + ": " + content, "parentId": strconv.FormatUint(uint64(parent.ID), 10), "tags": []string{"gql"},
+				}}, &created)
+			require.Empty(t, codes)
+		}
+	}
+	var completed struct {
+		CompleteTask struct {
+			Completed bool `json:"completed"`
+			Version   int  `json:"version"`
+		} `json:"completeTask"`
+	}
+	require.Empty(t, graphQL(t, `mutation ($id: ID!) { completeTask(id: $id, version: 1) { completed version } }`,
+		map[string]interface{}{"id": strconv.FormatUint(uint64(parents[0].ID), 10)}, &completed))
+	assert.True(t, completed.CompleteTask.Completed)
+
+	var data struct {
+		Tasks struct {
+			Edges []struct {
+				Node struct {
+					Content string `json:"content"`
+					History []struct {
+						Type string `json:"type"`
+					} `json:"history"`
+					Subtasks []struct {
+						Content string `json:"content"`
+						Tags    []struct {
+							Name string `json:"name"`
+						} `json:"tags"`
+						Parent struct {
+							Content string `json:"content"`
+						} `json:"parent"`
+					} `json:"subtasks"`
+				} `json:"node"`
+			} `json:"edges"`
+			TotalCount int `json:"totalCount"`
+		} `json:"tasks"`
+	}
+	require.Empty(t, graphQL(t, `{
+		tasks(filter: {parentId: "0"}, orderBy: [{field: CONTENT}]) {
+			totalCount
+			edges { node {
+				content
+				history { type }
+				subtasks { content tags { name } parent { content } }
+			} }
+		}
+	}`, nil, &data))
+
+	require.Equal(t, 2, data.Tasks.TotalCount)
+	move := data.Tasks.Edges[0].Node
+	assert.Equal(t, "Move house", move.Content)
+	require.Len(t, move.History, 2)
+	assert.Equal(t, "completed", move.History[0].Type)
+	assert.Equal(t, "created", move.History[1].Type)
+	require.Len(t, move.Subtasks, 2)
+	assert.Equal(t, "Move house: Book", move.Subtasks[0].Content)
+	assert.Equal(t, "gql", move.Subtasks[0].Tags[0].Name)
+	assert.Equal(t, "Move house", move.Subtasks[0].Parent.Content)
+	assert.Len(t, data.Tasks.Edges[1].Node.Subtasks, 2)
+
+	// Errors carry the codes of the REST API
+	codes := graphQL(t, `mutation ($id: ID!) { deleteTask(id: $id, cascade: true, version: 1) }`,
+		map[string]interface{}{"id": strconv.FormatUint(uint64(parents[0].ID), 10)}, nil)
+	assert.Equal(t, []string{"VERSION_CONFLICT"}, codes)
+}
@@ -0,0 +1,84 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+	todov1 "github.com/todo-api-go-sda/pkg/api/todo/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// dialGRPC serves the gRPC API in memory and returns a client of it
+func dialGRPC(t *testing.T) todov1.TaskServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	go testGRPC.Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return todov1.NewTaskServiceClient(conn)
+}
+
+func TestGRPC_TaskService(t *testing.T) {
+	cleanupTasks(t)
+	client := dialGRPC(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+testToken)
+
+	stream, err := client.WatchTasks(ctx, &todov1.WatchTasksRequest{})
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	created, err := client.CreateTask(ctx, &todov1.CreateTaskRequest{Task: &todov1.Task{Content: "From gRPC", Tags: []string{"rpc"}}})
+	require.NoError(t, err)
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, todov1.TaskEvent_TYPE_CREATED, event.Type)
+	assert.Equal(t, created.Id, event.TaskId)
+
+	// Both APIs see the same tasks
+	w := makeRequest(http.MethodGet, "/api/v1/tasks?tag=rpc", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	require.Equal(t, 1, list.Count)
+	assert.Equal(t, "From gRPC", list.Tasks[0].Content)
+
+	updated, err := client.UpdateTask(ctx, &todov1.UpdateTaskRequest{
+		Task:       &todov1.Task{Id: created.Id, Completed: true},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"completed", "tags"}},
+		Version:    &created.Version,
+	})
+	require.NoError(t, err)
+	assert.True(t, updated.Completed)
+	assert.Empty(t, updated.Tags)
+
+	listed, err := client.ListTasks(ctx, &todov1.ListTasksRequest{Completed: &updated.Completed})
+	require.NoError(t, err)
+	require.Len(t, listed.Tasks, 1)
+	assert.Equal(t, created.Id, listed.Tasks[0].Id)
+
+	_, err = client.DeleteTask(ctx, &todov1.DeleteTaskRequest{Id: created.Id})
+	require.NoError(t, err)
+	_, err = client.GetTask(ctx, &todov1.GetTaskRequest{Id: created.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -9,12 +9,22 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/todo-api-go-sda/internal/auth"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/database/migrations"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/graph"
+	"github.com/todo-api-go-sda/internal/grpcapi"
 	"github.com/todo-api-go-sda/internal/handlers"
+	"github.com/todo-api-go-sda/internal/middleware"
 	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/realtime"
 	"github.com/todo-api-go-sda/internal/repository"
 	"github.com/todo-api-go-sda/internal/services"
+	"google.golang.org/grpc"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -22,6 +32,9 @@ import (
 var (
 	testDB     *gorm.DB
 	testRouter *gin.Engine
+	testToken  string
+	testBus    *events.Bus
+	testGRPC   *grpc.Server
 )
 
 // TestMain sets up and tears down the test environment
@@ -44,19 +57,31 @@ func setup() {
 
 	// Connect to test database
 	dsn := getTestDSN()
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		panic("Failed to connect to test database: " + err.Error())
 	}
 	testDB = db
 
 	// Migrate schema
-	if err := testDB.AutoMigrate(&models.Task{}); err != nil {
+	migrator, err := migrations.New(testDB)
+	if err != nil {
+		panic("Failed to load migrations: " + err.Error())
+	}
+	if err := migrator.Up(); err != nil {
 		panic("Failed to migrate test database: " + err.Error())
 	}
 
+	// Start from an empty user table; tasks are removed along with their owners
+	if err := testDB.Exec("DELETE FROM users").Error; err != nil {
+		panic("Failed to cleanup users: " + err.Error())
+	}
+
 	// Setup router
 	testRouter = setupTestRouter(testDB)
+
+	// Sign up the user that owns the tasks created by the tests
+	testToken = registerUser("owner@example.com")
 }
 
 func teardown() {
@@ -88,36 +113,232 @@ func setupTestRouter(db *gorm.DB) *gin.Engine {
 	router := gin.New()
 
 	// Initialize dependencies
+	tokens, err := auth.NewTokenManager(&config.AuthConfig{
+		JWTAlgorithm:    "HS256",
+		JWTSecret:       "integration-test-secret",
+		JWTIssuer:       "todo-api",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+	if err != nil {
+		panic("Failed to create token manager: " + err.Error())
+	}
+	userRepo := repository.NewUserRepository(db)
+	authService := services.NewAuthService(userRepo, tokens)
+	authHandler := handlers.NewAuthHandler(authService)
+	apiTokenService := services.NewAPITokenService(repository.NewAPITokenRepository(db))
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenService)
+	requireAuth := middleware.RequireAuth(tokens, apiTokenService)
+	testBus = events.NewBus(100)
 	taskRepo := repository.NewTaskRepository(db)
-	taskService := services.NewTaskService(taskRepo)
-	taskHandler := handlers.NewTaskHandler(taskService)
+	projectRepo := repository.NewProjectRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	taskService := services.NewTaskService(taskRepo, projectRepo, tagRepo, &config.TaskConfig{MaxSubtaskDepth: 3}, testBus)
+	projectService := services.NewProjectService(projectRepo)
+	projectHandler := handlers.NewProjectHandler(projectService)
+	tagHandler := handlers.NewTagHandler(services.NewTagService(tagRepo))
+	taskHandler := handlers.NewTaskHandler(taskService, &config.ServerConfig{
+		DefaultPageSize: 50,
+		MaxPageSize:     100,
+		CursorSecret:    "integration-test-secret",
+	})
+	eventsConfig := &config.EventsConfig{HeartbeatInterval: time.Second}
+	eventHandler := handlers.NewEventHandler(testBus, eventsConfig)
+	realtimeHandler := handlers.NewRealtimeHandler(realtime.NewHub(taskService, projectService, testBus, eventsConfig))
+	testGRPC = grpcapi.NewServer(taskService, testBus, grpcapi.NewAuthenticator(tokens, apiTokenService, authService), &config.ServerConfig{
+		DefaultPageSize: 50,
+		MaxPageSize:     100,
+		CursorSecret:    "integration-test-secret",
+	}, eventsConfig)
+	schema, err := graph.NewSchema(taskService, projectService, &config.ServerConfig{
+		DefaultPageSize: 50,
+		MaxPageSize:     100,
+		CursorSecret:    "integration-test-secret",
+	}, &config.GraphQLConfig{MaxDepth: 10, MaxComplexity: 20000})
+	if err != nil {
+		panic("Failed to build the GraphQL schema: " + err.Error())
+	}
+	graphqlHandler := handlers.NewGraphQLHandler(schema)
+	webhookHandler := handlers.NewWebhookHandler(services.NewWebhookService(repository.NewWebhookRepository(db)), &config.ServerConfig{
+		DefaultPageSize: 50,
+		MaxPageSize:     100,
+		CursorSecret:    "integration-test-secret",
+	})
+	idempotent := middleware.Idempotency(services.NewIdempotencyService(repository.NewIdempotencyRepository(db), &config.IdempotencyConfig{
+		KeyTTL:      time.Hour,
+		LockTimeout: time.Minute,
+	}))
 
 	// Setup routes
+	router.Use(middleware.AssignRequestID())
 	v1 := router.Group("/api/v1")
 	{
-		tasks := v1.Group("/tasks")
+		authRoutes := v1.Group("/auth")
+		{
+			authRoutes.POST("/register", authHandler.Register)
+			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/refresh", authHandler.Refresh)
+		}
+
+		apiTokens := v1.Group("/tokens", requireAuth, middleware.RequireSession())
+		{
+			apiTokens.POST("", apiTokenHandler.CreateToken)
+			apiTokens.GET("", apiTokenHandler.ListTokens)
+			apiTokens.DELETE("/:id", apiTokenHandler.RevokeToken)
+		}
+
+		// Account settings can be read with any credential but only changed
+		// from a login session
+		me := v1.Group("/me", requireAuth)
+		{
+			me.GET("", authHandler.GetProfile)
+			me.PUT("", middleware.RequireSession(), authHandler.UpdateProfile)
+		}
+
+		read := middleware.RequireScope(auth.ScopeTasksRead)
+		write := middleware.RequireScope(auth.ScopeTasksWrite)
+		del := middleware.RequireScope(auth.ScopeTasksDelete)
+		v1.GET("/ws", requireAuth, middleware.ResolveTimeZone(authService), read, realtimeHandler.Connect)
+		v1.POST("/graphql", requireAuth, middleware.ResolveTimeZone(authService), read, idempotent, graphqlHandler.Query)
+
+		tasks := v1.Group("/tasks", requireAuth, middleware.ResolveTimeZone(authService), idempotent)
+		{
+			tasks.POST("", write, taskHandler.CreateTask)
+			tasks.POST("/bulk", write, taskHandler.BulkTasks)
+			tasks.POST("/bulk/complete", write, taskHandler.CompleteMatchingTasks)
+			tasks.POST("/bulk/delete", del, taskHandler.DeleteMatchingTasks)
+			tasks.GET("", read, taskHandler.ListTasks)
+			tasks.GET("/search", read, taskHandler.SearchTasks)
+			tasks.GET("/events", read, eventHandler.Stream)
+			tasks.GET("/overdue", read, taskHandler.OverdueTasks)
+			tasks.GET("/today", read, taskHandler.TodayTasks)
+			tasks.GET("/upcoming", read, taskHandler.UpcomingTasks)
+			tasks.GET("/:id", read, taskHandler.GetTask)
+			tasks.GET("/:id/subtasks", read, taskHandler.ListSubtasks)
+			tasks.GET("/:id/occurrences", read, taskHandler.PreviewOccurrences)
+			tasks.PUT("/:id", write, taskHandler.UpdateTask)
+			tasks.PATCH("/:id", write, taskHandler.PatchTask)
+			tasks.DELETE("/:id/recurrence", write, taskHandler.EndSeries)
+			tasks.DELETE("/:id", del, taskHandler.DeleteTask)
+			tasks.POST("/:id/restore", write, taskHandler.RestoreTask)
+			tasks.GET("/:id/history", read, taskHandler.TaskHistory)
+			tasks.POST("/:id/revert", write, taskHandler.RevertTask)
+		}
+
+		// The audit log lists the history of all of the user's tasks
+		audit := v1.Group("/audit", requireAuth)
+		{
+			audit.GET("", read, taskHandler.ListAudit)
+		}
+
+		trash := v1.Group("/trash", requireAuth, idempotent)
+		{
+			trash.GET("", read, taskHandler.ListTrash)
+			trash.DELETE("/:id", del, taskHandler.DeleteTrashedTask)
+		}
+
+		projects := v1.Group("/projects", requireAuth, idempotent)
+		{
+			projects.POST("", write, projectHandler.CreateProject)
+			projects.GET("", read, projectHandler.ListProjects)
+			projects.GET("/:id", read, projectHandler.GetProject)
+			projects.GET("/:id/tasks", read, taskHandler.ListProjectTasks)
+			projects.PUT("/:id", write, projectHandler.UpdateProject)
+			projects.DELETE("/:id", del, projectHandler.DeleteProject)
+		}
+
+		webhooks := v1.Group("/webhooks", requireAuth, idempotent)
+		{
+			webhooks.POST("", write, webhookHandler.CreateWebhook)
+			webhooks.GET("", read, webhookHandler.ListWebhooks)
+			webhooks.GET("/dead-letters", read, webhookHandler.ListDeadLetters)
+			webhooks.GET("/:id", read, webhookHandler.GetWebhook)
+			webhooks.PATCH("/:id", write, webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", del, webhookHandler.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", read, webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", write, webhookHandler.Redeliver)
+		}
+
+		tags := v1.Group("/tags", requireAuth, idempotent)
 		{
-			tasks.POST("", taskHandler.CreateTask)
-			tasks.GET("", taskHandler.ListTasks)
-			tasks.GET("/:id", taskHandler.GetTask)
-			tasks.PUT("/:id", taskHandler.UpdateTask)
-			tasks.DELETE("/:id", taskHandler.DeleteTask)
+			tags.GET("", read, tagHandler.ListTags)
+			tags.PUT("/:id", write, tagHandler.RenameTag)
+			tags.POST("/:id/merge", write, tagHandler.MergeTag)
+			tags.DELETE("/:id", del, tagHandler.DeleteTag)
 		}
 	}
 
 	return router
 }
 
-// cleanupTasks removes all tasks from the test database
+// cleanupTasks removes all tasks, projects, tags and webhooks from the test
+// database, along with the responses stored for Idempotency-Keys
 func cleanupTasks(t *testing.T) {
 	t.Helper()
+	if err := testDB.Exec("DELETE FROM outbox_messages").Error; err != nil {
+		t.Fatalf("Failed to cleanup outbox messages: %v", err)
+	}
+	if err := testDB.Exec("DELETE FROM webhooks").Error; err != nil {
+		t.Fatalf("Failed to cleanup webhooks: %v", err)
+	}
+	if err := testDB.Exec("DELETE FROM idempotency_keys").Error; err != nil {
+		t.Fatalf("Failed to cleanup idempotency keys: %v", err)
+	}
+	if err := testDB.Exec("DELETE FROM task_events").Error; err != nil {
+		t.Fatalf("Failed to cleanup task events: %v", err)
+	}
 	if err := testDB.Exec("DELETE FROM tasks").Error; err != nil {
 		t.Fatalf("Failed to cleanup tasks: %v", err)
 	}
+	if err := testDB.Exec("DELETE FROM projects").Error; err != nil {
+		t.Fatalf("Failed to cleanup projects: %v", err)
+	}
+	if err := testDB.Exec("DELETE FROM tags").Error; err != nil {
+		t.Fatalf("Failed to cleanup tags: %v", err)
+	}
 }
 
-// makeRequest is a helper to make HTTP requests to the test router
+// registerUser signs up a user and returns its access token
+func registerUser(email string) string {
+	w := makeRequestAs("", http.MethodPost, "/api/v1/auth/register",
+		models.RegisterRequest{Email: email, Password: "password123"})
+	if w.Code != http.StatusCreated {
+		panic("Failed to register test user: " + w.Body.String())
+	}
+	var response models.AuthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		panic("Failed to parse register response: " + err.Error())
+	}
+	return response.AccessToken
+}
+
+// makeRequest is a helper to make HTTP requests to the test router as the
+// test user
 func makeRequest(method, path string, body interface{}) *httptest.ResponseRecorder {
+	return makeRequestAs(testToken, method, path, body)
+}
+
+// makeRequestAs makes an HTTP request with the given bearer token, or
+// anonymously when the token is empty
+func makeRequestAs(token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	return makeRequestWithHeaders(token, method, path, body, nil)
+}
+
+// makeConditionalRequest makes an HTTP request as the test user with extra
+// headers, such as If-Match
+func makeConditionalRequest(method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	return makeRequestWithHeaders(testToken, method, path, body, headers)
+}
+
+// makePatchRequest makes a PATCH request as the test user with a JSON Merge
+// Patch body
+func makePatchRequest(path string, body interface{}) *httptest.ResponseRecorder {
+	return makeConditionalRequest(http.MethodPatch, path, body, map[string]string{"Content-Type": "application/merge-patch+json"})
+}
+
+// makeRequestWithHeaders makes an HTTP request with the given bearer token
+// and extra headers
+func makeRequestWithHeaders(token, method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
 	var reqBody *bytes.Buffer
 	if body != nil {
 		jsonBody, _ := json.Marshal(body)
@@ -128,6 +349,12 @@ func makeRequest(method, path string, body interface{}) *httptest.ResponseRecord
 
 	req, _ := http.NewRequest(method, path, reqBody)
 	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 
 	w := httptest.NewRecorder()
 	testRouter.ServeHTTP(w, req)
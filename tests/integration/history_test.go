@@ -0,0 +1,99 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+func TestHistory_RecordsWrites(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequestWithHeaders(testToken, http.MethodPost, "/api/v1/tasks",
+		models.CreateTaskRequest{Content: "Draft"}, map[string]string{"X-Request-ID": "create-1"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "create-1", w.Header().Get("X-Request-ID"))
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+
+	w = makePatchRequest(path, map[string]interface{}{"content": "Final"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = makePatchRequest(path, map[string]interface{}{"completed": true})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = makeRequest(http.MethodDelete, path, nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	// The history of a task in the trash can still be read
+	w = makeRequest(http.MethodGet, path+"/history", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var history models.TaskEventListResponse
+	parseResponse(t, w, &history)
+	require.Equal(t, 4, history.Count)
+	types := make([]string, history.Count)
+	for i, event := range history.Events {
+		types[i] = event.Type
+	}
+	assert.Equal(t, []string{"deleted", "completed", "updated", "created"}, types)
+	assert.Equal(t, "create-1", history.Events[3].RequestID)
+	assert.JSONEq(t, `{"content": {"from": "Draft", "to": "Final"}}`, string(history.Events[2].Changes))
+
+	w = makeRequest(http.MethodGet, "/api/v1/audit?request_id=create-1", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var audit models.TaskEventListResponse
+	parseResponse(t, w, &audit)
+	require.Equal(t, 1, audit.Count)
+	assert.Equal(t, task.ID, audit.Events[0].TaskID)
+
+	// Other users see none of it
+	other := registerUser("history-other@example.com")
+	w = makeRequestAs(other, http.MethodGet, "/api/v1/audit", nil)
+	parseResponse(t, w, &audit)
+	assert.Equal(t, 0, audit.Count)
+	w = makeRequestAs(other, http.MethodGet, path+"/history", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHistory_Revert(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Draft", Tags: []string{"work"}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+
+	w = makePatchRequest(path, map[string]interface{}{"content": "Final", "tags": []string{}, "priority": "high"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = makeConditionalRequest(http.MethodPost, path+"/revert", models.RevertTaskRequest{Version: 1},
+		map[string]string{"If-Match": `"1"`})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = makeRequest(http.MethodPost, path+"/revert", models.RevertTaskRequest{Version: 1})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var reverted models.TaskResponse
+	parseResponse(t, w, &reverted)
+	assert.Equal(t, "Draft", reverted.Content)
+	assert.Equal(t, []string{"work"}, reverted.Tags)
+	assert.Equal(t, "none", reverted.Priority)
+	assert.Equal(t, uint(3), reverted.Version)
+
+	w = makeRequest(http.MethodPost, path+"/revert", models.RevertTaskRequest{Version: 7})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "VERSION_NOT_FOUND")
+
+	// Reverting is recorded as an update
+	w = makeRequest(http.MethodGet, path+"/history?limit=1", nil)
+	var history models.TaskEventListResponse
+	parseResponse(t, w, &history)
+	require.Equal(t, 1, history.Count)
+	assert.Equal(t, "updated", history.Events[0].Type)
+	assert.NotEmpty(t, history.NextCursor)
+}
@@ -0,0 +1,45 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+func TestCreateTask_IdempotencyKey(t *testing.T) {
+	cleanupTasks(t)
+	headers := map[string]string{"Idempotency-Key": "3f0c1a52-create-milk"}
+
+	w := makeConditionalRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Buy milk"}, headers)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created models.TaskResponse
+	parseResponse(t, w, &created)
+
+	// A retry gets the same response without creating another task
+	w = makeConditionalRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Buy milk"}, headers)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	var replayed models.TaskResponse
+	parseResponse(t, w, &replayed)
+	assert.Equal(t, created.ID, replayed.ID)
+
+	w = makeRequest(http.MethodGet, "/api/v1/tasks", nil)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	assert.Equal(t, 1, list.Count)
+
+	// The key cannot be reused for a different task
+	w = makeConditionalRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Buy bread"}, headers)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Keys are scoped to their user
+	other := registerUser("idempotency-other@example.com")
+	w = makeRequestWithHeaders(other, http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Buy milk"}, headers)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+}
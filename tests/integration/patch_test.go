@@ -0,0 +1,77 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+func TestPatchTask_MergePatch(t *testing.T) {
+	cleanupTasks(t)
+
+	dueAt := "2026-10-20"
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{
+		Content: "Buy milk", Tags: []string{"home"}, Priority: "high", DueAt: &dueAt,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created models.TaskResponse
+	parseResponse(t, w, &created)
+
+	w = makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", created.ID), map[string]interface{}{
+		"content": "Buy oat milk", "due_at": nil,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var patched models.TaskResponse
+	parseResponse(t, w, &patched)
+	assert.Equal(t, "Buy oat milk", patched.Content)
+	assert.Nil(t, patched.DueAt)
+	assert.Equal(t, "high", patched.Priority)
+	assert.Equal(t, []string{"home"}, patched.Tags)
+	assert.Equal(t, created.Version+1, patched.Version)
+
+	w = makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", created.ID), map[string]interface{}{"content": nil})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPatchTask_JSONPatch(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Buy milk", Tags: []string{"home"}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created models.TaskResponse
+	parseResponse(t, w, &created)
+	path := fmt.Sprintf("/api/v1/tasks/%d", created.ID)
+	headers := map[string]string{"Content-Type": "application/json-patch+json"}
+
+	w = makeConditionalRequest(http.MethodPatch, path, []map[string]interface{}{
+		{"op": "test", "path": "/completed", "value": false},
+		{"op": "replace", "path": "/completed", "value": true},
+		{"op": "add", "path": "/tags/0", "value": "errands"},
+	}, headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var patched models.TaskResponse
+	parseResponse(t, w, &patched)
+	assert.True(t, patched.Completed)
+	assert.ElementsMatch(t, []string{"errands", "home"}, patched.Tags)
+
+	// The test fails now that the task is completed, so nothing changes
+	w = makeConditionalRequest(http.MethodPatch, path, []map[string]interface{}{
+		{"op": "test", "path": "/completed", "value": false},
+		{"op": "replace", "path": "/content", "value": "Buy bread"},
+	}, headers)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = makeRequest(http.MethodGet, path, nil)
+	var current models.TaskResponse
+	parseResponse(t, w, &current)
+	assert.Equal(t, "Buy milk", current.Content)
+
+	w = makeRequest(http.MethodPatch, path, map[string]interface{}{"content": "Buy bread"})
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
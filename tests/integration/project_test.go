@@ -0,0 +1,137 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// createProject creates a project for the test user
+func createProject(t *testing.T, name string) models.ProjectResponse {
+	t.Helper()
+	w := makeRequest(http.MethodPost, "/api/v1/projects", models.CreateProjectRequest{Name: name})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create project: %s", w.Body.String())
+	}
+	var project models.ProjectResponse
+	parseResponse(t, w, &project)
+	return project
+}
+
+// createTaskIn creates a task for the test user in the project
+func createTaskIn(t *testing.T, content string, projectID uint) models.TaskResponse {
+	t.Helper()
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: content, ProjectID: &projectID})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create task: %s", w.Body.String())
+	}
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	return task
+}
+
+func TestProjects_CRUD(t *testing.T) {
+	cleanupTasks(t)
+
+	work := createProject(t, "Work")
+	home := createProject(t, "Home")
+	assert.Equal(t, work.Position+1, home.Position)
+
+	archived := true
+	w := makeRequest(http.MethodPut, fmt.Sprintf("/api/v1/projects/%d", home.ID), models.UpdateProjectRequest{Archived: &archived})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeRequest(http.MethodGet, "/api/v1/projects", nil)
+	var list models.ProjectListResponse
+	parseResponse(t, w, &list)
+	assert.Equal(t, 1, list.Count)
+
+	w = makeRequest(http.MethodGet, "/api/v1/projects?include_archived=true", nil)
+	parseResponse(t, w, &list)
+	assert.Equal(t, 2, list.Count)
+
+	w = makeRequest(http.MethodGet, "/api/v1/projects/999999", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response models.ErrorResponse
+	parseResponse(t, w, &response)
+	assert.Equal(t, "PROJECT_NOT_FOUND", response.Error.Code)
+}
+
+func TestProjects_TasksAndMoving(t *testing.T) {
+	cleanupTasks(t)
+
+	work := createProject(t, "Work")
+	home := createProject(t, "Home")
+	task := createTaskIn(t, "Write report", work.ID)
+	makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Inbox task"})
+
+	w := makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/projects/%d/tasks", work.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	assert.Equal(t, 1, list.Count)
+
+	w = makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", task.ID), models.UpdateTaskRequest{ProjectID: &home.ID})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var moved models.TaskResponse
+	parseResponse(t, w, &moved)
+	assert.Equal(t, home.ID, *moved.ProjectID)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/projects/%d/tasks", work.ID), nil)
+	parseResponse(t, w, &list)
+	assert.Equal(t, 0, list.Count)
+
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?project_id=0", nil)
+	parseResponse(t, w, &list)
+	assert.Equal(t, 1, list.Count)
+	assert.Equal(t, "Inbox task", list.Tasks[0].Content)
+}
+
+func TestProjects_DeleteMovesTasksToInbox(t *testing.T) {
+	cleanupTasks(t)
+
+	work := createProject(t, "Work")
+	task := createTaskIn(t, "Write report", work.ID)
+
+	w := makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/projects/%d", work.ID), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d", task.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.TaskResponse
+	parseResponse(t, w, &response)
+	assert.Nil(t, response.ProjectID)
+}
+
+func TestProjects_DeleteCascade(t *testing.T) {
+	cleanupTasks(t)
+
+	work := createProject(t, "Work")
+	task := createTaskIn(t, "Write report", work.ID)
+
+	w := makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/projects/%d?tasks=cascade", work.ID), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d", task.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProjects_OtherUsersProjectNotFound(t *testing.T) {
+	cleanupTasks(t)
+	cleanupUser(t, "intruder@example.com")
+	intruder := registerUser("intruder@example.com")
+
+	work := createProject(t, "Work")
+
+	w := makeRequestAs(intruder, http.MethodGet, fmt.Sprintf("/api/v1/projects/%d/tasks", work.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = makeRequestAs(intruder, http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Sneaky", ProjectID: &work.ID})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,76 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/events"
+	"github.com/todo-api-go-sda/internal/middleware"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/realtime"
+)
+
+// dialChannel opens the realtime channel as a browser would, with the
+// token offered as a subprotocol
+func dialChannel(t *testing.T, server *httptest.Server, token string) (*websocket.Conn, *http.Response, error) {
+	dialer := websocket.Dialer{Subprotocols: []string{realtime.Protocol, middleware.WebSocketTokenPrefix + token}}
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/ws", nil)
+	if conn != nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+// readMessage reads the next message of the channel
+func readMessage(t *testing.T, conn *websocket.Conn) realtime.Message {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var message realtime.Message
+	require.NoError(t, conn.ReadJSON(&message))
+	return message
+}
+
+func TestRealtime_Channel(t *testing.T) {
+	cleanupTasks(t)
+	server := httptest.NewServer(testRouter)
+	defer server.Close()
+
+	_, resp, err := dialChannel(t, server, "not-a-token")
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	conn, resp, err := dialChannel(t, server, testToken)
+	require.NoError(t, err)
+	assert.Equal(t, realtime.Protocol, resp.Header.Get("Sec-WebSocket-Protocol"))
+	assert.Equal(t, realtime.TypeWelcome, readMessage(t, conn).Type)
+
+	inbox := uint(0)
+	require.NoError(t, conn.WriteJSON(realtime.Request{ID: "1", Type: realtime.TypeSubscribe, ProjectID: &inbox}))
+	assert.Equal(t, realtime.Message{Type: realtime.TypeReply, ReplyTo: "1"}, readMessage(t, conn))
+
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "From REST"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	event := readMessage(t, conn)
+	assert.Equal(t, events.TaskCreated, event.Type)
+	assert.Contains(t, string(event.Data), "From REST")
+
+	require.NoError(t, conn.WriteJSON(realtime.Request{ID: "2", Type: realtime.TypeCreateTask, Task: &models.CreateTaskRequest{Content: "From the board"}}))
+	reply := readMessage(t, conn)
+	require.Equal(t, realtime.TypeReply, reply.Type, reply.Error)
+	assert.Equal(t, "2", reply.ReplyTo)
+	assert.Equal(t, events.TaskCreated, readMessage(t, conn).Type)
+
+	w = makeRequest(http.MethodGet, "/api/v1/tasks", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	assert.Equal(t, 2, list.Count)
+}
@@ -0,0 +1,104 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+func TestRecurringTask_CompleteCreatesNextOccurrence(t *testing.T) {
+	cleanupTasks(t)
+
+	dueAt := "2026-10-19T09:00:00Z"
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{
+		Content: "Standup", DueAt: &dueAt, Recurrence: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3", Tags: []string{"work"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var first models.TaskResponse
+	parseResponse(t, w, &first)
+	require.NotNil(t, first.SeriesID)
+	assert.Equal(t, first.ID, *first.SeriesID)
+	assert.Equal(t, 1, first.Occurrence)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d/occurrences?count=5", first.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var preview models.OccurrencesResponse
+	parseResponse(t, w, &preview)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 26, 9, 0, 0, 0, time.UTC),
+	}, preview.Occurrences)
+
+	completed := true
+	w = makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", first.ID), models.UpdateTaskRequest{Completed: &completed})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks?series_id=%d&sort=created_at", first.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var series models.TaskListResponse
+	parseResponse(t, w, &series)
+	require.Equal(t, 2, series.Count)
+	assert.True(t, series.Tasks[0].Completed)
+	assert.Empty(t, series.Tasks[0].Recurrence)
+	next := series.Tasks[1]
+	assert.False(t, next.Completed)
+	assert.Equal(t, 2, next.Occurrence)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3", next.Recurrence)
+	assert.Equal(t, []string{"work"}, next.Tags)
+	require.NotNil(t, next.DueAt)
+	assert.True(t, next.DueAt.Equal(time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestRecurringTask_EndSeries(t *testing.T) {
+	cleanupTasks(t)
+
+	dueAt := "2026-10-19"
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{
+		Content: "Water plants", DueAt: &dueAt, Recurrence: "FREQ=DAILY",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var first models.TaskResponse
+	parseResponse(t, w, &first)
+
+	completed := true
+	w = makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", first.ID), models.UpdateTaskRequest{Completed: &completed})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d/recurrence", first.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var current models.TaskResponse
+	parseResponse(t, w, &current)
+	assert.NotEqual(t, first.ID, current.ID)
+	assert.Empty(t, current.Recurrence)
+
+	w = makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", current.ID), models.UpdateTaskRequest{Completed: &completed})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?completed=false", nil)
+	var open models.TaskListResponse
+	parseResponse(t, w, &open)
+	assert.Equal(t, 0, open.Count)
+
+	w = makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d/recurrence", first.ID), nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestRecurringTask_Validation(t *testing.T) {
+	cleanupTasks(t)
+
+	dueAt := "2026-10-19"
+	for _, req := range []models.CreateTaskRequest{
+		{Content: "No due date", Recurrence: "FREQ=DAILY"},
+		{Content: "Hourly", DueAt: &dueAt, Recurrence: "FREQ=HOURLY"},
+		{Content: "Bad weekday", DueAt: &dueAt, Recurrence: "FREQ=WEEKLY;BYDAY=XY"},
+	} {
+		w := makeRequest(http.MethodPost, "/api/v1/tasks", req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, req.Content)
+	}
+}
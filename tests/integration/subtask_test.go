@@ -0,0 +1,96 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// createSubtask creates a task for the test user below the given parent,
+// or at the top level when parentID is 0
+func createSubtask(t *testing.T, content string, parentID uint) models.TaskResponse {
+	t.Helper()
+	req := models.CreateTaskRequest{Content: content}
+	if parentID != 0 {
+		req.ParentID = &parentID
+	}
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	return task
+}
+
+func TestSubtasks_TreeAndRollUp(t *testing.T) {
+	cleanupTasks(t)
+
+	parent := createSubtask(t, "Move house", 0)
+	packing := createSubtask(t, "Pack", parent.ID)
+	createSubtask(t, "Book movers", parent.ID)
+	createSubtask(t, "Buy boxes", packing.ID)
+
+	w := makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d/subtasks", parent.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	require.Equal(t, 2, list.Count)
+	assert.Equal(t, "Pack", list.Tasks[0].Content)
+	assert.Equal(t, int64(1), list.Tasks[0].SubtaskCount)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d?include=subtasks", parent.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var tree models.TaskResponse
+	parseResponse(t, w, &tree)
+	assert.Equal(t, int64(2), tree.SubtaskCount)
+	require.Len(t, tree.Subtasks, 2)
+	require.Len(t, tree.Subtasks[0].Subtasks, 1)
+	assert.Equal(t, "Buy boxes", tree.Subtasks[0].Subtasks[0].Content)
+
+	w = makeRequest(http.MethodPut, fmt.Sprintf("/api/v1/tasks/%d", parent.ID), models.ReplaceTaskRequest{
+		TaskDocument: models.TaskDocument{Content: parent.Content, Completed: true}, CompleteSubtasks: true,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?completed=false", nil)
+	parseResponse(t, w, &list)
+	assert.Equal(t, 0, list.Count)
+}
+
+func TestSubtasks_MaxDepthAndCycles(t *testing.T) {
+	cleanupTasks(t)
+
+	root := createSubtask(t, "Level 0", 0)
+	parentID := root.ID
+	for level := 1; level <= 3; level++ {
+		parentID = createSubtask(t, fmt.Sprintf("Level %d", level), parentID).ID
+	}
+
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Level 4", ParentID: &parentID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", root.ID), models.UpdateTaskRequest{ParentID: &parentID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSubtasks_DeletePolicy(t *testing.T) {
+	cleanupTasks(t)
+
+	parent := createSubtask(t, "Move house", 0)
+	child := createSubtask(t, "Pack", parent.ID)
+
+	path := fmt.Sprintf("/api/v1/tasks/%d", parent.ID)
+	w := makeRequest(http.MethodDelete, path, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = makeRequest(http.MethodDelete, path+"?subtasks=cascade", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d", child.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,92 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// createTagged creates a task for the test user carrying the tags
+func createTagged(t *testing.T, content string, tags ...string) models.TaskResponse {
+	t.Helper()
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: content, Tags: tags})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create task: %s", w.Body.String())
+	}
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	return task
+}
+
+// listTags returns the test user's tags keyed by name
+func listTags(t *testing.T) map[string]models.TagSummaryResponse {
+	t.Helper()
+	w := makeRequest(http.MethodGet, "/api/v1/tags", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list models.TagListResponse
+	parseResponse(t, w, &list)
+	tags := make(map[string]models.TagSummaryResponse, list.Count)
+	for _, tag := range list.Tags {
+		tags[tag.Name] = tag
+	}
+	return tags
+}
+
+func TestTags_CreateAndFilter(t *testing.T) {
+	cleanupTasks(t)
+
+	both := createTagged(t, "Fix login", "Urgent", "backend")
+	assert.Equal(t, []string{"backend", "urgent"}, both.Tags)
+	createTagged(t, "Call plumber", "urgent")
+	createTagged(t, "Wait for review", "waiting")
+
+	w := makeRequest(http.MethodGet, "/api/v1/tasks?tag=urgent&tag=backend", nil)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	assert.Equal(t, 1, list.Count)
+
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?tag=backend&tag=waiting&tag_mode=any", nil)
+	parseResponse(t, w, &list)
+	assert.Equal(t, 2, list.Count)
+
+	tags := listTags(t)
+	assert.Equal(t, int64(2), tags["urgent"].TaskCount)
+
+	w = makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", both.ID), map[string]interface{}{"tags": []string{}})
+	var updated models.TaskResponse
+	parseResponse(t, w, &updated)
+	assert.Empty(t, updated.Tags)
+}
+
+func TestTags_RenameAndMerge(t *testing.T) {
+	cleanupTasks(t)
+
+	task := createTagged(t, "Fix login", "bug", "defect")
+	createTagged(t, "Fix logout", "defect")
+	tags := listTags(t)
+
+	w := makeRequest(http.MethodPut, fmt.Sprintf("/api/v1/tags/%d", tags["defect"].ID), models.RenameTagRequest{Name: "bug"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = makeRequest(http.MethodPost, fmt.Sprintf("/api/v1/tags/%d/merge", tags["defect"].ID),
+		models.MergeTagRequest{TargetID: tags["bug"].ID})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	tags = listTags(t)
+	assert.Len(t, tags, 1)
+	assert.Equal(t, int64(2), tags["bug"].TaskCount)
+
+	w = makeRequest(http.MethodPut, fmt.Sprintf("/api/v1/tags/%d", tags["bug"].ID), models.RenameTagRequest{Name: "Defect"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d", task.ID), nil)
+	var found models.TaskResponse
+	parseResponse(t, w, &found)
+	assert.Equal(t, []string{"defect"}, found.Tags)
+}
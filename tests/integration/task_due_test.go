@@ -0,0 +1,95 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+// createDue creates a task for the test user due at the given time
+func createDue(t *testing.T, content string, dueAt time.Time) models.TaskResponse {
+	t.Helper()
+	due := dueAt.Format(time.RFC3339)
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: content, DueAt: &due})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	return task
+}
+
+// listView returns the contents of the tasks in a due date view
+func listView(t *testing.T, path string) []string {
+	t.Helper()
+	w := makeRequest(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	contents := make([]string, len(list.Tasks))
+	for i, task := range list.Tasks {
+		contents[i] = task.Content
+	}
+	return contents
+}
+
+func TestDueDates_Views(t *testing.T) {
+	cleanupTasks(t)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	createDue(t, "Overdue", now.Add(-time.Minute))
+	createDue(t, "Later today", today.Add(24*time.Hour-time.Second))
+	createDue(t, "In three days", today.AddDate(0, 0, 3).Add(time.Hour))
+	createDue(t, "Next month", today.AddDate(0, 1, 1))
+	done := createDue(t, "Done", now.Add(-time.Hour))
+	completed := true
+	w := makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", done.ID), models.UpdateTaskRequest{Completed: &completed})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, []string{"Overdue"}, listView(t, "/api/v1/tasks/overdue"))
+	assert.Contains(t, listView(t, "/api/v1/tasks/today"), "Later today")
+	assert.Equal(t, []string{"In three days"}, listView(t, "/api/v1/tasks/upcoming?days=7"))
+	assert.Len(t, listView(t, "/api/v1/tasks/upcoming?days=60"), 2)
+	assert.Equal(t, []string{"Done"}, listView(t, "/api/v1/tasks/overdue?completed=true"))
+}
+
+func TestDueDates_CompletedAt(t *testing.T) {
+	cleanupTasks(t)
+
+	task := createDue(t, "File taxes", time.Now().Add(time.Hour))
+	assert.Nil(t, task.CompletedAt)
+
+	completed := true
+	w := makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", task.ID), models.UpdateTaskRequest{Completed: &completed})
+	parseResponse(t, w, &task)
+	require.NotNil(t, task.CompletedAt)
+
+	completed = false
+	w = makePatchRequest(fmt.Sprintf("/api/v1/tasks/%d", task.ID), models.UpdateTaskRequest{Completed: &completed})
+	parseResponse(t, w, &task)
+	assert.Nil(t, task.CompletedAt)
+}
+
+func TestDueDates_UserTimeZone(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequest(http.MethodPut, "/api/v1/me", models.UpdateProfileRequest{TimeZone: "Asia/Tokyo"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	defer makeRequest(http.MethodPut, "/api/v1/me", models.UpdateProfileRequest{TimeZone: models.DefaultTimeZone})
+
+	due := "2026-04-15T09:00"
+	w = makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Standup", DueAt: &due})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	assert.Equal(t, time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC), task.DueAt.UTC())
+
+	w = makeRequest(http.MethodPut, "/api/v1/me", models.UpdateProfileRequest{TimeZone: "Mars/Olympus_Mons"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
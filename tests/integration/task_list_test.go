@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -44,3 +45,89 @@ func TestListTasks_EmptyList(t *testing.T) {
 	assert.Equal(t, 0, response.Count)
 	assert.Empty(t, response.Tasks)
 }
+
+func TestListTasks_FilterAndSort(t *testing.T) {
+	cleanupTasks(t)
+
+	for _, content := range []string{"Buy milk", "Buy bread", "Walk the dog"} {
+		makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: content})
+	}
+
+	w := makeRequest(http.MethodGet, "/api/v1/tasks?completed=false&content_contains=buy&sort=content", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TaskListResponse
+	parseResponse(t, w, &response)
+
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, "Buy bread", response.Tasks[0].Content)
+	assert.Equal(t, "Buy milk", response.Tasks[1].Content)
+}
+
+func TestListTasks_InvalidSort(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequest(http.MethodGet, "/api/v1/tasks?sort=-id", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response models.ErrorResponse
+	parseResponse(t, w, &response)
+
+	assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+	assert.Contains(t, response.Error.Message, "sort")
+}
+
+func TestListTasks_Pagination(t *testing.T) {
+	cleanupTasks(t)
+
+	for i := 1; i <= 5; i++ {
+		makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: fmt.Sprintf("Task %d", i)})
+	}
+
+	w := makeRequest(http.MethodGet, "/api/v1/tasks?limit=2&include_total=true", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var first models.TaskListResponse
+	parseResponse(t, w, &first)
+
+	assert.Equal(t, 2, first.Count)
+	assert.Equal(t, int64(5), *first.Total)
+	assert.Equal(t, "Task 5", first.Tasks[0].Content)
+	assert.NotEmpty(t, first.NextCursor)
+	assert.Empty(t, first.PrevCursor)
+
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?limit=2&cursor="+first.NextCursor, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var second models.TaskListResponse
+	parseResponse(t, w, &second)
+
+	assert.Equal(t, 2, second.Count)
+	assert.Nil(t, second.Total)
+	assert.Equal(t, "Task 3", second.Tasks[0].Content)
+	assert.NotEmpty(t, second.PrevCursor)
+
+	w = makeRequest(http.MethodGet, "/api/v1/tasks?limit=2&cursor="+second.PrevCursor, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var back models.TaskListResponse
+	parseResponse(t, w, &back)
+
+	assert.Equal(t, "Task 5", back.Tasks[0].Content)
+}
+
+func TestListTasks_TamperedCursor(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequest(http.MethodGet, "/api/v1/tasks?cursor=eyJpIjoxfQ.AAAA", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response models.ErrorResponse
+	parseResponse(t, w, &response)
+
+	assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+	assert.Contains(t, response.Error.Message, "cursor")
+}
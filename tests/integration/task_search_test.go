@@ -0,0 +1,43 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+func TestSearchTasks_RankedMatches(t *testing.T) {
+	cleanupTasks(t)
+
+	makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Buy milk"})
+	makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Milk the cow and buy more milk"})
+	makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Walk the dog"})
+
+	w := makeRequest(http.MethodGet, "/api/v1/tasks/search?q=milk", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.TaskListResponse
+	parseResponse(t, w, &response)
+
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, "Milk the cow and buy more milk", response.Tasks[0].Content)
+	assert.Contains(t, response.Tasks[0].Snippet, "<mark>")
+}
+
+func TestSearchTasks_MissingQuery(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequest(http.MethodGet, "/api/v1/tasks/search", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response models.ErrorResponse
+	parseResponse(t, w, &response)
+
+	assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+}
@@ -21,8 +21,7 @@ func TestUpdateTask_UpdateContent(t *testing.T) {
 	parseResponse(t, createW, &created)
 
 	// Update content
-	newContent := "Buy organic groceries"
-	updateReq := models.UpdateTaskRequest{Content: &newContent}
+	updateReq := models.TaskDocument{Content: "Buy organic groceries"}
 	w := makeRequest(http.MethodPut, fmt.Sprintf("/api/v1/tasks/%d", created.ID), updateReq)
 
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -45,8 +44,7 @@ func TestUpdateTask_MarkCompleted(t *testing.T) {
 	parseResponse(t, createW, &created)
 
 	// Mark as completed
-	completed := true
-	updateReq := models.UpdateTaskRequest{Completed: &completed}
+	updateReq := models.TaskDocument{Content: "Buy groceries", Completed: true}
 	w := makeRequest(http.MethodPut, fmt.Sprintf("/api/v1/tasks/%d", created.ID), updateReq)
 
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -68,9 +66,7 @@ func TestUpdateTask_UpdateBothFields(t *testing.T) {
 	parseResponse(t, createW, &created)
 
 	// Update both fields
-	newContent := "Buy organic groceries"
-	completed := true
-	updateReq := models.UpdateTaskRequest{Content: &newContent, Completed: &completed}
+	updateReq := models.TaskDocument{Content: "Buy organic groceries", Completed: true}
 	w := makeRequest(http.MethodPut, fmt.Sprintf("/api/v1/tasks/%d", created.ID), updateReq)
 
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -85,8 +81,7 @@ func TestUpdateTask_UpdateBothFields(t *testing.T) {
 func TestUpdateTask_NotFound(t *testing.T) {
 	cleanupTasks(t)
 
-	newContent := "Updated content"
-	updateReq := models.UpdateTaskRequest{Content: &newContent}
+	updateReq := models.TaskDocument{Content: "Updated content"}
 	w := makeRequest(http.MethodPut, "/api/v1/tasks/999", updateReq)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
@@ -96,3 +91,28 @@ func TestUpdateTask_NotFound(t *testing.T) {
 
 	assert.Equal(t, "TASK_NOT_FOUND", response.Error.Code)
 }
+
+func TestUpdateTask_ReplacesEveryField(t *testing.T) {
+	cleanupTasks(t)
+
+	dueAt := "2026-10-20"
+	createReq := models.CreateTaskRequest{Content: "Buy groceries", Tags: []string{"home"}, Priority: "high", DueAt: &dueAt}
+	createW := makeRequest(http.MethodPost, "/api/v1/tasks", createReq)
+	var created models.TaskResponse
+	parseResponse(t, createW, &created)
+
+	// Fields missing from the body are cleared
+	w := makeRequest(http.MethodPut, fmt.Sprintf("/api/v1/tasks/%d", created.ID), map[string]interface{}{"content": "Buy bread"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response models.TaskResponse
+	parseResponse(t, w, &response)
+
+	assert.Equal(t, "Buy bread", response.Content)
+	assert.Empty(t, response.Tags)
+	assert.Equal(t, "none", response.Priority)
+	assert.Nil(t, response.DueAt)
+
+	w = makeRequest(http.MethodPut, fmt.Sprintf("/api/v1/tasks/%d", created.ID), map[string]interface{}{"completed": true})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,90 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/models"
+)
+
+func TestTrash_DeleteAndRestore(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Plan trip"})
+	var parent models.TaskResponse
+	parseResponse(t, w, &parent)
+	w = makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Book hotel", ParentID: &parent.ID})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var child models.TaskResponse
+	parseResponse(t, w, &child)
+
+	w = makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d?subtasks=cascade", parent.ID), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = makeRequest(http.MethodGet, "/api/v1/tasks", nil)
+	var list models.TaskListResponse
+	parseResponse(t, w, &list)
+	assert.Equal(t, 0, list.Count)
+
+	w = makeRequest(http.MethodGet, "/api/v1/trash", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var trash models.TaskListResponse
+	parseResponse(t, w, &trash)
+	// Subtasks deleted with their parent are listed through the parent only
+	require.Equal(t, 1, trash.Count)
+	assert.Equal(t, parent.ID, trash.Tasks[0].ID)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d?include=trashed", parent.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var trashed models.TaskResponse
+	parseResponse(t, w, &trashed)
+	assert.NotNil(t, trashed.DeletedAt)
+
+	// The subtask cannot come back before its parent
+	w = makeRequest(http.MethodPost, fmt.Sprintf("/api/v1/tasks/%d/restore", child.ID), nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = makeRequest(http.MethodPost, fmt.Sprintf("/api/v1/tasks/%d/restore", parent.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var restored models.TaskResponse
+	parseResponse(t, w, &restored)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, int64(1), restored.SubtaskCount)
+
+	w = makeRequest(http.MethodGet, "/api/v1/trash", nil)
+	parseResponse(t, w, &trash)
+	assert.Equal(t, 0, trash.Count)
+}
+
+func TestTrash_DeletePermanently(t *testing.T) {
+	cleanupTasks(t)
+
+	w := makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Old note"})
+	var created models.TaskResponse
+	parseResponse(t, w, &created)
+
+	// Only tasks in the trash can be deleted permanently
+	w = makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/trash/%d", created.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d", created.ID), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/trash/%d", created.ID), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = makeRequest(http.MethodPost, fmt.Sprintf("/api/v1/tasks/%d/restore", created.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// The audit log keeps the history of the task, ending with its purge
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/audit?task_id=%d", created.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var audit models.TaskEventListResponse
+	parseResponse(t, w, &audit)
+	require.Equal(t, 3, audit.Count)
+	assert.Equal(t, models.TaskEventPurged, audit.Events[0].Type)
+}
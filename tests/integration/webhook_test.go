@@ -0,0 +1,220 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/todo-api-go-sda/internal/config"
+	"github.com/todo-api-go-sda/internal/models"
+	"github.com/todo-api-go-sda/internal/repository"
+	"github.com/todo-api-go-sda/internal/services"
+)
+
+// webhookReceiver records the event types and IDs of the deliveries it
+// receives, answering with status
+type webhookReceiver struct {
+	*httptest.Server
+	mu         sync.Mutex
+	status     int
+	events     []string
+	deliveries []string
+}
+
+func newWebhookReceiver(t *testing.T, secret string, status int) *webhookReceiver {
+	r := &webhookReceiver{status: status}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		assert.Equal(t, services.SignWebhookPayload(secret, body), req.Header.Get(services.WebhookSignatureHeader))
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, req.Header.Get(services.WebhookEventHeader))
+		r.deliveries = append(r.deliveries, req.Header.Get(services.WebhookDeliveryHeader))
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// newTestDispatcher creates a dispatcher for the test database handling
+// batchSize messages and deliveries at a time
+func newTestDispatcher(maxAttempts, batchSize int) *services.WebhookDispatcher {
+	return services.NewWebhookDispatcher(repository.NewWebhookRepository(testDB), &config.WebhookConfig{
+		Timeout:        5 * time.Second,
+		Lease:          time.Minute,
+		MaxAttempts:    maxAttempts,
+		RetryBaseDelay: time.Minute,
+		RetryMaxDelay:  time.Hour,
+		BatchSize:      batchSize,
+	})
+}
+
+// deliverWebhooks dispatches the outbox and attempts due deliveries once
+func deliverWebhooks(t *testing.T, maxAttempts int) {
+	t.Helper()
+	dispatcher := newTestDispatcher(maxAttempts, 100)
+	_, err := dispatcher.Dispatch()
+	require.NoError(t, err)
+	_, err = dispatcher.Deliver(context.Background())
+	require.NoError(t, err)
+}
+
+func TestWebhooks_DeliverTaskEvents(t *testing.T) {
+	cleanupTasks(t)
+	receiver := newWebhookReceiver(t, "s3cret", http.StatusOK)
+
+	w := makeRequest(http.MethodPost, "/api/v1/webhooks", models.CreateWebhookRequest{
+		URL: receiver.URL, Secret: "s3cret", Events: []string{"task.created", "task.completed"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var webhook models.CreateWebhookResponse
+	parseResponse(t, w, &webhook)
+	assert.Equal(t, "s3cret", webhook.Secret)
+
+	w = makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Ship it"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+	w = makePatchRequest(path, map[string]interface{}{"content": "Ship it today"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = makePatchRequest(path, map[string]interface{}{"completed": true})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	deliverWebhooks(t, 3)
+	assert.Equal(t, []string{"task.created", "task.completed"}, receiver.events)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/webhooks/%d/deliveries", webhook.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var deliveries models.WebhookDeliveryListResponse
+	parseResponse(t, w, &deliveries)
+	require.Equal(t, 2, deliveries.Count)
+	assert.Equal(t, "task.completed", deliveries.Deliveries[0].Type)
+	assert.Equal(t, models.DeliverySucceeded, deliveries.Deliveries[0].Status)
+	assert.Equal(t, 1, deliveries.Deliveries[0].Attempts)
+
+	// Other users cannot see the webhook
+	other := registerUser("webhook-other@example.com")
+	w = makeRequestAs(other, http.MethodGet, fmt.Sprintf("/api/v1/webhooks/%d", webhook.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebhooks_DeadLetterAndRedeliver(t *testing.T) {
+	cleanupTasks(t)
+	receiver := newWebhookReceiver(t, "s3cret", http.StatusInternalServerError)
+
+	w := makeRequest(http.MethodPost, "/api/v1/webhooks", models.CreateWebhookRequest{URL: receiver.URL, Secret: "s3cret"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var webhook models.CreateWebhookResponse
+	parseResponse(t, w, &webhook)
+
+	w = makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Flaky"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	deliverWebhooks(t, 1)
+
+	w = makeRequest(http.MethodGet, "/api/v1/webhooks/dead-letters", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var dead models.WebhookDeliveryListResponse
+	parseResponse(t, w, &dead)
+	require.Equal(t, 1, dead.Count)
+	delivery := dead.Deliveries[0]
+	assert.Equal(t, models.DeliveryDead, delivery.Status)
+	assert.Equal(t, http.StatusInternalServerError, *delivery.ResponseStatus)
+
+	receiver.mu.Lock()
+	receiver.status = http.StatusNoContent
+	receiver.mu.Unlock()
+	w = makeRequest(http.MethodPost, fmt.Sprintf("/api/v1/webhooks/%d/deliveries/%d/redeliver", webhook.ID, delivery.ID), nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	deliverWebhooks(t, 1)
+
+	w = makeRequest(http.MethodGet, fmt.Sprintf("/api/v1/webhooks/%d/deliveries?status=succeeded", webhook.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var succeeded models.WebhookDeliveryListResponse
+	parseResponse(t, w, &succeeded)
+	require.Equal(t, 1, succeeded.Count)
+	assert.Equal(t, delivery.ID, succeeded.Deliveries[0].ID)
+	assert.Equal(t, []string{"task.created", "task.created"}, receiver.events)
+}
+
+func TestWebhooks_DeliverRestoresAndPurges(t *testing.T) {
+	cleanupTasks(t)
+	receiver := newWebhookReceiver(t, "s3cret", http.StatusOK)
+
+	w := makeRequest(http.MethodPost, "/api/v1/webhooks", models.CreateWebhookRequest{
+		URL: receiver.URL, Secret: "s3cret", Events: []string{"task.restored", "task.purged"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: "Old note"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var task models.TaskResponse
+	parseResponse(t, w, &task)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+	w = makeRequest(http.MethodDelete, path, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = makeRequest(http.MethodPost, path+"/restore", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = makeRequest(http.MethodDelete, path, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	w = makeRequest(http.MethodDelete, fmt.Sprintf("/api/v1/trash/%d", task.ID), nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	deliverWebhooks(t, 3)
+	assert.Equal(t, []string{"task.restored", "task.purged"}, receiver.events)
+}
+
+func TestWebhooks_ConcurrentDispatchersDeliverOnce(t *testing.T) {
+	cleanupTasks(t)
+	receiver := newWebhookReceiver(t, "s3cret", http.StatusOK)
+
+	w := makeRequest(http.MethodPost, "/api/v1/webhooks", models.CreateWebhookRequest{URL: receiver.URL, Secret: "s3cret", Events: []string{"task.created"}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	const tasks = 40
+	for i := 0; i < tasks; i++ {
+		w = makeRequest(http.MethodPost, "/api/v1/tasks", models.CreateTaskRequest{Content: fmt.Sprintf("Task %d", i)})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// Two replicas dispatch the outbox and deliver in small batches at the
+	// same time until neither finds anything left to do
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		dispatcher := newTestDispatcher(3, 5)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				dispatched, err := dispatcher.Dispatch()
+				assert.NoError(t, err)
+				attempted, err := dispatcher.Deliver(context.Background())
+				assert.NoError(t, err)
+				if err != nil || dispatched+attempted == 0 {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	deliverWebhooks(t, 3)
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	seen := map[string]int{}
+	for _, id := range receiver.deliveries {
+		seen[id]++
+	}
+	assert.Len(t, seen, tasks)
+	for id, count := range seen {
+		assert.Equal(t, 1, count, "delivery %s", id)
+	}
+}